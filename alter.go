@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NewColumn is a column added by AlterStream and AlterTable
+type NewColumn struct {
+	Name string
+	// Value is a value of the Go type of the column, its ksql type is
+	// rendered with SchemaOf, ex. int32(0) is INTEGER
+	Value interface{}
+}
+
+// AlterStream runs ALTER STREAM name ADD COLUMN ... with the columns, waits
+// until the command is completed and returns the updated schema of the stream.
+func (api *KsqldbClient) AlterStream(ctx context.Context, name string, columns ...NewColumn) (*SourceSchema, error) {
+	return api.alterSource(ctx, "STREAM", name, columns)
+}
+
+// AlterTable runs ALTER TABLE name ADD COLUMN ... with the columns, waits
+// until the command is completed and returns the updated schema of the table.
+func (api *KsqldbClient) AlterTable(ctx context.Context, name string, columns ...NewColumn) (*SourceSchema, error) {
+	return api.alterSource(ctx, "TABLE", name, columns)
+}
+
+func (api *KsqldbClient) alterSource(ctx context.Context, typ string, name string, columns []NewColumn) (*SourceSchema, error) {
+	stmnt, err := alterStatement(typ, name, columns)
+	if err != nil {
+		return nil, fmt.Errorf("can't alter %v: %w", name, err)
+	}
+	response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+	if err != nil {
+		return nil, fmt.Errorf("can't alter %v: %w", name, err)
+	}
+	if len(*response) == 0 {
+		return nil, fmt.Errorf("can't alter %v: %w: no command status", name, ErrNotFound)
+	}
+	if _, err := api.waitForCommand(ctx, (*response)[0]); err != nil {
+		return nil, fmt.Errorf("can't alter %v: %w", name, err)
+	}
+	return api.SourceSchema(ctx, name)
+}
+
+// alterStatement returns ALTER typ name ADD COLUMN col type, ADD COLUMN ...;
+func alterStatement(typ string, name string, columns []NewColumn) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("no columns to add")
+	}
+	adds := make([]string, len(columns))
+	for idx, col := range columns {
+		if col.Name == "" {
+			return "", fmt.Errorf("column %v has no name", idx)
+		}
+		schema, err := SchemaOf(col.Value)
+		if err != nil {
+			return "", fmt.Errorf("column %v: %w", col.Name, err)
+		}
+		adds[idx] = fmt.Sprintf("ADD COLUMN %v %v", quoteFieldName(col.Name), schema)
+	}
+	return fmt.Sprintf("ALTER %v %v %v;", typ, quoteFieldName(name), strings.Join(adds, ", ")), nil
+}
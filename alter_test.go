@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestAlterStream(t *testing.T) {
+	m := mockKsql(t, []string{"ALTER STREAM DOGS ADD COLUMN AGE INTEGER, ADD COLUMN TAGS ARRAY<STRING>;", "DESCRIBE DOGS;"}, []string{
+		`[{"@type":"currentStatus","statementText":"ALTER STREAM DOGS ADD COLUMN AGE INTEGER, ADD COLUMN TAGS ARRAY<STRING>;","commandId":"stream/DOGS/alter","commandStatus":{"status":"QUEUED","message":"Statement written to command topic"}}]`,
+		describeDogs,
+	})
+	m.Mock.On("Get", "http://localhost/status/stream/DOGS/alter").Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status":"SUCCESS","message":"Stream DOGS altered"}`))),
+	}, nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+
+	schema, err := kcl.AlterStream(context.Background(), "DOGS",
+		ksqldb.NewColumn{Name: "AGE", Value: int32(0)},
+		ksqldb.NewColumn{Name: "TAGS", Value: []string{}})
+	require.Nil(t, err)
+	col, ok := schema.Column("TAGS")
+	require.True(t, ok)
+	require.Equal(t, "ARRAY<STRING>", col.Schema.String())
+	m.AssertExpectations(t)
+}
+
+func TestAlterTable_Struct(t *testing.T) {
+	type address struct {
+		Zip  int64  `ksql:"ZIP"`
+		City string `ksql:"CITY"`
+	}
+	stmnt := "ALTER TABLE DOGS ADD COLUMN ADDRESS STRUCT<`ZIP` BIGINT, `CITY` STRING>;"
+	m := mockKsql(t, []string{stmnt}, []string{
+		`[{"@type":"currentStatus","commandId":"table/DOGS/alter","commandStatus":{"status":"ERROR","message":"Cannot add column ADDRESS"}}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.AlterTable(context.Background(), "DOGS", ksqldb.NewColumn{Name: "ADDRESS", Value: address{}})
+	require.Equal(t, "can't alter DOGS: statement error: command table/DOGS/alter failed: Cannot add column ADDRESS", err.Error())
+}
+
+func TestAlterStream_InvalidColumns(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockKsql(t, nil, nil))
+
+	_, err := kcl.AlterStream(context.Background(), "DOGS")
+	require.Equal(t, "can't alter DOGS: no columns to add", err.Error())
+
+	_, err = kcl.AlterStream(context.Background(), "DOGS", ksqldb.NewColumn{Name: "SIZES", Value: map[int]string{}})
+	require.Equal(t, "can't alter DOGS: column SIZES: unsupported param type: map[int]string, map keys must be strings", err.Error())
+}
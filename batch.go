@@ -0,0 +1,113 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DEFAULT_BATCH_ROWS is the default max number of rows of a batch
+	DEFAULT_BATCH_ROWS = 100
+	// DEFAULT_BATCH_WAIT is the default max wait of a batch
+	DEFAULT_BATCH_WAIT = 100 * time.Millisecond
+)
+
+// BatchOptions configures the batches of PushBatch
+type BatchOptions struct {
+	// MaxRows is the max number of rows of a batch; defaults to DEFAULT_BATCH_ROWS
+	MaxRows int
+	// MaxWait is the max time the first row of a batch waits for delivery;
+	// defaults to DEFAULT_BATCH_WAIT
+	MaxWait time.Duration
+}
+
+// PushBatch is Push delivering the rows in batches instead of single rows.
+// A batch is delivered when it has MaxRows rows or MaxWait after its first row.
+// The rows received when the query finishes are delivered as last batch unless
+// ctx is done, then batchChannel is closed.
+func (api *KsqldbClient) PushBatch(ctx context.Context, sql string, options BatchOptions, batchChannel chan<- Payload, headerChannel chan<- Header) error {
+	maxRows := options.MaxRows
+	if maxRows < 1 {
+		maxRows = DEFAULT_BATCH_ROWS
+	}
+	maxWait := options.MaxWait
+	if maxWait <= 0 {
+		maxWait = DEFAULT_BATCH_WAIT
+	}
+
+	rowChannel := make(chan Row, maxRows)
+	batched := make(chan struct{})
+	go func() {
+		defer close(batched)
+		batchRows(ctx, rowChannel, batchChannel, maxRows, maxWait)
+	}()
+
+	err := api.Push(ctx, sql, rowChannel, headerChannel)
+	// Push doesn't close the channel if the query can't be started
+	closeRowChannel(rowChannel)
+	<-batched
+	return err
+}
+
+// batchRows collects the rows of rowChannel into batches until rowChannel is closed
+func batchRows(ctx context.Context, rowChannel <-chan Row, batchChannel chan<- Payload, maxRows int, maxWait time.Duration) {
+	defer close(batchChannel)
+
+	var batch Payload
+	var timeout <-chan time.Time
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		// prefer delivery, like deliverRow
+		select {
+		case batchChannel <- batch:
+		default:
+			select {
+			case batchChannel <- batch:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		batch = nil
+		timeout = nil
+		return true
+	}
+
+	for {
+		select {
+		case row, ok := <-rowChannel:
+			if !ok {
+				flush()
+				return
+			}
+			if len(batch) == 0 {
+				timeout = time.After(maxWait)
+			}
+			batch = append(batch, row)
+			if len(batch) >= maxRows && !flush() {
+				return
+			}
+		case <-timeout:
+			if !flush() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestPushBatch(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batchChannel := make(chan ksqldb.Payload, 3)
+	done := make(chan error)
+	go func() {
+		done <- kcl.PushBatch(ctx, "select * from dogs emit changes;", ksqldb.BatchOptions{MaxRows: 2, MaxWait: 20 * time.Millisecond}, batchChannel, nil)
+	}()
+
+	// full batch
+	go m.send(streamHeader, `["a"]`, `["b"]`, `["c"]`)
+	require.Equal(t, ksqldb.Payload{{"a"}, {"b"}}, <-batchChannel)
+	// batch after MaxWait
+	require.Equal(t, ksqldb.Payload{{"c"}}, <-batchChannel)
+
+	cancel()
+	require.Nil(t, <-done)
+	_, ok := <-batchChannel
+	require.False(t, ok)
+}
+
+func TestPushBatch_Error(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(newStreamMock())
+	batchChannel := make(chan ksqldb.Payload)
+	err := kcl.PushBatch(context.Background(), "select * from;", ksqldb.BatchOptions{}, batchChannel, nil)
+	require.NotNil(t, err)
+	_, ok := <-batchChannel
+	require.False(t, ok)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bridge streams the rows of push queries to browsers with
+// Server-Sent Events or WebSockets:
+//
+//	http.Handle("/dogs", bridge.SSEHandler(&client, "SELECT * FROM DOGS EMIT CHANGES;", bridge.SSEOptions{}))
+//	http.Handle("/ws/dogs", bridge.WebSocketHandler(&client, "SELECT * FROM DOGS EMIT CHANGES;", bridge.WebSocketOptions{}))
+//
+// The connections of a handler share one push query, see
+// ksqldb.SubscribeShared and ksqldb.Hub. Rows are sent as JSON objects keyed by the
+// column names, ex. {"ID":"1","NAME":"Rover"}.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// SSEOptions configures an SSEHandler
+type SSEOptions struct {
+	// Subscribe configures the subscriptions of the connections
+	Subscribe ksqldb.SubscribeOptions
+	// Retry is the reconnect delay sent to the browsers; 0 keeps the browser default
+	Retry time.Duration
+	// Heartbeat is the interval of comment lines keeping idle connections open; 0 disables them
+	Heartbeat time.Duration
+}
+
+// columnMessage is a column of the header event
+type columnMessage struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// headerMessage is the data of the header event
+type headerMessage struct {
+	QueryId string          `json:"queryId"`
+	Columns []columnMessage `json:"columns"`
+}
+
+// SSEHandler returns a handler streaming the rows of the push query as
+// Server-Sent Events. Every connection receives a header event with the
+// columns of the query, then a message event per row:
+//
+//	event: header
+//	data: {"queryId":"transient_DOGS_1","columns":[{"name":"ID","type":"STRING"}]}
+//
+//	id: 1
+//	data: {"ID":"1"}
+//
+// The event ids count the rows of the connection. A reconnecting browser
+// sends the id of its last event in Last-Event-ID and the ids continue from
+// there, so they never repeat for the browser; rows sent while it was
+// disconnected are lost. If the query fails, an error event with the message
+// is sent before the response ends.
+func SSEHandler(client *ksqldb.KsqldbClient, sql string, options SSEOptions) http.Handler {
+	return &sseHandler{client: client, sql: sql, options: options}
+}
+
+type sseHandler struct {
+	client  *ksqldb.KsqldbClient
+	sql     string
+	options SSEOptions
+}
+
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ctx := r.Context()
+	sub, err := h.client.SubscribeShared(ctx, h.sql, h.options.Subscribe)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer sub.Unsubscribe()
+	header, err := waitForHeader(ctx, sub)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disables the response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if h.options.Retry > 0 {
+		fmt.Fprintf(w, "retry: %v\n\n", h.options.Retry.Milliseconds())
+	}
+	if err := writeEvent(w, "header", newHeaderMessage(header)); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.options.Heartbeat > 0 {
+		ticker := time.NewTicker(h.options.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	id := lastEventID(r)
+	var data bytes.Buffer
+	writer := ksqldb.NewJSONLinesWriter(&data, header)
+	for {
+		select {
+		case row, ok := <-sub.Rows():
+			if !ok {
+				if err := sub.Err(); err != nil {
+					_ = writeEvent(w, "error", err.Error())
+					flusher.Flush()
+				}
+				return
+			}
+			data.Reset()
+			if err := writer.Write(row); err != nil {
+				_ = writeEvent(w, "error", err.Error())
+				flusher.Flush()
+				return
+			}
+			id++
+			if _, err := fmt.Fprintf(w, "id: %v\ndata: %s\n\n", id, bytes.TrimSpace(data.Bytes())); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// waitForHeader waits for the header of the query until ctx is done
+func waitForHeader(ctx context.Context, sub *ksqldb.SharedSubscription) (ksqldb.Header, error) {
+	type result struct {
+		header ksqldb.Header
+		err    error
+	}
+	ready := make(chan result, 1)
+	go func() {
+		header, err := sub.Header()
+		ready <- result{header, err}
+	}()
+	select {
+	case res := <-ready:
+		return res.header, res.err
+	case <-ctx.Done():
+		return ksqldb.Header{}, ctx.Err()
+	}
+}
+
+// newHeaderMessage returns the message of the header event
+func newHeaderMessage(header ksqldb.Header) headerMessage {
+	msg := headerMessage{QueryId: header.QueryId(), Columns: []columnMessage{}}
+	for _, col := range header.Columns() {
+		msg.Columns = append(msg.Columns, columnMessage{Name: col.Name, Type: col.Type})
+	}
+	return msg
+}
+
+// writeEvent writes an event with the JSON encoded data
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %v\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// lastEventID returns the id of the last event received by a reconnecting browser; 0 for new connections
+func lastEventID(r *http.Request) int64 {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.Header.Get("Last-Event-ID")), 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
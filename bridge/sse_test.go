@@ -0,0 +1,136 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bridge_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/bridge"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// streamMock is a http client mock with an endless query stream, which
+// ends when the query is closed
+type streamMock struct {
+	mocknet.HTTPClient
+	stream *io.PipeWriter
+	closed int32
+}
+
+func newStreamMock() *streamMock {
+	m := &streamMock{}
+	pr, pw := io.Pipe()
+	m.stream = pw
+	m.On("GetUrl", ksqldb.QUERY_STREAM_ENDPOINT).Return("http://localhost/query-stream")
+	m.On("GetUrl", ksqldb.CLOSE_QUERY_ENDPOINT).Return("http://localhost/close-query")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			atomic.AddInt32(&m.closed, 1)
+			pw.Close()
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		go func() {
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: 200, Body: pr}
+	}, nil)
+	return m
+}
+
+func (m *streamMock) send(lines ...string) {
+	for _, line := range lines {
+		_, _ = m.stream.Write([]byte(line + "\n"))
+	}
+}
+
+const streamHeader = `{"queryId":"abc","columnNames":["ID","AGE"],"columnTypes":["STRING","INTEGER"]}`
+
+// readEvent reads the lines of the next event
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	lines := []string{}
+	for {
+		line, err := r.ReadString('\n')
+		require.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	srv := httptest.NewServer(bridge.SSEHandler(&kcl, "select * from dogs emit changes;", bridge.SSEOptions{
+		Retry:     time.Second,
+		Heartbeat: 20 * time.Millisecond,
+	}))
+	defer srv.Close()
+
+	go m.send(streamHeader, `["a",1]`)
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	// a reconnecting browser
+	req.Header.Set("Last-Event-ID", "5")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(res.Body)
+	require.Equal(t, []string{"retry: 1000"}, readEvent(t, r))
+	require.Equal(t, []string{
+		"event: header",
+		`data: {"queryId":"abc","columns":[{"name":"ID","type":"STRING"},{"name":"AGE","type":"INTEGER"}]}`,
+	}, readEvent(t, r))
+	require.Equal(t, []string{"id: 6", `data: {"ID":"a","AGE":1}`}, readEvent(t, r))
+	require.Equal(t, []string{": heartbeat"}, readEvent(t, r))
+	go m.send(`["b",2]`)
+	event := readEvent(t, r)
+	for event[0] == ": heartbeat" {
+		event = readEvent(t, r)
+	}
+	require.Equal(t, []string{"id: 7", `data: {"ID":"b","AGE":2}`}, event)
+
+	// the disconnect of the last browser stops the query
+	res.Body.Close()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&m.closed) == 1 }, 5*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(kcl.ActiveQueries()) == 0 }, 5*time.Second, time.Millisecond)
+}
+
+func TestSSEHandler_Error(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	srv := httptest.NewServer(bridge.SSEHandler(&kcl, "select * from;", bridge.SSEOptions{}))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadGateway, res.StatusCode)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketOptions configures a WebSocketHandler
+type WebSocketOptions struct {
+	// Hub configures the query, the buffer of the connections and the
+	// policy for connections which can't keep up, see ksqldb.HubOptions
+	Hub ksqldb.HubOptions
+	// Filter returns the row filter of the connection, ex. built from the
+	// query parameters of the request; nil filters send all rows
+	Filter func(r *http.Request, header ksqldb.Header) func(ksqldb.Row) bool
+	// CheckOrigin accepts or rejects the Origin of the handshake; nil
+	// accepts requests with a valid Origin, see websocket.Handler
+	CheckOrigin func(r *http.Request) bool
+	// WriteTimeout of the messages; 0 is unlimited
+	WriteTimeout time.Duration
+}
+
+// wsMessage is a message of the WebSocketHandler, exactly one field is set
+type wsMessage struct {
+	Header *headerMessage   `json:"header,omitempty"`
+	Row    *json.RawMessage `json:"row,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// WebSocketHandler returns a handler streaming the rows of the push query to
+// WebSocket connections. The connections subscribe to a ksqldb.Hub of the
+// query, which is started with the first connection and stopped after the
+// last one. Every connection receives the header, then the rows matching its
+// filter as JSON objects keyed by the column names:
+//
+//	{"header":{"queryId":"transient_DOGS_1","columns":[{"name":"ID","type":"STRING"}]}}
+//	{"row":{"ID":"1"}}
+//
+// Rows are filtered by the hub, only the matching rows are buffered per
+// connection with Hub.BufferSize. Connections which can't keep up are
+// handled by Hub.SlowConsumer, ex. SLOW_CONSUMER_EVICT closes them. Before
+// a connection is closed because of an error, an error message is sent,
+// ex. {"error":"slow consumer evicted"}. Messages of the browsers are ignored.
+func WebSocketHandler(client *ksqldb.KsqldbClient, sql string, options WebSocketOptions) http.Handler {
+	h := &wsHandler{hub: client.NewHub(context.Background(), sql, options.Hub), options: options}
+	if options.CheckOrigin == nil {
+		return websocket.Handler(h.serve)
+	}
+	return websocket.Server{
+		Handler: h.serve,
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if !options.CheckOrigin(r) {
+				return errors.New("origin not allowed")
+			}
+			return nil
+		},
+	}
+}
+
+type wsHandler struct {
+	hub     *ksqldb.Hub
+	options WebSocketOptions
+}
+
+func (h *wsHandler) serve(ws *websocket.Conn) {
+	defer ws.Close()
+	// the filter of the connection is built with the header, it is applied
+	// by the hub so unwanted rows are not buffered
+	var filter atomic.Value
+	sub, err := h.hub.Subscribe(func(row ksqldb.Row) bool {
+		f, _ := filter.Load().(func(ksqldb.Row) bool)
+		return f == nil || f(row)
+	})
+	if err != nil {
+		_ = h.send(ws, wsMessage{Error: err.Error()})
+		return
+	}
+	defer sub.Unsubscribe()
+
+	// the reader notices closed connections
+	go func() {
+		var ignored []byte
+		for websocket.Message.Receive(ws, &ignored) == nil {
+		}
+		sub.Unsubscribe()
+	}()
+
+	header, err := sub.Header()
+	if err != nil {
+		_ = h.send(ws, wsMessage{Error: err.Error()})
+		return
+	}
+	var rowFilter func(ksqldb.Row) bool
+	if h.options.Filter != nil {
+		if rowFilter = h.options.Filter(ws.Request(), header); rowFilter != nil {
+			filter.Store(rowFilter)
+		}
+	}
+	msg := newHeaderMessage(header)
+	if err := h.send(ws, wsMessage{Header: &msg}); err != nil {
+		return
+	}
+
+	var data bytes.Buffer
+	writer := ksqldb.NewJSONLinesWriter(&data, header)
+	for row := range sub.Rows() {
+		// rows buffered before the filter was set are filtered here
+		if rowFilter != nil && !rowFilter(row) {
+			continue
+		}
+		data.Reset()
+		if err := writer.Write(row); err != nil {
+			_ = h.send(ws, wsMessage{Error: err.Error()})
+			return
+		}
+		raw := json.RawMessage(bytes.TrimSpace(data.Bytes()))
+		if err := h.send(ws, wsMessage{Row: &raw}); err != nil {
+			return
+		}
+	}
+	if err := sub.Err(); err != nil {
+		_ = h.send(ws, wsMessage{Error: err.Error()})
+	}
+}
+
+// send sends the message as JSON text message
+func (h *wsHandler) send(ws *websocket.Conn, msg wsMessage) error {
+	if h.options.WriteTimeout > 0 {
+		_ = ws.SetWriteDeadline(time.Now().Add(h.options.WriteTimeout))
+	}
+	return websocket.JSON.Send(ws, msg)
+}
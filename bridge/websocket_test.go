@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bridge_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/bridge"
+	"golang.org/x/net/websocket"
+)
+
+func receive(t *testing.T, ws *websocket.Conn) string {
+	var msg string
+	require.Nil(t, websocket.Message.Receive(ws, &msg))
+	return msg
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	srv := httptest.NewServer(bridge.WebSocketHandler(&kcl, "select * from dogs emit changes;", bridge.WebSocketOptions{
+		Hub: ksqldb.HubOptions{BufferSize: 10},
+		Filter: func(r *http.Request, header ksqldb.Header) func(ksqldb.Row) bool {
+			id := r.URL.Query().Get("id")
+			if id == "" {
+				return nil
+			}
+			return func(row ksqldb.Row) bool { return row[0] == id }
+		},
+		WriteTimeout: time.Second,
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	all, err := websocket.Dial(url, "", "http://localhost/")
+	require.Nil(t, err)
+	filtered, err := websocket.Dial(url+"?id=b", "", "http://localhost/")
+	require.Nil(t, err)
+	// both connections are subscribed before the rows are sent
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, kcl.ActiveQueries(), 1)
+
+	go m.send(streamHeader, `["a",1]`, `["b",2]`)
+	header := `{"header":{"queryId":"abc","columns":[{"name":"ID","type":"STRING"},{"name":"AGE","type":"INTEGER"}]}}`
+	require.Equal(t, header, strings.TrimSpace(receive(t, all)))
+	require.Equal(t, `{"row":{"ID":"a","AGE":1}}`, strings.TrimSpace(receive(t, all)))
+	require.Equal(t, `{"row":{"ID":"b","AGE":2}}`, strings.TrimSpace(receive(t, all)))
+	require.Equal(t, header, strings.TrimSpace(receive(t, filtered)))
+	require.Equal(t, `{"row":{"ID":"b","AGE":2}}`, strings.TrimSpace(receive(t, filtered)))
+
+	// the query is stopped after the last connection
+	all.Close()
+	filtered.Close()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&m.closed) == 1 }, 5*time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return len(kcl.ActiveQueries()) == 0 }, 5*time.Second, time.Millisecond)
+}
+
+func TestWebSocketHandler_CheckOrigin(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	srv := httptest.NewServer(bridge.WebSocketHandler(&kcl, "select * from dogs emit changes;", bridge.WebSocketOptions{
+		CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == "https://dashboard.example.com" },
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	_, err := websocket.Dial(url, "", "https://evil.example.com")
+	require.NotNil(t, err)
+	require.Empty(t, kcl.ActiveQueries())
+}
+
+func TestWebSocketHandler_FilterBeforeBuffer(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	srv := httptest.NewServer(bridge.WebSocketHandler(&kcl, "select * from dogs emit changes;", bridge.WebSocketOptions{
+		Hub: ksqldb.HubOptions{BufferSize: 1, SlowConsumer: ksqldb.SLOW_CONSUMER_EVICT},
+		Filter: func(r *http.Request, header ksqldb.Header) func(ksqldb.Row) bool {
+			return func(row ksqldb.Row) bool { return row[0] == "b" }
+		},
+	}))
+	defer srv.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", "http://localhost/")
+	require.Nil(t, err)
+	defer ws.Close()
+	go m.send(streamHeader)
+	receive(t, ws)
+
+	// the unwanted rows don't fill the buffer of the connection
+	rows := []string{}
+	for i := 0; i < 50; i++ {
+		rows = append(rows, `["a",1]`)
+	}
+	go m.send(append(rows, `["b",2]`)...)
+	require.Equal(t, `{"row":{"ID":"b","AGE":2}}`, strings.TrimSpace(receive(t, ws)))
+}
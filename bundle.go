@@ -0,0 +1,173 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// typeWord matches the words of a column type, ex. ADDRESS in ARRAY<ADDRESS>
+var typeWord = regexp.MustCompile("[A-Za-z_][A-Za-z0-9_]*")
+
+// OrderStatements returns the statements of the sql in dependency order:
+// statements reading from or inserting into a source come after the
+// statement creating it, statements using a custom type come after the
+// CREATE TYPE. Independent statements keep their order. Sources and types
+// which are not created by the statements are expected to exist.
+//
+// Statements which depend on each other are returned as error wrapping
+// ErrDependencyCycle with the cycle, ex. dependency cycle: A -> B -> A.
+func OrderStatements(sql string) ([]string, error) {
+	stmnts, err := parser.SplitStatements(sql)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*parser.StatementNode, len(stmnts))
+	for idx, stmnt := range stmnts {
+		parsed, err := parser.Parse(stmnt.Text)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", stmnt.Line, err)
+		}
+		nodes[idx] = parsed[0]
+	}
+
+	// creators are the statements creating sources and types by name
+	creators := map[string]int{}
+	for idx, node := range nodes {
+		switch node.Kind {
+		case parser.KindCreateStream, parser.KindCreateStreamAs, parser.KindCreateTable, parser.KindCreateTableAs:
+			creators[node.Target.Name] = idx
+		case parser.KindCreateType:
+			creators[node.TypeName] = idx
+		}
+	}
+
+	deps := make([][]int, len(nodes))
+	for idx, node := range nodes {
+		names := []string{}
+		for _, source := range node.Sources() {
+			names = append(names, source.Name)
+		}
+		if node.Kind == parser.KindInsertInto || node.Kind == parser.KindInsertValues {
+			names = append(names, node.Target.Name)
+		}
+		for _, col := range node.Columns {
+			// unquoted type names are upper cased
+			names = append(names, typeWord.FindAllString(strings.ToUpper(col.Type), -1)...)
+		}
+		for _, name := range names {
+			if creator, ok := creators[name]; ok && creator != idx {
+				deps[idx] = append(deps[idx], creator)
+			}
+		}
+	}
+
+	order, cycle := topologicalOrder(deps)
+	if cycle != nil {
+		names := make([]string, len(cycle))
+		for idx, n := range cycle {
+			names[idx] = statementName(nodes[n])
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDependencyCycle, strings.Join(names, " -> "))
+	}
+	ordered := make([]string, len(order))
+	for idx, n := range order {
+		ordered[idx] = stmnts[n].Text
+	}
+	return ordered, nil
+}
+
+// statementName returns the created source or type of the statement for cycle reports
+func statementName(node *parser.StatementNode) string {
+	if node.TypeName != "" {
+		return node.TypeName
+	}
+	if node.Target != nil {
+		return node.Target.Name
+	}
+	return node.Text
+}
+
+// topologicalOrder returns the nodes ordered after their dependencies,
+// independent nodes keep their order. If the dependencies have a cycle, the
+// cycle is returned, starting and ending with the same node.
+func topologicalOrder(deps [][]int) (order []int, cycle []int) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(deps))
+	path := []int{}
+	var visit func(n int) bool
+	visit = func(n int) bool {
+		switch state[n] {
+		case done:
+			return true
+		case visiting:
+			// the cycle starts with the first visit of n
+			for idx, p := range path {
+				if p == n {
+					cycle = append(append([]int{}, path[idx:]...), n)
+					break
+				}
+			}
+			return false
+		}
+		state[n] = visiting
+		path = append(path, n)
+		for _, dep := range deps[n] {
+			if !visit(dep) {
+				return false
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		order = append(order, n)
+		return true
+	}
+	for n := range deps {
+		if !visit(n) {
+			return nil, cycle
+		}
+	}
+	return order, nil
+}
+
+// ExecuteBundle executes the statements of the sql in dependency order, see
+// OrderStatements. It stops at the first failing statement; the responses
+// of the executed statements are returned with the error.
+func (api *KsqldbClient) ExecuteBundle(ctx context.Context, sql string) ([]KsqlResponseSlice, error) {
+	stmnts, err := OrderStatements(sql)
+	if err != nil {
+		return nil, err
+	}
+	responses := []KsqlResponseSlice{}
+	for _, stmnt := range stmnts {
+		response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+		if err != nil {
+			return responses, fmt.Errorf("can't execute %v: %w", stmnt, err)
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
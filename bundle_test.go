@@ -0,0 +1,72 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+const dogsBundle = `
+INSERT INTO DOGS (ID, NAME) VALUES ('1', 'Pluto');
+CREATE TABLE DOGS_BY_SIZE AS SELECT DOG_SIZE, COUNT(*) FROM BIG_DOGS GROUP BY DOG_SIZE;
+CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOG_SIZE = 'large';
+-- the owners of the dogs
+CREATE STREAM OWNERS (ID STRING KEY, ADDRESS ARRAY<address>) WITH (KAFKA_TOPIC='owners', VALUE_FORMAT='JSON');
+CREATE STREAM DOGS (ID STRING KEY, NAME STRING, DOG_SIZE STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');
+CREATE TYPE ADDRESS AS STRUCT<STREET STRING>;
+`
+
+func TestOrderStatements(t *testing.T) {
+	stmnts, err := ksqldb.OrderStatements(dogsBundle)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"CREATE STREAM DOGS (ID STRING KEY, NAME STRING, DOG_SIZE STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');",
+		"INSERT INTO DOGS (ID, NAME) VALUES ('1', 'Pluto');",
+		"CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOG_SIZE = 'large';",
+		"CREATE TABLE DOGS_BY_SIZE AS SELECT DOG_SIZE, COUNT(*) FROM BIG_DOGS GROUP BY DOG_SIZE;",
+		"CREATE TYPE ADDRESS AS STRUCT<STREET STRING>;",
+		"CREATE STREAM OWNERS (ID STRING KEY, ADDRESS ARRAY<address>) WITH (KAFKA_TOPIC='owners', VALUE_FORMAT='JSON');",
+	}, stmnts)
+}
+
+func TestOrderStatements_Cycle(t *testing.T) {
+	_, err := ksqldb.OrderStatements(`CREATE STREAM A AS SELECT * FROM B;
+CREATE STREAM B AS SELECT * FROM C; CREATE STREAM C AS SELECT * FROM b;`)
+	require.True(t, errors.Is(err, ksqldb.ErrDependencyCycle))
+	require.Equal(t, "dependency cycle: B -> C -> B", err.Error())
+
+	_, err = ksqldb.OrderStatements(`CREATE STREAM A AS SELECT * FROM;`)
+	require.NotNil(t, err)
+}
+
+func TestExecuteBundle(t *testing.T) {
+	create := "CREATE STREAM DOGS (ID STRING KEY) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');"
+	insert := "INSERT INTO DOGS (ID) VALUES ('1');"
+	m := mockKsql(t, []string{create, insert}, []string{`[{"@type":"currentStatus","commandStatus":{"status":"SUCCESS"}}]`, `[]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	responses, err := kcl.ExecuteBundle(context.Background(), insert+"\n"+create)
+	require.Nil(t, err)
+	require.Len(t, responses, 2)
+	require.Equal(t, "SUCCESS", responses[0][0].CommandStatus.Status)
+	m.AssertExpectations(t)
+}
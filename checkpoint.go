@@ -0,0 +1,140 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import "sync"
+
+// Checkpointer tracks the processing of the rows of a push query for
+// at-least-once downstream processing. The consumer acknowledges every
+// processed row; once all rows up to a row are acknowledged, the checkpoint
+// func is called with that row, ex. to persist its ROWTIME. After a restart
+// the query is replayed from the earliest offset and the rows up to the
+// persisted checkpoint are skipped, ex. with WHERE ROWTIME > <checkpoint>.
+//
+//	cp := ksqldb.NewCheckpointer(100, func(row ksqldb.Row) error {
+//		return store.Save(row[0]) // ROWTIME
+//	})
+//	for tracked := range cp.Track(rowChannel) {
+//		process(tracked.Row)
+//		if err := tracked.Ack(); err != nil {
+//			...
+//		}
+//	}
+//	err := cp.Flush()
+//
+// It is safe for concurrent use, rows can be acknowledged out of order.
+type Checkpointer struct {
+	every      uint64
+	checkpoint func(Row) error
+
+	mu sync.Mutex
+	// next is the sequence number of the next tracked row
+	next uint64
+	// done is the sequence number of the first row not acknowledged
+	done uint64
+	// acked are the acknowledged rows from done on
+	acked map[uint64]Row
+	// last is the last row of the acknowledged prefix
+	last Row
+	// checkpointed is done at the last checkpoint
+	checkpointed uint64
+}
+
+// TrackedRow is a row tracked by a Checkpointer
+type TrackedRow struct {
+	Row Row
+	seq uint64
+	cp  *Checkpointer
+}
+
+// NewCheckpointer returns a Checkpointer which calls checkpoint after every
+// acknowledged rows; every < 1 means after every row
+func NewCheckpointer(every int, checkpoint func(Row) error) *Checkpointer {
+	if every < 1 {
+		every = 1
+	}
+	return &Checkpointer{every: uint64(every), checkpoint: checkpoint, acked: map[uint64]Row{}}
+}
+
+// Track returns the rows of rowChannel as tracked rows. The returned channel
+// is closed when rowChannel is closed.
+func (c *Checkpointer) Track(rowChannel <-chan Row) <-chan *TrackedRow {
+	tracked := make(chan *TrackedRow, cap(rowChannel))
+	go func() {
+		defer close(tracked)
+		for row := range rowChannel {
+			tracked <- c.track(row)
+		}
+	}()
+	return tracked
+}
+
+func (c *Checkpointer) track(row Row) *TrackedRow {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &TrackedRow{Row: row, seq: c.next, cp: c}
+	c.next++
+	return t
+}
+
+// Ack acknowledges the row as processed. The error of the checkpoint func is returned.
+func (r *TrackedRow) Ack() error {
+	return r.cp.ack(r.seq, r.Row)
+}
+
+func (c *Checkpointer) ack(seq uint64, row Row) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if seq < c.done {
+		// acknowledged twice
+		return nil
+	}
+	c.acked[seq] = row
+	for {
+		next, ok := c.acked[c.done]
+		if !ok {
+			break
+		}
+		delete(c.acked, c.done)
+		c.last = next
+		c.done++
+	}
+	if c.done-c.checkpointed < c.every {
+		return nil
+	}
+	return c.save()
+}
+
+// Flush calls the checkpoint func with the last row of the acknowledged
+// prefix if it was not checkpointed yet
+func (c *Checkpointer) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == c.checkpointed {
+		return nil
+	}
+	return c.save()
+}
+
+// save calls the checkpoint func, c.mu must be held
+func (c *Checkpointer) save() error {
+	if err := c.checkpoint(c.last); err != nil {
+		return err
+	}
+	c.checkpointed = c.done
+	return nil
+}
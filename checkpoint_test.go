@@ -0,0 +1,86 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func trackRows(cp *ksqldb.Checkpointer, rows ...ksqldb.Row) []*ksqldb.TrackedRow {
+	rowChannel := make(chan ksqldb.Row, len(rows))
+	for _, row := range rows {
+		rowChannel <- row
+	}
+	close(rowChannel)
+	var tracked []*ksqldb.TrackedRow
+	for t := range cp.Track(rowChannel) {
+		tracked = append(tracked, t)
+	}
+	return tracked
+}
+
+func TestCheckpointer(t *testing.T) {
+	var checkpoints []ksqldb.Row
+	cp := ksqldb.NewCheckpointer(1, func(row ksqldb.Row) error {
+		checkpoints = append(checkpoints, row)
+		return nil
+	})
+	rows := trackRows(cp, ksqldb.Row{1.0}, ksqldb.Row{2.0}, ksqldb.Row{3.0})
+	require.Len(t, rows, 3)
+	require.Equal(t, ksqldb.Row{1.0}, rows[0].Row)
+
+	// the first row is not processed yet
+	require.Nil(t, rows[1].Ack())
+	require.Empty(t, checkpoints)
+	require.Nil(t, rows[0].Ack())
+	require.Equal(t, []ksqldb.Row{{2.0}}, checkpoints)
+	require.Nil(t, rows[0].Ack())
+	require.Nil(t, rows[2].Ack())
+	require.Equal(t, []ksqldb.Row{{2.0}, {3.0}}, checkpoints)
+	require.Nil(t, cp.Flush())
+	require.Len(t, checkpoints, 2)
+}
+
+func TestCheckpointer_Every(t *testing.T) {
+	var checkpoints []ksqldb.Row
+	fail := false
+	cp := ksqldb.NewCheckpointer(2, func(row ksqldb.Row) error {
+		if fail {
+			return errors.New("store down")
+		}
+		checkpoints = append(checkpoints, row)
+		return nil
+	})
+	rows := trackRows(cp, ksqldb.Row{1.0}, ksqldb.Row{2.0}, ksqldb.Row{3.0})
+
+	require.Nil(t, rows[0].Ack())
+	require.Empty(t, checkpoints)
+	require.Nil(t, rows[1].Ack())
+	require.Equal(t, []ksqldb.Row{{2.0}}, checkpoints)
+	require.Nil(t, rows[2].Ack())
+	require.Len(t, checkpoints, 1)
+
+	fail = true
+	require.Equal(t, "store down", cp.Flush().Error())
+	fail = false
+	require.Nil(t, cp.Flush())
+	require.Equal(t, []ksqldb.Row{{2.0}, {3.0}}, checkpoints)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
+	"time"
 
 	"github.com/thmeitz/ksqldb-go/net"
 )
@@ -12,11 +14,68 @@ import (
 type BodyReader func(io.Reader) ([]byte, error)
 type RespUnmarshaller func([]byte, interface{}) error
 
+// KsqldbClient is the client of a ksqlDB server.
+//
+// One client can be shared by goroutines: statements, pull and push queries,
+// the query handles and Close and Shutdown are safe for concurrent use, also
+// while other queries are in flight. Configure the client, ex. with SetTimeouts,
+// EnableParseSQL or WithDebug, before it is shared; the configuration methods
+// are not synchronized.
 type KsqldbClient struct {
 	http          net.HTTPClient
 	parseSQL      bool
+	strictTypes   bool
 	readBody      BodyReader
 	unMarshalResp RespUnmarshaller
+	// queries holds the active push queries
+	queries *queryRegistry
+	// shared holds the push queries shared by subscriptions, see SubscribeShared
+	shared *sharedQueries
+	// state is the connectivity state of the client, see WatchState
+	state *clientState
+	// closer retries the failed close requests of push queries, see PendingCloses
+	closer *backgroundCloser
+	// totals are the counters of all push queries of the client, see PublishExpvar
+	totals *QueryMetrics
+	// onQueryError is called with failing push queries
+	onQueryError func(*QueryHandle, error)
+	// readBufferSize and maxRowSize of push query responses
+	readBufferSize int
+	maxRowSize     int
+	// maxResponseSize of statement and pull query responses
+	maxResponseSize int
+	// rowPool enables pooled rows of push queries
+	rowPool bool
+	// decoder of push query responses; nil is the default decoder
+	decoder Decoder
+	// rowBufferSize and overflowPolicy of the row buffer of push queries
+	rowBufferSize  int
+	overflowPolicy OverflowPolicy
+	// legacyQuery uses the /query endpoint for push queries
+	legacyQuery bool
+	// pullHosts are the hosts of pull query routing
+	pullHosts []*url.URL
+	// lagRouting routes pull queries by the lags of the hosts
+	lagRouting *lagCache
+	// dedupe drops duplicate rows of push queries
+	dedupe *DedupeOptions
+	// pullCache caches the results of pull queries
+	pullCache *pullCache
+	// pullFlights coalesces identical pull queries
+	pullFlights *flightGroup
+	// timeouts of statements, pull and push queries
+	timeouts Timeouts
+	// retryPolicy of statements and pull queries
+	retryPolicy RetryPolicy
+	// tableScanPolicy of pull queries, tableKeys caches the key columns of the checked tables
+	tableScanPolicy TableScanPolicy
+	tableKeys       *tableKeys
+	onTableScan     func(sql string, reason string)
+	// closeVerification checks with SHOW QUERIES that closed push queries are removed
+	closeVerification bool
+	// decodeProfiling times the decode stages of push queries, onDecodeStage is called with the times
+	decodeProfiling bool
+	onDecodeStage   func(*QueryHandle, DecodeStage, time.Duration)
 }
 
 // NewClient returns a new KsqldbClient with the given net.HTTPclient
@@ -26,6 +85,12 @@ func NewClient(http net.HTTPClient) (KsqldbClient, error) {
 		parseSQL:      true,
 		readBody:      ioutil.ReadAll,
 		unMarshalResp: json.Unmarshal,
+		queries:       newQueryRegistry(),
+		shared:        &sharedQueries{queries: map[string]*sharedQuery{}},
+		state:         newClientState(),
+		closer:        newBackgroundCloser(),
+		totals:        NewQueryMetrics(),
+		timeouts:      Timeouts{Statement: DEFAULT_STATEMENT_TIMEOUT, Pull: DEFAULT_PULL_TIMEOUT},
 	}
 
 	return client, nil
@@ -35,7 +100,7 @@ func NewClient(http net.HTTPClient) (KsqldbClient, error) {
 func NewClientWithOptions(options net.Options) (KsqldbClient, error) {
 	http, err := net.NewHTTPClient(options, nil)
 	if err != nil {
-		return KsqldbClient{}, fmt.Errorf("%v", err)
+		return KsqldbClient{}, fmt.Errorf("%w", err)
 	}
 
 	return NewClient(&http)
@@ -51,7 +116,24 @@ func (cl *KsqldbClient) ParseSQLEnabled() bool {
 	return cl.parseSQL
 }
 
-// Close closes the underlying http transport
+// Close closes the underlying http transport. New statements and queries
+// fail with ErrClientShutdown, active push queries are stopped without
+// waiting for them, see Shutdown for a graceful shutdown. In-flight
+// statements and pull queries complete or fail, pending close requests of
+// push queries are abandoned, see PendingCloses.
+//
+// Close is safe to call concurrently and more than once.
 func (cl *KsqldbClient) Close() {
+	if cl.queries != nil {
+		for _, q := range cl.queries.close() {
+			q.cancel()
+		}
+	}
+	if cl.state != nil {
+		cl.state.set(CLIENT_CLOSED)
+	}
+	if cl.closer != nil {
+		cl.closer.stop()
+	}
 	cl.http.Close()
 }
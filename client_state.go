@@ -0,0 +1,146 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ClientState is the connectivity state of a client, see WatchState
+type ClientState string
+
+const (
+	// CLIENT_CONNECTED is the state of a client whose last request got a response
+	CLIENT_CONNECTED ClientState = "connected"
+	// CLIENT_DEGRADED is the state of a client whose last request failed without
+	// a response, ex. a connection error or a timeout, or got a 5xx response
+	CLIENT_DEGRADED ClientState = "degraded"
+	// CLIENT_CLOSED is the final state of a closed client, see Close and Shutdown
+	CLIENT_CLOSED ClientState = "closed"
+)
+
+// clientState holds the state of a client and its watchers
+type clientState struct {
+	// reconnects counts the changes from CLIENT_DEGRADED to CLIENT_CONNECTED
+	reconnects int64
+	mu         sync.Mutex
+	state      ClientState
+	done       chan struct{}
+	watchers   map[chan ClientState]struct{}
+}
+
+func newClientState() *clientState {
+	return &clientState{
+		state:    CLIENT_CONNECTED,
+		done:     make(chan struct{}),
+		watchers: map[chan ClientState]struct{}{},
+	}
+}
+
+// set changes the state and notifies the watchers, closed is final
+func (s *clientState) set(state ClientState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == state || s.state == CLIENT_CLOSED {
+		return
+	}
+	if s.state == CLIENT_DEGRADED && state == CLIENT_CONNECTED {
+		atomic.AddInt64(&s.reconnects, 1)
+	}
+	s.state = state
+	for ch := range s.watchers {
+		// the watchers get the latest state, stale states are dropped
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- state
+	}
+	if state == CLIENT_CLOSED {
+		close(s.done)
+		for ch := range s.watchers {
+			close(ch)
+			delete(s.watchers, ch)
+		}
+	}
+}
+
+// report sets the state by the outcome of a request
+func (s *clientState) report(res *http.Response, err error) {
+	switch {
+	case s == nil || errors.Is(err, context.Canceled):
+	case err != nil, res.StatusCode >= http.StatusInternalServerError:
+		s.set(CLIENT_DEGRADED)
+	default:
+		s.set(CLIENT_CONNECTED)
+	}
+}
+
+// State returns the current state of the client. It is set by the outcome
+// of the requests: CLIENT_CONNECTED after responses, CLIENT_DEGRADED after
+// connection errors, timeouts and 5xx responses; CLIENT_CLOSED after Close.
+func (cl *KsqldbClient) State() ClientState {
+	cl.state.mu.Lock()
+	defer cl.state.mu.Unlock()
+	return cl.state.state
+}
+
+// Done returns a channel which is closed when the client is closed, see Close and Shutdown
+func (cl *KsqldbClient) Done() <-chan struct{} {
+	return cl.state.done
+}
+
+// WatchState returns a channel receiving the current state of the client
+// and every change, ex. to react to cluster-wide connectivity problems. A
+// watcher which doesn't keep up receives the latest state only. The channel
+// is closed after CLIENT_CLOSED or when ctx is done.
+//
+//	for state := range client.WatchState(ctx) {
+//		if state == ksqldb.CLIENT_DEGRADED {
+//			// ex. mark the service unhealthy
+//		}
+//	}
+func (cl *KsqldbClient) WatchState(ctx context.Context) <-chan ClientState {
+	s := cl.state
+	ch := make(chan ClientState, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch <- s.state
+	if s.state == CLIENT_CLOSED {
+		close(ch)
+		return ch
+	}
+	s.watchers[ch] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.done:
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.watchers[ch]; ok {
+			delete(s.watchers, ch)
+			close(ch)
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func infoResponse(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(bytes.NewReader([]byte(`{}`)))}
+}
+
+func TestClientState(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.INFO_ENDPOINT).Return("http://localhost/info")
+	m.Mock.On("Close").Return()
+	m.Mock.On("Do", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	m.Mock.On("Do", mock.Anything).Return(infoResponse(200), nil).Once()
+	m.Mock.On("Do", mock.Anything).Return(infoResponse(503), nil).Once()
+
+	kcl, _ := ksqldb.NewClient(&m)
+	states := kcl.WatchState(context.Background())
+	require.Equal(t, ksqldb.CLIENT_CONNECTED, <-states)
+	require.Equal(t, ksqldb.CLIENT_CONNECTED, kcl.State())
+
+	_, _ = kcl.Ping(context.Background())
+	require.Equal(t, ksqldb.CLIENT_DEGRADED, <-states)
+	_, _ = kcl.Ping(context.Background())
+	require.Equal(t, ksqldb.CLIENT_CONNECTED, <-states)
+	_, _ = kcl.Ping(context.Background())
+	require.Equal(t, ksqldb.CLIENT_DEGRADED, kcl.State())
+
+	select {
+	case <-kcl.Done():
+		t.Fatal("client is not closed")
+	default:
+	}
+	kcl.Close()
+	<-kcl.Done()
+	// the slow watcher gets the latest state only
+	require.Equal(t, ksqldb.CLIENT_CLOSED, <-states)
+	_, ok := <-states
+	require.False(t, ok)
+	require.Equal(t, ksqldb.CLIENT_CLOSED, kcl.State())
+
+	// watchers of closed clients get the final state
+	states = kcl.WatchState(context.Background())
+	require.Equal(t, ksqldb.CLIENT_CLOSED, <-states)
+	_, ok = <-states
+	require.False(t, ok)
+}
+
+func TestClientState_WatchCancelled(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	kcl, _ := ksqldb.NewClient(&m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	states := kcl.WatchState(ctx)
+	require.Equal(t, ksqldb.CLIENT_CONNECTED, <-states)
+	cancel()
+	_, ok := <-states
+	require.False(t, ok)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// CLOSE_RETRY_INTERVAL is the first pause before a failed close request of a push query is retried
+	CLOSE_RETRY_INTERVAL = 500 * time.Millisecond
+	// CLOSE_RETRY_MAX_INTERVAL is the maximum pause between the retries of a close request
+	CLOSE_RETRY_MAX_INTERVAL = 30 * time.Second
+	// closeRequestTimeout is the timeout of a retried close request
+	closeRequestTimeout = 10 * time.Second
+)
+
+// backgroundCloser retries the failed close requests of push queries until the
+// server confirms them, so stopped queries don't keep running on the server.
+// Its goroutine runs while close requests are pending.
+type backgroundCloser struct {
+	mu      sync.Mutex
+	pending map[string]*pendingClose
+	running bool
+	stopped bool
+	// wake wakes the goroutine up to check for new requests or the stop
+	wake chan struct{}
+	// idle is closed when the goroutine ends
+	idle chan struct{}
+}
+
+// pendingClose is a close request waiting for its retry
+type pendingClose struct {
+	ctx      context.Context
+	close    func(ctx context.Context) error
+	attempts int
+	next     time.Time
+}
+
+func newBackgroundCloser() *backgroundCloser {
+	return &backgroundCloser{pending: map[string]*pendingClose{}, wake: make(chan struct{}, 1)}
+}
+
+// closeQueryOrRetry closes the push query on the server and verifies the close,
+// see EnableCloseVerification. If the close fails, it is retried in the
+// background and the error is returned.
+func (api *KsqldbClient) closeQueryOrRetry(ctx context.Context, queryId string) error {
+	err := api.closeAndVerify(ctx, queryId)
+	if err != nil && queryId != "" && api.closer != nil && !queryGone(err) {
+		api.closer.add(detachedContext{ctx}, queryId, func(ctx context.Context) error {
+			return api.closeAndVerify(ctx, queryId)
+		})
+	}
+	return err
+}
+
+// PendingCloses returns the ids of the push queries whose close requests
+// failed and are retried in the background, with backoff from
+// CLOSE_RETRY_INTERVAL to CLOSE_RETRY_MAX_INTERVAL, until the server
+// confirms them. Shutdown waits for them, Close abandons them.
+func (api *KsqldbClient) PendingCloses() []string {
+	if api.closer == nil {
+		return []string{}
+	}
+	return api.closer.queryIds()
+}
+
+// queryGone returns true if the server doesn't know the query, so there is nothing left to close
+func queryGone(err error) bool {
+	var respErr ResponseError
+	return errors.As(err, &respErr) && (respErr.StatusCode == http.StatusBadRequest || respErr.StatusCode == http.StatusNotFound)
+}
+
+// add adds the close request of the query; requests added after stop are dropped
+func (c *backgroundCloser) add(ctx context.Context, queryId string, close func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return
+	}
+	if _, ok := c.pending[queryId]; ok {
+		return
+	}
+	c.pending[queryId] = &pendingClose{ctx: ctx, close: close, attempts: 1, next: time.Now().Add(CLOSE_RETRY_INTERVAL)}
+	if !c.running {
+		c.running = true
+		c.idle = make(chan struct{})
+		go c.run()
+		return
+	}
+	c.signal()
+}
+
+// signal wakes the goroutine up
+func (c *backgroundCloser) signal() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run retries the due requests until no request is pending or the closer is stopped
+func (c *backgroundCloser) run() {
+	for {
+		c.mu.Lock()
+		if c.stopped || len(c.pending) == 0 {
+			c.running = false
+			close(c.idle)
+			c.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		due := []string{}
+		wait := CLOSE_RETRY_MAX_INTERVAL
+		for queryId, p := range c.pending {
+			if d := p.next.Sub(now); d <= 0 {
+				due = append(due, queryId)
+			} else if d < wait {
+				wait = d
+			}
+		}
+		c.mu.Unlock()
+
+		if len(due) == 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-c.wake:
+			}
+			timer.Stop()
+			continue
+		}
+		for _, queryId := range due {
+			c.retry(queryId)
+		}
+	}
+}
+
+// retry sends the close request of the query again
+func (c *backgroundCloser) retry(queryId string) {
+	c.mu.Lock()
+	p, ok := c.pending[queryId]
+	stopped := c.stopped
+	c.mu.Unlock()
+	if !ok || stopped {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, closeRequestTimeout)
+	err := p.close(ctx)
+	cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil || queryGone(err) {
+		delete(c.pending, queryId)
+		return
+	}
+	backoff := CLOSE_RETRY_INTERVAL << uint(p.attempts)
+	if backoff <= 0 || backoff > CLOSE_RETRY_MAX_INTERVAL {
+		backoff = CLOSE_RETRY_MAX_INTERVAL
+	}
+	p.attempts++
+	p.next = time.Now().Add(backoff)
+}
+
+// queryIds returns the ids of the pending requests
+func (c *backgroundCloser) queryIds() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.pending))
+	for queryId := range c.pending {
+		ids = append(ids, queryId)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// wait waits until no request is pending or ctx is done
+func (c *backgroundCloser) wait(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		running, idle := c.running, c.idle
+		c.mu.Unlock()
+		if !running {
+			return nil
+		}
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// stop abandons the pending requests and ends the goroutine
+func (c *backgroundCloser) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	c.pending = map[string]*pendingClose{}
+	c.signal()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// failingCloseMock streams a push query; its close requests fail with the
+// status until failures requests failed, failures < 0 fail forever
+func failingCloseMock(status int, failures int32) (*mocknet.HTTPClient, *int32) {
+	var closes int32
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Close").Return()
+	pr, pw := io.Pipe()
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			if n := atomic.AddInt32(&closes, 1); failures < 0 || n <= failures {
+				body := `{"@type":"generic_error","error_code":50301,"message":"not available"}`
+				return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(body))}
+			}
+			pw.Close()
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		go func() {
+			_, _ = pw.Write([]byte(streamHeader + "\n"))
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: http.StatusOK, Body: pr}
+	}, nil)
+	return &m, &closes
+}
+
+func TestClient_PendingCloses(t *testing.T) {
+	m, closes := failingCloseMock(http.StatusServiceUnavailable, 2)
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	_, err = q.Header()
+	require.Nil(t, err)
+	require.NotNil(t, q.Stop())
+	require.Equal(t, []string{"abc"}, kcl.PendingCloses())
+
+	// the close request is retried until it succeeds
+	require.Eventually(t, func() bool { return len(kcl.PendingCloses()) == 0 }, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, int32(3), atomic.LoadInt32(closes))
+}
+
+func TestClient_PendingClosesQueryGone(t *testing.T) {
+	m, closes := failingCloseMock(http.StatusBadRequest, -1)
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	_, err = q.Header()
+	require.Nil(t, err)
+	err = q.Stop()
+	var respErr ksqldb.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusBadRequest, respErr.StatusCode)
+	require.Contains(t, err.Error(), "close query failed: not available")
+	// the server doesn't know the query, it is not retried
+	require.Empty(t, kcl.PendingCloses())
+	require.GreaterOrEqual(t, atomic.LoadInt32(closes), int32(1))
+}
+
+func TestShutdown_WaitsForPendingCloses(t *testing.T) {
+	m, closes := failingCloseMock(http.StatusServiceUnavailable, 2)
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	_, err = q.Header()
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Nil(t, kcl.Shutdown(ctx))
+	require.Empty(t, kcl.PendingCloses())
+	require.Equal(t, int32(3), atomic.LoadInt32(closes))
+}
+
+func TestClose_AbandonsPendingCloses(t *testing.T) {
+	m, _ := failingCloseMock(http.StatusServiceUnavailable, -1)
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	_, err = q.Header()
+	require.Nil(t, err)
+	require.NotNil(t, q.Stop())
+	require.Equal(t, []string{"abc"}, kcl.PendingCloses())
+
+	kcl.Close()
+	require.Empty(t, kcl.PendingCloses())
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+const (
+	// CLOUD_TIMEOUT is the timeout of Confluent Cloud connections, see net.Options.Timeout
+	CLOUD_TIMEOUT = 30 * time.Second
+	// CLOUD_IDLE_CONN_TIMEOUT closes idle Confluent Cloud connections before the load balancer does
+	CLOUD_IDLE_CONN_TIMEOUT = 90 * time.Second
+)
+
+// NewCloudClient returns a client of a Confluent Cloud ksqlDB cluster.
+// The endpoint is the https endpoint of the cluster, ex.
+// https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud:443; the https scheme may be omitted.
+// apiKey and apiSecret are a ksqlDB API key of the cluster, they are sent with basic auth.
+//
+// The connections use the recommended timeouts, see CLOUD_TIMEOUT and CLOUD_IDLE_CONN_TIMEOUT.
+func NewCloudClient(endpoint string, apiKey string, apiSecret string) (KsqldbClient, error) {
+	baseUrl, err := cloudBaseUrl(endpoint)
+	if err != nil {
+		return KsqldbClient{}, err
+	}
+	if apiKey == "" || apiSecret == "" {
+		return KsqldbClient{}, fmt.Errorf("%w: missing API key or secret", ErrInvalidCloudEndpoint)
+	}
+
+	return NewClientWithOptions(net.Options{
+		BaseUrl:           baseUrl,
+		Credentials:       net.Credentials{Username: apiKey, Password: apiSecret},
+		ForceAttemptHTTP2: true,
+		Timeout:           CLOUD_TIMEOUT,
+		IdleConnTimeout:   CLOUD_IDLE_CONN_TIMEOUT,
+	})
+}
+
+// cloudBaseUrl validates the endpoint and returns it without path and default port
+func cloudBaseUrl(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCloudEndpoint, err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("%w: %v must use https", ErrInvalidCloudEndpoint, endpoint)
+	}
+	if u.Port() != "" && u.Port() != "443" {
+		return "", fmt.Errorf("%w: %v must use port 443", ErrInvalidCloudEndpoint, endpoint)
+	}
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" {
+		return "", fmt.Errorf("%w: %v must not have a path", ErrInvalidCloudEndpoint, endpoint)
+	}
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasPrefix(host, "pksqlc-") || !strings.HasSuffix(host, ".confluent.cloud") {
+		return "", fmt.Errorf("%w: %v is not like https://pksqlc-xxxxx.<region>.<provider>.confluent.cloud, see the ksqlDB cluster settings in the Confluent Cloud console", ErrInvalidCloudEndpoint, endpoint)
+	}
+	return "https://" + host, nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestNewCloudClient(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud:443",
+		"pksqlc-a1b2c.us-east-2.aws.confluent.cloud",
+		"https://PKSQLC-A1B2C.westeurope.azure.confluent.cloud/",
+	} {
+		kcl, err := ksqldb.NewCloudClient(endpoint, "key", "secret")
+		require.Nil(t, err, endpoint)
+		kcl.Close()
+	}
+}
+
+func TestNewCloudClient_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"http://pksqlc-a1b2c.us-east-2.aws.confluent.cloud":       "http://pksqlc-a1b2c.us-east-2.aws.confluent.cloud must use https",
+		"https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud:8088": "https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud:8088 must use port 443",
+		"https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud/ksql": "https://pksqlc-a1b2c.us-east-2.aws.confluent.cloud/ksql must not have a path",
+		"https://lkc-a1b2c.us-east-2.aws.confluent.cloud":         "https://lkc-a1b2c.us-east-2.aws.confluent.cloud is not like https://pksqlc-xxxxx.<region>.<provider>.confluent.cloud, see the ksqlDB cluster settings in the Confluent Cloud console",
+	}
+	for endpoint, want := range tests {
+		_, err := ksqldb.NewCloudClient(endpoint, "key", "secret")
+		require.True(t, errors.Is(err, ksqldb.ErrInvalidCloudEndpoint), endpoint)
+		require.Equal(t, "invalid Confluent Cloud ksqlDB endpoint: "+want, err.Error())
+	}
+
+	_, err := ksqldb.NewCloudClient("pksqlc-a1b2c.us-east-2.aws.confluent.cloud", "key", "")
+	require.Equal(t, "invalid Confluent Cloud ksqlDB endpoint: missing API key or secret", err.Error())
+}
@@ -0,0 +1,372 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// initialisms are upper cased in Go names
+var initialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "JSON": true, "HTTP": true, "API": true, "UUID": true, "IP": true,
+}
+
+// genSource is a source with its Go names
+type genSource struct {
+	Schema   *ksqldb.SourceSchema
+	TypeName string
+	VarName  string
+	Fields   []genField
+}
+
+// genField is a struct field of a generated type
+type genField struct {
+	Name   string
+	Type   string
+	Column string
+	Key    bool
+	// Layout is the ksqldb time layout constant of DATE and TIME columns;
+	// they are inserted as formatted strings
+	Layout string
+}
+
+// genStruct is a generated struct type
+type genStruct struct {
+	Name    string
+	Comment string
+	Fields  []genField
+}
+
+type generator struct {
+	structs []genStruct
+	time    bool
+}
+
+// generate returns the formatted Go source with typed bindings for the schemas
+func generate(pkg string, schemas []*ksqldb.SourceSchema) ([]byte, error) {
+	g := generator{}
+	sources := []genSource{}
+	for _, schema := range schemas {
+		src := genSource{Schema: schema, TypeName: goName(schema.Name)}
+		src.VarName = lowerFirst(src.TypeName) + "Schema"
+		for _, col := range schema.Columns {
+			src.Fields = append(src.Fields, genField{
+				Name:   goName(col.Name),
+				Type:   g.goType(src.TypeName+goName(col.Name), col.Schema),
+				Column: col.Name,
+				Key:    col.Key,
+				Layout: timeLayout(col.Schema.Type),
+			})
+		}
+		g.structs = append(g.structs, genStruct{
+			Name:    src.TypeName,
+			Comment: fmt.Sprintf("%v is a row of the %v %v", src.TypeName, strings.ToLower(schema.Type), schema.Name),
+			Fields:  src.Fields,
+		})
+		sources = append(sources, src)
+	}
+
+	// keep the output stable
+	sort.SliceStable(g.structs, func(i, j int) bool { return g.structs[i].Name < g.structs[j].Name })
+
+	var buf bytes.Buffer
+	err := genTemplate.Execute(&buf, map[string]interface{}{
+		"Package": pkg,
+		"Time":    g.time,
+		"Structs": g.structs,
+		"Sources": sources,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't execute template: %w", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("can't format generated code: %w\n%v", err, buf.String())
+	}
+	return src, nil
+}
+
+// goType returns the Go type of the schema. STRUCT schemas are generated as named types.
+func (g *generator) goType(name string, schema ksqldb.Schema) string {
+	switch schema.Type {
+	case "BOOLEAN":
+		return "bool"
+	case "INTEGER", "INT":
+		return "int32"
+	case "BIGINT":
+		return "int64"
+	case "DOUBLE", "DECIMAL":
+		return "float64"
+	case "STRING", "VARCHAR":
+		return "string"
+	case "BYTES":
+		return "[]byte"
+	case "TIMESTAMP", "DATE", "TIME":
+		g.time = true
+		return "time.Time"
+	case "ARRAY":
+		if schema.MemberSchema == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(name+"Item", *schema.MemberSchema)
+	case "MAP":
+		if schema.MemberSchema == nil {
+			return "map[string]interface{}"
+		}
+		return "map[string]" + g.goType(name+"Value", *schema.MemberSchema)
+	case "STRUCT":
+		st := genStruct{Name: name, Comment: fmt.Sprintf("%v is a STRUCT value", name)}
+		for _, field := range schema.Fields {
+			st.Fields = append(st.Fields, genField{
+				Name:   goName(field.Name),
+				Type:   g.goType(name+goName(field.Name), field.Schema),
+				Column: field.Name,
+			})
+		}
+		g.structs = append(g.structs, st)
+		return name
+	}
+	return "interface{}"
+}
+
+// timeLayout returns the layout constant of DATE and TIME columns.
+// TIMESTAMP columns are formatted by ksqldb.QuoteLiteral.
+func timeLayout(ksqlType string) string {
+	switch ksqlType {
+	case "DATE":
+		return "KSQL_DATE_FORMAT"
+	case "TIME":
+		return "KSQL_TIME_FORMAT"
+	}
+	return ""
+}
+
+// goName converts a ksql name like DOGS_BY_SIZE into a Go name like DogsBySize
+func goName(name string) string {
+	var sb strings.Builder
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		if word != upper && word != strings.ToLower(word) {
+			// keep mixed case names like dogSize
+			runes = []rune(word)
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	result := sb.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// schemaLiteral returns the Go literal of the schema
+func schemaLiteral(schema ksqldb.Schema) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ksqldb.Schema{Type: %q", schema.Type)
+	if len(schema.Fields) > 0 {
+		sb.WriteString(", Fields: []ksqldb.Field{")
+		for idx, field := range schema.Fields {
+			if idx > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "{Name: %q, Schema: %v}", field.Name, schemaLiteral(field.Schema))
+		}
+		sb.WriteString("}")
+	}
+	if schema.MemberSchema != nil {
+		fmt.Fprintf(&sb, ", MemberSchema: &%v", schemaLiteral(*schema.MemberSchema))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// insertPrefix returns the INSERT INTO statement up to the values
+func insertPrefix(src genSource) string {
+	columns := make([]string, len(src.Fields))
+	for idx, f := range src.Fields {
+		columns[idx] = ksqldb.QuoteIdentifier(f.Column)
+	}
+	return fmt.Sprintf("INSERT INTO %v (%v) VALUES (", ksqldb.QuoteIdentifier(src.Schema.Name), strings.Join(columns, ", "))
+}
+
+var genTemplate = template.Must(template.New("gen").Funcs(template.FuncMap{
+	"schema": schemaLiteral,
+	"quote":  func(s string) string { return fmt.Sprintf("%q", s) },
+	"tag": func(f genField) string {
+		if f.Key {
+			return fmt.Sprintf("`ksql:\"%v,key\"`", f.Column)
+		}
+		return fmt.Sprintf("`ksql:\"%v\"`", f.Column)
+	},
+	"insert": insertPrefix,
+}).Parse(`// Code generated by ksqldb-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"strings"
+{{- if .Time }}
+	"time"
+{{- end }}
+
+	"github.com/thmeitz/ksqldb-go"
+)
+{{ range .Structs }}
+// {{ .Comment }}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} {{ tag . }}
+{{- end }}
+}
+{{ end }}
+{{- range .Sources }}
+{{- $src := . }}
+var {{ .VarName }} = &ksqldb.SourceSchema{
+	Name:        {{ quote .Schema.Name }},
+	Type:        {{ quote .Schema.Type }},
+	Topic:       {{ quote .Schema.Topic }},
+	KeyFormat:   {{ quote .Schema.KeyFormat }},
+	ValueFormat: {{ quote .Schema.ValueFormat }},
+	WindowType:  {{ quote .Schema.WindowType }},
+	Columns: []ksqldb.SchemaColumn{
+	{{- range .Schema.Columns }}
+		{Name: {{ quote .Name }}, {{ if .Key }}Key: true, {{ end }}Schema: {{ schema .Schema }}},
+	{{- end }}
+	},
+}
+
+// {{ .TypeName }}Schema returns the schema of {{ .Schema.Name }}
+func {{ .TypeName }}Schema() *ksqldb.SourceSchema {
+	return {{ .VarName }}
+}
+
+// Pull{{ .TypeName }} runs the pull query and returns the rows as {{ .TypeName }}
+func Pull{{ .TypeName }}(ctx context.Context, client *ksqldb.KsqldbClient, options ksqldb.QueryOptions) ([]{{ .TypeName }}, error) {
+	header, payload, err := client.Pull(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	plan := {{ .VarName }}.DecodePlan(header)
+	result := make([]{{ .TypeName }}, 0, len(payload))
+	for _, row := range payload {
+		var item {{ .TypeName }}
+		record, err := plan.Decode(row)
+		if err != nil {
+			return nil, err
+		}
+		if err := record.Scan(&item); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// Push{{ .TypeName }} runs the push query and sends the rows as {{ .TypeName }} to the channel.
+// It blocks until the context is done or the query ends.
+func Push{{ .TypeName }}(ctx context.Context, client *ksqldb.KsqldbClient, sql string, rows chan<- {{ .TypeName }}) error {
+	// the query is stopped if the rows can't be decoded or delivered
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rowChannel := make(chan ksqldb.Row)
+	headerChannel := make(chan ksqldb.Header, 1)
+	errChannel := make(chan error, 1)
+	go func() {
+		errChannel <- client.Push(ctx, sql, rowChannel, headerChannel)
+	}()
+
+	var plan *ksqldb.DecodePlan
+	for {
+		select {
+		case err := <-errChannel:
+			return err
+		case row, ok := <-rowChannel:
+			if !ok {
+				return <-errChannel
+			}
+			if plan == nil {
+				// the header is sent before the first row
+				plan = {{ .VarName }}.DecodePlan(<-headerChannel)
+			}
+			var item {{ .TypeName }}
+			record, err := plan.Decode(row)
+			if err != nil {
+				return err
+			}
+			if err := record.Scan(&item); err != nil {
+				return err
+			}
+			select {
+			case rows <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Insert{{ .TypeName }} inserts the rows into {{ .Schema.Name }}
+func Insert{{ .TypeName }}(client *ksqldb.KsqldbClient, rows ...{{ .TypeName }}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		values := []interface{}{
+		{{- range .Fields }}
+			{{ if .Layout }}row.{{ .Name }}.Format(ksqldb.{{ .Layout }}){{ else }}row.{{ .Name }}{{ end }},
+		{{- end }}
+		}
+		literals := make([]string, len(values))
+		for idx, value := range values {
+			literal, err := ksqldb.QuoteLiteral(value)
+			if err != nil {
+				return err
+			}
+			literals[idx] = literal
+		}
+		sb.WriteString({{ quote (insert .) }} + strings.Join(literals, ", ") + ");")
+	}
+	_, err := client.Execute(ksqldb.ExecOptions{KSql: sb.String()})
+	return err
+}
+{{ end }}`))
@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+var dogsSchema = &ksqldb.SourceSchema{
+	Name: "DOGS_BY_SIZE",
+	Type: "TABLE",
+	Columns: []ksqldb.SchemaColumn{
+		{Name: "ID", Key: true, Schema: ksqldb.Schema{Type: "STRING"}},
+		{Name: "DOG_SIZE", Schema: ksqldb.Schema{Type: "INTEGER"}},
+		{Name: "BORN", Schema: ksqldb.Schema{Type: "TIMESTAMP"}},
+		{Name: "DAY", Schema: ksqldb.Schema{Type: "DATE"}},
+		{Name: "WALK", Schema: ksqldb.Schema{Type: "TIME"}},
+		{Name: "TAGS", Schema: ksqldb.Schema{Type: "ARRAY", MemberSchema: &ksqldb.Schema{Type: "STRING"}}},
+		{Name: "ADDRESS", Schema: ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{
+			{Name: "STREET", Schema: ksqldb.Schema{Type: "STRING"}},
+		}}},
+	},
+}
+
+func TestGoName(t *testing.T) {
+	require.Equal(t, "DogsBySize", goName("DOGS_BY_SIZE"))
+	require.Equal(t, "OwnerID", goName("OWNER_ID"))
+	require.Equal(t, "DogSize", goName("dogSize"))
+	require.Equal(t, "X1st", goName("1ST"))
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("dogs", []*ksqldb.SourceSchema{dogsSchema})
+	require.Nil(t, err)
+	code := string(src)
+
+	require.True(t, strings.HasPrefix(code, "// Code generated by ksqldb-gen. DO NOT EDIT.\n\npackage dogs\n"))
+	require.Contains(t, code, "\"time\"")
+	require.Contains(t, code, "type DogsBySize struct {")
+	require.Contains(t, code, "ID      string            `ksql:\"ID,key\"`")
+	require.Contains(t, code, "DogSize int32             `ksql:\"DOG_SIZE\"`")
+	require.Contains(t, code, "Tags    []string          `ksql:\"TAGS\"`")
+	require.Contains(t, code, "Address DogsBySizeAddress `ksql:\"ADDRESS\"`")
+	require.Contains(t, code, "type DogsBySizeAddress struct {")
+	require.Contains(t, code, "{Name: \"TAGS\", Schema: ksqldb.Schema{Type: \"ARRAY\", MemberSchema: &ksqldb.Schema{Type: \"STRING\"}}},")
+	require.Contains(t, code, "func PullDogsBySize(ctx context.Context, client *ksqldb.KsqldbClient, options ksqldb.QueryOptions) ([]DogsBySize, error)")
+	require.Contains(t, code, "func PushDogsBySize(ctx context.Context, client *ksqldb.KsqldbClient, sql string, rows chan<- DogsBySize) error")
+	require.Contains(t, code, "ctx, cancel := context.WithCancel(ctx)\n\tdefer cancel()\n")
+	require.Contains(t, code, "row.Born,\n")
+	require.Contains(t, code, "row.Day.Format(ksqldb.KSQL_DATE_FORMAT),\n")
+	require.Contains(t, code, "row.Walk.Format(ksqldb.KSQL_TIME_FORMAT),\n")
+	require.Contains(t, code, "func InsertDogsBySize(client *ksqldb.KsqldbClient, rows ...DogsBySize) error")
+	require.Contains(t, code, "sb.WriteString(\"INSERT INTO `DOGS_BY_SIZE` (`ID`, `DOG_SIZE`, `BORN`, `DAY`, `WALK`, `TAGS`, `ADDRESS`) VALUES (\" + strings.Join(literals, \", \") + \");\")")
+}
+
+func TestGenerate_Compiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	src, err := generate("dogs", []*ksqldb.SourceSchema{dogsSchema})
+	require.Nil(t, err)
+
+	// the package must be inside of the module to import ksqldb
+	dir, err := ioutil.TempDir(".", "_generated")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "dogs.go"), src, 0600))
+
+	out, err := exec.Command(gobin, "vet", "./"+filepath.Base(dir)).CombinedOutput()
+	require.Nil(t, err, string(out))
+}
+
+func TestGenerate_WithoutTime(t *testing.T) {
+	src, err := generate("dogs", []*ksqldb.SourceSchema{{Name: "CATS", Type: "STREAM"}})
+	require.Nil(t, err)
+	require.NotContains(t, string(src), "\"time\"")
+}
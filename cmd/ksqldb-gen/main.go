@@ -0,0 +1,102 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// ksqldb-gen generates Go structs and typed Pull, Push and Insert functions
+// for ksqlDB streams and tables.
+//
+// It connects to a ksqlDB server, runs DESCRIBE on the given sources
+// and writes the bindings into a single file. Use it with go:generate:
+//
+//	//go:generate go run github.com/thmeitz/ksqldb-go/cmd/ksqldb-gen -url http://localhost:8088 DOGS DOGS_BY_SIZE
+//
+// Flags:
+//
+//	-url       ksqlDB server url (default http://localhost:8088)
+//	-user      username for basic auth
+//	-password  password for basic auth
+//	-package   package name of the generated file (default $GOPACKAGE)
+//	-o         output file (default ksqldb_gen.go)
+//	-timeout   timeout for the DESCRIBE requests (default 30s)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ksqldb-gen: ")
+
+	url := flag.String("url", net.DefaultBaseUrl, "ksqlDB server url")
+	user := flag.String("user", "", "username for basic auth")
+	password := flag.String("password", "", "password for basic auth")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package name of the generated file")
+	output := flag.String("o", "ksqldb_gen.go", "output file")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for the DESCRIBE requests")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: ksqldb-gen [flags] SOURCE...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		log.Fatal("no package name given; use -package or run with go:generate")
+	}
+
+	options := net.Options{
+		Credentials: net.Credentials{Username: *user, Password: *password},
+		BaseUrl:     *url,
+		AllowHTTP:   true,
+	}
+	kcl, err := ksqldb.NewClientWithOptions(options)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer kcl.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	schemas := []*ksqldb.SourceSchema{}
+	for _, name := range flag.Args() {
+		schema, err := kcl.SourceSchema(ctx, name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	src, err := generate(*pkg, schemas)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
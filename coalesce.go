@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync"
+)
+
+// EnablePullCoalescing enables / disables the coalescing of identical pull
+// queries. Identical queries which are started while the query is running
+// share the server call and its result, which protects the server from
+// dogpiles, ex. during dashboard refreshes. Queries are identical if their
+// normalized query text, properties and routing key are equal.
+//
+// The shared call runs with the context of the first query. Other queries
+// return early if their context is done. Shared payloads must not be modified.
+func (cl *KsqldbClient) EnablePullCoalescing(activate bool) {
+	if !activate {
+		cl.pullFlights = nil
+		return
+	}
+	if cl.pullFlights == nil {
+		cl.pullFlights = &flightGroup{calls: map[string]*flight{}}
+	}
+}
+
+// PullCoalescingEnabled returns true if identical pull queries are coalesced; false otherwise
+func (cl *KsqldbClient) PullCoalescingEnabled() bool {
+	return cl.pullFlights != nil
+}
+
+// sharedPull runs the pull query, coalesced with identical running queries if enabled
+func (api *KsqldbClient) sharedPull(ctx context.Context, options QueryOptions) (Header, Payload, error) {
+	if api.pullFlights == nil {
+		return api.pull(ctx, options)
+	}
+	key, _ := pullCacheKey(options)
+	return api.pullFlights.do(ctx, key+"\x00"+options.RoutingKey, func() (Header, Payload, error) {
+		return api.pull(ctx, options)
+	})
+}
+
+// flight is a running pull query
+type flight struct {
+	done    chan struct{}
+	header  Header
+	payload Payload
+	err     error
+}
+
+// flightGroup holds the running pull queries by key
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// do runs fn if there is no running call of the key; otherwise it waits for the result of the running call
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (Header, Payload, error)) (Header, Payload, error) {
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.header, f.payload, f.err
+		case <-ctx.Done():
+			return Header{}, nil, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.header, f.payload, f.err = fn()
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+	return f.header, f.payload, f.err
+}
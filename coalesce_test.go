@@ -0,0 +1,79 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestPullCoalescing(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.Mock.On("Do", mock.Anything).Return(func(r *http.Request) *http.Response {
+		close(started)
+		<-release
+		return pullResponse(`[` + dogHeader + `,["1","Rex"]]`)(r)
+	}, nil).Once()
+	kcl, _ := ksqldb.NewClient(&m)
+	require.False(t, kcl.PullCoalescingEnabled())
+	kcl.EnablePullCoalescing(true)
+	require.True(t, kcl.PullCoalescingEnabled())
+
+	pull := func(results chan<- ksqldb.Payload) {
+		_, payload, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs where id = '1';"})
+		require.Nil(t, err)
+		results <- payload
+	}
+	results := make(chan ksqldb.Payload, 10)
+	go pull(results)
+	<-started
+
+	var wg sync.WaitGroup
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pull(results)
+		}()
+	}
+	// waiters return early if their context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS WHERE ID = '1';"})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	close(release)
+	wg.Wait()
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "Rex", (<-results)[0][1])
+	}
+	m.AssertExpectations(t)
+
+	kcl.EnablePullCoalescing(false)
+	require.False(t, kcl.PullCoalescingEnabled())
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+// newKsqlServer returns a server answering statements, pull queries and push
+// queries, which stream rows until they are closed
+func newKsqlServer(t *testing.T) *httptest.Server {
+	var queries int32
+	var closed sync.Map
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Sql     string `json:"sql"`
+			QueryId string `json:"queryId"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case ksqldb.KSQL_ENDPOINT:
+			fmt.Fprint(w, `[{"@type":"streams","streams":[{"type":"STREAM","name":"DOGS","topic":"dogs"}]}]`)
+		case ksqldb.CLOSE_QUERY_ENDPOINT:
+			closed.Store(body.QueryId, true)
+		case ksqldb.QUERY_STREAM_ENDPOINT:
+			if !strings.Contains(body.Sql, "EMIT CHANGES") {
+				fmt.Fprint(w, `[{"queryId":null,"columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`)
+				return
+			}
+			queryId := fmt.Sprintf("query-%v", atomic.AddInt32(&queries, 1))
+			fmt.Fprintf(w, "{\"queryId\":\"%v\",\"columnNames\":[\"ID\"],\"columnTypes\":[\"STRING\"]}\n", queryId)
+			for i := 0; ; i++ {
+				if _, ok := closed.Load(queryId); ok {
+					return
+				}
+				if _, err := fmt.Fprintf(w, "[\"%v\"]\n", i); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}
+	}))
+}
+
+func TestClient_ConcurrentUse(t *testing.T) {
+	srv := newKsqlServer(t)
+	defer srv.Close()
+	kcl, err := ksqldb.NewClientWithOptions(net.Options{BaseUrl: srv.URL})
+	require.Nil(t, err)
+	kcl.SetPullCache(&ksqldb.PullCacheOptions{TTL: time.Millisecond})
+	kcl.EnablePullCoalescing(true)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, _, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_ID WHERE ID = '1';"})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := kcl.ListStreams(ctx)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			q, err := kcl.Subscribe(ctx, "SELECT * FROM DOGS EMIT CHANGES;", ksqldb.SubscribeOptions{})
+			if err != nil {
+				errs <- err
+				return
+			}
+			<-q.Rows()
+			_ = kcl.ActiveQueries()
+			errs <- q.Stop()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.Nil(t, err)
+	}
+}
+
+func TestClient_CloseWithQueriesInFlight(t *testing.T) {
+	srv := newKsqlServer(t)
+	defer srv.Close()
+	kcl, err := ksqldb.NewClientWithOptions(net.Options{BaseUrl: srv.URL})
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	var handles []*ksqldb.QueryHandle
+	for i := 0; i < 5; i++ {
+		q, err := kcl.Subscribe(ctx, "SELECT * FROM DOGS EMIT CHANGES;", ksqldb.SubscribeOptions{})
+		require.Nil(t, err)
+		handles = append(handles, q)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_ID WHERE ID = '1';"})
+			// pull queries started after Close are rejected
+			if err != nil && !errors.Is(err, ksqldb.ErrClientShutdown) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			kcl.Close()
+		}()
+	}
+	wg.Wait()
+
+	for _, q := range handles {
+		select {
+		case <-q.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("push query not stopped by Close")
+		}
+	}
+	_, err = kcl.ListStreams(ctx)
+	require.True(t, errors.Is(err, ksqldb.ErrClientShutdown))
+	_, err = kcl.Subscribe(ctx, "SELECT * FROM DOGS EMIT CHANGES;", ksqldb.SubscribeOptions{})
+	require.True(t, errors.Is(err, ksqldb.ErrClientShutdown))
+}
@@ -29,3 +29,6 @@ const (
 	PROP_VALIDITY_ENPOINT      = "/is_valid_property"
 	TERMINATE_CLUSTER_ENDPOINT = "/ksql/terminate"
 )
+
+// DELIMITED_CONTENT_TYPE is the newline delimited response format of /query-stream
+const DELIMITED_CONTENT_TYPE = "application/vnd.ksqlapi.delimited.v1"
@@ -0,0 +1,150 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+const (
+	// KSQL_DATE_FORMAT is the format of DATE values
+	KSQL_DATE_FORMAT = "2006-01-02"
+	// KSQL_TIME_FORMAT is the format of TIME values
+	KSQL_TIME_FORMAT = "15:04:05"
+)
+
+// convertValue converts a json decoded value into the Go type of the schema.
+//
+// BOOLEAN is returned as bool, INTEGER as int32, BIGINT as int64, DOUBLE and DECIMAL
+// as float64, STRING as string, BYTES as []byte, TIMESTAMP, DATE and TIME as time.Time,
+// ARRAY as []interface{} and MAP and STRUCT as map[string]interface{}.
+// Values of unknown types are returned unchanged.
+func convertValue(schema Schema, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch schema.Type {
+	case "BOOLEAN":
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case "INTEGER", "INT":
+		if v, ok := value.(float64); ok {
+			return int32(v), nil
+		}
+	case "BIGINT":
+		if v, ok := value.(float64); ok {
+			return int64(v), nil
+		}
+	case "DOUBLE", "DECIMAL":
+		if v, ok := value.(float64); ok {
+			return v, nil
+		}
+	case "STRING", "VARCHAR":
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case "BYTES":
+		if v, ok := value.(string); ok {
+			return base64.StdEncoding.DecodeString(v)
+		}
+	case "TIMESTAMP":
+		return convertTime(value, KSQL_TIMESTAMP_FORMAT)
+	case "DATE":
+		return convertTime(value, KSQL_DATE_FORMAT)
+	case "TIME":
+		return convertTime(value, KSQL_TIME_FORMAT)
+	case "ARRAY":
+		if v, ok := value.([]interface{}); ok {
+			return convertArray(schema, v)
+		}
+	case "MAP":
+		if v, ok := value.(map[string]interface{}); ok {
+			return convertMap(schema, v)
+		}
+	case "STRUCT":
+		if v, ok := value.(map[string]interface{}); ok {
+			return convertStruct(schema, v)
+		}
+	default:
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("can't convert %T to %v", value, schema.Type)
+}
+
+func convertTime(value interface{}, layout string) (interface{}, error) {
+	v, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("can't convert %T to time", value)
+	}
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		return nil, fmt.Errorf("can't convert %v to time: %w", v, err)
+	}
+	return t, nil
+}
+
+func convertArray(schema Schema, values []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(values))
+	for idx, item := range values {
+		if schema.MemberSchema == nil {
+			result[idx] = item
+			continue
+		}
+		v, err := convertValue(*schema.MemberSchema, item)
+		if err != nil {
+			return nil, err
+		}
+		result[idx] = v
+	}
+	return result, nil
+}
+
+func convertMap(schema Schema, values map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(values))
+	for key, item := range values {
+		if schema.MemberSchema == nil {
+			result[key] = item
+			continue
+		}
+		v, err := convertValue(*schema.MemberSchema, item)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+func convertStruct(schema Schema, values map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(values))
+	for key, item := range values {
+		result[key] = item
+	}
+	for _, field := range schema.Fields {
+		v, err := convertValue(field.Schema, values[field.Name])
+		if err != nil {
+			return nil, fmt.Errorf("field %v: %w", field.Name, err)
+		}
+		result[field.Name] = v
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestConvertValue(t *testing.T) {
+	str := ksqldb.Schema{Type: "STRING"}
+	tests := []struct {
+		name   string
+		schema ksqldb.Schema
+		value  interface{}
+		want   interface{}
+	}{
+		{"nil", str, nil, nil},
+		{"boolean", ksqldb.Schema{Type: "BOOLEAN"}, true, true},
+		{"integer", ksqldb.Schema{Type: "INTEGER"}, float64(5), int32(5)},
+		{"bigint", ksqldb.Schema{Type: "BIGINT"}, float64(1637049600000), int64(1637049600000)},
+		{"double", ksqldb.Schema{Type: "DOUBLE"}, 1.5, 1.5},
+		{"string", str, "dog", "dog"},
+		{"bytes", ksqldb.Schema{Type: "BYTES"}, "aGk=", []byte("hi")},
+		{"timestamp", ksqldb.Schema{Type: "TIMESTAMP"}, "2021-11-16T06:00:00.000", time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)},
+		{"date", ksqldb.Schema{Type: "DATE"}, "2021-11-16", time.Date(2021, 11, 16, 0, 0, 0, 0, time.UTC)},
+		{"array", ksqldb.Schema{Type: "ARRAY", MemberSchema: &ksqldb.Schema{Type: "INTEGER"}}, []interface{}{float64(1), nil}, []interface{}{int32(1), nil}},
+		{"map", ksqldb.Schema{Type: "MAP", MemberSchema: &ksqldb.Schema{Type: "BIGINT"}}, map[string]interface{}{"a": float64(1)}, map[string]interface{}{"a": int64(1)}},
+		{"struct", ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{{Name: "AGE", Schema: ksqldb.Schema{Type: "INTEGER"}}}}, map[string]interface{}{"AGE": float64(3)}, map[string]interface{}{"AGE": int32(3)}},
+		{"unknown", ksqldb.Schema{Type: "UNKNOWN"}, "x", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ksqldb.ConvertValue(tt.schema, tt.value)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertValue_Mismatch(t *testing.T) {
+	_, err := ksqldb.ConvertValue(ksqldb.Schema{Type: "BIGINT"}, "5")
+	require.NotNil(t, err)
+	require.Equal(t, "can't convert string to BIGINT", err.Error())
+
+	_, err = ksqldb.ConvertValue(ksqldb.Schema{Type: "TIMESTAMP"}, "yesterday")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// commandPollInterval is the time between the status requests of a queued command
+const commandPollInterval = 100 * time.Millisecond
+
+// Command states of CommandStatus
+const (
+	COMMAND_QUEUED     = "QUEUED"
+	COMMAND_PARSING    = "PARSING"
+	COMMAND_EXECUTING  = "EXECUTING"
+	COMMAND_SUCCESS    = "SUCCESS"
+	COMMAND_ERROR      = "ERROR"
+	COMMAND_TERMINATED = "TERMINATED"
+)
+
+// Statement is a statement which can be built, ex. a *SelectBuilder or SQL
+type Statement interface {
+	Build() (string, error)
+}
+
+// SQL is a statement string
+type SQL string
+
+// Build returns the statement
+func (s SQL) Build() (string, error) {
+	return string(s), nil
+}
+
+// CreateStreamAsSelect runs CREATE STREAM name WITH (options) AS SELECT ...,
+// waits until the command is completed and returns the ID of the created query.
+func (api *KsqldbClient) CreateStreamAsSelect(ctx context.Context, name string, query Statement, options SinkOptions) (string, error) {
+	return api.createAsSelect(ctx, "STREAM", name, query, options)
+}
+
+// CreateTableAsSelect runs CREATE TABLE name WITH (options) AS SELECT ...,
+// waits until the command is completed and returns the ID of the created query.
+func (api *KsqldbClient) CreateTableAsSelect(ctx context.Context, name string, query Statement, options SinkOptions) (string, error) {
+	return api.createAsSelect(ctx, "TABLE", name, query, options)
+}
+
+func (api *KsqldbClient) createAsSelect(ctx context.Context, typ string, name string, query Statement, options SinkOptions) (string, error) {
+	sql, err := query.Build()
+	if err != nil {
+		return "", fmt.Errorf("can't build the query of %v: %w", name, err)
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	stmnt := fmt.Sprintf("CREATE %v %v", typ, quoteFieldName(name))
+	if with := options.String(); with != "" {
+		stmnt += " " + with
+	}
+	stmnt += " AS " + sql + ";"
+
+	response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+	if err != nil {
+		return "", fmt.Errorf("can't create %v: %w", name, err)
+	}
+	if len(*response) == 0 {
+		return "", fmt.Errorf("can't create %v: %w: no command status", name, ErrNotFound)
+	}
+	status, err := api.waitForCommand(ctx, (*response)[0])
+	if err != nil {
+		return "", fmt.Errorf("can't create %v: %w", name, err)
+	}
+	if status.QueryId != "" {
+		return status.QueryId, nil
+	}
+	if match := createdQueryId.FindStringSubmatch(status.Message); match != nil {
+		return match[1], nil
+	}
+	return "", fmt.Errorf("can't create %v: %w: no query id in %q", name, ErrNotFound, status.Message)
+}
+
+// createdQueryId matches the query id of the command message of older servers
+var createdQueryId = regexp.MustCompile(`Created query with ID (\S+)`)
+
+// waitForCommand polls the status of the command of the response until it
+// is completed. An ERROR status is returned as error matching ErrStatementError.
+func (api *KsqldbClient) waitForCommand(ctx context.Context, response KsqlResponse) (CommandStatus, error) {
+	status := response.CommandStatus
+	for {
+		switch status.Status {
+		case COMMAND_QUEUED, COMMAND_PARSING, COMMAND_EXECUTING:
+		case COMMAND_ERROR:
+			return status, fmt.Errorf("%w: command %v failed: %v", ErrStatementError, response.CommandId, status.Message)
+		default:
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(commandPollInterval):
+		}
+		commandId := response.CommandId
+		if !strings.HasPrefix(commandId, "/") {
+			commandId = "/" + commandId
+		}
+		qs, err := api.GetQueryStatus(commandId)
+		if err != nil {
+			return status, err
+		}
+		status = CommandStatus{Message: qs.Message, Status: qs.Status, QueryId: qs.QueryId}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestCreateTableAsSelect(t *testing.T) {
+	stmnt := "CREATE TABLE DOGS_BY_SIZE WITH (KAFKA_TOPIC='dogs_by_size', PARTITIONS=3) AS SELECT DOG_SIZE, COUNT(*) AS CNT FROM DOGS GROUP BY DOG_SIZE EMIT CHANGES;"
+	m := mockKsql(t, []string{stmnt}, []string{`[{"@type":"currentStatus","commandId":"table/DOGS_BY_SIZE/create","commandStatus":{"status":"QUEUED","message":"Statement written to command topic"}}]`})
+	m.Mock.On("Get", "http://localhost/status/table/DOGS_BY_SIZE/create").Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status":"SUCCESS","message":"Created query with ID CTAS_DOGS_BY_SIZE_7"}`))),
+	}, nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+
+	query := ksqldb.Select("DOG_SIZE", "COUNT(*) AS CNT").From("DOGS", "").GroupBy("DOG_SIZE").EmitChanges()
+	queryId, err := kcl.CreateTableAsSelect(context.Background(), "DOGS_BY_SIZE", query, ksqldb.SinkOptions{KafkaTopic: "dogs_by_size", Partitions: 3})
+	require.Nil(t, err)
+	require.Equal(t, "CTAS_DOGS_BY_SIZE_7", queryId)
+	m.AssertExpectations(t)
+}
+
+func TestCreateStreamAsSelect(t *testing.T) {
+	stmnt := "CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOG_SIZE = 'large' EMIT CHANGES;"
+	m := mockKsql(t, []string{stmnt, stmnt},
+		[]string{`[{"@type":"currentStatus","commandId":"stream/BIG_DOGS/create","commandStatus":{"status":"SUCCESS","message":"Created query with ID CSAS_BIG_DOGS_3","queryId":"CSAS_BIG_DOGS_3"}}]`,
+			`[{"@type":"currentStatus","commandId":"stream/BIG_DOGS/create","commandStatus":{"status":"ERROR","message":"Sink already exists"}}]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	query := ksqldb.SQL("SELECT * FROM DOGS WHERE DOG_SIZE = 'large' EMIT CHANGES;")
+	queryId, err := kcl.CreateStreamAsSelect(context.Background(), "BIG_DOGS", query, ksqldb.SinkOptions{})
+	require.Nil(t, err)
+	require.Equal(t, "CSAS_BIG_DOGS_3", queryId)
+
+	_, err = kcl.CreateStreamAsSelect(context.Background(), "BIG_DOGS", query, ksqldb.SinkOptions{})
+	require.True(t, errors.Is(err, ksqldb.ErrStatementError))
+	require.Equal(t, "can't create BIG_DOGS: statement error: command stream/BIG_DOGS/create failed: Sink already exists", err.Error())
+}
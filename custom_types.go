@@ -0,0 +1,161 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ListTypes runs SHOW TYPES and returns the custom types by name
+func (api *KsqldbClient) ListTypes(ctx context.Context) (map[string]Schema, error) {
+	var response []struct {
+		Types map[string]Schema
+	}
+	if err := api.executeInto(ctx, ExecOptions{KSql: "SHOW TYPES;"}, &response); err != nil {
+		return nil, fmt.Errorf("can't list types: %w", err)
+	}
+	for _, item := range response {
+		if item.Types != nil {
+			return item.Types, nil
+		}
+	}
+	return map[string]Schema{}, nil
+}
+
+// CreateType runs CREATE TYPE name AS <type> with the ksql type of the Go
+// value, see SchemaOf. Custom types can be used like built-in types in
+// stream and table definitions, ex. a struct value as STRUCT type.
+func (api *KsqldbClient) CreateType(ctx context.Context, name string, value interface{}) error {
+	schema, err := SchemaOf(value)
+	if err != nil {
+		return fmt.Errorf("can't create type %v: %w", name, err)
+	}
+	stmnt := fmt.Sprintf("CREATE TYPE %v AS %v;", quoteFieldName(name), schema)
+	if _, err := api.execute(ctx, ExecOptions{KSql: stmnt}); err != nil {
+		return fmt.Errorf("can't create type %v: %w", name, err)
+	}
+	return nil
+}
+
+// DropType runs DROP TYPE name
+func (api *KsqldbClient) DropType(ctx context.Context, name string) error {
+	stmnt := fmt.Sprintf("DROP TYPE %v;", quoteFieldName(name))
+	if _, err := api.execute(ctx, ExecOptions{KSql: stmnt}); err != nil {
+		return fmt.Errorf("can't drop type %v: %w", name, err)
+	}
+	return nil
+}
+
+// nullTypes are the ksql types of the nullable value types
+var nullTypes = map[reflect.Type]string{
+	reflect.TypeOf(NullString{}):      "STRING",
+	reflect.TypeOf(NullInt64{}):       "BIGINT",
+	reflect.TypeOf(NullFloat64{}):     "DOUBLE",
+	reflect.TypeOf(NullBool{}):        "BOOLEAN",
+	reflect.TypeOf(NullTime{}):        "TIMESTAMP",
+	reflect.TypeOf(sql.NullString{}):  "STRING",
+	reflect.TypeOf(sql.NullInt32{}):   "INTEGER",
+	reflect.TypeOf(sql.NullInt64{}):   "BIGINT",
+	reflect.TypeOf(sql.NullFloat64{}): "DOUBLE",
+	reflect.TypeOf(sql.NullBool{}):    "BOOLEAN",
+	reflect.TypeOf(sql.NullTime{}):    "TIMESTAMP",
+}
+
+// SchemaOf returns the ksql type of the Go value; it is the inverse of the
+// mapping of Scan.
+//
+// Strings are STRING, bool is BOOLEAN, int8, int16, int32, uint8 and uint16
+// are INTEGER, the other int types are BIGINT, floats are DOUBLE, time.Time
+// is TIMESTAMP and []byte is BYTES. Slices and arrays are ARRAY, maps with
+// string keys are MAP and structs are STRUCT with the fields named like with
+// QuoteLiteral. Pointers and the Null types have the type of their value.
+// Recursive types have no ksql type and return an error.
+func SchemaOf(value interface{}) (Schema, error) {
+	if value == nil {
+		return Schema{}, fmt.Errorf("%v: nil", QBUnsupportedType)
+	}
+	return schemaOf(reflect.TypeOf(value), map[reflect.Type]bool{})
+}
+
+// schemaOf returns the schema of t; path holds the types enclosing t to detect recursive types
+func schemaOf(t reflect.Type, path map[reflect.Type]bool) (Schema, error) {
+	if typ, ok := nullTypes[t]; ok {
+		return Schema{Type: typ}, nil
+	}
+	switch t {
+	case timeType:
+		return Schema{Type: "TIMESTAMP"}, nil
+	case reflect.TypeOf([]byte{}):
+		return Schema{Type: "BYTES"}, nil
+	}
+
+	if path[t] {
+		return Schema{}, fmt.Errorf("%v: %v, recursive type", QBUnsupportedType, t)
+	}
+	path[t] = true
+	defer delete(path, t)
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), path)
+	case reflect.String:
+		return Schema{Type: "STRING"}, nil
+	case reflect.Bool:
+		return Schema{Type: "BOOLEAN"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Schema{Type: "INTEGER"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return Schema{Type: "BIGINT"}, nil
+	case reflect.Float32, reflect.Float64:
+		return Schema{Type: "DOUBLE"}, nil
+	case reflect.Slice, reflect.Array:
+		member, err := schemaOf(t.Elem(), path)
+		if err != nil {
+			return Schema{}, err
+		}
+		return Schema{Type: "ARRAY", MemberSchema: &member}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return Schema{}, fmt.Errorf("%v: %v, map keys must be strings", QBUnsupportedType, t)
+		}
+		member, err := schemaOf(t.Elem(), path)
+		if err != nil {
+			return Schema{}, err
+		}
+		return Schema{Type: "MAP", MemberSchema: &member}, nil
+	case reflect.Struct:
+		schema := Schema{Type: "STRUCT", Fields: []Field{}}
+		for _, field := range structFields(t) {
+			fieldSchema, err := schemaOf(field.typ, path)
+			if err != nil {
+				return Schema{}, fmt.Errorf("field %v: %w", field.name, err)
+			}
+			name := field.name
+			if plainIdentifier.MatchString(name) {
+				// like unquoted names in ksql
+				name = strings.ToUpper(name)
+			}
+			schema.Fields = append(schema.Fields, Field{Name: name, Schema: fieldSchema})
+		}
+		return schema, nil
+	}
+	return Schema{}, fmt.Errorf("%v: %v", QBUnsupportedType, t)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+type typeAddress struct {
+	Street string            `ksql:"street"`
+	Number int32             `ksql:"NUMBER"`
+	Zip    *string           `ksql:"zip code"`
+	Since  time.Time         `ksql:"SINCE"`
+	Tags   map[string][]byte `ksql:"TAGS"`
+	Owner  ksqldb.NullString
+	Geo    sql.NullFloat64 `ksql:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := ksqldb.SchemaOf(typeAddress{})
+	require.Nil(t, err)
+	require.Equal(t, "STRUCT<`STREET` STRING, `NUMBER` INTEGER, `zip code` STRING, `SINCE` TIMESTAMP, `TAGS` MAP<STRING, BYTES>, `OWNER` STRING>", schema.String())
+
+	schema, err = ksqldb.SchemaOf([]*typeAddress{})
+	require.Nil(t, err)
+	require.Equal(t, "ARRAY", schema.Type)
+
+	for _, value := range []interface{}{nil, map[int]string{}, struct{ C chan int }{}} {
+		_, err = ksqldb.SchemaOf(value)
+		require.NotNil(t, err)
+	}
+}
+
+type typeNode struct {
+	Name     string
+	Next     *typeNode
+	Children []typeNode
+}
+
+type typeEdge struct {
+	From typeAddress
+	To   typeAddress
+}
+
+func TestSchemaOf_Recursive(t *testing.T) {
+	_, err := ksqldb.SchemaOf(typeNode{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "recursive type")
+
+	// the same type in sibling fields isn't recursive
+	schema, err := ksqldb.SchemaOf(typeEdge{})
+	require.Nil(t, err)
+	require.Len(t, schema.Fields, 2)
+}
+
+func TestCreateType(t *testing.T) {
+	stmnt := "CREATE TYPE ADDRESS AS STRUCT<`STREET` STRING, `NUMBER` INTEGER, `zip code` STRING, `SINCE` TIMESTAMP, `TAGS` MAP<STRING, BYTES>, `OWNER` STRING>;"
+	m := mockKsql(t, []string{stmnt, "SHOW TYPES;", "DROP TYPE ADDRESS;"}, []string{`[{"@type":"currentStatus","commandStatus":{"status":"SUCCESS"}}]`,
+		`[{"@type":"type_list","statementText":"SHOW TYPES;","types":{"ADDRESS":{"type":"STRUCT","fields":[{"name":"STREET","schema":{"type":"STRING","fields":null,"memberSchema":null}}],"memberSchema":null}},"warnings":[]}]`,
+		`[{"@type":"currentStatus","commandStatus":{"status":"SUCCESS"}}]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	require.Nil(t, kcl.CreateType(context.Background(), "ADDRESS", &typeAddress{}))
+	types, err := kcl.ListTypes(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "STRUCT<`STREET` STRING>", types["ADDRESS"].String())
+	require.Nil(t, kcl.DropType(context.Background(), "ADDRESS"))
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package datagen generates random rows from the schema of a stream or table
+// and inserts them with INSERT INTO ... VALUES, ex. for load tests and demos
+// without the Java datagen tool:
+//
+//	gen, err := datagen.Load(ctx, &client, "ORDERS", datagen.Options{
+//		KeyCardinality: 100,
+//		Columns: map[string]datagen.Distribution{
+//			"AMOUNT": datagen.Normal(50, 15),
+//			"STATUS": datagen.OneOf("NEW", "PAID", "SHIPPED"),
+//		},
+//		Rate: 500,
+//	})
+//	...
+//	err = gen.Insert(ctx, &client, 10000)
+package datagen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// DEFAULT_BATCH_SIZE is the number of inserts sent with one request
+const DEFAULT_BATCH_SIZE = 100
+
+// Options configures a Generator
+type Options struct {
+	// Seed of the random values; 0 seeds with the current time
+	Seed int64
+	// KeyCardinality is the number of distinct keys, the keys are picked
+	// uniformly; 0 generates a new key for every row
+	KeyCardinality int
+	// Columns are the distributions of columns by name, other columns get
+	// random values of their type
+	Columns map[string]Distribution
+	// NullRatio is the share of NULL values of value columns from 0 to 1
+	NullRatio float64
+	// Rate limits the inserted rows per second; 0 inserts as fast as possible
+	Rate float64
+	// BatchSize is the number of inserts sent with one request; defaults to DEFAULT_BATCH_SIZE
+	BatchSize int
+}
+
+// Generator generates rows of a source. It is not safe for concurrent use.
+type Generator struct {
+	schema  *ksqldb.SourceSchema
+	columns []ksqldb.SchemaColumn
+	options Options
+	rand    *rand.Rand
+	// rows is the number of generated rows
+	rows int
+}
+
+// Load describes the stream or table and returns a generator of its rows
+func Load(ctx context.Context, client *ksqldb.KsqldbClient, source string, options Options) (*Generator, error) {
+	schema, err := client.SourceSchema(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return New(schema, options), nil
+}
+
+// New returns a generator of rows of the schema. Pseudo columns like
+// ROWTIME are not generated.
+func New(schema *ksqldb.SourceSchema, options Options) *Generator {
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DEFAULT_BATCH_SIZE
+	}
+	g := Generator{schema: schema, options: options, rand: rand.New(rand.NewSource(options.Seed))}
+	for _, col := range schema.Columns {
+		if !isPseudoColumn(col.Name) {
+			g.columns = append(g.columns, col)
+		}
+	}
+	return &g
+}
+
+// isPseudoColumn returns true for the pseudo columns of the row metadata
+func isPseudoColumn(name string) bool {
+	for _, pseudo := range ksqldb.PSEUDO_COLUMNS {
+		if strings.EqualFold(name, pseudo) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the next row. Values are Go values like the ones of
+// ksqldb.NewDecodePlan, STRUCT values are maps keyed by the field names.
+func (g *Generator) Next() ksqldb.Record {
+	// the key values are generated from the key number, so they repeat with the number
+	key := g.rows
+	if g.options.KeyCardinality > 0 {
+		key = g.rand.Intn(g.options.KeyCardinality)
+	}
+	keyRand := rand.New(rand.NewSource(g.options.Seed + int64(key) + 1))
+	g.rows++
+
+	record := make(ksqldb.Record, len(g.columns))
+	for _, col := range g.columns {
+		dist, ok := g.options.Columns[col.Name]
+		switch {
+		case col.Key && ok:
+			record[col.Name] = dist(keyRand)
+		case col.Key && col.Schema.Type == "STRING":
+			record[col.Name] = fmt.Sprintf("key-%v", key)
+		case col.Key && col.Schema.Type == "INTEGER":
+			record[col.Name] = int32(key)
+		case col.Key && col.Schema.Type == "BIGINT":
+			record[col.Name] = int64(key)
+		case col.Key:
+			record[col.Name] = randomValue(keyRand, col.Schema)
+		case g.options.NullRatio > 0 && g.rand.Float64() < g.options.NullRatio:
+			record[col.Name] = nil
+		case ok:
+			record[col.Name] = dist(g.rand)
+		default:
+			record[col.Name] = randomValue(g.rand, col.Schema)
+		}
+	}
+	return record
+}
+
+// Statement returns the INSERT INTO ... VALUES statement of the record
+func (g *Generator) Statement(record ksqldb.Record) (string, error) {
+	columns := make([]string, 0, len(g.columns))
+	values := make([]string, 0, len(g.columns))
+	for _, col := range g.columns {
+		value, ok := record[col.Name]
+		if !ok {
+			continue
+		}
+		literal, err := Literal(col.Schema, value)
+		if err != nil {
+			return "", fmt.Errorf("can't insert %v: %w", col.Name, err)
+		}
+		columns = append(columns, ksqldb.QuoteIdentifier(col.Name))
+		values = append(values, literal)
+	}
+	return fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v);", ksqldb.QuoteIdentifier(g.schema.Name),
+		strings.Join(columns, ", "), strings.Join(values, ", ")), nil
+}
+
+// Insert generates count rows and inserts them into the source. The inserts
+// are sent in batches of Options.BatchSize statements at the Options.Rate.
+func (g *Generator) Insert(ctx context.Context, client *ksqldb.KsqldbClient, count int) error {
+	started := time.Now()
+	inserted := 0
+	for inserted < count {
+		n := g.options.BatchSize
+		if count-inserted < n {
+			n = count - inserted
+		}
+		stmnts := make([]string, n)
+		for idx := range stmnts {
+			stmnt, err := g.Statement(g.Next())
+			if err != nil {
+				return err
+			}
+			stmnts[idx] = stmnt
+		}
+
+		if g.options.Rate > 0 {
+			// the batch is due when the rows before it are inserted at the rate
+			due := started.Add(time.Duration(float64(inserted) / g.options.Rate * float64(time.Second)))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := client.ExecuteContext(ctx, ksqldb.ExecOptions{KSql: strings.Join(stmnts, "\n")}); err != nil {
+			return fmt.Errorf("can't insert into %v: %w", g.schema.Name, err)
+		}
+		inserted += n
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datagen_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/datagen"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func ordersSchema() *ksqldb.SourceSchema {
+	return &ksqldb.SourceSchema{
+		Name: "ORDERS",
+		Columns: []ksqldb.SchemaColumn{
+			{Name: "ID", Key: true, Schema: ksqldb.Schema{Type: "STRING"}},
+			{Name: "ROWTIME", Schema: ksqldb.Schema{Type: "BIGINT"}},
+			{Name: "AMOUNT", Schema: ksqldb.Schema{Type: "DOUBLE"}},
+			{Name: "STATUS", Schema: ksqldb.Schema{Type: "STRING"}},
+			{Name: "CREATED", Schema: ksqldb.Schema{Type: "DATE"}},
+			{Name: "ITEMS", Schema: ksqldb.Schema{Type: "ARRAY", MemberSchema: &ksqldb.Schema{Type: "INTEGER"}}},
+			{Name: "ADDRESS", Schema: ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{
+				{Name: "CITY", Schema: ksqldb.Schema{Type: "STRING"}},
+				{Name: "ZIP", Schema: ksqldb.Schema{Type: "INTEGER"}},
+			}}},
+			{Name: "PRICE", Schema: ksqldb.Schema{Type: "DECIMAL", Parameters: map[string]interface{}{"precision": float64(4), "scale": float64(2)}}},
+		},
+	}
+}
+
+func TestGenerator_Next(t *testing.T) {
+	gen := datagen.New(ordersSchema(), datagen.Options{
+		Seed:           42,
+		KeyCardinality: 3,
+		Columns: map[string]datagen.Distribution{
+			"STATUS": datagen.OneOf("NEW", "PAID"),
+			"AMOUNT": datagen.UniformFloat(10, 20),
+		},
+	})
+	keys := map[interface{}]bool{}
+	for i := 0; i < 100; i++ {
+		record := gen.Next()
+		require.NotContains(t, record, "ROWTIME")
+		keys[record["ID"]] = true
+		require.Contains(t, []interface{}{"NEW", "PAID"}, record["STATUS"])
+		require.GreaterOrEqual(t, record["AMOUNT"], 10.0)
+		require.Less(t, record["AMOUNT"], 20.0)
+		require.IsType(t, time.Time{}, record["CREATED"])
+		require.Less(t, record["PRICE"], 100.0)
+		require.Len(t, record["ADDRESS"], 2)
+	}
+	require.Equal(t, map[interface{}]bool{"key-0": true, "key-1": true, "key-2": true}, keys)
+
+	// the rows repeat with the seed
+	first := datagen.New(ordersSchema(), datagen.Options{Seed: 7}).Next()
+	require.Equal(t, first, datagen.New(ordersSchema(), datagen.Options{Seed: 7}).Next())
+}
+
+func TestGenerator_NullRatio(t *testing.T) {
+	gen := datagen.New(ordersSchema(), datagen.Options{Seed: 1, NullRatio: 1})
+	record := gen.Next()
+	require.Equal(t, "key-0", record["ID"])
+	require.Nil(t, record["STATUS"])
+	require.Nil(t, record["ADDRESS"])
+}
+
+func TestGenerator_Statement(t *testing.T) {
+	gen := datagen.New(ordersSchema(), datagen.Options{Seed: 1})
+	stmnt, err := gen.Statement(ksqldb.Record{
+		"ID":      "1",
+		"STATUS":  "it's new",
+		"CREATED": time.Date(2021, 10, 4, 12, 0, 0, 0, time.UTC),
+		"ITEMS":   []interface{}{int32(1), int32(2)},
+		"ADDRESS": map[string]interface{}{"CITY": "Berlin", "ZIP": int32(10115)},
+		"PRICE":   nil,
+	})
+	require.Nil(t, err)
+	require.Equal(t, "INSERT INTO `ORDERS` (`ID`, `STATUS`, `CREATED`, `ITEMS`, `ADDRESS`, `PRICE`) "+
+		"VALUES ('1', 'it''s new', '2021-10-04', ARRAY[1, 2], STRUCT(`CITY` := 'Berlin', `ZIP` := 10115), NULL);", stmnt)
+}
+
+func TestLiteral(t *testing.T) {
+	literal, err := datagen.Literal(ksqldb.Schema{Type: "TIME"}, time.Date(2021, 10, 4, 12, 30, 5, 0, time.UTC))
+	require.Nil(t, err)
+	require.Equal(t, "'12:30:05'", literal)
+
+	literal, err = datagen.Literal(ksqldb.Schema{Type: "MAP", MemberSchema: &ksqldb.Schema{Type: "BIGINT"}},
+		map[string]interface{}{"b": int64(2), "a": int64(1)})
+	require.Nil(t, err)
+	require.Equal(t, "MAP('a' := 1, 'b' := 2)", literal)
+
+	literal, err = datagen.Literal(ksqldb.Schema{Type: "TIMESTAMP"}, time.Date(2021, 10, 4, 12, 30, 5, 0, time.UTC))
+	require.Nil(t, err)
+	require.Equal(t, "'2021-10-04T12:30:05.000'", literal)
+}
+
+func TestDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	seq := datagen.Sequence(5)
+	require.Equal(t, int64(5), seq(r))
+	require.Equal(t, int64(6), seq(r))
+
+	uniform := datagen.Uniform(1, 3)
+	zipf := datagen.Zipf(2, 9)
+	weighted := datagen.Weighted(map[interface{}]float64{"A": 1, "B": 0})
+	for i := 0; i < 100; i++ {
+		require.Contains(t, []interface{}{int64(1), int64(2), int64(3)}, uniform(r))
+		require.LessOrEqual(t, zipf(r), int64(9))
+		require.Equal(t, "A", weighted(r))
+	}
+}
+
+func TestGenerator_Insert(t *testing.T) {
+	stmnts := []string{}
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		var options ksqldb.ExecOptions
+		body, _ := ioutil.ReadAll(req.Body)
+		require.Nil(t, json.Unmarshal(body, &options))
+		stmnts = append(stmnts, options.KSql)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte("[]")))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	gen := datagen.New(ordersSchema(), datagen.Options{Seed: 1, BatchSize: 2, Rate: 100})
+	started := time.Now()
+	require.Nil(t, gen.Insert(context.Background(), &kcl, 5))
+	require.Len(t, stmnts, 3)
+	require.Equal(t, 2, strings.Count(stmnts[0], "INSERT INTO `ORDERS`"))
+	require.Equal(t, 1, strings.Count(stmnts[2], "INSERT INTO `ORDERS`"))
+	// 4 rows are inserted before the last batch
+	require.GreaterOrEqual(t, int64(time.Since(started)), int64(40*time.Millisecond))
+}
+
+type ctxKey struct{}
+
+func TestGenerator_InsertContext(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(nil, func(req *http.Request) error {
+		// the inserts are bound to the context of Insert
+		require.Equal(t, "insert", req.Context().Value(ctxKey{}))
+		return context.Canceled
+	})
+	kcl, _ := ksqldb.NewClient(&m)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "insert")
+	gen := datagen.New(ordersSchema(), datagen.Options{Seed: 1})
+	err := gen.Insert(ctx, &kcl, 1)
+	require.True(t, errors.Is(err, context.Canceled))
+}
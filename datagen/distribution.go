@@ -0,0 +1,257 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datagen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// Distribution returns a random value of a column
+type Distribution func(r *rand.Rand) interface{}
+
+// Uniform returns integers from min to max, max included
+func Uniform(min, max int64) Distribution {
+	return func(r *rand.Rand) interface{} {
+		return min + r.Int63n(max-min+1)
+	}
+}
+
+// UniformFloat returns floats from min to max, max excluded
+func UniformFloat(min, max float64) Distribution {
+	return func(r *rand.Rand) interface{} {
+		return min + r.Float64()*(max-min)
+	}
+}
+
+// Normal returns normally distributed floats
+func Normal(mean, stddev float64) Distribution {
+	return func(r *rand.Rand) interface{} {
+		return mean + r.NormFloat64()*stddev
+	}
+}
+
+// Zipf returns integers from 0 to max with a Zipf distribution, a few values
+// are very common, ex. hot keys; s > 1 is the skew
+func Zipf(s float64, max uint64) Distribution {
+	var source *rand.Rand
+	var z *rand.Zipf
+	return func(r *rand.Rand) interface{} {
+		// a Zipf draws from the source it was created with
+		if r != source {
+			source, z = r, rand.NewZipf(r, s, 1, max)
+		}
+		return int64(z.Uint64())
+	}
+}
+
+// OneOf returns one of the values picked uniformly
+func OneOf(values ...interface{}) Distribution {
+	return func(r *rand.Rand) interface{} {
+		return values[r.Intn(len(values))]
+	}
+}
+
+// Weighted returns the values with the given weights, ex.
+// Weighted(map[interface{}]float64{"NEW": 8, "CANCELLED": 2})
+func Weighted(weights map[interface{}]float64) Distribution {
+	values := make([]interface{}, 0, len(weights))
+	for value := range weights {
+		values = append(values, value)
+	}
+	// map order is random, the values are sorted to repeat with the seed
+	sort.Slice(values, func(i, j int) bool { return fmt.Sprint(values[i]) < fmt.Sprint(values[j]) })
+	total := 0.0
+	for _, value := range values {
+		total += weights[value]
+	}
+	return func(r *rand.Rand) interface{} {
+		pick := r.Float64() * total
+		for _, value := range values {
+			if pick -= weights[value]; pick < 0 {
+				return value
+			}
+		}
+		return values[len(values)-1]
+	}
+}
+
+// Sequence returns increasing integers starting with start
+func Sequence(start int64) Distribution {
+	next := start
+	return func(*rand.Rand) interface{} {
+		value := next
+		next++
+		return value
+	}
+}
+
+// Now returns the current time, ex. for event time columns
+func Now() Distribution {
+	return func(*rand.Rand) interface{} {
+		return time.Now()
+	}
+}
+
+// randomValue returns a random value of the type
+func randomValue(r *rand.Rand, schema ksqldb.Schema) interface{} {
+	switch schema.Type {
+	case "BOOLEAN":
+		return r.Intn(2) == 1
+	case "INTEGER", "INT":
+		return r.Int31n(1000)
+	case "BIGINT":
+		return r.Int63n(1000000)
+	case "DOUBLE":
+		return math.Round(r.Float64()*100000) / 100
+	case "DECIMAL":
+		precision, scale := parameter(schema, "precision"), parameter(schema, "scale")
+		if precision == 0 {
+			precision, scale = 10, 2
+		}
+		// the value has precision digits, scale of them after the point;
+		// larger precisions aren't exact with float64
+		if precision > 15 {
+			precision = 15
+		}
+		max := math.Pow(10, float64(precision))
+		return math.Floor(r.Float64()*max) / math.Pow(10, float64(scale))
+	case "STRING", "VARCHAR":
+		return randomWord(r)
+	case "BYTES":
+		b := make([]byte, 8)
+		r.Read(b)
+		return b
+	case "TIMESTAMP", "DATE", "TIME":
+		// within the last 30 days
+		return time.Now().UTC().Add(-time.Duration(r.Int63n(int64(30 * 24 * time.Hour)))).Truncate(time.Millisecond)
+	case "ARRAY":
+		items := make([]interface{}, r.Intn(4))
+		for idx := range items {
+			items[idx] = randomMember(r, schema)
+		}
+		return items
+	case "MAP":
+		entries := map[string]interface{}{}
+		for n := r.Intn(4); n > 0; n-- {
+			entries[randomWord(r)] = randomMember(r, schema)
+		}
+		return entries
+	case "STRUCT":
+		fields := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			fields[field.Name] = randomValue(r, field.Schema)
+		}
+		return fields
+	}
+	return nil
+}
+
+// parameter returns the integer parameter of the type, ex. the precision of a DECIMAL
+func parameter(schema ksqldb.Schema, name string) int {
+	switch v := schema.Parameters[name].(type) {
+	case int:
+		return v
+	case float64:
+		// parameters of DESCRIBE responses
+		return int(v)
+	}
+	return 0
+}
+
+// randomMember returns a random member of an ARRAY or a random value of a MAP
+func randomMember(r *rand.Rand, schema ksqldb.Schema) interface{} {
+	if schema.MemberSchema == nil {
+		return randomWord(r)
+	}
+	return randomValue(r, *schema.MemberSchema)
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// randomWord returns a lower case word of 4 to 10 letters
+func randomWord(r *rand.Rand) string {
+	var sb strings.Builder
+	for n := 4 + r.Intn(7); n > 0; n-- {
+		sb.WriteByte(letters[r.Intn(len(letters))])
+	}
+	return sb.String()
+}
+
+// Literal encodes the value as ksql literal of the type, ex. time.Time as DATE
+// literal of DATE columns and maps as STRUCT literals of STRUCT columns.
+// Other values are encoded with ksqldb.QuoteLiteral.
+func Literal(schema ksqldb.Schema, value interface{}) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	switch schema.Type {
+	case "DATE", "TIME":
+		layout := ksqldb.KSQL_DATE_FORMAT
+		if schema.Type == "TIME" {
+			layout = ksqldb.KSQL_TIME_FORMAT
+		}
+		if t, ok := value.(time.Time); ok {
+			return ksqldb.QuoteString(t.UTC().Format(layout)), nil
+		}
+	case "ARRAY":
+		if items, ok := value.([]interface{}); ok && schema.MemberSchema != nil {
+			literals := make([]string, len(items))
+			for idx, item := range items {
+				literal, err := Literal(*schema.MemberSchema, item)
+				if err != nil {
+					return "", err
+				}
+				literals[idx] = literal
+			}
+			return "ARRAY[" + strings.Join(literals, ", ") + "]", nil
+		}
+	case "MAP":
+		if entries, ok := value.(map[string]interface{}); ok && schema.MemberSchema != nil {
+			literals := make([]string, 0, len(entries))
+			for key, entry := range entries {
+				literal, err := Literal(*schema.MemberSchema, entry)
+				if err != nil {
+					return "", err
+				}
+				literals = append(literals, ksqldb.QuoteString(key)+" := "+literal)
+			}
+			// map keys have no order
+			sort.Strings(literals)
+			return "MAP(" + strings.Join(literals, ", ") + ")", nil
+		}
+	case "STRUCT":
+		if fields, ok := value.(map[string]interface{}); ok {
+			literals := make([]string, 0, len(schema.Fields))
+			for _, field := range schema.Fields {
+				literal, err := Literal(field.Schema, fields[field.Name])
+				if err != nil {
+					return "", err
+				}
+				literals = append(literals, ksqldb.QuoteIdentifier(field.Name)+" := "+literal)
+			}
+			return "STRUCT(" + strings.Join(literals, ", ") + ")", nil
+		}
+	}
+	return ksqldb.QuoteLiteral(value)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+// DEBUG_BODY_LIMIT is the maximum number of bytes of a dumped body or chunk
+const DEBUG_BODY_LIMIT = 1024
+
+// redactedHeaders are dumped without their values
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// WithDebug dumps the HTTP exchanges of the client to w: the request line,
+// headers and body, the response status and headers and each chunk of the
+// response body as it is read, so streamed rows of push queries are dumped
+// when they arrive. Credentials are redacted, bodies and chunks are truncated
+// after DEBUG_BODY_LIMIT bytes. Requests are prefixed with >, responses with <.
+//
+// A nil writer disables the dump.
+func (cl *KsqldbClient) WithDebug(w io.Writer) {
+	if d, ok := cl.http.(*debugClient); ok {
+		cl.http = d.next
+	}
+	if w != nil {
+		cl.http = &debugClient{next: cl.http, w: w}
+	}
+}
+
+// debugClient dumps the requests of the next client
+type debugClient struct {
+	next net.HTTPClient
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func (d *debugClient) GetUrl(endpoint string) string {
+	return d.next.GetUrl(endpoint)
+}
+
+func (d *debugClient) Close() {
+	d.next.Close()
+}
+
+func (d *debugClient) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.Do(req)
+}
+
+func (d *debugClient) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return d.Do(req)
+}
+
+func (d *debugClient) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	d.dump(func(sb *strings.Builder) {
+		fmt.Fprintf(sb, "> %v %v %v\n", req.Method, req.URL, req.Proto)
+		dumpHeader(sb, ">", req.Header)
+		if len(body) > 0 {
+			fmt.Fprintf(sb, "> %v\n", truncate(body))
+		}
+	})
+
+	res, err := d.next.Do(req)
+	if err != nil {
+		d.dump(func(sb *strings.Builder) {
+			fmt.Fprintf(sb, "! %v %v: %v\n", req.Method, req.URL, err)
+		})
+		return res, err
+	}
+	d.dump(func(sb *strings.Builder) {
+		fmt.Fprintf(sb, "< %v %v\n", res.StatusCode, http.StatusText(res.StatusCode))
+		dumpHeader(sb, "<", res.Header)
+	})
+	if res.Body != nil {
+		res.Body = &debugBody{ReadCloser: res.Body, client: d}
+	}
+	return res, nil
+}
+
+// dump writes the lines of fn at once, so the lines of concurrent requests aren't mixed
+func (d *debugClient) dump(fn func(sb *strings.Builder)) {
+	var sb strings.Builder
+	fn(&sb)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = io.WriteString(d.w, sb.String())
+}
+
+// debugBody dumps the chunks of a response body as they are read
+type debugBody struct {
+	io.ReadCloser
+	client *debugClient
+	closed bool
+}
+
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.client.dump(func(sb *strings.Builder) {
+			fmt.Fprintf(sb, "< %v\n", truncate(bytes.TrimRight(p[:n], "\n")))
+		})
+	}
+	return n, err
+}
+
+func (b *debugBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.client.dump(func(sb *strings.Builder) {
+			sb.WriteString("< (end of body)\n")
+		})
+	}
+	return b.ReadCloser.Close()
+}
+
+// dumpHeader writes the sorted headers with redacted credentials
+func dumpHeader(sb *strings.Builder, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(header[k], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			value = "[REDACTED]"
+		}
+		fmt.Fprintf(sb, "%v %v: %v\n", prefix, k, value)
+	}
+}
+
+// truncate returns the body truncated after DEBUG_BODY_LIMIT bytes
+func truncate(body []byte) string {
+	if len(body) <= DEBUG_BODY_LIMIT {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%v bytes)", body[:DEBUG_BODY_LIMIT], len(body))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestWithDebug(t *testing.T) {
+	m := mockKsql(t, []string{"select * from dogs;"}, []string{
+		`[{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	var buf bytes.Buffer
+	kcl.WithDebug(&buf)
+
+	ctx := context.Background()
+	_, rows, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Payload{{"1"}}, rows)
+
+	dump := buf.String()
+	require.Contains(t, dump, "> POST http://localhost/query-stream HTTP/1.1\n")
+	require.Contains(t, dump, "> Accept: application/json; charset=utf-8\n")
+	require.Contains(t, dump, `> {"sql":"select * from dogs;"`)
+	require.Contains(t, dump, "< 200 OK\n")
+	require.Contains(t, dump, `< [{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`+"\n")
+	require.Contains(t, dump, "< (end of body)\n")
+
+	// the dump is disabled with a nil writer
+	buf.Reset()
+	kcl.WithDebug(nil)
+	m.Mock.On("Do", matchStatement(t, "select * from cats;")).Return(pullResponse(`[{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`), nil).Once()
+	_, _, err = kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "select * from cats;"})
+	require.Nil(t, err)
+	require.Equal(t, "", buf.String())
+}
+
+func TestWithDebug_RedactsAndTruncates(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	long := strings.Repeat("x", ksqldb.DEBUG_BODY_LIMIT+10)
+	m.Mock.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Set-Cookie": []string{"session=secret"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`[{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`)),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	var buf bytes.Buffer
+	kcl.WithDebug(&buf)
+
+	_, _, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs where id = '" + long + "';"})
+	require.Nil(t, err)
+	dump := buf.String()
+	require.Contains(t, dump, "< Set-Cookie: [REDACTED]\n")
+	require.NotContains(t, dump, "secret")
+	// the request body is truncated
+	require.NotContains(t, dump, long)
+	require.Contains(t, dump, fmt.Sprintf("... (%v bytes)\n", len(`{"sql":"select * from dogs where id = '';","properties":null}`+long)))
+}
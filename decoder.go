@@ -0,0 +1,319 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// streamDecoder decodes the newline delimited header and rows of a
+// query stream response. The line buffer is reused for all lines and
+// rows are decoded directly into Row slices.
+type streamDecoder struct {
+	reader     *bufio.Reader
+	maxRowSize int
+	// line is the last line read; only valid until the next read
+	line []byte
+}
+
+// headerMessage is the header line of a query stream response, ex.
+// {"queryId":null,"columnNames":["WINDOW_START","WINDOW_END","DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","STRING","STRING","BIGINT"]}
+type headerMessage struct {
+	QueryId     string   `json:"queryId"`
+	ColumnNames []string `json:"columnNames"`
+	ColumnTypes []string `json:"columnTypes"`
+}
+
+// newStreamDecoder returns a decoder with the read buffer and the max row size of the client
+func (api *KsqldbClient) newStreamDecoder(body io.Reader) *streamDecoder {
+	return &streamDecoder{reader: api.newResponseReader(body), maxRowSize: api.MaxRowSize()}
+}
+
+// readLine reads the next line; see readLine
+func (d *streamDecoder) readLine() ([]byte, error) {
+	var err error
+	d.line, err = readLine(d.reader, d.maxRowSize, d.line)
+	return d.line, err
+}
+
+// Decoder decodes the header and row lines of query stream responses.
+// Set it with SetDecoder to use faster JSON libraries or custom decoding,
+// see UnmarshalDecoder.
+type Decoder interface {
+	// DecodeHeader decodes a header line, ex.
+	// {"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]}
+	DecodeHeader(line []byte) (Header, error)
+	// DecodeRow decodes a row line, ex. ["1"], and appends the values to row.
+	// Numbers should be float64 like with json.Unmarshal.
+	DecodeRow(line []byte, row Row) (Row, error)
+}
+
+// SetDecoder sets the decoder of push query responses; nil resets the default decoder
+func (cl *KsqldbClient) SetDecoder(decoder Decoder) {
+	cl.decoder = decoder
+}
+
+// Decoder returns the decoder of push query responses
+func (cl *KsqldbClient) Decoder() Decoder {
+	if cl.decoder == nil {
+		return defaultDecoder{}
+	}
+	return cl.decoder
+}
+
+// UnmarshalDecoder returns a Decoder using the unmarshal function of any
+// encoding/json compatible library, ex. jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal
+func UnmarshalDecoder(unmarshal RespUnmarshaller) Decoder {
+	return unmarshalDecoder{unmarshal: unmarshal}
+}
+
+type unmarshalDecoder struct {
+	unmarshal RespUnmarshaller
+}
+
+func (d unmarshalDecoder) DecodeHeader(line []byte) (Header, error) {
+	var msg headerMessage
+	if err := d.unmarshal(line, &msg); err != nil {
+		return Header{}, err
+	}
+	return msg.header(), nil
+}
+
+func (d unmarshalDecoder) DecodeRow(line []byte, row Row) (Row, error) {
+	err := d.unmarshal(line, &row)
+	return row, err
+}
+
+// defaultDecoder decodes rows in place, see decodeRow
+type defaultDecoder struct{}
+
+func (defaultDecoder) DecodeHeader(line []byte) (Header, error) {
+	return unmarshalDecoder{unmarshal: json.Unmarshal}.DecodeHeader(line)
+}
+
+func (defaultDecoder) DecodeRow(line []byte, row Row) (Row, error) {
+	return decodeRow(line, row)
+}
+
+// header returns the header with the columns of the message.
+// Columns without name or type are skipped.
+func (msg headerMessage) header() Header {
+	header := Header{queryId: msg.QueryId}
+	for idx, name := range msg.ColumnNames {
+		if name != "" && idx < len(msg.ColumnTypes) && msg.ColumnTypes[idx] != "" {
+			header.columns = append(header.columns, Column{Name: name, Type: msg.ColumnTypes[idx]})
+		}
+	}
+	return header
+}
+
+// decodeLine decodes a header or a row line. Both are nil for blank lines.
+// columns is the expected row size, pooled rows are taken from the row pool.
+func decodeLine(decoder Decoder, line []byte, columns int, pooled bool) (*Header, Row, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil, nil, nil
+	}
+
+	switch line[0] {
+	case '{':
+		header, err := decoder.DecodeHeader(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(line))
+		}
+		return &header, nil, nil
+	case '[':
+		row, err := decoder.DecodeRow(line, newRow(columns, pooled))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(line))
+		}
+		return nil, row, nil
+	}
+	return nil, nil, fmt.Errorf("could not parse the response: unexpected line\n%v", string(line))
+}
+
+// decodeRow decodes a JSON array of column values. Scalars are decoded in
+// place, nested values (ARRAY, MAP and STRUCT columns) and strings with
+// escapes fall back to encoding/json. Numbers are float64 like with
+// json.Unmarshal. The values are appended to row.
+func decodeRow(data []byte, row Row) (Row, error) {
+	s := rowScanner{data: data}
+	if !s.consume('[') {
+		return nil, s.syntaxError()
+	}
+	if s.consume(']') {
+		return row, s.end()
+	}
+	for {
+		value, err := s.value()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, value)
+		if s.consume(',') {
+			continue
+		}
+		if s.consume(']') {
+			return row, s.end()
+		}
+		return nil, s.syntaxError()
+	}
+}
+
+// rowScanner scans the values of a row
+type rowScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *rowScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips spaces and the next byte if it is c
+func (s *rowScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// end checks that nothing follows the row
+func (s *rowScanner) end() error {
+	s.skipSpace()
+	if s.pos != len(s.data) {
+		return s.syntaxError()
+	}
+	return nil
+}
+
+func (s *rowScanner) syntaxError() error {
+	return errors.New("invalid character at offset " + strconv.Itoa(s.pos))
+}
+
+func (s *rowScanner) literal(lit string, value interface{}) (interface{}, error) {
+	if len(s.data)-s.pos < len(lit) || string(s.data[s.pos:s.pos+len(lit)]) != lit {
+		return nil, s.syntaxError()
+	}
+	s.pos += len(lit)
+	return value, nil
+}
+
+func (s *rowScanner) value() (interface{}, error) {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return nil, s.syntaxError()
+	}
+	switch c := s.data[s.pos]; {
+	case c == 'n':
+		return s.literal("null", nil)
+	case c == 't':
+		return s.literal("true", true)
+	case c == 'f':
+		return s.literal("false", false)
+	case c == '"':
+		return s.string()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return s.number()
+	case c == '[' || c == '{':
+		return s.nested()
+	}
+	return nil, s.syntaxError()
+}
+
+func (s *rowScanner) string() (interface{}, error) {
+	start := s.pos
+	plain := true
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch c := s.data[s.pos]; {
+		case c == '\\':
+			plain = false
+			s.pos++
+		case c < 0x20:
+			return nil, s.syntaxError()
+		case c == '"':
+			s.pos++
+			raw := s.data[start:s.pos]
+			if plain && utf8.Valid(raw) {
+				return string(raw[1 : len(raw)-1]), nil
+			}
+			var value string
+			err := json.Unmarshal(raw, &value)
+			return value, err
+		}
+	}
+	return nil, s.syntaxError()
+}
+
+func (s *rowScanner) number() (interface{}, error) {
+	start := s.pos
+	for ; s.pos < len(s.data); s.pos++ {
+		c := s.data[s.pos]
+		if !(c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E') {
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(string(s.data[start:s.pos]), 64)
+	if err != nil {
+		return nil, s.syntaxError()
+	}
+	return value, nil
+}
+
+// nested decodes an ARRAY, MAP or STRUCT value with encoding/json
+func (s *rowScanner) nested() (interface{}, error) {
+	start := s.pos
+	depth := 0
+	for ; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '"':
+			// skip strings, they can contain brackets
+			for s.pos++; s.pos < len(s.data) && s.data[s.pos] != '"'; s.pos++ {
+				if s.data[s.pos] == '\\' {
+					s.pos++
+				}
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				s.pos++
+				var value interface{}
+				err := json.Unmarshal(s.data[start:s.pos], &value)
+				return value, err
+			}
+		}
+	}
+	return nil, s.syntaxError()
+}
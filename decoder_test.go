@@ -0,0 +1,184 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestDecodeLine(t *testing.T) {
+	header, row, err := ksqldb.DecodeLine([]byte(`{"queryId":"q1","columnNames":["ID","NAME",null],"columnTypes":["STRING","STRING","INTEGER"]}`+"\n"), 3, false)
+	require.Nil(t, err)
+	require.Nil(t, row)
+	require.Equal(t, []ksqldb.Column{{Name: "ID", Type: "STRING"}, {Name: "NAME", Type: "STRING"}}, header.Columns())
+
+	header, row, err = ksqldb.DecodeLine([]byte(`["1",null,3.5]`+"\n"), 3, false)
+	require.Nil(t, err)
+	require.Nil(t, header)
+	require.Equal(t, ksqldb.Row{"1", nil, 3.5}, row)
+
+	header, row, err = ksqldb.DecodeLine([]byte(" \n"), 3, false)
+	require.Nil(t, err)
+	require.Nil(t, header)
+	require.Nil(t, row)
+
+	_, _, err = ksqldb.DecodeLine([]byte(`"oops"`), 3, false)
+	require.NotNil(t, err)
+	require.Equal(t, "could not parse the response: unexpected line\n\"oops\"", err.Error())
+}
+
+func TestDecodeLine_SameAsUnmarshal(t *testing.T) {
+	for _, line := range []string{
+		`[]`,
+		` [ "a" , -1.5e3, true,false ,null ] `,
+		`["esc\"aped\\ \u00e9\/","ünïcödé","[not nested]"]`,
+		`[[1,"]",[2]],{"a":{"b":"}"}},{}]`,
+	} {
+		var want ksqldb.Row
+		require.Nil(t, json.Unmarshal([]byte(line), &want))
+		_, row, err := ksqldb.DecodeLine([]byte(line), 0, false)
+		require.Nil(t, err, line)
+		require.Equal(t, want, row, line)
+	}
+
+	for _, line := range []string{`[1,]`, `[1 2]`, `[1]x`, `["open`, `[nul]`, `[--1]`, `[{"a":1]`} {
+		_, _, err := ksqldb.DecodeLine([]byte(line), 0, false)
+		require.NotNil(t, err, line)
+	}
+}
+
+// upperDecoder decodes the values of string columns upper case
+type upperDecoder struct {
+	ksqldb.Decoder
+}
+
+func (d upperDecoder) DecodeRow(line []byte, row ksqldb.Row) (ksqldb.Row, error) {
+	return d.Decoder.DecodeRow(bytes.ToUpper(line), row)
+}
+
+func TestClient_SetDecoder(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	require.NotNil(t, kcl.Decoder())
+	kcl.SetDecoder(upperDecoder{ksqldb.UnmarshalDecoder(json.Unmarshal)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rowChannel := make(chan ksqldb.Row)
+	headerChannel := make(chan ksqldb.Header, 1)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+	go m.send(streamHeader, `["a"]`)
+
+	require.Equal(t, ksqldb.NewHeader("abc", []ksqldb.Column{{Name: "ID", Type: "STRING"}}), <-headerChannel)
+	require.Equal(t, ksqldb.Row{"A"}, <-rowChannel)
+	cancel()
+	require.Nil(t, <-done)
+
+	kcl.SetDecoder(nil)
+	_, ok := kcl.Decoder().(upperDecoder)
+	require.False(t, ok)
+}
+
+// streamBody returns a query stream response with a header and n rows
+func streamBody(n int) []byte {
+	var body bytes.Buffer
+	body.WriteString(`{"queryId":"q1","columnNames":["ID","NAME","AGE","WEIGHT"],"columnTypes":["STRING","STRING","INTEGER","DOUBLE"]}` + "\n")
+	for i := 0; i < n; i++ {
+		body.WriteString(`["` + strings.Repeat("a", 16) + `","Lara",3,12.5]` + "\n")
+	}
+	return body.Bytes()
+}
+
+// BenchmarkDecode_Interface is the former decoding: a new line per row
+// unmarshaled into interface{}
+func BenchmarkDecode_Interface(b *testing.B) {
+	body := streamBody(1000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for i := 0; i < b.N; i++ {
+		reader := bufio.NewReader(bytes.NewReader(body))
+		var row interface{}
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if err := json.Unmarshal(line, &row); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkDecode_Stream(b *testing.B) {
+	body := streamBody(1000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for i := 0; i < b.N; i++ {
+		reader := bufio.NewReader(bytes.NewReader(body))
+		var line []byte
+		for {
+			var err error
+			line, err = ksqldb.ReadLine(reader, 0, line)
+			if len(line) > 0 {
+				if _, _, err := ksqldb.DecodeLine(line, 4, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkDecode_Pooled(b *testing.B) {
+	body := streamBody(1000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	for i := 0; i < b.N; i++ {
+		reader := bufio.NewReader(bytes.NewReader(body))
+		var line []byte
+		for {
+			var err error
+			line, err = ksqldb.ReadLine(reader, 0, line)
+			if len(line) > 0 {
+				_, row, err := ksqldb.DecodeLine(line, 4, true)
+				if err != nil {
+					b.Fatal(err)
+				}
+				row.Release()
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+	}
+}
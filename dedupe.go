@@ -0,0 +1,85 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// DEFAULT_DEDUPE_WINDOW is the number of row keys remembered by default
+const DEFAULT_DEDUPE_WINDOW = 10000
+
+// DedupeOptions of push queries, see SetDedupe
+type DedupeOptions struct {
+	// Key returns the identity of a row, ex. its key column and ROWTIME;
+	// nil uses all columns
+	Key func(Row) string
+	// Window is the number of recent keys remembered;
+	// < 1 is DEFAULT_DEDUPE_WINDOW
+	Window int
+}
+
+// SetDedupe drops rows of push queries whose key was seen within the last
+// Window rows before they are delivered to the row channel. This is useful
+// after reconnects and replays, where duplicate rows are expected.
+// Dropped duplicates are counted in QueryMetrics.DuplicateRows.
+// nil disables deduplication (default).
+func (cl *KsqldbClient) SetDedupe(options *DedupeOptions) {
+	cl.dedupe = options
+}
+
+// Dedupe returns the dedupe options of push queries; nil if disabled
+func (cl *KsqldbClient) Dedupe() *DedupeOptions {
+	return cl.dedupe
+}
+
+// deduper remembers the most recently seen row keys
+type deduper struct {
+	key    func(Row) string
+	window int
+	// recent holds the keys, the most recent first
+	recent *list.List
+	seen   map[string]*list.Element
+}
+
+func newDeduper(options DedupeOptions) *deduper {
+	d := &deduper{key: options.Key, window: options.Window, recent: list.New(), seen: map[string]*list.Element{}}
+	if d.key == nil {
+		d.key = func(row Row) string { return fmt.Sprint([]interface{}(row)) }
+	}
+	if d.window < 1 {
+		d.window = DEFAULT_DEDUPE_WINDOW
+	}
+	return d
+}
+
+// duplicate returns true if the key of the row was seen within the window
+func (d *deduper) duplicate(row Row) bool {
+	key := d.key(row)
+	if elem, ok := d.seen[key]; ok {
+		d.recent.MoveToFront(elem)
+		return true
+	}
+	d.seen[key] = d.recent.PushFront(key)
+	if d.recent.Len() > d.window {
+		oldest := d.recent.Back()
+		d.recent.Remove(oldest)
+		delete(d.seen, oldest.Value.(string))
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestPush_Dedupe(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	require.Nil(t, kcl.Dedupe())
+	kcl.SetDedupe(&ksqldb.DedupeOptions{Window: 2})
+	require.Equal(t, 2, kcl.Dedupe().Window)
+
+	metrics := ksqldb.NewQueryMetrics()
+	ctx := ksqldb.WithQueryMetrics(context.Background(), metrics)
+	rowChannel := make(chan ksqldb.Row, 10)
+	go func() {
+		m.send(streamHeader)
+		// "1" is out of the window when it is received the third time
+		for _, id := range []string{"1", "2", "1", "2", "3", "1"} {
+			m.send(`["` + id + `"]`)
+		}
+		m.stream.Close()
+	}()
+	require.Nil(t, kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, nil))
+
+	var ids []string
+	for row := range rowChannel {
+		ids = append(ids, row[0].(string))
+	}
+	require.Equal(t, []string{"1", "2", "3", "1"}, ids)
+	require.Equal(t, int64(6), metrics.RowsReceived())
+	require.Equal(t, int64(2), metrics.Stats().DuplicateRows)
+}
+
+func TestPush_DedupeKey(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableRowPool(true)
+	kcl.SetDedupe(&ksqldb.DedupeOptions{Key: func(row ksqldb.Row) string { return row[0].(string)[:1] }})
+
+	rowChannel := make(chan ksqldb.Row, 10)
+	go func() {
+		m.send(streamHeader)
+		for _, id := range []string{"a1", "a2", "b1"} {
+			m.send(`["` + id + `"]`)
+		}
+		m.stream.Close()
+	}()
+	require.Nil(t, kcl.Push(context.Background(), "select * from dogs emit changes;", rowChannel, nil))
+
+	var ids []string
+	for row := range rowChannel {
+		ids = append(ids, row[0].(string))
+	}
+	require.Equal(t, []string{"a1", "b1"}, ids)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TOMBSTONE_STREAM_SUFFIX is appended to the table name for the stream
+// Delete writes the tombstones with
+const TOMBSTONE_STREAM_SUFFIX = "_TOMBSTONES"
+
+// Delete deletes the row with the key from the table by writing a tombstone,
+// a record with a null value, to the topic of the table.
+//
+// ksqlDB has no DELETE statement and an INSERT with NULL columns writes a
+// row with null columns, not a tombstone. So Delete creates a stream over the
+// topic of the table with the KAFKA value format, which serializes a null
+// STRING as null value, and inserts the key with a null value into it:
+//
+//	CREATE STREAM IF NOT EXISTS DOGS_TOMBSTONES (ID STRING KEY, TOMBSTONE STRING)
+//		WITH (KAFKA_TOPIC='dogs', KEY_FORMAT='KAFKA', VALUE_FORMAT='KAFKA');
+//	INSERT INTO DOGS_TOMBSTONES (ID, TOMBSTONE) VALUES ('1', CAST(NULL AS STRING));
+//
+// key is a struct with all key columns tagged with the key option, like for
+// Upsert, or the value of the key column for tables with one key column.
+//
+// Only source tables are supported: a tombstone in the topic of a table derived
+// by a persistent query, like CREATE TABLE AS SELECT, doesn't remove the row from
+// the state of the query, so these tables and windowed tables are rejected.
+func (api *KsqldbClient) Delete(ctx context.Context, table string, key interface{}) error {
+	desc, err := api.describe(ctx, table)
+	if err != nil {
+		return err
+	}
+	schema := NewSourceSchema(*desc)
+	if schema.Type != "TABLE" {
+		return fmt.Errorf("can't delete from %v: not a table", table)
+	}
+	if len(desc.WriteQueries) > 0 {
+		return fmt.Errorf("can't delete from %v: the table is derived by the query %v, only source tables are supported", table, desc.WriteQueries[0].ID)
+	}
+	if schema.Windowed() {
+		return fmt.Errorf("can't delete from %v: windowed tables are not supported", table)
+	}
+
+	keyColumns := schema.KeyColumns()
+	var names, literals []string
+	rv := reflect.ValueOf(key)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct && rv.Type() != timeType {
+		fields, err := insertFields(key)
+		if err != nil {
+			return err
+		}
+		if names, literals, err = keyValues(key, fields); err != nil {
+			return err
+		}
+	} else {
+		if len(keyColumns) != 1 {
+			return fmt.Errorf("%w: %v has %v key columns", ErrMissingKey, table, len(keyColumns))
+		}
+		literal, err := QuoteLiteral(key)
+		if err != nil {
+			return fmt.Errorf("can't quote key %v: %w", keyColumns[0].Name, err)
+		}
+		names, literals = []string{keyColumns[0].Name}, []string{literal}
+	}
+
+	given := map[string]bool{}
+	for idx := range names {
+		col, ok := schema.Column(names[idx])
+		if !ok {
+			return fmt.Errorf("%w: %v is no column of %v", ErrMissingKey, names[idx], table)
+		}
+		if !col.Key {
+			return fmt.Errorf("%w: %v is no key column of %v", ErrMissingKey, names[idx], table)
+		}
+		given[col.Name] = true
+		names[idx] = QuoteIdentifier(names[idx])
+	}
+	for _, col := range keyColumns {
+		if !given[col.Name] {
+			return fmt.Errorf("%w: %v of %v is missing in %T", ErrMissingKey, col.Name, table, key)
+		}
+	}
+
+	columns := []string{}
+	for _, col := range keyColumns {
+		columns = append(columns, QuoteIdentifier(col.Name)+" "+col.Schema.String()+" KEY")
+	}
+	stream := quoteFieldName(table + TOMBSTONE_STREAM_SUFFIX)
+	options := SourceOptions{KafkaTopic: schema.Topic, KeyFormat: schema.KeyFormat, ValueFormat: "KAFKA"}
+	create := fmt.Sprintf("CREATE STREAM IF NOT EXISTS %v (%v, TOMBSTONE STRING) %v;", stream, strings.Join(columns, ", "), options)
+	if _, err := api.execute(ctx, ExecOptions{KSql: create}); err != nil {
+		return fmt.Errorf("can't create the tombstone stream of %v: %w", table, err)
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %v (%v, TOMBSTONE) VALUES (%v, CAST(NULL AS STRING));",
+		stream, strings.Join(names, ", "), strings.Join(literals, ", "))
+	if _, err := api.execute(ctx, ExecOptions{KSql: insert}); err != nil {
+		return fmt.Errorf("can't delete from %v: %w", table, err)
+	}
+	return nil
+}
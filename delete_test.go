@@ -0,0 +1,92 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+var describeDogsTable = strings.Replace(describeDogs, `"type":"STREAM"`, `"type":"TABLE"`, 1)
+
+const createDogsTombstones = "CREATE STREAM IF NOT EXISTS DOGS_TOMBSTONES (`ID` STRING KEY, TOMBSTONE STRING) WITH (KAFKA_TOPIC='dogs', KEY_FORMAT='KAFKA', VALUE_FORMAT='KAFKA');"
+
+func TestDelete(t *testing.T) {
+	insert := "INSERT INTO DOGS_TOMBSTONES (`ID`, TOMBSTONE) VALUES ('1', CAST(NULL AS STRING));"
+	m := mockKsql(t,
+		[]string{"DESCRIBE DOGS;", createDogsTombstones, insert, "DESCRIBE DOGS;", createDogsTombstones, insert},
+		[]string{describeDogsTable, `[]`, `[]`, describeDogsTable, `[]`, `[]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	require.Nil(t, kcl.Delete(context.Background(), "DOGS", "1"))
+	require.Nil(t, kcl.Delete(context.Background(), "DOGS", &insertDog{ID: "1"}))
+	m.AssertExpectations(t)
+}
+
+func TestDelete_Errors(t *testing.T) {
+	m := mockKsql(t,
+		[]string{"DESCRIBE DOGS;", "DESCRIBE DOGS;", "DESCRIBE DOGS;"},
+		[]string{describeDogs, describeDogsTable, describeDogsTable})
+	kcl, _ := ksqldb.NewClient(m)
+
+	err := kcl.Delete(context.Background(), "DOGS", "1")
+	require.Equal(t, "can't delete from DOGS: not a table", err.Error())
+	err = kcl.Delete(context.Background(), "DOGS", insertDog{})
+	require.True(t, errors.Is(err, ksqldb.ErrMissingKey))
+	err = kcl.Delete(context.Background(), "DOGS", struct {
+		Name string `ksql:"NAME,key"`
+	}{"Pluto"})
+	require.Equal(t, "missing key column: NAME is no column of DOGS", err.Error())
+}
+
+func TestDelete_KeyColumns(t *testing.T) {
+	ageKey := strings.Replace(describeDogsTable, `"memberSchema":null}}`, `"memberSchema":null},"type":"KEY"}`, 1)
+	m := mockKsql(t,
+		[]string{"DESCRIBE DOGS;", "DESCRIBE DOGS;", "DESCRIBE DOGS;"},
+		[]string{ageKey, ageKey, describeDogsTable})
+	kcl, _ := ksqldb.NewClient(m)
+
+	// all key columns are required
+	err := kcl.Delete(context.Background(), "DOGS", &insertDog{ID: "1"})
+	require.True(t, errors.Is(err, ksqldb.ErrMissingKey))
+	require.Equal(t, "missing key column: AGE of DOGS is missing in *ksqldb_test.insertDog", err.Error())
+	err = kcl.Delete(context.Background(), "DOGS", "1")
+	require.True(t, errors.Is(err, ksqldb.ErrMissingKey))
+
+	// value columns aren't keys
+	err = kcl.Delete(context.Background(), "DOGS", struct {
+		ID  string `ksql:"ID,key"`
+		Age int    `ksql:"AGE,key"`
+	}{"1", 3})
+	require.Equal(t, "missing key column: AGE is no key column of DOGS", err.Error())
+}
+
+func TestDelete_DerivedTable(t *testing.T) {
+	derived := strings.Replace(describeDogsTable, `"writeQueries":[]`,
+		`"writeQueries":[{"id":"CTAS_DOGS_1","queryString":"CREATE TABLE DOGS AS SELECT ...","sinks":["DOGS"]}]`, 1)
+	m := mockKsql(t, []string{"DESCRIBE DOGS;"}, []string{derived})
+	kcl, _ := ksqldb.NewClient(m)
+
+	err := kcl.Delete(context.Background(), "DOGS", "1")
+	require.Equal(t, "can't delete from DOGS: the table is derived by the query CTAS_DOGS_1, only source tables are supported", err.Error())
+	m.AssertExpectations(t)
+}
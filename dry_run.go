@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// DRY_RUN_RESPONSE is the type of the responses of dry runs of statements
+// which are validated by the client only
+const DRY_RUN_RESPONSE = "dry_run"
+
+// DryRun validates the statements of options.KSql without applying them
+// and returns what would happen, one response per statement. It is used
+// by Execute for ExecOptions.DryRun, ex. as CI gate of pipeline changes.
+//
+// Queries, CREATE ... AS SELECT and INSERT INTO ... SELECT are validated by
+// the server with EXPLAIN, their responses contain the QueryDescription
+// with the schema, sources, sinks and the execution plan.
+//
+// ksqlDB can't validate other statements without applying them. They are
+// parsed and checked against the existing streams and tables, statements
+// which would fail get a warning, ex. CREATE STREAM of an existing stream
+// without IF NOT EXISTS. Their responses have the type DRY_RUN_RESPONSE.
+//
+// Sources created or dropped by a statement are seen by the following
+// statements. Queries reading from a source created by a previous statement
+// can't be explained by the server, they get a warning.
+func (api *KsqldbClient) DryRun(ctx context.Context, options ExecOptions) (*KsqlResponseSlice, error) {
+	if options.EmptyQuery() {
+		return nil, ErrEmptyQuery
+	}
+	if options.Idempotent {
+		var err error
+		if options.KSql, err = IdempotentStatements(options.KSql); err != nil {
+			return nil, err
+		}
+	}
+	stmnts, err := parser.SplitStatements(options.KSql)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := api.sourceNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// created are the sources created by previous statements
+	created := map[string]bool{}
+
+	responses := KsqlResponseSlice{}
+	for _, stmnt := range stmnts {
+		nodes, err := parser.Parse(stmnt.Text)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", stmnt.Line, err)
+		}
+		node := nodes[0]
+		response := KsqlResponse{StatementText: stmnt.Text, Type: DRY_RUN_RESPONSE}
+		warn := func(format string, args ...interface{}) {
+			response.Warnings = append(response.Warnings, fmt.Sprintf(format, args...))
+		}
+
+		if node.Query != nil {
+			explain := true
+			for _, source := range node.Sources() {
+				if created[source.Name] {
+					warn("not explained, %v is created by a previous statement", source.Name)
+					explain = false
+					break
+				}
+			}
+			if explain {
+				explained, err := api.explain(ctx, options, stmnt.Text)
+				if err != nil {
+					return nil, fmt.Errorf("line %v: %w", stmnt.Line, err)
+				}
+				response.Type = explained.Type
+				response.QueryDescription = explained.QueryDescription
+				response.Warnings = append(response.Warnings, explained.Warnings...)
+			}
+		}
+
+		switch node.Kind {
+		case parser.KindCreateStream, parser.KindCreateStreamAs, parser.KindCreateTable, parser.KindCreateTableAs:
+			name := node.Target.Name
+			if exists[name] && !node.IfNotExists && !node.OrReplace {
+				warn("%v already exists", name)
+			} else if !exists[name] {
+				created[name] = true
+			}
+			exists[name] = true
+		case parser.KindDropStream, parser.KindDropTable:
+			name := node.Target.Name
+			if !exists[name] && !node.IfExists {
+				warn("%v does not exist", name)
+			}
+			delete(exists, name)
+			delete(created, name)
+		case parser.KindAlterSource, parser.KindInsertInto, parser.KindInsertValues:
+			if !exists[node.Target.Name] {
+				warn("%v does not exist", node.Target.Name)
+			}
+		}
+		responses = append(responses, response)
+	}
+	return &responses, nil
+}
+
+// explain runs EXPLAIN of the statement with the properties and session variables of options
+func (api *KsqldbClient) explain(ctx context.Context, options ExecOptions, stmnt string) (*KsqlResponse, error) {
+	options.KSql = "EXPLAIN " + stmnt
+	response, err := api.execute(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("can't explain statement: %w", err)
+	}
+	for _, item := range *response {
+		if item.QueryDescription != nil {
+			return &item, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no query description for %v", ErrNotFound, stmnt)
+}
+
+// sourceNames returns the names of the existing streams and tables
+func (api *KsqldbClient) sourceNames(ctx context.Context) (map[string]bool, error) {
+	names := map[string]bool{}
+	streams, err := api.ListStreams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range streams {
+		names[s.Name] = true
+	}
+	tables, err := api.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		names[t.Name] = true
+	}
+	return names, nil
+}
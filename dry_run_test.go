@@ -0,0 +1,77 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestDryRun(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW STREAMS;", "SHOW TABLES;", "EXPLAIN CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOGSIZE = 'large';"}, []string{
+		`[{"@type":"streams","streams":[{"type":"STREAM","name":"DOGS","topic":"dogs"}]}]`,
+		`[{"@type":"tables","tables":[]}]`,
+		`[{"@type":"queryDescription","statementText":"EXPLAIN CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOGSIZE = 'large';","queryDescription":{"sources":["DOGS"],"sinks":["BIG_DOGS"]},"warnings":[]}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	sql := `CREATE STREAM DOGS (ID STRING KEY, DOGSIZE STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');
+CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOGSIZE = 'large';
+CREATE TABLE DOGS_BY_SIZE AS SELECT DOGSIZE, COUNT(*) AS CT FROM BIG_DOGS GROUP BY DOGSIZE;
+DROP TABLE CATS;
+DROP STREAM IF EXISTS CATS;`
+	response, err := kcl.Execute(ksqldb.ExecOptions{KSql: sql, DryRun: true})
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+	require.Len(t, *response, 5)
+
+	r := *response
+	require.Equal(t, ksqldb.DRY_RUN_RESPONSE, r[0].Type)
+	require.Equal(t, []string{"DOGS already exists"}, r[0].Warnings)
+
+	require.Equal(t, "queryDescription", r[1].Type)
+	require.Equal(t, "CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS WHERE DOGSIZE = 'large';", r[1].StatementText)
+	require.Equal(t, []string{"BIG_DOGS"}, r[1].QueryDescription.Sinks)
+	require.Empty(t, r[1].Warnings)
+
+	require.Equal(t, ksqldb.DRY_RUN_RESPONSE, r[2].Type)
+	require.Equal(t, []string{"not explained, BIG_DOGS is created by a previous statement"}, r[2].Warnings)
+	require.Equal(t, []string{"CATS does not exist"}, r[3].Warnings)
+	require.Empty(t, r[4].Warnings)
+}
+
+func TestDryRun_ExplainError(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW STREAMS;", "SHOW TABLES;"}, []string{
+		`[{"@type":"streams","streams":[]}]`,
+		`[{"@type":"tables","tables":[]}]`,
+	})
+	m.On("Do", matchStatement(t, "EXPLAIN SELECT * FROM DOGS EMIT CHANGES;")).Return(&http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"@type":"statement_error","error_code":40001,"message":"DOGS does not exist."}`)),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.DryRun(context.Background(), ksqldb.ExecOptions{KSql: "SELECT * FROM DOGS EMIT CHANGES;"})
+	require.NotNil(t, err)
+	require.Equal(t, "line 1: can't explain statement: DOGS does not exist.", err.Error())
+}
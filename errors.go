@@ -17,19 +17,83 @@ limitations under the License.
 package ksqldb
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
+	"time"
 )
 
 var (
+	// ErrNotFound is returned for empty results of pull queries and matches
+	// ResponseErrors with the status 404
 	ErrNotFound = errors.New("no result found")
+	// ErrEmptyQuery is returned for empty statements and queries
+	ErrEmptyQuery = errors.New("empty ksql query")
+	// ErrStatementError matches the ResponseErrors of statements rejected by
+	// the server, ex. of unknown sources or invalid syntax
+	ErrStatementError = errors.New("statement error")
+	// ErrUnauthorized matches the ResponseErrors of the status 401 and 403,
+	// ex. of invalid credentials or missing ACLs
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrTimeout matches failed requests which timed out, see SetTimeouts,
+	// and the ResponseErrors of the status 408 and 504
+	ErrTimeout = errors.New("timeout")
+	// ErrClientShutdown is returned for statements and queries started after Shutdown or Close
+	ErrClientShutdown = errors.New("client is shut down")
+	// ErrQueryClosed is returned for the header of a query closed before its header was received
+	ErrQueryClosed = errors.New("query is closed")
+	// ErrRowTooLarge is returned by push queries for rows larger than the MaxRowSize of the client
+	ErrRowTooLarge = errors.New("row too large")
+	// ErrResponseTooLarge is returned by statements and pull queries for responses larger than the MaxResponseSize of the client
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrInvalidCloudEndpoint is returned by NewCloudClient for endpoints which are not Confluent Cloud ksqlDB endpoints
+	ErrInvalidCloudEndpoint = errors.New("invalid Confluent Cloud ksqlDB endpoint")
+	// ErrReplicaTooFarBehind matches the ResponseError of pull queries which found no host
+	// within the max allowed offset lag, see QueryOptions.SetMaxAllowedOffsetLag
+	ErrReplicaTooFarBehind = errors.New("replica too far behind")
+	// ErrMissingKey is returned by Upsert for values without a populated key column
+	ErrMissingKey = errors.New("missing key column")
+	// ErrNotVerified is returned by Upsert if the row could not be verified with a pull query
+	ErrNotVerified = errors.New("upsert not verified")
+	// ErrSourceInUse matches the *SourceInUseError of DROP statements of sources
+	// which are referenced by queries or other sources
+	ErrSourceInUse = errors.New("source in use")
+	// ErrDependencyCycle is returned by OrderStatements for statements which depend on each other
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrInvalidQuery matches the *InvalidQueryError of ValidateQuery
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrTableScan is returned by pull queries scanning the whole table with TABLE_SCAN_REJECT, see SetTableScanPolicy
+	ErrTableScan = errors.New("pull query scans the table")
+	// ErrHubClosed is returned by Hub.Subscribe after the hub is closed
+	ErrHubClosed = errors.New("hub is closed")
+	// ErrSlowConsumer is the error of hub subscriptions evicted with SLOW_CONSUMER_EVICT
+	ErrSlowConsumer = errors.New("slow consumer evicted")
+	// ErrQueryNotClosed matches the *QueryNotClosedError of push queries still listed after their close, see EnableCloseVerification
+	ErrQueryNotClosed = errors.New("query not closed")
+	// ErrIdleTimeout is returned by push queries which received no row within Timeouts.PushIdle
+	ErrIdleTimeout = errors.New("push query idle timeout")
+	// ErrUnsupported matches the *UnsupportedError of statements which are not supported by the server version
+	ErrUnsupported = errors.New("unsupported by the server")
+	// ErrIncompatibleSchema matches the *IncompatibleSchemaError of ReplaceSource
+	ErrIncompatibleSchema = errors.New("incompatible schema")
 )
 
+// ResponseError is an error response of the server. It matches the sentinel
+// errors ErrStatementError, ErrUnauthorized, ErrTimeout, ErrNotFound and
+// ErrReplicaTooFarBehind with errors.Is.
 type ResponseError struct {
 	ErrType string `json:"@type"`
 	ErrCode int    `json:"error_code"`
 	Message string `json:"message"`
+	// StatusCode is the HTTP status of the response
+	StatusCode int `json:"-"`
+	// RetryAfter is the Retry-After of the response, ex. of the status 429 and 503; 0 if it has none
+	RetryAfter time.Duration `json:"-"`
+	// RequestID is the id of the failed request, see net.REQUEST_ID_HEADER
+	RequestID string `json:"-"`
 }
 
 func (e ResponseError) Error() string {
@@ -38,7 +102,206 @@ func (e ResponseError) Error() string {
 	return fmt.Sprintf("%v", e.Message)
 }
 
-// func (e *ResponseError) Is(target error) bool {
-// 	_, ok := target.(*ResponseError)
-// 	return ok
-// }
+// Is matches the sentinel errors of server errors, ex. errors.Is(err, ErrReplicaTooFarBehind)
+func (e ResponseError) Is(target error) bool {
+	// the error codes of ksqlDB start with the status, ex. 40001
+	status := e.StatusCode
+	if status == 0 {
+		status = e.ErrCode / 100
+	}
+	switch target {
+	case ErrReplicaTooFarBehind:
+		return strings.Contains(e.Message, "exceeds maximum allowed lag")
+	case ErrStatementError:
+		return e.ErrType == "statement_error" || e.ErrCode == 40001
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized || status == http.StatusForbidden
+	case ErrTimeout:
+		return status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	}
+	return false
+}
+
+// RequestError is a request which failed without a response of the server,
+// ex. a connection error. It carries the id of the request. Requests which
+// timed out match ErrTimeout.
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the request
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrTimeout for requests which exceeded their deadline
+func (e *RequestError) Is(target error) bool {
+	if target != ErrTimeout {
+		return false
+	}
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+}
+
+// RequestID returns the id of the failed request of err; empty if err carries none.
+// The id is sent in the X-Request-ID header, so failures can be found in the server logs.
+func RequestID(err error) string {
+	var respErr ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.RequestID
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.RequestID
+	}
+	return ""
+}
+
+// withRequestID adds the request id to the error of the request
+func withRequestID(err error, id string) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case ResponseError:
+		e.RequestID = id
+		return e
+	case *SourceInUseError:
+		e.Response.RequestID = id
+		return e
+	}
+	return &RequestError{RequestID: id, Err: err}
+}
+
+// SourceInUseError is returned by DROP statements of sources which are
+// referenced by queries or other sources. The queries have to be terminated
+// and the sources dropped before the source can be dropped.
+type SourceInUseError struct {
+	// Source is the name of the source which could not be dropped
+	Source string
+	// ReadQueries and WriteQueries are the ids of the queries reading from and writing into the source
+	ReadQueries  []string
+	WriteQueries []string
+	// Sources are the streams and tables reading from the source
+	Sources []string
+	// Response is the error of the server
+	Response ResponseError
+}
+
+func (e *SourceInUseError) Error() string {
+	return e.Response.Error()
+}
+
+// Unwrap returns the ResponseError
+func (e *SourceInUseError) Unwrap() error {
+	return e.Response
+}
+
+// Is matches ErrSourceInUse
+func (e *SourceInUseError) Is(target error) bool {
+	return target == ErrSourceInUse
+}
+
+// Queries returns the ids of the queries reading from or writing into the source
+func (e *SourceInUseError) Queries() []string {
+	return append(append([]string{}, e.ReadQueries...), e.WriteQueries...)
+}
+
+var (
+	cannotDrop       = regexp.MustCompile(`Cannot drop (.+?)\.(\s|$)`)
+	readQueries      = regexp.MustCompile(`queries read from this source: \[([^\]]*)\]`)
+	writeQueries     = regexp.MustCompile(`queries write into this source: \[([^\]]*)\]`)
+	dependentSources = regexp.MustCompile(`streams and/or tables read from this source: \[([^\]]*)\]`)
+)
+
+// sourceInUseError returns a *SourceInUseError if the response error is one; nil otherwise
+func sourceInUseError(e ResponseError) error {
+	source := cannotDrop.FindStringSubmatch(e.Message)
+	if source == nil {
+		return nil
+	}
+	err := &SourceInUseError{
+		Source:       source[1],
+		ReadQueries:  matchedList(readQueries, e.Message),
+		WriteQueries: matchedList(writeQueries, e.Message),
+		Sources:      matchedList(dependentSources, e.Message),
+		Response:     e,
+	}
+	if len(err.ReadQueries)+len(err.WriteQueries)+len(err.Sources) == 0 {
+		return nil
+	}
+	return err
+}
+
+// matchedList returns the items of the list matched by the first group of re, ex. [A, B]
+func matchedList(re *regexp.Regexp, s string) []string {
+	items := []string{}
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return items
+	}
+	for _, item := range strings.Split(match[1], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// QueryNotClosedError is returned if a push query is still listed by SHOW
+// QUERIES after it was closed, see EnableCloseVerification. The query keeps
+// using resources on the server.
+type QueryNotClosedError struct {
+	QueryID string
+	// State is the state of the listed query, ex. RUNNING
+	State string
+}
+
+func (e *QueryNotClosedError) Error() string {
+	return fmt.Sprintf("query %v is still %v after close", e.QueryID, e.State)
+}
+
+// Is matches ErrQueryNotClosed
+func (e *QueryNotClosedError) Is(target error) bool {
+	return target == ErrQueryNotClosed
+}
+
+// UnsupportedError is returned for statements which the server rejects,
+// because they were added in a later version, ex. PAUSE and RESUME.
+type UnsupportedError struct {
+	// Statement is the rejected statement, ex. PAUSE
+	Statement string
+	// MinVersion is the ksqlDB version which added the statement
+	MinVersion string
+	// ServerVersion is the version of the server; empty if it is unknown
+	ServerVersion string
+	// Response is the error of the server
+	Response error
+}
+
+func (e *UnsupportedError) Error() string {
+	version := e.ServerVersion
+	if version == "" {
+		version = "unknown"
+	}
+	return fmt.Sprintf("%v requires ksqlDB %v, the server version is %v", e.Statement, e.MinVersion, version)
+}
+
+// Unwrap returns the error of the server
+func (e *UnsupportedError) Unwrap() error {
+	return e.Response
+}
+
+// Is matches ErrUnsupported
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
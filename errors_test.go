@@ -17,10 +17,14 @@ limitations under the License.
 package ksqldb_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
 )
 
 func TestResponseError_ErrorMessage(t *testing.T) {
@@ -31,3 +35,60 @@ func TestResponseError_ErrorMessage(t *testing.T) {
 	}
 	require.Equal(t, "Some error occured Next line", error.Error())
 }
+
+func TestHandleRequestError_SourceInUse(t *testing.T) {
+	body := `{"@type":"statement_error","error_code":40001,"message":"Cannot drop DOGS.\nThe following queries read from this source: [CSAS_BIG_DOGS_3, CTAS_DOGS_BY_SIZE_7].\nThe following queries write into this source: [INSERTQUERY_5].\nYou need to terminate them before dropping DOGS.","statementText":"DROP STREAM DOGS;","entities":[]}`
+	err := ksqldb.HandleRequestError(400, []byte(body))
+	require.True(t, errors.Is(err, ksqldb.ErrSourceInUse))
+	var inUse *ksqldb.SourceInUseError
+	require.True(t, errors.As(err, &inUse))
+	require.Equal(t, "DOGS", inUse.Source)
+	require.Equal(t, []string{"CSAS_BIG_DOGS_3", "CTAS_DOGS_BY_SIZE_7"}, inUse.ReadQueries)
+	require.Equal(t, []string{"INSERTQUERY_5"}, inUse.WriteQueries)
+	require.Equal(t, []string{"CSAS_BIG_DOGS_3", "CTAS_DOGS_BY_SIZE_7", "INSERTQUERY_5"}, inUse.Queries())
+	require.Empty(t, inUse.Sources)
+	var responseErr ksqldb.ResponseError
+	require.True(t, errors.As(err, &responseErr))
+	require.Equal(t, 40001, responseErr.ErrCode)
+
+	body = `{"@type":"statement_error","error_code":40001,"message":"Cannot drop DOGS.\nThe following streams and/or tables read from this source: [BIG_DOGS].\nYou need to drop them before dropping DOGS."}`
+	err = ksqldb.HandleRequestError(400, []byte(body))
+	require.True(t, errors.As(err, &inUse))
+	require.Equal(t, []string{"BIG_DOGS"}, inUse.Sources)
+	require.Empty(t, inUse.Queries())
+
+	err = ksqldb.HandleRequestError(400, []byte(`{"@type":"statement_error","error_code":40001,"message":"Cannot drop DOGS. It does not exist."}`))
+	require.False(t, errors.Is(err, ksqldb.ErrSourceInUse))
+}
+
+func TestResponseError_Sentinels(t *testing.T) {
+	err := ksqldb.HandleRequestError(400, []byte(`{"@type":"statement_error","error_code":40001,"message":"DOGS does not exist."}`))
+	require.True(t, errors.Is(err, ksqldb.ErrStatementError))
+	require.False(t, errors.Is(err, ksqldb.ErrUnauthorized))
+
+	// proxies respond without an error body
+	err = ksqldb.HandleRequestError(401, []byte("Unauthorized"))
+	require.True(t, errors.Is(err, ksqldb.ErrUnauthorized))
+	require.False(t, errors.Is(err, ksqldb.ErrStatementError))
+	var respErr ksqldb.ResponseError
+	require.True(t, errors.As(err, &respErr))
+	require.Equal(t, 401, respErr.StatusCode)
+
+	require.True(t, errors.Is(ksqldb.ResponseError{ErrCode: 40300}, ksqldb.ErrUnauthorized))
+	require.True(t, errors.Is(ksqldb.HandleRequestError(504, nil), ksqldb.ErrTimeout))
+	require.True(t, errors.Is(ksqldb.HandleRequestError(404, []byte(`{"@type":"generic_error","error_code":40400,"message":"not found"}`)), ksqldb.ErrNotFound))
+}
+
+func TestPull_TimeoutError(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.Mock.On("Do", mock.Anything).Return(nil, context.DeadlineExceeded)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	_, _, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.True(t, errors.Is(err, ksqldb.ErrTimeout))
+	require.False(t, errors.Is(err, ksqldb.ErrUnauthorized))
+
+	_, _, err = kcl.Pull(context.Background(), ksqldb.QueryOptions{})
+	require.True(t, errors.Is(err, ksqldb.ErrEmptyQuery))
+}
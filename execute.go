@@ -25,6 +25,7 @@ package ksqldb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -38,6 +39,14 @@ type ExecOptions struct {
 	StreamsProperties     PropertyMap         `json:"streamsProperties,omitempty"`
 	SessionVariables      SessionVariablesMap `json:"sessionVariables,omitempty"`
 	CommandSequenceNumber int64               `json:"commandSequenceNumber,omitempty"`
+	// DryRun validates the statements without applying them, see DryRun
+	DryRun bool `json:"-"`
+	// Idempotent rewrites CREATE to CREATE IF NOT EXISTS and DROP to DROP IF EXISTS,
+	// see IdempotentStatements
+	Idempotent bool `json:"-"`
+	// skipParse skips the parsing of statements which are newer than the
+	// grammar of the parser, ex. PAUSE
+	skipParse bool
 }
 
 func (o *ExecOptions) SanitizeQuery() {
@@ -58,53 +67,77 @@ func (o *ExecOptions) EmptyQuery() bool {
 // Ref: https://docs.ksqldb.io/en/latest/developer-guide/ksqldb-rest-api/ksql-endpoint/
 //
 func (api *KsqldbClient) Execute(options ExecOptions) (*KsqlResponseSlice, error) {
-	var err error
+	return api.ExecuteContext(context.Background(), options)
+}
+
+// ExecuteContext is Execute bound to ctx, the request is canceled when ctx is done
+func (api *KsqldbClient) ExecuteContext(ctx context.Context, options ExecOptions) (*KsqlResponseSlice, error) {
+	if options.DryRun {
+		return api.DryRun(ctx, options)
+	}
+	return api.execute(ctx, options)
+}
+
+// execute runs the statement on the ksql endpoint with the given context
+func (api *KsqldbClient) execute(ctx context.Context, options ExecOptions) (*KsqlResponseSlice, error) {
 	var response = new(KsqlResponseSlice)
+	if err := api.executeInto(ctx, options, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// executeInto runs the statement on the ksql endpoint with the given context
+// and unmarshals the response into response. It is used for responses which
+// don't fit into KsqlResponse.
+func (api *KsqldbClient) executeInto(ctx context.Context, options ExecOptions, response interface{}) error {
+	var err error
 
 	if options.EmptyQuery() {
-		return nil, fmt.Errorf("empty ksql query")
+		return ErrEmptyQuery
+	}
+	if api.queries != nil && api.queries.isClosed() {
+		return ErrClientShutdown
+	}
+	if err := options.StreamsProperties.Validate(); err != nil {
+		return err
 	}
-	// remove \t \n from query
+	if options.Idempotent {
+		if options.KSql, err = IdempotentStatements(options.KSql); err != nil {
+			return err
+		}
+	}
+	// collapse whitespaces and comments outside of literals into single spaces
 	options.SanitizeQuery()
 
-	if api.ParseSQLEnabled() {
+	if api.ParseSQLEnabled() && !options.skipParse {
 		ksqlerr := parser.ParseSql(options.KSql)
 		if ksqlerr != nil {
-			return nil, ksqlerr
+			return ksqlerr
 		}
 	}
 
 	jsonData, err := json.Marshal(options)
 	if err != nil {
-		return nil, fmt.Errorf("can't marshal input data")
-	}
-
-	// make the request
-	req, err := newKsqlRequest(api.http, bytes.NewReader(jsonData))
-	// api.logger.Debugf("sending ksqlDB request:%v", q)
-	if err != nil {
-		return nil, fmt.Errorf("can't create new request: %w", err)
-	}
-
-	res, err := api.http.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("can't do request: %w", err)
+		return fmt.Errorf("can't marshal input data")
 	}
-	defer res.Body.Close()
 
-	body, err := api.readBody(res.Body)
+	ctx, cancel := withTimeout(ctx, api.timeouts.Statement)
+	defer cancel()
+	body, err := api.do(ctx, func() (*http.Request, error) {
+		req, err := newKsqlRequest(api.http, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("can't create new request: %w", err)
+		}
+		return req.WithContext(ctx), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("can't read response body: %w", err)
+		return err
 	}
 
-	// this is only one side of the coin
-	if res.StatusCode != http.StatusOK {
-		return nil, handleRequestError(res.StatusCode, body)
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("could not parse the response: %w\n%v", err, string(body))
 	}
 
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(body))
-	}
-
-	return response, nil
+	return nil
 }
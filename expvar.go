@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarMu serializes the check and the publishing of expvar names, expvar.Publish panics on taken names
+var expvarMu sync.Mutex
+
+// PublishExpvar publishes the counters of the client with expvar under the
+// name prefix, ex. ksqldb, for environments which scrape /debug/vars:
+//
+//	{"ksqldb": {"active_queries": 2, "bytes_read": 5120, "decode_errors": 0, "dropped_rows": 0,
+//	  "duplicate_rows": 0, "reconnects": 1, "rows_received": 64, "state": "connected"}}
+//
+// The counters are totals of all push queries of the client, reconnects
+// counts the changes from CLIENT_DEGRADED to CLIENT_CONNECTED. Names can
+// be published once per process, an error is returned if prefix is taken.
+func (cl *KsqldbClient) PublishExpvar(prefix string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if expvar.Get(prefix) != nil {
+		return fmt.Errorf("expvar %v is already published", prefix)
+	}
+	vars := new(expvar.Map).Init()
+	vars.Set("active_queries", expvar.Func(func() interface{} { return len(cl.ActiveQueries()) }))
+	vars.Set("bytes_read", expvar.Func(func() interface{} { return cl.totals.BytesRead() }))
+	vars.Set("rows_received", expvar.Func(func() interface{} { return cl.totals.RowsReceived() }))
+	vars.Set("decode_errors", expvar.Func(func() interface{} { return cl.totals.DecodeErrors() }))
+	vars.Set("dropped_rows", expvar.Func(func() interface{} { return cl.totals.DroppedRows() }))
+	vars.Set("duplicate_rows", expvar.Func(func() interface{} { return cl.totals.DuplicateRows() }))
+	vars.Set("reconnects", expvar.Func(func() interface{} { return atomic.LoadInt64(&cl.state.reconnects) }))
+	vars.Set("state", expvar.Func(func() interface{} { return cl.State() }))
+	expvar.Publish(prefix, vars)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// expvars returns the published vars of the name
+func expvars(t *testing.T, name string) map[string]interface{} {
+	vars := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars))
+	return vars
+}
+
+func TestPublishExpvar(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	// the names are unique per process, also with -count
+	name := fmt.Sprintf("ksqldb_expvar_test_%v", time.Now().UnixNano())
+	require.Nil(t, kcl.PublishExpvar(name))
+	require.EqualError(t, kcl.PublishExpvar(name), "expvar "+name+" is already published")
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 2})
+	require.Nil(t, err)
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	<-q.Rows()
+	<-q.Rows()
+
+	vars := expvars(t, name)
+	require.Equal(t, float64(1), vars["active_queries"])
+	require.Equal(t, float64(2), vars["rows_received"])
+	require.Equal(t, float64(len(streamHeader)+len(`["a"]`)+len(`["b"]`)+3), vars["bytes_read"])
+	require.Equal(t, float64(0), vars["decode_errors"])
+	require.Equal(t, "connected", vars["state"])
+
+	// the totals outlive the queries
+	require.Nil(t, q.Stop())
+	vars = expvars(t, name)
+	require.Equal(t, float64(0), vars["active_queries"])
+	require.Equal(t, float64(2), vars["rows_received"])
+}
+
+func TestPublishExpvar_Reconnects(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.INFO_ENDPOINT).Return("http://localhost/info")
+	m.Mock.On("Do", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	m.Mock.On("Do", mock.Anything).Return(infoResponse(200), nil).Once()
+	kcl, _ := ksqldb.NewClient(&m)
+	name := fmt.Sprintf("ksqldb_expvar_reconnects_test_%v", time.Now().UnixNano())
+	require.Nil(t, kcl.PublishExpvar(name))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _ = kcl.Ping(ctx)
+	require.Equal(t, "degraded", expvars(t, name)["state"])
+	_, _ = kcl.Ping(ctx)
+	vars := expvars(t, name)
+	require.Equal(t, "connected", vars["state"])
+	require.Equal(t, float64(1), vars["reconnects"])
+}
+
+func TestPublishExpvar_Concurrent(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(newStreamMock())
+	name := fmt.Sprintf("ksqldb_expvar_concurrent_test_%v", time.Now().UnixNano())
+
+	// one of the concurrent calls publishes the name, the others fail without panic
+	var wg sync.WaitGroup
+	var published int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if kcl.PublishExpvar(name) == nil {
+				atomic.AddInt32(&published, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), published)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Function is a function returned by SHOW FUNCTIONS
+type Function struct {
+	Name string
+	// Type is SCALAR, AGGREGATE or TABLE
+	Type     string
+	Category string
+}
+
+// FunctionDescription is a function returned by DESCRIBE FUNCTION
+type FunctionDescription struct {
+	Name        string
+	Type        string
+	Description string
+	Author      string
+	Version     string
+	// Path is internal for built-in functions, else the jar of the UDF
+	Path string
+	// Functions are the signatures of the function
+	Functions []FunctionSignature
+}
+
+// FunctionSignature is a variant of a function
+type FunctionSignature struct {
+	Arguments   []FunctionArgument
+	ReturnType  string
+	Description string
+}
+
+// FunctionArgument is an argument of a FunctionSignature
+type FunctionArgument struct {
+	Name        string
+	Type        string
+	Description string
+	IsVariadic  bool
+}
+
+// ListFunctions runs SHOW FUNCTIONS and returns the scalar, aggregate and
+// table functions including the installed UDFs, UDAFs and UDTFs
+func (api *KsqldbClient) ListFunctions(ctx context.Context) ([]Function, error) {
+	var response []struct {
+		Functions []Function
+	}
+	if err := api.executeInto(ctx, ExecOptions{KSql: "SHOW FUNCTIONS;"}, &response); err != nil {
+		return nil, fmt.Errorf("can't list functions: %w", err)
+	}
+	for _, item := range response {
+		if item.Functions != nil {
+			return item.Functions, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no functions in the response of SHOW FUNCTIONS", ErrNotFound)
+}
+
+// DescribeFunction runs DESCRIBE FUNCTION and returns the signatures of the function
+func (api *KsqldbClient) DescribeFunction(ctx context.Context, name string) (*FunctionDescription, error) {
+	var response []struct {
+		Type string `json:"@type"`
+		FunctionDescription
+	}
+	stmnt := fmt.Sprintf("DESCRIBE FUNCTION %v;", quoteFieldName(name))
+	if err := api.executeInto(ctx, ExecOptions{KSql: stmnt}, &response); err != nil {
+		return nil, fmt.Errorf("can't describe function %v: %w", name, err)
+	}
+	for _, item := range response {
+		if item.Type == "describe_function" {
+			return &item.FunctionDescription, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no description of function %v", ErrNotFound, name)
+}
+
+// RequireFunctions returns an error wrapping ErrNotFound with the missing
+// functions if not all of the functions are installed, ex. to check that the
+// UDFs of queries are installed before they are deployed
+func (api *KsqldbClient) RequireFunctions(ctx context.Context, names ...string) error {
+	functions, err := api.ListFunctions(ctx)
+	if err != nil {
+		return err
+	}
+	installed := map[string]bool{}
+	for _, f := range functions {
+		installed[strings.ToUpper(f.Name)] = true
+	}
+	missing := []string{}
+	for _, name := range names {
+		if !installed[strings.ToUpper(name)] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: missing functions %v", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
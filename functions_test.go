@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+const showFunctions = `[{"@type":"function_names","statementText":"SHOW FUNCTIONS;","functions":[
+{"name":"ABS","type":"SCALAR","category":"MATHEMATICAL"},
+{"name":"COUNT","type":"AGGREGATE","category":"AGGREGATE"},
+{"name":"DOG_AGE","type":"SCALAR","category":"OTHER"}],"warnings":[]}]`
+
+func TestListFunctions(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW FUNCTIONS;", "SHOW FUNCTIONS;"}, []string{showFunctions, showFunctions})
+	kcl, _ := ksqldb.NewClient(m)
+
+	functions, err := kcl.ListFunctions(context.Background())
+	require.Nil(t, err)
+	require.Len(t, functions, 3)
+	require.Equal(t, ksqldb.Function{Name: "COUNT", Type: "AGGREGATE", Category: "AGGREGATE"}, functions[1])
+
+	err = kcl.RequireFunctions(context.Background(), "abs", "TOP_DOG", "CAT_AGE", "dog_age")
+	require.True(t, errors.Is(err, ksqldb.ErrNotFound))
+	require.Equal(t, "no result found: missing functions CAT_AGE, TOP_DOG", err.Error())
+}
+
+func TestDescribeFunction(t *testing.T) {
+	m := mockKsql(t, []string{"DESCRIBE FUNCTION ABS;"}, []string{`[{"@type":"describe_function","statementText":"DESCRIBE FUNCTION ABS;",
+"name":"ABS","description":"","author":"Confluent","version":"","path":"internal","type":"SCALAR","functions":[
+{"arguments":[{"name":"col1","type":"INT","description":"","isVariadic":false}],"returnType":"INT","description":"Returns the absolute value of its argument.","argumentTypes":["INT"]},
+{"arguments":[{"name":"col1","type":"DOUBLE","description":"","isVariadic":false}],"returnType":"DOUBLE","description":"Returns the absolute value of its argument.","argumentTypes":["DOUBLE"]}],
+"warnings":[]}]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	desc, err := kcl.DescribeFunction(context.Background(), "ABS")
+	require.Nil(t, err)
+	require.Equal(t, "ABS", desc.Name)
+	require.Equal(t, "SCALAR", desc.Type)
+	require.Equal(t, "internal", desc.Path)
+	require.Len(t, desc.Functions, 2)
+	require.Equal(t, ksqldb.FunctionSignature{
+		Arguments:   []ksqldb.FunctionArgument{{Name: "col1", Type: "DOUBLE"}},
+		ReturnType:  "DOUBLE",
+		Description: "Returns the absolute value of its argument.",
+	}, desc.Functions[1])
+}
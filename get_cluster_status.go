@@ -17,7 +17,9 @@ limitations under the License.
 package ksqldb
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -68,7 +70,7 @@ type StateStoreLag struct {
 type LagByPartitionMap map[string]LagByPartition
 
 type LagByPartition struct {
-	Partition Partition
+	Partition Partition `mapstructure:",squash"`
 }
 
 type PartitionMap map[string]Partition
@@ -82,8 +84,6 @@ type Partition struct {
 // GetClusterStatus
 // @see https://docs.ksqldb.io/en/latest/developer-guide/ksqldb-rest-api/cluster-status-endpoint/
 func (api *KsqldbClient) GetClusterStatus() (*ClusterStatusResponse, error) {
-	var csr ClusterStatusResponse
-	var input map[string]interface{}
 	var body *[]byte
 	var err error
 
@@ -92,8 +92,27 @@ func (api *KsqldbClient) GetClusterStatus() (*ClusterStatusResponse, error) {
 	if body, err = handleGetRequest(api.http, url); err != nil {
 		return nil, fmt.Errorf("%w", err)
 	}
+	return api.decodeClusterStatus(*body)
+}
+
+// getClusterStatus is GetClusterStatus bound to ctx
+func (api *KsqldbClient) getClusterStatus(ctx context.Context) (*ClusterStatusResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.http.GetUrl(CLUSTER_STATUS_ENDPOINT), nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't create cluster status request: %w", err)
+	}
+	body, err := api.doOnce(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't get cluster status: %w", err)
+	}
+	return api.decodeClusterStatus(body)
+}
+
+func (api *KsqldbClient) decodeClusterStatus(body []byte) (*ClusterStatusResponse, error) {
+	var csr ClusterStatusResponse
+	var input map[string]interface{}
 
-	if err := api.unMarshalResp(*body, &input); err != nil {
+	if err := api.unMarshalResp(body, &input); err != nil {
 		return nil, fmt.Errorf("could not parse the response:%w", err)
 	}
 
@@ -163,6 +163,8 @@ func TestClusterStatus_Successful(t *testing.T) {
 	val, err := kcl.GetClusterStatus()
 	require.Nil(t, err)
 	require.NotNil(t, val)
+	lags := val.ClusterStatus.Host["other.ksqldb.host:8088"].HostStoreLags.StateStoreLags["_confluent-ksql-default_query_CTAS_MY_AGG_TABLE_3#Aggregate-Aggregate-Materialize"]
+	require.Equal(t, uint64(1), lags.LagByPartition["0"].Partition.CurrentOffsetPosition)
 }
 
 func TestClusterStatus_UnmarshalError(t *testing.T) {
@@ -23,6 +23,8 @@ import (
 type QueryStatus struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
+	// QueryId of the query created by the command, if any
+	QueryId string `json:"queryId,omitempty"`
 }
 
 // GetQueryStatus returns the current command status for a CREATE, DROP, or TERMINATE statement.
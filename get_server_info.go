@@ -40,13 +40,13 @@ func (api *KsqldbClient) GetServerInfo() (*KsqlServerInfo, error) {
 	res, err := api.http.Get(api.http.GetUrl(INFO_ENDPOINT))
 
 	if err != nil {
-		return nil, fmt.Errorf("can't get server info: %v", err)
+		return nil, fmt.Errorf("can't get server info: %w", err)
 	}
 	defer res.Body.Close()
 
 	body, readErr := api.readBody(res.Body)
 	if readErr != nil {
-		return nil, fmt.Errorf("could not read response body: %v", readErr)
+		return nil, fmt.Errorf("could not read response body: %w", readErr)
 	}
 
 	if err := api.unMarshalResp(body, &info); err != nil {
@@ -41,13 +41,13 @@ func (api *KsqldbClient) GetServerStatus() (*ServerStatusResponse, error) {
 
 	res, err := api.http.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("can't get healthcheck informations: %v", err)
+		return nil, fmt.Errorf("can't get healthcheck informations: %w", err)
 	}
 	defer res.Body.Close()
 
 	body, readErr := api.readBody(res.Body)
 	if readErr != nil {
-		return nil, fmt.Errorf("could not read response body: %v", readErr)
+		return nil, fmt.Errorf("could not read response body: %w", readErr)
 	}
 
 	if err := api.unMarshalResp(body, &info); err != nil {
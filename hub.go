@@ -0,0 +1,369 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// SlowConsumerPolicy is applied by a Hub if the row channel of a subscription is full
+type SlowConsumerPolicy int
+
+const (
+	// SLOW_CONSUMER_BLOCK waits until the subscription has room, it delays the other subscriptions
+	SLOW_CONSUMER_BLOCK SlowConsumerPolicy = iota
+	// SLOW_CONSUMER_DROP drops the row for the subscription, see Subscription.Dropped
+	SLOW_CONSUMER_DROP
+	// SLOW_CONSUMER_EVICT ends the subscription with ErrSlowConsumer
+	SLOW_CONSUMER_EVICT
+)
+
+// HubOptions configures a Hub
+type HubOptions struct {
+	// Query configures the push query of the hub; its BufferSize is the buffer between the query and the hub
+	Query SubscribeOptions
+	// BufferSize is the capacity of the row channels of the subscriptions
+	BufferSize int
+	// SlowConsumer is applied if the row channel of a subscription is full
+	SlowConsumer SlowConsumerPolicy
+}
+
+// Hub distributes the rows of a push query to subscriptions which come and
+// go, each with a filter, its own row channel and the slow consumer policy
+// of the hub. The query is started with the first subscription and stopped
+// after the last one, see NewHub. With EnableRowPool every subscription gets
+// its own row, which it has to release. It is safe for concurrent use.
+type Hub struct {
+	api     *KsqldbClient
+	ctx     context.Context
+	sql     string
+	options HubOptions
+	mu      sync.Mutex
+	feed    *hubFeed
+	closed  bool
+}
+
+// hubFeed is a running query of a hub with its subscriptions
+type hubFeed struct {
+	// started is closed when the query is started, source or err are set then
+	started chan struct{}
+	source  *SharedSubscription
+	err     error
+	subs    map[*Subscription]struct{}
+}
+
+// NewHub returns a hub of the push query. The query is shared with other hubs
+// and subscriptions of the client, see SubscribeShared. The hub is closed when
+// ctx is done.
+//
+//	hub := client.NewHub(ctx, "SELECT * FROM ORDERS EMIT CHANGES;", ksqldb.HubOptions{
+//		BufferSize:   100,
+//		SlowConsumer: ksqldb.SLOW_CONSUMER_EVICT,
+//	})
+//	defer hub.Close()
+//	sub, err := hub.Subscribe(func(row ksqldb.Row) bool { return row[1] == "EUR" })
+//	...
+//	for row := range sub.Rows() {
+//		// process the row
+//	}
+//	return sub.Err()
+func (api *KsqldbClient) NewHub(ctx context.Context, sql string, options HubOptions) *Hub {
+	return &Hub{api: api, ctx: ctx, sql: sql, options: options}
+}
+
+// Subscribe adds a subscription receiving the rows matching the filter, nil
+// receives all rows. The rows arriving after the subscription are delivered.
+// Errors starting the query are returned directly; ErrHubClosed after Close.
+func (h *Hub) Subscribe(filter func(Row) bool) (*Subscription, error) {
+	for {
+		h.mu.Lock()
+		if h.closed {
+			h.mu.Unlock()
+			return nil, ErrHubClosed
+		}
+		if err := h.ctx.Err(); err != nil {
+			h.mu.Unlock()
+			return nil, err
+		}
+		feed := h.feed
+		if feed == nil {
+			// the feed is registered before the query is started, so the
+			// lock isn't held during the request and the other
+			// subscriptions wait for it
+			feed = &hubFeed{started: make(chan struct{}), subs: map[*Subscription]struct{}{}}
+			h.feed = feed
+			h.mu.Unlock()
+			s := h.newSubscription(feed, filter)
+			if err := h.start(feed, s); err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+		h.mu.Unlock()
+
+		<-feed.started
+		if feed.err != nil {
+			return nil, feed.err
+		}
+		h.mu.Lock()
+		if h.feed == feed {
+			s := h.newSubscription(feed, filter)
+			feed.subs[s] = struct{}{}
+			h.mu.Unlock()
+			return s, nil
+		}
+		h.mu.Unlock()
+		// the query is finished or the hub is closed, the next round starts a new query or fails
+	}
+}
+
+func (h *Hub) newSubscription(feed *hubFeed, filter func(Row) bool) *Subscription {
+	return &Subscription{
+		hub:    h,
+		feed:   feed,
+		filter: filter,
+		rows:   make(chan Row, h.options.BufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// start starts the query of the registered feed with its first subscription
+func (h *Hub) start(feed *hubFeed, first *Subscription) error {
+	source, err := h.api.SubscribeShared(h.ctx, h.sql, h.options.Query)
+	h.mu.Lock()
+	if err == nil && h.feed != feed {
+		// the hub was closed during the start
+		err = ErrHubClosed
+	}
+	if err != nil {
+		if h.feed == feed {
+			h.feed = nil
+		}
+		feed.err = err
+	} else {
+		feed.source = source
+		feed.subs[first] = struct{}{}
+		go h.dispatch(feed)
+	}
+	h.mu.Unlock()
+	close(feed.started)
+	if err != nil && source != nil {
+		source.Unsubscribe()
+	}
+	return err
+}
+
+// Len returns the number of subscriptions
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.feed == nil {
+		return 0
+	}
+	return len(h.feed.subs)
+}
+
+// Close ends the subscriptions and stops the query of the hub. Calling Close again is a no-op.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	feed := h.feed
+	h.feed = nil
+	// a feed which is being started is released by start
+	started := feed != nil && feed.source != nil
+	h.mu.Unlock()
+	if !started {
+		return
+	}
+	for _, s := range h.subscribers(feed) {
+		s.end(nil)
+	}
+	feed.source.Unsubscribe()
+}
+
+// subscribers returns the current subscriptions of the feed
+func (h *Hub) subscribers(feed *hubFeed) []*Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := make([]*Subscription, 0, len(feed.subs))
+	for s := range feed.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// dispatch delivers the rows of the feed and ends its subscriptions when the query is finished
+func (h *Hub) dispatch(feed *hubFeed) {
+	pooled := feed.source.query.pooled
+	for row := range feed.source.Rows() {
+		matched := []*Subscription{}
+		for _, s := range h.subscribers(feed) {
+			if s.filter == nil || s.filter(row) {
+				matched = append(matched, s)
+			}
+		}
+		for idx, s := range matched {
+			// every subscription gets its own pooled row to release, the
+			// last one gets the row of the query
+			r := row
+			if pooled && idx < len(matched)-1 {
+				r = copyRow(row, true)
+			}
+			if !s.deliver(r, h.options.SlowConsumer) && pooled {
+				r.Release()
+			}
+		}
+		if pooled && len(matched) == 0 {
+			row.Release()
+		}
+	}
+	err := feed.source.Err()
+	if ctxErr := h.ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	h.mu.Lock()
+	if h.feed == feed {
+		h.feed = nil
+	}
+	if h.ctx.Err() != nil {
+		h.closed = true
+	}
+	h.mu.Unlock()
+	for _, s := range h.subscribers(feed) {
+		s.end(err)
+	}
+}
+
+// leave removes the subscription, the query is released after the last one
+func (h *Hub) leave(s *Subscription) {
+	h.mu.Lock()
+	delete(s.feed.subs, s)
+	last := len(s.feed.subs) == 0 && h.feed == s.feed
+	if last {
+		h.feed = nil
+	}
+	h.mu.Unlock()
+	if last {
+		s.feed.source.Unsubscribe()
+	}
+}
+
+// Subscription is a subscription of a Hub
+type Subscription struct {
+	hub    *Hub
+	feed   *hubFeed
+	filter func(Row) bool
+	rows   chan Row
+	// mu guards the row channel against sends after it is closed
+	mu        sync.Mutex
+	closed    bool
+	err       error
+	dropped   int64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Header waits for the header of the query of the hub, see QueryHandle.Header
+func (s *Subscription) Header() (Header, error) {
+	return s.feed.source.Header()
+}
+
+// Rows returns the row channel of the subscription, it is closed when the subscription ends
+func (s *Subscription) Rows() <-chan Row {
+	return s.rows
+}
+
+// Done returns a channel which is closed when the subscription ends
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the subscription ended: ErrSlowConsumer if it was evicted,
+// the error of the query or of the context of the hub; nil otherwise
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of rows dropped by SLOW_CONSUMER_DROP
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Unsubscribe ends the subscription and closes its row channel. Calling Unsubscribe again is a no-op.
+func (s *Subscription) Unsubscribe() {
+	if s.end(nil) {
+		s.hub.leave(s)
+	}
+}
+
+// end closes the subscription with the error; false if it was closed already
+func (s *Subscription) end(err error) bool {
+	ended := false
+	s.closeOnce.Do(func() {
+		// done unblocks a pending send, so the lock is released
+		close(s.done)
+		s.mu.Lock()
+		s.err = err
+		s.closed = true
+		close(s.rows)
+		s.mu.Unlock()
+		ended = true
+	})
+	return ended
+}
+
+// deliver sends the row to the subscription with the slow consumer policy;
+// false if the row was not delivered
+func (s *Subscription) deliver(row Row, policy SlowConsumerPolicy) bool {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	delivered := true
+	switch policy {
+	case SLOW_CONSUMER_DROP:
+		select {
+		case s.rows <- row:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+			delivered = false
+		}
+	case SLOW_CONSUMER_EVICT:
+		select {
+		case s.rows <- row:
+		default:
+			s.mu.Unlock()
+			if s.end(ErrSlowConsumer) {
+				s.hub.leave(s)
+			}
+			return false
+		}
+	default:
+		select {
+		case s.rows <- row:
+		case <-s.done:
+			delivered = false
+		}
+	}
+	s.mu.Unlock()
+	return delivered
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestHub(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	hub := kcl.NewHub(context.TODO(), "select * from dogs emit changes;", ksqldb.HubOptions{BufferSize: 4})
+	all, err := hub.Subscribe(nil)
+	require.Nil(t, err)
+	odd, err := hub.Subscribe(func(row ksqldb.Row) bool { return row[0] == "a" || row[0] == "c" })
+	require.Nil(t, err)
+	require.Equal(t, 2, hub.Len())
+	require.Len(t, kcl.ActiveQueries(), 1)
+
+	go m.send(streamHeader, `["a"]`, `["b"]`, `["c"]`)
+	require.Equal(t, ksqldb.Row{"a"}, <-all.Rows())
+	require.Equal(t, ksqldb.Row{"b"}, <-all.Rows())
+	require.Equal(t, ksqldb.Row{"c"}, <-all.Rows())
+	require.Equal(t, ksqldb.Row{"a"}, <-odd.Rows())
+	require.Equal(t, ksqldb.Row{"c"}, <-odd.Rows())
+
+	all.Unsubscribe()
+	_, ok := <-all.Rows()
+	require.False(t, ok)
+	require.Nil(t, all.Err())
+	require.Equal(t, 1, hub.Len())
+	require.Equal(t, int32(0), atomic.LoadInt32(&m.closed))
+
+	// the last subscription stops the query
+	odd.Unsubscribe()
+	require.Equal(t, 0, hub.Len())
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+
+	hub.Close()
+	_, err = hub.Subscribe(nil)
+	require.Equal(t, ksqldb.ErrHubClosed, err)
+}
+
+func TestHub_SlowConsumer(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	evicting := kcl.NewHub(context.TODO(), "select * from dogs emit changes;", ksqldb.HubOptions{BufferSize: 1, SlowConsumer: ksqldb.SLOW_CONSUMER_EVICT})
+	dropping := kcl.NewHub(context.TODO(), "select * from dogs emit changes;", ksqldb.HubOptions{BufferSize: 1, SlowConsumer: ksqldb.SLOW_CONSUMER_DROP})
+	evicted, err := evicting.Subscribe(nil)
+	require.Nil(t, err)
+	dropped, err := dropping.Subscribe(nil)
+	require.Nil(t, err)
+	// the hubs share the query
+	require.Len(t, kcl.ActiveQueries(), 1)
+
+	go m.send(streamHeader, `["a"]`, `["b"]`, `["c"]`)
+	select {
+	case <-evicted.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow consumer not evicted")
+	}
+	require.Equal(t, ksqldb.ErrSlowConsumer, evicted.Err())
+	require.Equal(t, ksqldb.Row{"a"}, <-evicted.Rows())
+	_, ok := <-evicted.Rows()
+	require.False(t, ok)
+
+	require.Eventually(t, func() bool { return dropped.Dropped() == 2 }, 5*time.Second, time.Millisecond)
+	require.Equal(t, ksqldb.Row{"a"}, <-dropped.Rows())
+	require.Nil(t, dropped.Err())
+
+	dropping.Close()
+	_, ok = <-dropped.Rows()
+	require.False(t, ok)
+	require.Empty(t, kcl.ActiveQueries())
+}
+
+func TestHub_QueryEnd(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := kcl.NewHub(ctx, "select * from dogs emit changes;", ksqldb.HubOptions{})
+	sub, err := hub.Subscribe(nil)
+	require.Nil(t, err)
+	go m.send(streamHeader)
+
+	cancel()
+	select {
+	case <-sub.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended")
+	}
+	require.Equal(t, context.Canceled, sub.Err())
+	require.Eventually(t, func() bool { return len(kcl.ActiveQueries()) == 0 }, 5*time.Second, time.Millisecond)
+	_, err = hub.Subscribe(nil)
+	require.Equal(t, ksqldb.ErrHubClosed, err)
+}
+
+func TestHub_RowPool(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableRowPool(true)
+
+	hub := kcl.NewHub(context.TODO(), "select * from dogs emit changes;", ksqldb.HubOptions{BufferSize: 2})
+	defer hub.Close()
+	sub1, err := hub.Subscribe(nil)
+	require.Nil(t, err)
+	sub2, err := hub.Subscribe(nil)
+	require.Nil(t, err)
+
+	go m.send(streamHeader, `["a"]`)
+	row1, row2 := <-sub1.Rows(), <-sub2.Rows()
+	// the release of one subscription doesn't clear the row of the other one
+	row1.Release()
+	require.Equal(t, ksqldb.Row{"a"}, row2)
+	row2.Release()
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+var (
+	createSource = regexp.MustCompile(`(?i)^(CREATE\s+(?:SOURCE\s+)?(?:STREAM|TABLE))\s+`)
+	createType   = regexp.MustCompile(`(?i)^(CREATE\s+TYPE)\s+`)
+	dropObject   = regexp.MustCompile(`(?i)^(DROP\s+(?:STREAM|TABLE|TYPE))\s+`)
+)
+
+// IdempotentStatements rewrites the statements of the sql so they can be
+// executed repeatedly, ex. by deployments of pipelines:
+//
+//	CREATE STREAM|TABLE|TYPE becomes CREATE ... IF NOT EXISTS
+//	DROP STREAM|TABLE|TYPE becomes DROP ... IF EXISTS
+//
+// CREATE OR REPLACE is kept as it is, ksqlDB doesn't allow IF NOT EXISTS with
+// OR REPLACE. Other statements are not changed. The statements are returned
+// separated by new lines.
+func IdempotentStatements(sql string) (string, error) {
+	stmnts, err := parser.SplitStatements(sql)
+	if err != nil {
+		return "", err
+	}
+	rewritten := make([]string, len(stmnts))
+	for idx, stmnt := range stmnts {
+		nodes, err := parser.Parse(stmnt.Text)
+		if err != nil {
+			return "", fmt.Errorf("line %v: %w", stmnt.Line, err)
+		}
+		rewritten[idx] = idempotentStatement(nodes[0], stmnt.Text)
+	}
+	return strings.Join(rewritten, "\n"), nil
+}
+
+// idempotentStatement rewrites the text of the parsed statement
+func idempotentStatement(node *parser.StatementNode, text string) string {
+	switch node.Kind {
+	case parser.KindCreateStream, parser.KindCreateStreamAs, parser.KindCreateTable, parser.KindCreateTableAs:
+		if !node.IfNotExists && !node.OrReplace {
+			return createSource.ReplaceAllString(text, "$1 IF NOT EXISTS ")
+		}
+	case parser.KindCreateType:
+		if !node.IfNotExists {
+			return createType.ReplaceAllString(text, "$1 IF NOT EXISTS ")
+		}
+	case parser.KindDropStream, parser.KindDropTable, parser.KindDropType:
+		if !node.IfExists {
+			return dropObject.ReplaceAllString(text, "$1 IF EXISTS ")
+		}
+	}
+	return text
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestIdempotentStatements(t *testing.T) {
+	sql := `create stream dogs (id string key) with (kafka_topic='dogs', value_format='json');
+CREATE SOURCE TABLE CATS (ID STRING PRIMARY KEY) WITH (KAFKA_TOPIC='cats', VALUE_FORMAT='JSON');
+CREATE OR REPLACE STREAM BIG_DOGS AS SELECT * FROM DOGS;
+CREATE TABLE IF NOT EXISTS T AS SELECT ID, COUNT(*) AS CT FROM DOGS GROUP BY ID;
+CREATE TYPE ADDRESS AS STRUCT<STREET STRING>;
+drop   table  T delete topic;
+DROP TYPE IF EXISTS ADDRESS;
+INSERT INTO DOGS (ID) VALUES ('1');`
+	rewritten, err := ksqldb.IdempotentStatements(sql)
+	require.Nil(t, err)
+	require.Equal(t, `create stream IF NOT EXISTS dogs (id string key) with (kafka_topic='dogs', value_format='json');
+CREATE SOURCE TABLE IF NOT EXISTS CATS (ID STRING PRIMARY KEY) WITH (KAFKA_TOPIC='cats', VALUE_FORMAT='JSON');
+CREATE OR REPLACE STREAM BIG_DOGS AS SELECT * FROM DOGS;
+CREATE TABLE IF NOT EXISTS T AS SELECT ID, COUNT(*) AS CT FROM DOGS GROUP BY ID;
+CREATE TYPE IF NOT EXISTS ADDRESS AS STRUCT<STREET STRING>;
+drop   table IF EXISTS T delete topic;
+DROP TYPE IF EXISTS ADDRESS;
+INSERT INTO DOGS (ID) VALUES ('1');`, rewritten)
+
+	_, err = ksqldb.IdempotentStatements("create stream;")
+	require.NotNil(t, err)
+}
+
+func TestExecute_Idempotent(t *testing.T) {
+	m := mockKsql(t, []string{"CREATE STREAM IF NOT EXISTS DOGS (ID STRING KEY) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');"}, []string{
+		`[{"@type":"currentStatus","commandStatus":{"status":"SUCCESS","message":"Stream created"}}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	_, err := kcl.Execute(ksqldb.ExecOptions{
+		KSql:       "CREATE STREAM DOGS (ID STRING KEY) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');",
+		Idempotent: true,
+	})
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+}
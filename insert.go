@@ -0,0 +1,105 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Insert inserts the struct value into the stream or table with
+// INSERT INTO ... VALUES on the ksql endpoint. It is meant for low volume
+// writes which don't need a streaming insert session.
+//
+// The fields are mapped to columns like with Scan: by their `ksql` tag or by
+// the field name; fields tagged with `ksql:"-"`, unexported fields and
+// Window fields are skipped. value can be a struct or a pointer to a struct.
+//
+//	type Dog struct {
+//		ID   string `ksql:"ID,key"`
+//		Name string `ksql:"NAME"`
+//	}
+//	err := client.Insert(ctx, "DOGS", Dog{ID: "1", Name: "Pluto"})
+//
+// Names which are not plain identifiers are back quoted, so they are
+// case sensitive.
+func (api *KsqldbClient) Insert(ctx context.Context, stream string, value interface{}) error {
+	stmnt, err := InsertStatement(stream, value)
+	if err != nil {
+		return err
+	}
+	if _, err := api.execute(ctx, ExecOptions{KSql: stmnt}); err != nil {
+		return fmt.Errorf("can't insert into %v: %w", stream, err)
+	}
+	return nil
+}
+
+// InsertStatement returns the INSERT INTO ... VALUES statement of Insert
+func InsertStatement(stream string, value interface{}) (string, error) {
+	columns, values, err := insertColumns(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v);", quoteFieldName(stream), strings.Join(columns, ", "), strings.Join(values, ", ")), nil
+}
+
+// insertColumn is a column of an insert with its field
+type insertColumn struct {
+	field structField
+	value reflect.Value
+}
+
+// insertFields returns the fields of the struct value which are inserted
+func insertFields(value interface{}) ([]insertColumn, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't insert %T: value must be a struct or a non nil pointer to a struct", value)
+	}
+	fields := []insertColumn{}
+	for _, field := range structFields(rv.Type()) {
+		if field.typ == windowType || field.typ == reflect.PtrTo(windowType) {
+			continue
+		}
+		fields = append(fields, insertColumn{field: field, value: rv.FieldByIndex(field.index)})
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("can't insert %T: no columns", value)
+	}
+	return fields, nil
+}
+
+// insertColumns returns the quoted columns and the literals of the struct value
+func insertColumns(value interface{}) (columns []string, values []string, err error) {
+	fields, err := insertFields(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, f := range fields {
+		literal, err := QuoteLiteral(f.value.Interface())
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't insert %v: %w", f.field.name, err)
+		}
+		columns = append(columns, quoteFieldName(f.field.name))
+		values = append(values, literal)
+	}
+	return columns, values, nil
+}
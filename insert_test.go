@@ -0,0 +1,110 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// mockKsql returns a client mock which expects the statements on the ksql
+// and the query-stream endpoint in order and answers them with the responses
+func mockKsql(t *testing.T, statements []string, responses []string) *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	for idx := range statements {
+		m.Mock.On("Do", matchStatement(t, statements[idx])).Return(pullResponse(responses[idx]), nil).Once()
+	}
+	return &m
+}
+
+// matchStatement matches requests of the ksql and the query-stream endpoint with the statement
+func matchStatement(t *testing.T, stmnt string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool {
+		var options struct {
+			KSql string `json:"ksql"`
+			Sql  string `json:"sql"`
+		}
+		if req.Body == nil {
+			return false
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		require.Nil(t, json.Unmarshal(body, &options))
+		return options.KSql+options.Sql == stmnt
+	})
+}
+
+// pullResponse returns a new OK response with the body for every call
+func pullResponse(body string) func(*http.Request) *http.Response {
+	return func(*http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}
+}
+
+type insertDog struct {
+	ID     string        `ksql:"ID,key"`
+	Name   string        `ksql:"NAME"`
+	Age    *int          `ksql:"AGE"`
+	Tags   []string      `ksql:"TAGS"`
+	Window ksqldb.Window `ksql:"WINDOW"`
+	Note   string        `ksql:"-"`
+}
+
+func TestInsertStatement(t *testing.T) {
+	stmnt, err := ksqldb.InsertStatement("DOGS", &insertDog{ID: "1", Name: "Pluto's", Tags: []string{"small"}})
+	require.Nil(t, err)
+	require.Equal(t, "INSERT INTO DOGS (ID, NAME, AGE, TAGS) VALUES ('1', 'Pluto''s', NULL, ARRAY['small']);", stmnt)
+
+	stmnt, err = ksqldb.InsertStatement("my dogs", struct{ Name string }{"Rex"})
+	require.Nil(t, err)
+	require.Equal(t, "INSERT INTO `my dogs` (Name) VALUES ('Rex');", stmnt)
+
+	_, err = ksqldb.InsertStatement("DOGS", "Rex")
+	require.Equal(t, "can't insert string: value must be a struct or a non nil pointer to a struct", err.Error())
+	_, err = ksqldb.InsertStatement("DOGS", struct{}{})
+	require.Equal(t, "can't insert struct {}: no columns", err.Error())
+}
+
+func TestInsert(t *testing.T) {
+	stmnt := "INSERT INTO DOGS (ID, NAME, AGE, TAGS) VALUES ('1', 'Pluto', NULL, NULL);"
+	m := mockKsql(t, []string{stmnt}, []string{`[]`})
+	kcl, _ := ksqldb.NewClient(m)
+	require.Nil(t, kcl.Insert(context.Background(), "DOGS", insertDog{ID: "1", Name: "Pluto"}))
+	m.AssertExpectations(t)
+}
+
+func TestInsert_Error(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/ksql")
+	m.Mock.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"@type":"statement_error","error_code":40001,"message":"Cannot insert values into an unknown stream/table: DOGS"}`))),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	err := kcl.Insert(context.Background(), "DOGS", insertDog{ID: "1"})
+	require.Equal(t, "can't insert into DOGS: Cannot insert values into an unknown stream/table: DOGS", err.Error())
+}
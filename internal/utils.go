@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/thmeitz/ksqldb-go/parser"
 )
 
 // ValidateUrl checks the url; url must not contain a trailing slash
@@ -40,9 +43,22 @@ func GetUrl(path string) (*url.URL, error) {
 
 // SanitizeQuery sanitizes the given content
 //
-// eventually we can use the KSqlParser to rewrite the query
+// The content is tokenized by the KSqlLexer first. Whitespaces and comments
+// are collapsed into a single space, leading and trailing ones are removed.
+// String literals and quoted identifiers are never altered.
 func SanitizeQuery(content string) string {
-	content = strings.ReplaceAll(content, "\t", "")
-	content = strings.ReplaceAll(content, "\n", "")
-	return content
+	var sb strings.Builder
+	space := false
+	for _, token := range parser.Tokenize(content) {
+		if token.GetChannel() != antlr.TokenDefaultChannel {
+			space = true
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		space = false
+		sb.WriteString(token.GetText())
+	}
+	return sb.String()
 }
@@ -58,3 +58,19 @@ func TestClientSanitizeQuery(t *testing.T) {
 	`)
 	require.Equal(t, "This is the house of Nicolas", sanitizedString)
 }
+
+func TestSanitizeQuery_KeepsLiterals(t *testing.T) {
+	sanitizedString := internal.SanitizeQuery("INSERT INTO DOGS (ID, NAME)\n\tVALUES ('1', 'two\n\tlines  and spaces'); ")
+	require.Equal(t, "INSERT INTO DOGS (ID, NAME) VALUES ('1', 'two\n\tlines  and spaces');", sanitizedString)
+
+	sanitizedString = internal.SanitizeQuery("SELECT `my\tcol`, \"a\nb\"\nFROM DOGS;")
+	require.Equal(t, "SELECT `my\tcol`, \"a\nb\" FROM DOGS;", sanitizedString)
+}
+
+func TestSanitizeQuery_Comments(t *testing.T) {
+	sanitizedString := internal.SanitizeQuery("SELECT NAME -- the name\nFROM DOGS /* all\ndogs */ EMIT CHANGES;")
+	require.Equal(t, "SELECT NAME FROM DOGS EMIT CHANGES;", sanitizedString)
+
+	sanitizedString = internal.SanitizeQuery("SELECT NAME\nFROM DOGS;")
+	require.Equal(t, "SELECT NAME FROM DOGS;", sanitizedString)
+}
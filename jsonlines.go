@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSONLinesWriter writes rows as newline delimited JSON objects
+// keyed by the column names of the header, ex.
+//
+//	{"ID":"1","NAME":"Lara","AGE":3}
+//
+// The keys keep the column order. Values of INTEGER and BIGINT columns
+// are written as integers.
+type JSONLinesWriter struct {
+	w       io.Writer
+	columns []Column
+	keys    [][]byte
+}
+
+// NewJSONLinesWriter returns a JSONLinesWriter for the columns of the header
+func NewJSONLinesWriter(w io.Writer, header Header) *JSONLinesWriter {
+	jw := JSONLinesWriter{w: w, columns: header.columns}
+	for _, col := range header.columns {
+		key, _ := json.Marshal(col.Name)
+		jw.keys = append(jw.keys, key)
+	}
+	return &jw
+}
+
+// Write writes the row as JSON object followed by a newline
+func (jw *JSONLinesWriter) Write(row Row) error {
+	if len(row) != len(jw.columns) {
+		return fmt.Errorf("can't write row: got %v values for %v columns", len(row), len(jw.columns))
+	}
+
+	// render the whole line first, so no partial lines are written
+	var line bytes.Buffer
+	line.WriteByte('{')
+	for idx, value := range row {
+		if idx > 0 {
+			line.WriteByte(',')
+		}
+		line.Write(jw.keys[idx])
+		line.WriteByte(':')
+		data, err := renderJSONValue(jw.columns[idx], value)
+		if err != nil {
+			return fmt.Errorf("can't write column %v: %w", jw.columns[idx].Name, err)
+		}
+		line.Write(data)
+	}
+	line.WriteString("}\n")
+	_, err := jw.w.Write(line.Bytes())
+	return err
+}
+
+// WritePayload writes all rows of the payload
+func (jw *JSONLinesWriter) WritePayload(payload Payload) error {
+	for _, row := range payload {
+		if err := jw.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// baseType returns the type without parameters, ex. ARRAY for ARRAY<STRING>
+func baseType(typ string) string {
+	if idx := strings.IndexAny(typ, "<("); idx >= 0 {
+		typ = typ[:idx]
+	}
+	return strings.TrimSpace(typ)
+}
+
+func renderJSONValue(col Column, value interface{}) ([]byte, error) {
+	switch baseType(col.Type) {
+	case "INTEGER", "INT", "BIGINT":
+		if v, ok := value.(float64); ok {
+			return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+		}
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "ID", Type: "STRING"},
+		{Name: "ROWTIME", Type: "BIGINT"},
+		{Name: "WEIGHT", Type: "DOUBLE"},
+		{Name: "TAGS", Type: "ARRAY<STRING>"},
+		{Name: "ADDRESS", Type: "STRUCT<`STREET` STRING>"},
+	})
+	var buf bytes.Buffer
+	jw := ksqldb.NewJSONLinesWriter(&buf, header)
+
+	err := jw.WritePayload(ksqldb.Payload{
+		{"1", float64(1637049600000000000), 12.5, []interface{}{"a"}, map[string]interface{}{"STREET": "Main"}},
+		{"2\"", nil, nil, nil, nil},
+	})
+	require.Nil(t, err)
+	require.Equal(t, `{"ID":"1","ROWTIME":1637049600000000000,"WEIGHT":12.5,"TAGS":["a"],"ADDRESS":{"STREET":"Main"}}
+{"ID":"2\"","ROWTIME":null,"WEIGHT":null,"TAGS":null,"ADDRESS":null}
+`, buf.String())
+}
+
+func TestJSONLinesWriter_ColumnMismatch(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{{Name: "ID", Type: "STRING"}})
+	var buf bytes.Buffer
+	err := ksqldb.NewJSONLinesWriter(&buf, header).Write(ksqldb.Row{"1", "2"})
+	require.NotNil(t, err)
+	require.Equal(t, "can't write row: got 2 values for 1 columns", err.Error())
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,363 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ksqlarrow converts ksqlDB query results into Apache Arrow
+// record batches and Parquet files.
+//
+// It is a separate module, so the Arrow dependencies are only pulled in
+// if you need them:
+//
+//	go get github.com/thmeitz/ksqldb-go/ksqlarrow
+package ksqlarrow
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// ArrowType returns the Arrow data type of the ksql schema.
+//
+// BOOLEAN is mapped to Boolean, INTEGER to Int32, BIGINT to Int64, DOUBLE to Float64,
+// DECIMAL to Decimal128, STRING to String, BYTES to Binary, TIMESTAMP to Timestamp(ms, UTC),
+// DATE to Date32, TIME to Time32(ms), ARRAY to List, MAP to Map and STRUCT to Struct.
+func ArrowType(schema ksqldb.Schema) (arrow.DataType, error) {
+	switch schema.Type {
+	case "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean, nil
+	case "INTEGER", "INT":
+		return arrow.PrimitiveTypes.Int32, nil
+	case "BIGINT":
+		return arrow.PrimitiveTypes.Int64, nil
+	case "DOUBLE":
+		return arrow.PrimitiveTypes.Float64, nil
+	case "DECIMAL":
+		precision, okp := intParameter(schema.Parameters["precision"])
+		scale, oks := intParameter(schema.Parameters["scale"])
+		if !okp || !oks {
+			return arrow.PrimitiveTypes.Float64, nil
+		}
+		return &arrow.Decimal128Type{Precision: int32(precision), Scale: int32(scale)}, nil
+	case "STRING", "VARCHAR":
+		return arrow.BinaryTypes.String, nil
+	case "BYTES":
+		return arrow.BinaryTypes.Binary, nil
+	case "TIMESTAMP":
+		return &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, nil
+	case "DATE":
+		return arrow.FixedWidthTypes.Date32, nil
+	case "TIME":
+		return arrow.FixedWidthTypes.Time32ms, nil
+	case "ARRAY", "MAP":
+		if schema.MemberSchema == nil {
+			return nil, fmt.Errorf("%v without member type", schema.Type)
+		}
+		member, err := ArrowType(*schema.MemberSchema)
+		if err != nil {
+			return nil, err
+		}
+		if schema.Type == "ARRAY" {
+			return arrow.ListOf(member), nil
+		}
+		return arrow.MapOf(arrow.BinaryTypes.String, member), nil
+	case "STRUCT":
+		fields := make([]arrow.Field, len(schema.Fields))
+		for idx, field := range schema.Fields {
+			dt, err := ArrowType(field.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("field %v: %w", field.Name, err)
+			}
+			fields[idx] = arrow.Field{Name: field.Name, Type: dt, Nullable: true}
+		}
+		return arrow.StructOf(fields...), nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", schema.Type)
+}
+
+// intParameter returns a numeric schema parameter. Parsed schemas hold int,
+// schemas decoded from JSON (ex. DESCRIBE) hold float64.
+func intParameter(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), v == float64(int(v))
+	}
+	return 0, false
+}
+
+// Schema returns the Arrow schema for the columns of the header
+func Schema(header ksqldb.Header) (*arrow.Schema, error) {
+	fields := []arrow.Field{}
+	for _, col := range header.Columns() {
+		schema, err := ksqldb.ParseSchema(col.Type)
+		if err != nil {
+			return nil, err
+		}
+		dt, err := ArrowType(schema)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %w", col.Name, err)
+		}
+		fields = append(fields, arrow.Field{Name: col.Name, Type: dt, Nullable: true})
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// RecordBuilder appends rows and builds Arrow record batches
+type RecordBuilder struct {
+	schema  *arrow.Schema
+	builder *array.RecordBuilder
+}
+
+// NewRecordBuilder returns a RecordBuilder for the columns of the header.
+// Call Release when the builder is no longer needed.
+func NewRecordBuilder(mem memory.Allocator, header ksqldb.Header) (*RecordBuilder, error) {
+	schema, err := Schema(header)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordBuilder{schema: schema, builder: array.NewRecordBuilder(mem, schema)}, nil
+}
+
+// Schema returns the Arrow schema of the records
+func (b *RecordBuilder) Schema() *arrow.Schema {
+	return b.schema
+}
+
+// Append appends the row to the current record batch. The row is converted
+// before it is appended, so a row which can't be converted is not appended
+// and the record batch stays valid.
+func (b *RecordBuilder) Append(row ksqldb.Row) error {
+	if len(row) != len(b.schema.Fields()) {
+		return fmt.Errorf("can't append row: got %v values for %v columns", len(row), len(b.schema.Fields()))
+	}
+	values := make([]interface{}, len(row))
+	for idx, value := range row {
+		field := b.schema.Field(idx)
+		v, err := convertValue(field.Type, value)
+		if err != nil {
+			return fmt.Errorf("can't append column %v: %w", field.Name, err)
+		}
+		values[idx] = v
+	}
+	for idx, v := range values {
+		appendValue(b.builder.Field(idx), v)
+	}
+	return nil
+}
+
+// NewRecord returns the appended rows as record batch and resets the builder.
+// The caller must Release the record.
+func (b *RecordBuilder) NewRecord() arrow.Record {
+	return b.builder.NewRecord()
+}
+
+// Release releases the builder
+func (b *RecordBuilder) Release() {
+	b.builder.Release()
+}
+
+// NewRecord converts the payload into a record batch.
+// The caller must Release the record.
+func NewRecord(mem memory.Allocator, header ksqldb.Header, payload ksqldb.Payload) (arrow.Record, error) {
+	b, err := NewRecordBuilder(mem, header)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Release()
+
+	for _, row := range payload {
+		if err := b.Append(row); err != nil {
+			return nil, err
+		}
+	}
+	return b.NewRecord(), nil
+}
+
+// mapValue is a converted MAP value with sorted keys
+type mapValue struct {
+	keys   []string
+	values []interface{}
+}
+
+// convertValue converts the json decoded value into the value of the Arrow
+// type which is appended by appendValue: ARRAY and STRUCT values are
+// converted into []interface{}, MAP values into mapValue.
+func convertValue(dt arrow.DataType, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := dt.(type) {
+	case *arrow.BooleanType:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case *arrow.Int32Type:
+		if v, ok := value.(float64); ok {
+			return int32(v), nil
+		}
+	case *arrow.Int64Type:
+		if v, ok := value.(float64); ok {
+			return int64(v), nil
+		}
+	case *arrow.Float64Type:
+		if v, ok := value.(float64); ok {
+			return v, nil
+		}
+	case *arrow.Decimal128Type:
+		if v, ok := value.(float64); ok {
+			return decimal128.FromFloat64(v, t.Precision, t.Scale)
+		}
+	case *arrow.StringType:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case *arrow.BinaryType:
+		if v, ok := value.(string); ok {
+			return base64.StdEncoding.DecodeString(v)
+		}
+	case *arrow.TimestampType:
+		switch v := value.(type) {
+		case string:
+			ts, err := time.Parse(ksqldb.KSQL_TIMESTAMP_FORMAT, v)
+			if err != nil {
+				return nil, err
+			}
+			return arrow.Timestamp(ts.UnixNano() / int64(time.Millisecond)), nil
+		case float64:
+			// epoch millis
+			return arrow.Timestamp(v), nil
+		}
+	case *arrow.Date32Type:
+		if v, ok := value.(string); ok {
+			d, err := time.Parse(ksqldb.KSQL_DATE_FORMAT, v)
+			if err != nil {
+				return nil, err
+			}
+			return arrow.Date32FromTime(d), nil
+		}
+	case *arrow.Time32Type:
+		if v, ok := value.(string); ok {
+			tm, err := time.Parse(ksqldb.KSQL_TIME_FORMAT, v)
+			if err != nil {
+				return nil, err
+			}
+			midnight := time.Date(tm.Year(), tm.Month(), tm.Day(), 0, 0, 0, 0, time.UTC)
+			return arrow.Time32(tm.Sub(midnight) / time.Millisecond), nil
+		}
+	case *arrow.ListType:
+		if v, ok := value.([]interface{}); ok {
+			items := make([]interface{}, len(v))
+			for idx, item := range v {
+				converted, err := convertValue(t.Elem(), item)
+				if err != nil {
+					return nil, err
+				}
+				items[idx] = converted
+			}
+			return items, nil
+		}
+	case *arrow.MapType:
+		if v, ok := value.(map[string]interface{}); ok {
+			m := mapValue{keys: make([]string, 0, len(v))}
+			for key := range v {
+				m.keys = append(m.keys, key)
+			}
+			sort.Strings(m.keys)
+			for _, key := range m.keys {
+				converted, err := convertValue(t.ItemType(), v[key])
+				if err != nil {
+					return nil, err
+				}
+				m.values = append(m.values, converted)
+			}
+			return m, nil
+		}
+	case *arrow.StructType:
+		if v, ok := value.(map[string]interface{}); ok {
+			fields := make([]interface{}, len(t.Fields()))
+			for idx, field := range t.Fields() {
+				converted, err := convertValue(field.Type, v[field.Name])
+				if err != nil {
+					return nil, fmt.Errorf("field %v: %w", field.Name, err)
+				}
+				fields[idx] = converted
+			}
+			return fields, nil
+		}
+	}
+	return nil, fmt.Errorf("can't append %T to %v", value, dt)
+}
+
+// appendValue appends the value converted by convertValue to the builder
+func appendValue(builder array.Builder, value interface{}) {
+	if value == nil {
+		builder.AppendNull()
+		return
+	}
+
+	switch b := builder.(type) {
+	case *array.BooleanBuilder:
+		b.Append(value.(bool))
+	case *array.Int32Builder:
+		b.Append(value.(int32))
+	case *array.Int64Builder:
+		b.Append(value.(int64))
+	case *array.Float64Builder:
+		b.Append(value.(float64))
+	case *array.Decimal128Builder:
+		b.Append(value.(decimal128.Num))
+	case *array.StringBuilder:
+		b.Append(value.(string))
+	case *array.BinaryBuilder:
+		b.Append(value.([]byte))
+	case *array.TimestampBuilder:
+		b.Append(value.(arrow.Timestamp))
+	case *array.Date32Builder:
+		b.Append(value.(arrow.Date32))
+	case *array.Time32Builder:
+		b.Append(value.(arrow.Time32))
+	case *array.ListBuilder:
+		b.Append(true)
+		for _, item := range value.([]interface{}) {
+			appendValue(b.ValueBuilder(), item)
+		}
+	case *array.MapBuilder:
+		b.Append(true)
+		m := value.(mapValue)
+		for idx, key := range m.keys {
+			b.KeyBuilder().(*array.StringBuilder).Append(key)
+			appendValue(b.ItemBuilder(), m.values[idx])
+		}
+	case *array.StructBuilder:
+		b.Append(true)
+		for idx, field := range value.([]interface{}) {
+			appendValue(b.FieldBuilder(idx), field)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqlarrow_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/ksqlarrow"
+)
+
+var dogColumns = []ksqldb.Column{
+	{Name: "ID", Type: "STRING"},
+	{Name: "AGE", Type: "INTEGER"},
+	{Name: "ROWTIME", Type: "BIGINT"},
+	{Name: "PRICE", Type: "DECIMAL(4, 2)"},
+	{Name: "BORN", Type: "TIMESTAMP"},
+	{Name: "TAGS", Type: "ARRAY<STRING>"},
+	{Name: "SCORES", Type: "MAP<STRING, INTEGER>"},
+	{Name: "ADDRESS", Type: "STRUCT<`STREET` STRING>"},
+}
+
+var dogPayload = ksqldb.Payload{
+	{"1", float64(3), float64(1637049600000), 12.5, "2021-11-16T06:00:00.000", []interface{}{"small"}, map[string]interface{}{"b": float64(2), "a": float64(1)}, map[string]interface{}{"STREET": "Main"}},
+	{"2", nil, float64(1637049600001), nil, nil, nil, nil, nil},
+}
+
+func headerWith(columns []ksqldb.Column) ksqldb.Header {
+	return ksqldb.NewHeader("", columns)
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := ksqlarrow.Schema(headerWith(dogColumns))
+	require.Nil(t, err)
+	require.Equal(t, "schema:\n  fields: 8\n"+
+		"    - ID: type=utf8, nullable\n"+
+		"    - AGE: type=int32, nullable\n"+
+		"    - ROWTIME: type=int64, nullable\n"+
+		"    - PRICE: type=decimal(4, 2), nullable\n"+
+		"    - BORN: type=timestamp[ms, tz=UTC], nullable\n"+
+		"    - TAGS: type=list<item: utf8, nullable>, nullable\n"+
+		"    - SCORES: type=map<utf8, int32, items_nullable>, nullable\n"+
+		"    - ADDRESS: type=struct<STREET: utf8>, nullable", schema.String())
+}
+
+func TestArrowType_DecimalFromJSON(t *testing.T) {
+	var schema ksqldb.Schema
+	require.Nil(t, json.Unmarshal([]byte(`{"type":"DECIMAL","parameters":{"precision":4,"scale":2}}`), &schema))
+	dt, err := ksqlarrow.ArrowType(schema)
+	require.Nil(t, err)
+	require.Equal(t, &arrow.Decimal128Type{Precision: 4, Scale: 2}, dt)
+}
+
+func TestNewRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	record, err := ksqlarrow.NewRecord(mem, headerWith(dogColumns), dogPayload)
+	require.Nil(t, err)
+	defer record.Release()
+
+	require.Equal(t, int64(2), record.NumRows())
+	require.Equal(t, "2", record.Column(0).(*array.String).Value(1))
+	require.Equal(t, int32(3), record.Column(1).(*array.Int32).Value(0))
+	require.True(t, record.Column(1).IsNull(1))
+	require.Equal(t, int64(1637049600001), record.Column(2).(*array.Int64).Value(1))
+	require.Equal(t, "12.50", record.Column(3).(*array.Decimal128).Value(0).ToString(2))
+	require.Equal(t, arrow.Timestamp(1637042400000), record.Column(4).(*array.Timestamp).Value(0))
+	require.Equal(t, `["small"]`, record.Column(5).(*array.List).ValueStr(0))
+	require.JSONEq(t, `{"STREET":"Main"}`, record.Column(7).(*array.Struct).ValueStr(0))
+}
+
+func TestNewRecord_Errors(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	_, err := ksqlarrow.NewRecord(mem, headerWith(dogColumns[:2]), ksqldb.Payload{{"1", "old"}})
+	require.NotNil(t, err)
+	require.Equal(t, "can't append column AGE: can't append string to int32", err.Error())
+
+	_, err = ksqlarrow.NewRecord(mem, headerWith(dogColumns[:2]), ksqldb.Payload{{"1"}})
+	require.NotNil(t, err)
+
+	_, err = ksqlarrow.Schema(headerWith([]ksqldb.Column{{Name: "X", Type: "GEOMETRY"}}))
+	require.NotNil(t, err)
+	require.Equal(t, "column X: unsupported type GEOMETRY", err.Error())
+}
+
+func TestRecordBuilder_AppendError(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b, err := ksqlarrow.NewRecordBuilder(mem, headerWith(dogColumns))
+	require.Nil(t, err)
+	defer b.Release()
+
+	require.Nil(t, b.Append(dogPayload[0]))
+	// the failing nested value of the last column must not append the other columns
+	bad := ksqldb.Row{"3", float64(1), float64(1637049600002), nil, nil, []interface{}{"big"}, nil, map[string]interface{}{"STREET": float64(1)}}
+	require.Equal(t, "can't append column ADDRESS: field STREET: can't append float64 to utf8", b.Append(bad).Error())
+	require.Nil(t, b.Append(dogPayload[1]))
+
+	record := b.NewRecord()
+	defer record.Release()
+	require.Equal(t, int64(2), record.NumRows())
+	for idx, col := range record.Columns() {
+		require.Equal(t, 2, col.Len(), record.ColumnName(idx))
+	}
+	require.Equal(t, `["small"]`, record.Column(5).(*array.List).ValueStr(0))
+	require.True(t, record.Column(5).IsNull(1))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqlarrow
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// WriteParquet writes the payload as Parquet file.
+// If props is nil, the default writer properties are used.
+func WriteParquet(w io.Writer, header ksqldb.Header, payload ksqldb.Payload, props *parquet.WriterProperties) error {
+	mem := memory.NewGoAllocator()
+	record, err := NewRecord(mem, header, payload)
+	if err != nil {
+		return err
+	}
+	defer record.Release()
+
+	if props == nil {
+		props = parquet.NewWriterProperties(parquet.WithAllocator(mem))
+	}
+	fw, err := pqarrow.NewFileWriter(record.Schema(), w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return fmt.Errorf("can't create parquet writer: %w", err)
+	}
+	if err := fw.Write(record); err != nil {
+		fw.Close()
+		return fmt.Errorf("can't write parquet file: %w", err)
+	}
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("can't close parquet writer: %w", err)
+	}
+	return nil
+}
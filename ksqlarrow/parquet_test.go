@@ -0,0 +1,40 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqlarrow_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/ksqlarrow"
+)
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, ksqlarrow.WriteParquet(&buf, headerWith(dogColumns), dogPayload, nil))
+
+	table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.NewGoAllocator())
+	require.Nil(t, err)
+	defer table.Release()
+	require.Equal(t, int64(2), table.NumRows())
+	require.Equal(t, int64(8), table.NumCols())
+	require.Equal(t, "ADDRESS", table.Schema().Field(7).Name)
+}
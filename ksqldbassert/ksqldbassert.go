@@ -0,0 +1,276 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ksqldbassert has assertions for end-to-end tests of ksqlDB pipelines.
+// The assertions run a query until its result matches or the timeout expires
+// and fail the test with a diff of the expected and the actual result:
+//
+//	assert := ksqldbassert.New(t, &client).WithTimeout(time.Minute)
+//	assert.ExpectHeader("SELECT * FROM BIG_DOGS EMIT CHANGES;",
+//		ksqldb.Column{Name: "ID", Type: "STRING"},
+//		ksqldb.Column{Name: "AGE", Type: "INTEGER"})
+//	assert.ExpectRows("SELECT * FROM BIG_DOGS EMIT CHANGES;",
+//		ksqldb.Record{"ID": "1", "AGE": 12},
+//		ksqldb.Record{"ID": "2", "AGE": 15})
+//
+// Pull queries are repeated until they match. Push queries read from the
+// earliest offset and match the rows received so far after every row.
+package ksqldbassert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+const (
+	// DEFAULT_TIMEOUT is the time an assertion waits for a matching result
+	DEFAULT_TIMEOUT = 30 * time.Second
+	// POLL_INTERVAL is the pause between two runs of a query
+	POLL_INTERVAL = 200 * time.Millisecond
+)
+
+// TestingT is the part of *testing.T used by the assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	FailNow()
+}
+
+// Assert runs the assertions of a test with a client
+type Assert struct {
+	t       TestingT
+	client  *ksqldb.KsqldbClient
+	timeout time.Duration
+}
+
+// New returns the assertions of the test with the client and the DEFAULT_TIMEOUT
+func New(t TestingT, client *ksqldb.KsqldbClient) *Assert {
+	return &Assert{t: t, client: client, timeout: DEFAULT_TIMEOUT}
+}
+
+// WithTimeout returns a copy of the assertions with the timeout
+func (a *Assert) WithTimeout(timeout time.Duration) *Assert {
+	c := *a
+	c.timeout = timeout
+	return &c
+}
+
+// ExpectHeader asserts the column names and types of the query, ex. of the
+// sink of a pipeline. It returns the header.
+//
+// Pull returns no header for empty results, so pull queries have to return a row.
+func (a *Assert) ExpectHeader(sql string, columns ...ksqldb.Column) ksqldb.Header {
+	a.t.Helper()
+	header, _ := a.Eventually(sql, func(header ksqldb.Header, payload ksqldb.Payload) error {
+		return diff("columns differ", renderColumns(columns), renderColumns(header.Columns()))
+	})
+	return header
+}
+
+// ExpectRows asserts the rows of the query in any order. Only the columns
+// of the expected records are compared, so volatile columns like ROWTIME
+// can be left out. Values are compared by their JSON encoding, ex. 12 matches
+// the INTEGER 12. It returns the header and the rows.
+func (a *Assert) ExpectRows(sql string, rows ...ksqldb.Record) (ksqldb.Header, ksqldb.Payload) {
+	a.t.Helper()
+	keys := map[string]bool{}
+	for _, row := range rows {
+		for key := range row {
+			keys[key] = true
+		}
+	}
+	expected := make([]string, len(rows))
+	for idx, row := range rows {
+		expected[idx] = renderRecord(row, keys)
+	}
+	sort.Strings(expected)
+	return a.Eventually(sql, func(header ksqldb.Header, payload ksqldb.Payload) error {
+		actual := make([]string, len(payload))
+		for idx, row := range payload {
+			record := ksqldb.Record{}
+			for col, column := range header.Columns() {
+				if col < len(row) {
+					record[column.Name] = row[col]
+				}
+			}
+			actual[idx] = renderRecord(record, keys)
+		}
+		sort.Strings(actual)
+		return diff("rows differ", expected, actual)
+	})
+}
+
+// Eventually runs the query until the condition returns nil for its result
+// and returns the result. The condition of push queries is called with all
+// rows received so far. If the timeout expires, the test fails with the last
+// error of the condition or the query.
+func (a *Assert) Eventually(sql string, condition func(header ksqldb.Header, payload ksqldb.Payload) error) (ksqldb.Header, ksqldb.Payload) {
+	a.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	run := a.pull
+	if isPushQuery(sql) {
+		run = a.push
+	}
+	for {
+		header, payload, err := run(ctx, sql, condition)
+		if err == nil {
+			return header, payload
+		}
+		select {
+		case <-ctx.Done():
+			a.t.Errorf("%v\nno match within %v: %v", sql, a.timeout, err)
+			a.t.FailNow()
+			return header, payload
+		case <-time.After(POLL_INTERVAL):
+		}
+	}
+}
+
+// pull runs the pull query once and checks its result
+func (a *Assert) pull(ctx context.Context, sql string, condition func(ksqldb.Header, ksqldb.Payload) error) (ksqldb.Header, ksqldb.Payload, error) {
+	header, payload, err := a.client.Pull(ctx, ksqldb.QueryOptions{Sql: sql, CacheTTL: -1})
+	if err != nil && !errors.Is(err, ksqldb.ErrNotFound) {
+		return header, payload, err
+	}
+	if payload == nil {
+		payload = ksqldb.Payload{}
+	}
+	return header, payload, condition(header, payload)
+}
+
+// push runs the push query and checks its result after the header and every row
+// until the condition matches, the query finishes or the context is done
+func (a *Assert) push(ctx context.Context, sql string, condition func(ksqldb.Header, ksqldb.Payload) error) (ksqldb.Header, ksqldb.Payload, error) {
+	q, err := a.client.Subscribe(ctx, sql, ksqldb.SubscribeOptions{
+		BufferSize: 100,
+		Properties: ksqldb.PropertyMap{"ksql.streams.auto.offset.reset": "earliest"},
+	})
+	if err != nil {
+		return ksqldb.Header{}, nil, err
+	}
+	defer func() { _ = q.Stop() }()
+
+	header, err := q.Header()
+	if err != nil {
+		return header, nil, err
+	}
+	payload := ksqldb.Payload{}
+	err = condition(header, payload)
+	for err != nil {
+		select {
+		case row, ok := <-q.Rows():
+			if !ok {
+				if qerr := q.Err(); qerr != nil {
+					return header, payload, qerr
+				}
+				return header, payload, err
+			}
+			payload = append(payload, row)
+			err = condition(header, payload)
+		case <-ctx.Done():
+			return header, payload, err
+		}
+	}
+	return header, payload, nil
+}
+
+// isPushQuery returns true for queries with EMIT CHANGES or EMIT FINAL
+func isPushQuery(sql string) bool {
+	nodes, err := parser.Parse(sql)
+	if err != nil || len(nodes) == 0 || nodes[0].Query == nil {
+		// the server reports the error
+		return false
+	}
+	return nodes[0].Query.Emit != ""
+}
+
+// renderColumns renders the columns as lines of name and type
+func renderColumns(columns []ksqldb.Column) []string {
+	lines := make([]string, len(columns))
+	for idx, col := range columns {
+		lines[idx] = col.Name + " " + col.Type
+	}
+	return lines
+}
+
+// renderRecord renders the values of the keys as JSON object with sorted keys
+func renderRecord(record ksqldb.Record, keys map[string]bool) string {
+	projected := map[string]interface{}{}
+	for key, value := range record {
+		if keys[key] {
+			projected[key] = value
+		}
+	}
+	data, err := json.Marshal(projected)
+	if err != nil {
+		return fmt.Sprintf("%v", projected)
+	}
+	return string(data)
+}
+
+// diff returns an error with the line diff of expected and actual; nil if they are equal.
+// Lines only in expected are prefixed with -, lines only in actual with +.
+func diff(message string, expected, actual []string) error {
+	// lcs[i][j] is the length of the longest common subsequence of expected[i:] and actual[j:]
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			switch {
+			case expected[i] == actual[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	if lcs[0][0] == len(expected) && len(expected) == len(actual) {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(message + " (-expected +actual):\n")
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			sb.WriteString("  " + expected[i] + "\n")
+			i++
+			j++
+		case j == len(actual) || i < len(expected) && lcs[i+1][j] >= lcs[i][j+1]:
+			sb.WriteString("- " + expected[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + actual[j] + "\n")
+			j++
+		}
+	}
+	return fmt.Errorf("%v", strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldbassert_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/ksqldbassert"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// recorder records the failures of the assertions
+type recorder struct {
+	errors []string
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) FailNow() {
+	r.failed = true
+}
+
+const dogsHeader = `{"queryId":"abc","columnNames":["ID","AGE"],"columnTypes":["STRING","INTEGER"]}`
+
+// pullMock answers pull queries with the responses, the last one is repeated
+func pullMock(responses ...string) *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	calls := 0
+	m.On("Do", mock.Anything).Return(func(*http.Request) *http.Response {
+		body := responses[len(responses)-1]
+		if calls < len(responses) {
+			body = responses[calls]
+		}
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	return &m
+}
+
+// pushMock streams the lines of one query and keeps the stream open until the query is closed
+func pushMock(lines ...string) *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	closed := make(chan struct{})
+	var closeOnce sync.Once
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			closeOnce.Do(func() { close(closed) })
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = pw.Write([]byte(strings.Join(lines, "\n") + "\n"))
+			select {
+			case <-closed:
+				pw.Close()
+			case <-req.Context().Done():
+				pw.CloseWithError(req.Context().Err())
+			}
+		}()
+		return &http.Response{StatusCode: http.StatusOK, Body: pr}
+	}, nil)
+	return &m
+}
+
+func TestExpectRows_Pull(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pullMock(
+		`[`+dogsHeader+`]`,
+		`[`+dogsHeader+`,["1",12]]`,
+		`[`+dogsHeader+`,["2",15],["1",12]]`,
+	))
+	r := &recorder{}
+	header, payload := ksqldbassert.New(r, &kcl).WithTimeout(5*time.Second).ExpectRows("SELECT * FROM DOGS;",
+		ksqldb.Record{"ID": "1", "AGE": 12},
+		ksqldb.Record{"ID": "2", "AGE": 15},
+	)
+	require.False(t, r.failed, r.errors)
+	require.Equal(t, "AGE", header.Columns()[1].Name)
+	require.Len(t, payload, 2)
+}
+
+func TestExpectRows_Diff(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pullMock(`[` + dogsHeader + `,["1",12],["2",16]]`))
+	r := &recorder{}
+	ksqldbassert.New(r, &kcl).WithTimeout(300*time.Millisecond).ExpectRows("SELECT * FROM DOGS;",
+		ksqldb.Record{"ID": "1", "AGE": 12},
+		ksqldb.Record{"ID": "2", "AGE": 15},
+	)
+	require.True(t, r.failed)
+	require.Len(t, r.errors, 1)
+	require.Equal(t, "SELECT * FROM DOGS;\nno match within 300ms: rows differ (-expected +actual):\n"+
+		`  {"AGE":12,"ID":"1"}`+"\n"+
+		`- {"AGE":15,"ID":"2"}`+"\n"+
+		`+ {"AGE":16,"ID":"2"}`, r.errors[0])
+}
+
+func TestExpectRows_PartialRecords(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pullMock(`[` + dogsHeader + `,["1",12]]`))
+	r := &recorder{}
+	ksqldbassert.New(r, &kcl).ExpectRows("SELECT * FROM DOGS;", ksqldb.Record{"ID": "1"})
+	require.False(t, r.failed, r.errors)
+}
+
+func TestExpectHeader(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pullMock(`[` + dogsHeader + `,["1",12]]`))
+	r := &recorder{}
+	a := ksqldbassert.New(r, &kcl).WithTimeout(300 * time.Millisecond)
+	a.ExpectHeader("SELECT * FROM DOGS;",
+		ksqldb.Column{Name: "ID", Type: "STRING"},
+		ksqldb.Column{Name: "AGE", Type: "INTEGER"},
+	)
+	require.False(t, r.failed, r.errors)
+
+	a.ExpectHeader("SELECT * FROM DOGS;",
+		ksqldb.Column{Name: "ID", Type: "STRING"},
+		ksqldb.Column{Name: "AGE", Type: "BIGINT"},
+	)
+	require.True(t, r.failed)
+	require.Contains(t, r.errors[0], "columns differ (-expected +actual):\n  ID STRING\n- AGE BIGINT\n+ AGE INTEGER")
+}
+
+func TestExpectRows_Push(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pushMock(dogsHeader, `["1",12]`, `["2",15]`))
+	r := &recorder{}
+	_, payload := ksqldbassert.New(r, &kcl).WithTimeout(5*time.Second).ExpectRows("SELECT * FROM DOGS EMIT CHANGES;",
+		ksqldb.Record{"ID": "2", "AGE": 15},
+		ksqldb.Record{"ID": "1", "AGE": 12},
+	)
+	require.False(t, r.failed, r.errors)
+	require.Len(t, payload, 2)
+}
+
+func TestEventually_Push(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(pushMock(dogsHeader, `["1",12]`))
+	r := &recorder{}
+	ksqldbassert.New(r, &kcl).WithTimeout(300*time.Millisecond).Eventually("SELECT * FROM DOGS EMIT CHANGES;",
+		func(header ksqldb.Header, payload ksqldb.Payload) error {
+			if len(payload) < 2 {
+				return fmt.Errorf("%v rows received", len(payload))
+			}
+			return nil
+		})
+	require.True(t, r.failed)
+	require.Equal(t, "SELECT * FROM DOGS EMIT CHANGES;\nno match within 300ms: 1 rows received", r.errors[0])
+}
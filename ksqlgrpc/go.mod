@@ -0,0 +1,31 @@
+module github.com/thmeitz/ksqldb-go/ksqlgrpc
+
+go 1.18
+
+require (
+	github.com/stretchr/testify v1.7.0
+	github.com/thmeitz/ksqldb-go v0.0.0
+	google.golang.org/grpc v1.49.0
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/Masterminds/log-go v0.4.0 // indirect
+	github.com/antlr/antlr4/runtime/Go/antlr v0.0.0-20211106181442-e4c1a74c66bd // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/mitchellh/mapstructure v1.4.2 // indirect
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.1.0 // indirect
+	golang.org/x/net v0.0.0-20210805182204-aaa1db679c0d // indirect
+	golang.org/x/sys v0.0.0-20211103235746-7861aae1554b // indirect
+	golang.org/x/text v0.3.7 // indirect
+	google.golang.org/genproto v0.0.0-20210828152312-66f60bf46e71 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// ksqlgrpc is released together with the root module, both are tagged with the
+// same version and the release pins the root module to that tag. Until then
+// v0.0.0 is resolved by the replace with the root module of the same commit.
+replace github.com/thmeitz/ksqldb-go => ../
@@ -0,0 +1,227 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ksqlgrpc serves ksqlDB pull and push queries over a generic gRPC
+// streaming service, so consumers in other languages get ksqlDB data through
+// a Go service without speaking the ksqlDB REST protocol.
+//
+// The service uses the well-known protobuf types only, see ksqldb.proto:
+// requests and headers are google.protobuf.Struct, so clients generate their
+// stubs without further message definitions. The first response of a query
+// is the header with the schema, every row follows as a list aligned with
+// the columns of the header:
+//
+//	{"header": {"queryId": "transient_DOGS_1", "columns": [{"name": "ID", "type": "STRING"}]}}
+//	{"row": ["1"]}
+//
+// It is a separate module, so the gRPC dependencies are only pulled in
+// if you need them:
+//
+//	go get github.com/thmeitz/ksqldb-go/ksqlgrpc
+package ksqlgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// SERVICE_NAME is the full name of the gRPC service
+const SERVICE_NAME = "ksqldb.v1.QueryService"
+
+// Options configures a Server
+type Options struct {
+	// Authorize is called with the sql of every query before it is run; a
+	// non nil error rejects the query and is returned to the caller, ex. a
+	// status.Error with codes.PermissionDenied
+	Authorize func(ctx context.Context, sql string) error
+	// BufferSize is the capacity of the row channel of push queries
+	BufferSize int
+}
+
+// Server serves the queries of a KsqldbClient, see Register
+type Server struct {
+	client  *ksqldb.KsqldbClient
+	options Options
+}
+
+// NewServer returns a server running the queries with the client
+func NewServer(client *ksqldb.KsqldbClient, options Options) *Server {
+	return &Server{client: client, options: options}
+}
+
+// ServiceDesc is the description of the service, it is registered by Register
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: SERVICE_NAME,
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Pull", Handler: pullHandler, ServerStreams: true},
+		{StreamName: "Push", Handler: pushHandler, ServerStreams: true},
+	},
+	Metadata: "ksqldb.proto",
+}
+
+// Register registers the service of the server, ex. on a *grpc.Server
+//
+//	srv := grpc.NewServer()
+//	ksqlgrpc.NewServer(&client, ksqlgrpc.Options{}).Register(srv)
+//	srv.Serve(listener)
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&ServiceDesc, s)
+}
+
+// request is a query request: {"sql": "...", "properties": {"name": "value"}}
+type request struct {
+	sql        string
+	properties ksqldb.PropertyMap
+}
+
+// receiveRequest receives and checks the request of the stream
+func (s *Server) receiveRequest(stream grpc.ServerStream) (request, error) {
+	msg := structpb.Struct{}
+	if err := stream.RecvMsg(&msg); err != nil {
+		return request{}, err
+	}
+	req := request{}
+	fields := msg.GetFields()
+	req.sql = fields["sql"].GetStringValue()
+	if req.sql == "" {
+		return request{}, status.Error(codes.InvalidArgument, "sql is required")
+	}
+	if props := fields["properties"].GetStructValue(); props != nil {
+		req.properties = ksqldb.PropertyMap{}
+		for name, value := range props.GetFields() {
+			req.properties[name] = fmt.Sprint(value.AsInterface())
+		}
+	}
+	if s.options.Authorize != nil {
+		if err := s.options.Authorize(stream.Context(), req.sql); err != nil {
+			return request{}, err
+		}
+	}
+	return req, nil
+}
+
+func pullHandler(srv interface{}, stream grpc.ServerStream) error {
+	s := srv.(*Server)
+	req, err := s.receiveRequest(stream)
+	if err != nil {
+		return err
+	}
+	header, payload, err := s.client.Pull(stream.Context(), ksqldb.QueryOptions{Sql: req.sql, Properties: req.properties})
+	if errors.Is(err, ksqldb.ErrNotFound) && len(header.Columns()) > 0 {
+		// empty results have a header
+		err = nil
+	}
+	if err != nil {
+		return statusError(err)
+	}
+	if err := sendHeader(stream, header); err != nil {
+		return err
+	}
+	for _, row := range payload {
+		if err := sendRow(stream, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pushHandler(srv interface{}, stream grpc.ServerStream) error {
+	s := srv.(*Server)
+	req, err := s.receiveRequest(stream)
+	if err != nil {
+		return err
+	}
+	// the query ends with the call
+	q, err := s.client.Subscribe(stream.Context(), req.sql, ksqldb.SubscribeOptions{
+		BufferSize: s.options.BufferSize,
+		Properties: req.properties,
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	defer q.Stop()
+	header, err := q.Header()
+	if err != nil {
+		return statusError(err)
+	}
+	if err := sendHeader(stream, header); err != nil {
+		return err
+	}
+	for row := range q.Rows() {
+		if err := sendRow(stream, row); err != nil {
+			return err
+		}
+	}
+	if err := q.Err(); err != nil {
+		return statusError(err)
+	}
+	return stream.Context().Err()
+}
+
+// sendHeader sends the header message
+func sendHeader(stream grpc.ServerStream, header ksqldb.Header) error {
+	columns := []interface{}{}
+	for _, col := range header.Columns() {
+		columns = append(columns, map[string]interface{}{"name": col.Name, "type": col.Type})
+	}
+	msg, err := structpb.NewStruct(map[string]interface{}{
+		"header": map[string]interface{}{"queryId": header.QueryId(), "columns": columns},
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(msg)
+}
+
+// sendRow sends the row message
+func sendRow(stream grpc.ServerStream, row ksqldb.Row) error {
+	values, err := structpb.NewList(row)
+	if err != nil {
+		return status.Errorf(codes.Internal, "can't convert row: %v", err)
+	}
+	msg := &structpb.Struct{Fields: map[string]*structpb.Value{"row": structpb.NewListValue(values)}}
+	return stream.SendMsg(msg)
+}
+
+// statusError returns the gRPC status of the query error
+func statusError(err error) error {
+	code := codes.Unknown
+	var syntaxErr *parser.SqlSyntaxErrorList
+	switch {
+	case errors.As(err, &syntaxErr), errors.Is(err, ksqldb.ErrStatementError), errors.Is(err, ksqldb.ErrEmptyQuery):
+		code = codes.InvalidArgument
+	case errors.Is(err, ksqldb.ErrUnauthorized):
+		code = codes.PermissionDenied
+	case errors.Is(err, ksqldb.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, ksqldb.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ksqldb.ErrClientShutdown):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqlgrpc_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/ksqlgrpc"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// dial serves the client and returns a connection to the service
+func dial(t *testing.T, kcl *ksqldb.KsqldbClient, options ksqlgrpc.Options) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	ksqlgrpc.NewServer(kcl, options).Register(srv)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// query starts the method with the request
+func query(t *testing.T, ctx context.Context, conn *grpc.ClientConn, method string, req map[string]interface{}) grpc.ClientStream {
+	desc := &grpc.StreamDesc{StreamName: method, ServerStreams: true}
+	stream, err := conn.NewStream(ctx, desc, "/"+ksqlgrpc.SERVICE_NAME+"/"+method)
+	require.Nil(t, err)
+	msg, err := structpb.NewStruct(req)
+	require.Nil(t, err)
+	require.Nil(t, stream.SendMsg(msg))
+	require.Nil(t, stream.CloseSend())
+	return stream
+}
+
+// receive returns the next message as map
+func receive(stream grpc.ClientStream) (map[string]interface{}, error) {
+	msg := structpb.Struct{}
+	if err := stream.RecvMsg(&msg); err != nil {
+		return nil, err
+	}
+	return msg.AsMap(), nil
+}
+
+var header = map[string]interface{}{"header": map[string]interface{}{
+	"queryId": "abc",
+	"columns": []interface{}{
+		map[string]interface{}{"name": "ID", "type": "STRING"},
+		map[string]interface{}{"name": "AGE", "type": "INTEGER"},
+	},
+}}
+
+func TestPull(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", ksqldb.QUERY_STREAM_ENDPOINT).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body: ioutil.NopCloser(strings.NewReader(
+			`[{"queryId":"abc","columnNames":["ID","AGE"],"columnTypes":["STRING","INTEGER"]},["a",1],["b",null]]`)),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	var authorized string
+	conn := dial(t, &kcl, ksqlgrpc.Options{Authorize: func(ctx context.Context, sql string) error {
+		authorized = sql
+		return nil
+	}})
+	stream := query(t, context.Background(), conn, "Pull", map[string]interface{}{"sql": "select * from dogs where id = 'a';"})
+
+	msg, err := receive(stream)
+	require.Nil(t, err)
+	require.Equal(t, header, msg)
+	msg, err = receive(stream)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"row": []interface{}{"a", float64(1)}}, msg)
+	msg, err = receive(stream)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"row": []interface{}{"b", nil}}, msg)
+	_, err = receive(stream)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "select * from dogs where id = 'a';", authorized)
+}
+
+func TestPull_Errors(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	kcl, _ := ksqldb.NewClient(&m)
+	conn := dial(t, &kcl, ksqlgrpc.Options{Authorize: func(ctx context.Context, sql string) error {
+		if strings.Contains(sql, "secrets") {
+			return status.Error(codes.PermissionDenied, "no access to secrets")
+		}
+		return nil
+	}})
+
+	_, err := receive(query(t, context.Background(), conn, "Pull", map[string]interface{}{}))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = receive(query(t, context.Background(), conn, "Pull", map[string]interface{}{"sql": "select * from;"}))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = receive(query(t, context.Background(), conn, "Pull", map[string]interface{}{"sql": "select * from secrets;"}))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestPush(t *testing.T) {
+	pr, pw := io.Pipe()
+	closed := make(chan struct{})
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", ksqldb.QUERY_STREAM_ENDPOINT).Return("http://localhost/query-stream")
+	m.On("GetUrl", ksqldb.CLOSE_QUERY_ENDPOINT).Return("http://localhost/close-query")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			close(closed)
+			pw.Close()
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		go func() {
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: 200, Body: pr}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	conn := dial(t, &kcl, ksqlgrpc.Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := query(t, ctx, conn, "Push", map[string]interface{}{
+		"sql":        "select * from dogs emit changes;",
+		"properties": map[string]interface{}{"ksql.streams.auto.offset.reset": "earliest"},
+	})
+	go func() {
+		_, _ = pw.Write([]byte(`{"queryId":"abc","columnNames":["ID","AGE"],"columnTypes":["STRING","INTEGER"]}` + "\n" + `["a",1]` + "\n"))
+	}()
+	msg, err := receive(stream)
+	require.Nil(t, err)
+	require.Equal(t, header, msg)
+	msg, err = receive(stream)
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"row": []interface{}{"a", float64(1)}}, msg)
+
+	// cancelling the call closes the query
+	cancel()
+	<-closed
+	_, err = receive(stream)
+	require.Equal(t, codes.Canceled, status.Code(err))
+}
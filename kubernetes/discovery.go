@@ -0,0 +1,242 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubernetes discovers the ksqlDB pods of a Kubernetes Service.
+//
+// The Discovery resolves the Service to the addresses of its ready endpoints
+// with the EndpointSlice API, with the port of the endpoints, which is the
+// target port of the Service and may differ from the port of the base url.
+// Use it as LookupHost of the client, so new connections, ex. of push
+// queries, spread over the replicas and follow scaling events:
+//
+//	discovery, err := kubernetes.InCluster("ksqldb")
+//	...
+//	client, err := ksqldb.NewClientWithOptions(net.Options{
+//		BaseUrl:            "http://ksqldb.default.svc:8088",
+//		DNSRefreshInterval: 30 * time.Second,
+//		LookupHost:         discovery.LookupHost,
+//	})
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SERVICE_ACCOUNT_DIR holds the credentials of pods
+	SERVICE_ACCOUNT_DIR = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// SERVICE_NAME_LABEL links EndpointSlices to their Service
+	SERVICE_NAME_LABEL = "kubernetes.io/service-name"
+)
+
+// ErrNoEndpoints is returned if the Service has no ready endpoints
+var ErrNoEndpoints = errors.New("no ready endpoints")
+
+// Options configures a Discovery
+type Options struct {
+	// ApiUrl is the URL of the Kubernetes API server
+	ApiUrl string
+	// Namespace of the Service
+	Namespace string
+	// Service is the name of the ksqlDB Service
+	Service string
+	// Port is the name or number of the port of the endpoints, ex. "http" or "8088";
+	// defaults to the port of the EndpointSlices if they have a single port
+	Port string
+	// TokenFile holds the bearer token, it is read for every request
+	// as tokens are rotated; no authentication if empty
+	TokenFile string
+	// HTTPClient of the API server; defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Discovery resolves a Service to the addresses of its ready endpoints
+type Discovery struct {
+	options Options
+}
+
+// New returns a Discovery with the given options
+func New(options Options) *Discovery {
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	return &Discovery{options: options}
+}
+
+// WithPort returns a copy of the Discovery with the Port option, ex. for
+// InCluster discoveries of Services with several ports
+func (d *Discovery) WithPort(port string) *Discovery {
+	options := d.options
+	options.Port = port
+	return &Discovery{options: options}
+}
+
+// InCluster returns a Discovery of the Service in the namespace of the pod,
+// using the service account of the pod. The service account needs the
+// permission to list endpointslices.discovery.k8s.io.
+func InCluster(service string) (*Discovery, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster")
+	}
+	namespace, err := ioutil.ReadFile(SERVICE_ACCOUNT_DIR + "/namespace")
+	if err != nil {
+		return nil, fmt.Errorf("can't read namespace: %w", err)
+	}
+	ca, err := ioutil.ReadFile(SERVICE_ACCOUNT_DIR + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("can't read ca certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid ca certificate")
+	}
+
+	return New(Options{
+		ApiUrl:    "https://" + net.JoinHostPort(host, port),
+		Namespace: strings.TrimSpace(string(namespace)),
+		Service:   service,
+		TokenFile: SERVICE_ACCOUNT_DIR + "/token",
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		}},
+	}), nil
+}
+
+// endpointSliceList is the part of discovery.k8s.io/v1 EndpointSliceList we need
+type endpointSliceList struct {
+	Items []struct {
+		Ports     []endpointPort `json:"ports"`
+		Endpoints []struct {
+			Addresses  []string `json:"addresses"`
+			Conditions struct {
+				// nil means ready
+				Ready *bool `json:"ready"`
+			} `json:"conditions"`
+		} `json:"endpoints"`
+	} `json:"items"`
+}
+
+type endpointPort struct {
+	Name string `json:"name"`
+	// nil means all ports
+	Port *int32 `json:"port"`
+}
+
+// port returns the port of the endpoints matching the Port option; empty if the
+// endpoints have all ports and false if no port matches.
+func (d *Discovery) port(ports []endpointPort) (string, bool, error) {
+	if d.options.Port == "" {
+		switch {
+		case len(ports) == 0 || ports[0].Port == nil:
+			return "", true, nil
+		case len(ports) > 1:
+			return "", false, fmt.Errorf("service %v/%v has %v ports, set the Port option, see WithPort", d.options.Namespace, d.options.Service, len(ports))
+		}
+		return strconv.Itoa(int(*ports[0].Port)), true, nil
+	}
+	for _, port := range ports {
+		if port.Port == nil {
+			continue
+		}
+		number := strconv.Itoa(int(*port.Port))
+		if port.Name == d.options.Port || number == d.options.Port {
+			return number, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// LookupHost returns the addresses of the ready endpoints of the Service.
+// The addresses have the port of the endpoints, so the client dials them instead of
+// the port of the base url. host is ignored, every host resolves to the Service; see net.Options.LookupHost.
+func (d *Discovery) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return d.Endpoints(ctx)
+}
+
+// Endpoints returns the host:port addresses of the ready endpoints of the Service;
+// addresses of endpoints with all ports have no port
+func (d *Discovery) Endpoints(ctx context.Context) ([]string, error) {
+	query := url.Values{"labelSelector": {SERVICE_NAME_LABEL + "=" + d.options.Service}}
+	endpoint := fmt.Sprintf("%v/apis/discovery.k8s.io/v1/namespaces/%v/endpointslices?%v",
+		strings.TrimSuffix(d.options.ApiUrl, "/"), url.PathEscape(d.options.Namespace), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't create request: %w", err)
+	}
+	if d.options.TokenFile != "" {
+		token, err := ioutil.ReadFile(d.options.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	res, err := d.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't list endpoint slices: %w", err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't read endpoint slices: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't list endpoint slices: %v %v", res.Status, string(body))
+	}
+
+	var slices endpointSliceList
+	if err := json.Unmarshal(body, &slices); err != nil {
+		return nil, fmt.Errorf("can't parse endpoint slices: %w", err)
+	}
+	var addrs []string
+	for _, slice := range slices.Items {
+		port, ok, err := d.port(slice.Ports)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, addr := range endpoint.Addresses {
+				if port != "" {
+					addr = net.JoinHostPort(addr, port)
+				}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("%w for service %v/%v", ErrNoEndpoints, d.options.Namespace, d.options.Service)
+	}
+	return addrs, nil
+}
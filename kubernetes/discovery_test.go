@@ -0,0 +1,104 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/kubernetes"
+)
+
+func TestDiscovery_Endpoints(t *testing.T) {
+	slices := `{"items":[
+		{"endpoints":[{"addresses":["10.0.0.1"],"conditions":{"ready":true}},{"addresses":["10.0.0.2"],"conditions":{"ready":false}}]},
+		{"endpoints":[{"addresses":["10.0.0.3"],"conditions":{}}]}
+	]}`
+	var req *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		_, _ = w.Write([]byte(slices))
+	}))
+	defer srv.Close()
+
+	token, err := ioutil.TempFile("", "token")
+	require.Nil(t, err)
+	defer os.Remove(token.Name())
+	_, _ = token.WriteString("secret\n")
+	token.Close()
+
+	discovery := kubernetes.New(kubernetes.Options{ApiUrl: srv.URL, Namespace: "default", Service: "ksqldb", TokenFile: token.Name()})
+	addrs, err := discovery.LookupHost(context.Background(), "ksqldb.default.svc")
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.0.1", "10.0.0.3"}, addrs)
+	require.Equal(t, "/apis/discovery.k8s.io/v1/namespaces/default/endpointslices", req.URL.Path)
+	require.Equal(t, "kubernetes.io/service-name=ksqldb", req.URL.Query().Get("labelSelector"))
+	require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+	slices = `{"items":[]}`
+	_, err = discovery.Endpoints(context.Background())
+	require.True(t, errors.Is(err, kubernetes.ErrNoEndpoints))
+	require.Equal(t, "no ready endpoints for service default/ksqldb", err.Error())
+}
+
+func TestDiscovery_Ports(t *testing.T) {
+	slices := `{"items":[
+		{"ports":[{"name":"http","port":8088},{"name":"metrics","port":9090}],"endpoints":[{"addresses":["10.0.0.1"]}]},
+		{"ports":[{"name":"metrics","port":9090}],"endpoints":[{"addresses":["10.0.0.2"]}]},
+		{"ports":[{"name":"http","port":8088}],"endpoints":[{"addresses":["fd00::3"]}]}
+	]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(slices))
+	}))
+	defer srv.Close()
+
+	// named and numbered ports
+	for _, port := range []string{"http", "8088"} {
+		discovery := kubernetes.New(kubernetes.Options{ApiUrl: srv.URL, Namespace: "default", Service: "ksqldb", Port: port})
+		addrs, err := discovery.Endpoints(context.Background())
+		require.Nil(t, err)
+		require.Equal(t, []string{"10.0.0.1:8088", "[fd00::3]:8088"}, addrs)
+	}
+
+	// the port is required for services with several ports
+	discovery := kubernetes.New(kubernetes.Options{ApiUrl: srv.URL, Namespace: "default", Service: "ksqldb"})
+	_, err := discovery.Endpoints(context.Background())
+	require.Equal(t, "service default/ksqldb has 2 ports, set the Port option, see WithPort", err.Error())
+	addrs, err := discovery.WithPort("http").Endpoints(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.0.1:8088", "[fd00::3]:8088"}, addrs)
+
+	// the single port is the default
+	slices = `{"items":[{"ports":[{"name":"","port":8080}],"endpoints":[{"addresses":["10.0.0.1"]}]}]}`
+	addrs, err = discovery.Endpoints(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.0.1:8080"}, addrs)
+}
+
+func TestInCluster_NotInCluster(t *testing.T) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		t.Skip("running in a Kubernetes cluster")
+	}
+	_, err := kubernetes.InCluster("ksqldb")
+	require.Equal(t, "not running in a Kubernetes cluster", err.Error())
+}
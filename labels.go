@@ -0,0 +1,36 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+// Labels are user defined key value pairs attached to queries, see net.Labels
+type Labels = net.Labels
+
+// WithLabels returns a copy of ctx carrying the labels.
+// Queries started with the context add the labels to their QueryMetrics,
+// to the log entries and to the tracing spans of their requests.
+//
+//	ctx := ksqldb.WithLabels(ctx, ksqldb.Labels{"service": "billing", "owner": "team-a"})
+//	err := client.Push(ctx, sql, rows, headers)
+func WithLabels(ctx context.Context, labels Labels) context.Context {
+	return net.WithLabels(ctx, labels)
+}
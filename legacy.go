@@ -0,0 +1,189 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnableLegacyQueryEndpoint enables / disables the legacy /query endpoint for push queries.
+// Use it for old servers or HTTP/1.1 proxies which don't support /query-stream.
+// The chunked responses of /query are adapted to the same Row and Header types.
+//
+// Legacy queries have no query id, they are closed by closing their connection.
+func (cl *KsqldbClient) EnableLegacyQueryEndpoint(activate bool) {
+	cl.legacyQuery = activate
+}
+
+// LegacyQueryEndpointEnabled returns true if push queries use the legacy /query endpoint; false otherwise
+func (cl *KsqldbClient) LegacyQueryEndpointEnabled() bool {
+	return cl.legacyQuery
+}
+
+// QueryEndpoint returns the endpoint of push queries, QUERY_STREAM_ENDPOINT
+// or the legacy QUERY_ENDPOINT
+func (cl *KsqldbClient) QueryEndpoint() string {
+	if cl.legacyQuery {
+		return QUERY_ENDPOINT
+	}
+	return QUERY_STREAM_ENDPOINT
+}
+
+// NegotiateQueryEndpoint selects the endpoint of push queries by the server version.
+// /query-stream with the delimited format is preferred, it's available since
+// ksqlDB 0.10 (Confluent Platform 6.0); older servers use the legacy /query endpoint.
+// It returns the selected endpoint, see QueryEndpoint.
+func (cl *KsqldbClient) NegotiateQueryEndpoint() (string, error) {
+	info, err := cl.GetServerInfo()
+	if err != nil {
+		return "", err
+	}
+	major, minor, err := parseServerVersion(info.Version)
+	if err != nil {
+		return "", err
+	}
+	// Confluent Platform versions start with 5
+	queryStream := major > 0 && major < 5 || major == 0 && minor >= 10 || major >= 6
+	cl.EnableLegacyQueryEndpoint(!queryStream)
+	return cl.QueryEndpoint(), nil
+}
+
+// parseServerVersion returns major and minor of a server version, ex. 0.29.0-rc1 or 7.3.1
+func parseServerVersion(version string) (major int, minor int, err error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid server version %q", version)
+	}
+	if major, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid server version %q", version)
+	}
+	if minor, err = strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })); err != nil {
+		return 0, 0, fmt.Errorf("invalid server version %q", version)
+	}
+	return major, minor, nil
+}
+
+// legacyQueryOptions is the request of the /query endpoint
+type legacyQueryOptions struct {
+	Ksql              string      `json:"ksql"`
+	StreamsProperties PropertyMap `json:"streamsProperties"`
+}
+
+// legacyMessage is an element of the /query response array, ex.
+//
+//	[{"header":{"queryId":"transient_DOGS_1","schema":"`ID` STRING, `NAME` STRING"}},
+//	{"row":{"columns":["1","Lara"]}},
+//	{"finalMessage":"Limit Reached"}]
+type legacyMessage struct {
+	Header *struct {
+		Schema string `json:"schema"`
+	} `json:"header"`
+	Row *struct {
+		Columns json.RawMessage `json:"columns"`
+	} `json:"row"`
+	ErrorMessage *ResponseError `json:"errorMessage"`
+	FinalMessage string         `json:"finalMessage"`
+}
+
+// decodeLegacyLine decodes a line of the /query response, see decodeLine.
+// The array brackets and the element separators are stripped from the line.
+func decodeLegacyLine(decoder Decoder, line []byte, columns int, pooled bool) (*Header, Row, error) {
+	line = bytes.TrimSpace(line)
+	line = bytes.TrimPrefix(line, []byte("["))
+	line = bytes.TrimSuffix(line, []byte("]"))
+	line = bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+	if len(line) == 0 {
+		return nil, nil, nil
+	}
+
+	var msg legacyMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(line))
+	}
+	switch {
+	case msg.ErrorMessage != nil:
+		return nil, nil, *msg.ErrorMessage
+	case msg.Header != nil:
+		cols, err := parseLegacySchema(msg.Header.Schema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(line))
+		}
+		return &Header{columns: cols}, nil, nil
+	case msg.Row != nil:
+		row, err := decoder.DecodeRow(msg.Row.Columns, newRow(columns, pooled))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse the response: %w\n%v", err, string(line))
+		}
+		return nil, row, nil
+	}
+	// final message
+	return nil, nil, nil
+}
+
+// parseLegacySchema parses the columns of a legacy header schema, ex.
+// `ID` STRING, `ADDRESS` STRUCT<`ZIP` BIGINT, `CITY` STRING>
+func parseLegacySchema(schema string) ([]Column, error) {
+	var cols []Column
+	for _, field := range splitTopLevel(schema) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		var name, typ string
+		if strings.HasPrefix(field, "`") {
+			end := strings.Index(field[1:], "`")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid column %v", field)
+			}
+			name, typ = field[1:end+1], field[end+2:]
+		} else if idx := strings.IndexAny(field, " \t"); idx > 0 {
+			name, typ = field[:idx], field[idx:]
+		}
+		typ = strings.TrimSpace(typ)
+		if name == "" || typ == "" {
+			return nil, errors.New("invalid column " + field)
+		}
+		cols = append(cols, Column{Name: name, Type: typ})
+	}
+	return cols, nil
+}
+
+// splitTopLevel splits the schema at the commas outside of type parameters and quoted names
+func splitTopLevel(schema string) []string {
+	var fields []string
+	depth, start, quoted := 0, 0, false
+	for idx, c := range schema {
+		switch {
+		case c == '`':
+			quoted = !quoted
+		case quoted:
+		case c == '<' || c == '(':
+			depth++
+		case c == '>' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			fields = append(fields, schema[start:idx])
+			start = idx + 1
+		}
+	}
+	return append(fields, schema[start:])
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func legacyMock(body string) *mocknet.HTTPClient {
+	m := &mocknet.HTTPClient{}
+	m.On("GetUrl", ksqldb.QUERY_ENDPOINT).Return("http://localhost/query")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+	}, nil)
+	return m
+}
+
+func TestPush_LegacyQueryEndpoint(t *testing.T) {
+	m := legacyMock("[{\"header\":{\"queryId\":\"transient_DOGS_1\",\"schema\":\"`ID` STRING, `ADDRESS` STRUCT<`ZIP` BIGINT, `CITY` STRING>\"}},\n" +
+		"{\"row\":{\"columns\":[\"1\",{\"ZIP\":1,\"CITY\":\"Berlin\"}]}},\n" +
+		"\n" +
+		"{\"row\":{\"columns\":[\"2\",null]}},\n" +
+		"{\"finalMessage\":\"Limit Reached\"}]\n")
+	kcl, _ := ksqldb.NewClient(m)
+	require.False(t, kcl.LegacyQueryEndpointEnabled())
+	kcl.EnableLegacyQueryEndpoint(true)
+	require.True(t, kcl.LegacyQueryEndpointEnabled())
+
+	rowChannel := make(chan ksqldb.Row, 2)
+	headerChannel := make(chan ksqldb.Header, 1)
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes limit 2;", rowChannel, headerChannel)
+	require.Nil(t, err)
+
+	require.Equal(t, ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "ID", Type: "STRING"},
+		{Name: "ADDRESS", Type: "STRUCT<`ZIP` BIGINT, `CITY` STRING>"},
+	}), <-headerChannel)
+	require.Equal(t, ksqldb.Row{"1", map[string]interface{}{"ZIP": float64(1), "CITY": "Berlin"}}, <-rowChannel)
+	require.Equal(t, ksqldb.Row{"2", nil}, <-rowChannel)
+
+	req := m.Calls[len(m.Calls)-1].Arguments.Get(0).(*http.Request)
+	body, _ := ioutil.ReadAll(req.Body)
+	require.JSONEq(t, `{"ksql":"select * from dogs emit changes limit 2;","streamsProperties":{"ksql.streams.auto.offset.reset":"latest"}}`, string(body))
+	// legacy queries are not closed with /close-query
+	m.AssertNotCalled(t, "GetUrl", ksqldb.CLOSE_QUERY_ENDPOINT)
+}
+
+func TestPush_LegacyQueryEndpointError(t *testing.T) {
+	m := legacyMock("[{\"header\":{\"schema\":\"`ID` STRING\"}},\n" +
+		"{\"errorMessage\":{\"@type\":\"generic_error\",\"error_code\":50000,\"message\":\"boom\"}}]\n")
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableLegacyQueryEndpoint(true)
+
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes;", make(chan ksqldb.Row), nil)
+	require.Equal(t, ksqldb.ResponseError{ErrType: "generic_error", ErrCode: 50000, Message: "boom"}, err)
+}
+
+func TestClient_NegotiateQueryEndpoint(t *testing.T) {
+	for version, want := range map[string]string{
+		"0.21.0":     ksqldb.QUERY_STREAM_ENDPOINT,
+		"0.10.2-rc1": ksqldb.QUERY_STREAM_ENDPOINT,
+		"0.9.0":      ksqldb.QUERY_ENDPOINT,
+		"5.5.3":      ksqldb.QUERY_ENDPOINT,
+		"7.3.1":      ksqldb.QUERY_STREAM_ENDPOINT,
+	} {
+		m := &mocknet.HTTPClient{}
+		m.On("GetUrl", mock.Anything).Return("http://localhost/info")
+		m.On("Get", mock.Anything).Return(&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"KsqlServerInfo":{"version":"` + version + `"}}`))}, nil)
+		kcl, _ := ksqldb.NewClient(m)
+		require.Equal(t, ksqldb.QUERY_STREAM_ENDPOINT, kcl.QueryEndpoint())
+
+		endpoint, err := kcl.NegotiateQueryEndpoint()
+		require.Nil(t, err, version)
+		require.Equal(t, want, endpoint, version)
+		require.Equal(t, want, kcl.QueryEndpoint(), version)
+	}
+}
+
+func TestClient_NegotiateQueryEndpointError(t *testing.T) {
+	m := &mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return("http://localhost/info")
+	m.On("Get", mock.Anything).Return(&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"KsqlServerInfo":{"version":"latest"}}`))}, nil)
+	kcl, _ := ksqldb.NewClient(m)
+	_, err := kcl.NegotiateQueryEndpoint()
+	require.Equal(t, `invalid server version "latest"`, err.Error())
+}
+
+func TestSubscribe_Endpoint(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.QUERY_STREAM_ENDPOINT, q.Endpoint())
+	go m.send(streamHeader)
+	_, err = q.Header()
+	require.Nil(t, err)
+
+	req := m.Calls[len(m.Calls)-1].Arguments.Get(0).(*http.Request)
+	require.Equal(t, ksqldb.DELIMITED_CONTENT_TYPE, req.Header.Get("Accept"))
+	require.Nil(t, q.Stop())
+}
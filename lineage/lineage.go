@@ -0,0 +1,232 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lineage builds the lineage graph of a ksqlDB pipeline:
+// topics -> streams and tables -> queries -> sinks -> topics.
+//
+// The graph is built from SHOW STREAMS, SHOW TABLES and SHOW QUERIES EXTENDED
+// and answers impact analysis questions before changes:
+//
+//	graph, err := lineage.Load(ctx, &client)
+//	...
+//	for _, node := range graph.DownstreamOf(lineage.Stream("DOGS")) {
+//		fmt.Println(node) // ex. QUERY CSAS_BIG_DOGS_3, STREAM BIG_DOGS
+//	}
+package lineage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// Kind is the kind of a node
+type Kind string
+
+const (
+	TOPIC  Kind = "TOPIC"
+	STREAM Kind = "STREAM"
+	TABLE  Kind = "TABLE"
+	QUERY  Kind = "QUERY"
+)
+
+// Node is a topic, stream, table or query
+type Node struct {
+	Kind Kind
+	// Name is the name of the topic or source or the id of the query
+	Name string
+}
+
+// Topic returns the node of the topic
+func Topic(name string) Node { return Node{Kind: TOPIC, Name: name} }
+
+// Stream returns the node of the stream
+func Stream(name string) Node { return Node{Kind: STREAM, Name: name} }
+
+// Table returns the node of the table
+func Table(name string) Node { return Node{Kind: TABLE, Name: name} }
+
+// Query returns the node of the query
+func Query(id string) Node { return Node{Kind: QUERY, Name: id} }
+
+func (n Node) String() string {
+	return string(n.Kind) + " " + n.Name
+}
+
+// Client lists the metadata of the graph, it is implemented by *ksqldb.KsqldbClient
+type Client interface {
+	ListStreams(ctx context.Context) ([]ksqldb.Stream, error)
+	ListTables(ctx context.Context) ([]ksqldb.Table, error)
+	ListQueriesExtended(ctx context.Context) ([]ksqldb.QueryDescription, error)
+}
+
+// Graph is the lineage graph. Edges point downstream, in the direction of the data flow.
+type Graph struct {
+	nodes map[Node]bool
+	down  map[Node][]Node
+	up    map[Node][]Node
+}
+
+// Load lists the streams, tables and queries with the client and builds the graph
+func Load(ctx context.Context, client Client) (*Graph, error) {
+	streams, err := client.ListStreams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := client.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queries, err := client.ListQueriesExtended(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Build(streams, tables, queries), nil
+}
+
+// Build builds the graph of the streams, tables and queries.
+//
+// Queries read from their sources and write into their sinks. Sinks of
+// queries write into their topic, other sources read from their topic.
+func Build(streams []ksqldb.Stream, tables []ksqldb.Table, queries []ksqldb.QueryDescription) *Graph {
+	g := &Graph{nodes: map[Node]bool{}, down: map[Node][]Node{}, up: map[Node][]Node{}}
+
+	sources := map[string]Node{}
+	topics := map[string]string{}
+	for _, s := range streams {
+		sources[s.Name] = Stream(s.Name)
+		topics[s.Name] = s.Topic
+	}
+	for _, t := range tables {
+		sources[t.Name] = Table(t.Name)
+		topics[t.Name] = t.Topic
+	}
+	// source returns the node of a source of a query; unknown sources are streams
+	source := func(name string) Node {
+		if node, ok := sources[name]; ok {
+			return node
+		}
+		return Stream(name)
+	}
+
+	sinks := map[string]bool{}
+	for _, q := range queries {
+		query := Query(q.ID)
+		g.add(query)
+		for _, name := range q.Sources {
+			g.link(source(name), query)
+		}
+		for _, name := range q.Sinks {
+			g.link(query, source(name))
+			sinks[name] = true
+		}
+	}
+
+	for name, node := range sources {
+		g.add(node)
+		if topics[name] == "" {
+			continue
+		}
+		if sinks[name] {
+			g.link(node, Topic(topics[name]))
+		} else {
+			g.link(Topic(topics[name]), node)
+		}
+	}
+	return g
+}
+
+func (g *Graph) add(n Node) {
+	g.nodes[n] = true
+}
+
+func (g *Graph) link(from Node, to Node) {
+	g.add(from)
+	g.add(to)
+	for _, n := range g.down[from] {
+		if n == to {
+			return
+		}
+	}
+	g.down[from] = append(g.down[from], to)
+	g.up[to] = append(g.up[to], from)
+}
+
+// Nodes returns all nodes sorted by kind and name
+func (g *Graph) Nodes() []Node {
+	nodes := []Node{}
+	for n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// Contains returns true if the node is in the graph
+func (g *Graph) Contains(n Node) bool {
+	return g.nodes[n]
+}
+
+// Downstream returns the direct downstream nodes, sorted by kind and name
+func (g *Graph) Downstream(n Node) []Node {
+	return sortNodes(append([]Node{}, g.down[n]...))
+}
+
+// Upstream returns the direct upstream nodes, sorted by kind and name
+func (g *Graph) Upstream(n Node) []Node {
+	return sortNodes(append([]Node{}, g.up[n]...))
+}
+
+// DownstreamOf returns all nodes reachable from the node in breadth first
+// order, ex. the queries and sinks affected by a change of a stream
+func (g *Graph) DownstreamOf(n Node) []Node {
+	return g.traverse(n, g.down)
+}
+
+// UpstreamOf returns all nodes the node is reachable from in breadth first
+// order, ex. the topics and queries a table is derived from
+func (g *Graph) UpstreamOf(n Node) []Node {
+	return g.traverse(n, g.up)
+}
+
+func (g *Graph) traverse(start Node, edges map[Node][]Node) []Node {
+	visited := map[Node]bool{start: true}
+	result := []Node{}
+	queue := []Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, next := range sortNodes(append([]Node{}, edges[n]...)) {
+			if !visited[next] {
+				visited[next] = true
+				result = append(result, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return result
+}
+
+func sortNodes(nodes []Node) []Node {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Kind != nodes[j].Kind {
+			return nodes[i].Kind < nodes[j].Kind
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lineage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/lineage"
+)
+
+type client struct {
+	err error
+}
+
+func (c client) ListStreams(ctx context.Context) ([]ksqldb.Stream, error) {
+	return []ksqldb.Stream{
+		{Name: "DOGS", Topic: "dogs"},
+		{Name: "BIG_DOGS", Topic: "BIG_DOGS"},
+		{Name: "BIG_DOGS_RAW", Topic: "BIG_DOGS"},
+	}, c.err
+}
+
+func (c client) ListTables(ctx context.Context) ([]ksqldb.Table, error) {
+	return []ksqldb.Table{{Name: "DOGS_BY_SIZE", Topic: "DOGS_BY_SIZE"}}, nil
+}
+
+func (c client) ListQueriesExtended(ctx context.Context) ([]ksqldb.QueryDescription, error) {
+	return []ksqldb.QueryDescription{
+		{ID: "CSAS_BIG_DOGS_3", Sources: []string{"DOGS"}, Sinks: []string{"BIG_DOGS"}},
+		{ID: "CTAS_DOGS_BY_SIZE_7", Sources: []string{"BIG_DOGS_RAW"}, Sinks: []string{"DOGS_BY_SIZE"}},
+	}, nil
+}
+
+func TestLoad(t *testing.T) {
+	graph, err := lineage.Load(context.Background(), client{})
+	require.Nil(t, err)
+	require.Len(t, graph.Nodes(), 9)
+	require.True(t, graph.Contains(lineage.Topic("dogs")))
+
+	require.Equal(t, []lineage.Node{lineage.Query("CSAS_BIG_DOGS_3")}, graph.Downstream(lineage.Stream("DOGS")))
+	require.Equal(t, []lineage.Node{lineage.Topic("dogs")}, graph.Upstream(lineage.Stream("DOGS")))
+
+	// BIG_DOGS_RAW reads the topic written by BIG_DOGS
+	require.Equal(t, []lineage.Node{
+		lineage.Stream("DOGS"),
+		lineage.Query("CSAS_BIG_DOGS_3"),
+		lineage.Stream("BIG_DOGS"),
+		lineage.Topic("BIG_DOGS"),
+		lineage.Stream("BIG_DOGS_RAW"),
+		lineage.Query("CTAS_DOGS_BY_SIZE_7"),
+		lineage.Table("DOGS_BY_SIZE"),
+		lineage.Topic("DOGS_BY_SIZE"),
+	}, graph.DownstreamOf(lineage.Topic("dogs")))
+
+	require.Equal(t, []lineage.Node{
+		lineage.Query("CTAS_DOGS_BY_SIZE_7"),
+		lineage.Stream("BIG_DOGS_RAW"),
+		lineage.Topic("BIG_DOGS"),
+		lineage.Stream("BIG_DOGS"),
+		lineage.Query("CSAS_BIG_DOGS_3"),
+		lineage.Stream("DOGS"),
+		lineage.Topic("dogs"),
+	}, graph.UpstreamOf(lineage.Table("DOGS_BY_SIZE")))
+	require.Equal(t, "TABLE DOGS_BY_SIZE", lineage.Table("DOGS_BY_SIZE").String())
+
+	_, err = lineage.Load(context.Background(), client{err: errors.New("boom")})
+	require.Equal(t, "boom", err.Error())
+}
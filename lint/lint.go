@@ -0,0 +1,199 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint checks ksql statements against a set of configurable rules.
+//
+//	linter := lint.New()
+//	linter.Enable(lint.RulePushQueryWithoutLimit)
+//	issues, err := linter.Lint(sql)
+//
+// Custom rules are registered with Linter.Register.
+package lint
+
+import (
+	"fmt"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// Severity of an issue
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Rule checks a single statement
+type Rule interface {
+	// Name is the unique name of the rule
+	Name() string
+	// Severity is the default severity of the issues found by the rule
+	Severity() Severity
+	// Check returns a message for every issue found in the statement
+	Check(stmnt *parser.StatementNode) []string
+}
+
+// Issue is a rule violation found by the Linter
+type Issue struct {
+	Rule     string
+	Severity Severity
+	Message  string
+	// Statement is the canonical text of the statement
+	Statement string
+	// Line and Column of the statement in the linted sql
+	Line   int
+	Column int
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line(%v):column(%v): %v: %v (%v)", i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// Linter runs the enabled rules against statements
+type Linter struct {
+	rules      []Rule
+	disabled   map[string]bool
+	severities map[string]Severity
+}
+
+// New returns a Linter with the default rules.
+//
+// RulePushQueryWithoutLimit is registered but disabled,
+// enable it to lint the statements of your tests.
+func New() *Linter {
+	l := &Linter{
+		disabled:   map[string]bool{},
+		severities: map[string]Severity{},
+	}
+	for _, rule := range DefaultRules() {
+		l.Register(rule)
+	}
+	l.Disable(RulePushQueryWithoutLimit)
+	return l
+}
+
+// Register adds a rule; a rule with the same name is replaced
+func (l *Linter) Register(rule Rule) {
+	for idx, r := range l.rules {
+		if r.Name() == rule.Name() {
+			l.rules[idx] = rule
+			return
+		}
+	}
+	l.rules = append(l.rules, rule)
+}
+
+// Enable enables the rule with the given name
+func (l *Linter) Enable(name string) {
+	delete(l.disabled, name)
+}
+
+// Disable disables the rule with the given name
+func (l *Linter) Disable(name string) {
+	l.disabled[name] = true
+}
+
+// SetSeverity overrides the default severity of a rule
+func (l *Linter) SetSeverity(name string, severity Severity) {
+	l.severities[name] = severity
+}
+
+// Rules returns the names of all enabled rules
+func (l *Linter) Rules() []string {
+	names := []string{}
+	for _, rule := range l.rules {
+		if !l.disabled[rule.Name()] {
+			names = append(names, rule.Name())
+		}
+	}
+	return names
+}
+
+// Lint checks all statements of the given sql.
+//
+// Syntax errors are returned as *parser.SqlSyntaxErrorList.
+func (l *Linter) Lint(sql string) ([]Issue, error) {
+	issues := []Issue{}
+
+	stmnts, err := parser.SplitStatements(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmnt := range stmnts {
+		nodes, err := parser.Parse(stmnt.Text)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			issues = append(issues, l.check(node, stmnt)...)
+		}
+	}
+	return issues, nil
+}
+
+// LintStatement checks a single parsed statement
+func (l *Linter) LintStatement(node *parser.StatementNode) []Issue {
+	return l.check(node, parser.Statement{})
+}
+
+func (l *Linter) check(node *parser.StatementNode, stmnt parser.Statement) []Issue {
+	issues := []Issue{}
+	for _, rule := range l.rules {
+		if l.disabled[rule.Name()] {
+			continue
+		}
+		severity, ok := l.severities[rule.Name()]
+		if !ok {
+			severity = rule.Severity()
+		}
+		for _, msg := range rule.Check(node) {
+			issues = append(issues, Issue{
+				Rule:      rule.Name(),
+				Severity:  severity,
+				Message:   msg,
+				Statement: node.Text,
+				Line:      stmnt.Line,
+				Column:    stmnt.Column,
+			})
+		}
+	}
+	return issues
+}
+
+// HasErrors returns true if one of the issues has SeverityError
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity >= SeverityError {
+			return true
+		}
+	}
+	return false
+}
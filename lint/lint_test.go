@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/lint"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+const lintSql = `CREATE STREAM DOGS (ID STRING KEY, NAME STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');
+CREATE STREAM CATS (ID STRING KEY) WITH (KAFKA_TOPIC='cats', FORMAT='JSON');
+CREATE STREAM ALL_DOGS AS SELECT * FROM DOGS EMIT CHANGES;
+SELECT * FROM DOGS EMIT CHANGES;`
+
+func TestLinter_DefaultRules(t *testing.T) {
+	linter := lint.New()
+	issues, err := linter.Lint(lintSql)
+	require.Nil(t, err)
+	require.Len(t, issues, 2)
+
+	require.Equal(t, lint.RuleMissingKeyFormat, issues[0].Rule)
+	require.Equal(t, lint.SeverityWarning, issues[0].Severity)
+	require.Equal(t, 1, issues[0].Line)
+	require.Equal(t, "missing KEY_FORMAT for DOGS", issues[0].Message)
+
+	require.Equal(t, lint.RuleSelectStarInPersistent, issues[1].Rule)
+	require.Equal(t, 3, issues[1].Line)
+	require.Equal(t, "line(3):column(0): warning: SELECT * in persistent query for ALL_DOGS (select-star-in-persistent-query)", issues[1].String())
+	require.False(t, lint.HasErrors(issues))
+}
+
+func TestLinter_EnableDisableSeverity(t *testing.T) {
+	linter := lint.New()
+	linter.Enable(lint.RulePushQueryWithoutLimit)
+	linter.Disable(lint.RuleMissingKeyFormat)
+	linter.SetSeverity(lint.RuleSelectStarInPersistent, lint.SeverityError)
+
+	issues, err := linter.Lint(lintSql)
+	require.Nil(t, err)
+	require.Len(t, issues, 2)
+	require.Equal(t, lint.RuleSelectStarInPersistent, issues[0].Rule)
+	require.Equal(t, lint.SeverityError, issues[0].Severity)
+	require.Equal(t, lint.RulePushQueryWithoutLimit, issues[1].Rule)
+	require.True(t, lint.HasErrors(issues))
+
+	issues, err = linter.Lint("SELECT * FROM DOGS EMIT CHANGES LIMIT 5;")
+	require.Nil(t, err)
+	require.Len(t, issues, 0)
+}
+
+func TestLinter_CustomRule(t *testing.T) {
+	linter := lint.New()
+	linter.Register(lint.NewRule("no-drop", lint.SeverityError, func(stmnt *parser.StatementNode) []string {
+		if stmnt.Kind == parser.KindDropStream {
+			return []string{"DROP STREAM is not allowed"}
+		}
+		return nil
+	}))
+	require.Contains(t, linter.Rules(), "no-drop")
+	require.NotContains(t, linter.Rules(), lint.RulePushQueryWithoutLimit)
+
+	issues, err := linter.Lint("DROP STREAM DOGS;")
+	require.Nil(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, "DROP STREAM is not allowed", issues[0].Message)
+	require.Equal(t, "DROP STREAM DOGS;", issues[0].Statement)
+}
+
+func TestLinter_EmptyStatements(t *testing.T) {
+	issues, err := lint.New().Lint("DROP STREAM DOGS;;")
+	require.Nil(t, err)
+	require.Len(t, issues, 0)
+}
+
+func TestLinter_SyntaxError(t *testing.T) {
+	issues, err := lint.New().Lint("SELECT * FROM;")
+	require.Nil(t, issues)
+	require.NotNil(t, err)
+	require.Equal(t, "1 sql syntax error(s) found", err.Error())
+}
+
+func TestSeverity_String(t *testing.T) {
+	require.Equal(t, "info", lint.SeverityInfo.String())
+	require.Equal(t, "error", lint.SeverityError.String())
+	require.Equal(t, "severity(7)", lint.Severity(7).String())
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+const (
+	RulePushQueryWithoutLimit  = "push-query-without-limit"
+	RuleSelectStarInPersistent = "select-star-in-persistent-query"
+	RuleMissingKeyFormat       = "missing-key-format"
+)
+
+const (
+	keyFormatProperty = "KEY_FORMAT"
+	formatProperty    = "FORMAT"
+	emitChanges       = "CHANGES"
+)
+
+// CheckFunc checks a statement and returns the messages of the found issues
+type CheckFunc func(stmnt *parser.StatementNode) []string
+
+type funcRule struct {
+	name     string
+	severity Severity
+	check    CheckFunc
+}
+
+// NewRule creates a Rule from a CheckFunc
+func NewRule(name string, severity Severity, check CheckFunc) Rule {
+	return &funcRule{name: name, severity: severity, check: check}
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+
+func (r *funcRule) Severity() Severity {
+	return r.severity
+}
+
+func (r *funcRule) Check(stmnt *parser.StatementNode) []string {
+	return r.check(stmnt)
+}
+
+// DefaultRules returns the rules registered by New
+func DefaultRules() []Rule {
+	return []Rule{
+		NewRule(RulePushQueryWithoutLimit, SeverityWarning, checkPushQueryWithoutLimit),
+		NewRule(RuleSelectStarInPersistent, SeverityWarning, checkSelectStarInPersistent),
+		NewRule(RuleMissingKeyFormat, SeverityWarning, checkMissingKeyFormat),
+	}
+}
+
+// checkPushQueryWithoutLimit finds push queries which never terminate
+func checkPushQueryWithoutLimit(stmnt *parser.StatementNode) []string {
+	if stmnt.Kind != parser.KindQuery {
+		return nil
+	}
+	if stmnt.Query.Emit == emitChanges && stmnt.Query.Limit == nil {
+		return []string{"push query without LIMIT never terminates"}
+	}
+	return nil
+}
+
+// checkSelectStarInPersistent finds persistent queries with SELECT *;
+// the schema of the sink changes with the schema of the source
+func checkSelectStarInPersistent(stmnt *parser.StatementNode) []string {
+	switch stmnt.Kind {
+	case parser.KindCreateStreamAs, parser.KindCreateTableAs, parser.KindInsertInto:
+	default:
+		return nil
+	}
+	msgs := []string{}
+	for _, item := range stmnt.Query.Select {
+		if item.All {
+			msgs = append(msgs, "SELECT "+item.Expression+" in persistent query for "+stmnt.Target.Name)
+		}
+	}
+	return msgs
+}
+
+// checkMissingKeyFormat finds CREATE STREAM|TABLE without KEY_FORMAT or FORMAT;
+// the server default is used otherwise
+func checkMissingKeyFormat(stmnt *parser.StatementNode) []string {
+	switch stmnt.Kind {
+	case parser.KindCreateStream, parser.KindCreateTable:
+	default:
+		return nil
+	}
+	if _, ok := stmnt.Property(keyFormatProperty); ok {
+		return nil
+	}
+	if _, ok := stmnt.Property(formatProperty); ok {
+		return nil
+	}
+	return []string{"missing KEY_FORMAT for " + stmnt.Target.Name}
+}
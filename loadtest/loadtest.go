@@ -0,0 +1,242 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadtest measures the throughput and latency of pull queries and
+// push queries against a ksqlDB server, ex. to size a cluster or to tune
+// the options of the client:
+//
+//	result, err := loadtest.Run(ctx, &client, loadtest.Options{
+//		Mode:        loadtest.PULL,
+//		Sql:         "SELECT * FROM DOGS WHERE ID = '1';",
+//		Concurrency: 50,
+//		Duration:    time.Minute,
+//	})
+//	...
+//	fmt.Println(result) // pull: 50 concurrent, 60s, 91234 queries (1520.6/s), 91234 rows (1520.6/s), 0 errors, latency p50 31ms p90 48ms p99 95ms max 210ms
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// Mode is the kind of the queries of a load test
+type Mode int
+
+const (
+	// PULL runs pull queries in a loop on every connection
+	PULL Mode = iota
+	// PUSH starts one push query on every connection and receives its rows
+	PUSH
+)
+
+func (m Mode) String() string {
+	if m == PUSH {
+		return "push"
+	}
+	return "pull"
+}
+
+// Options configures a load test
+type Options struct {
+	Mode Mode
+	// Sql is the query
+	Sql string
+	// Properties of the queries
+	Properties ksqldb.PropertyMap
+	// Concurrency is the number of concurrent pull query loops or push queries; defaults to 1
+	Concurrency int
+	// Duration of the test; the test ends earlier if the context is done
+	Duration time.Duration
+}
+
+// Result is the result of a load test
+type Result struct {
+	Mode        Mode
+	Concurrency int
+	// Elapsed is the time the test ran
+	Elapsed time.Duration
+	// Queries is the number of successful pull queries or started push queries
+	Queries int64
+	// Rows is the number of received rows
+	Rows int64
+	// Errors is the number of failed queries, Err the last error
+	Errors int64
+	Err    error
+	// Latency is the latency of the pull queries or the time to the header of the push queries
+	Latency Latency
+}
+
+// Latency are the latency percentiles of the queries
+type Latency struct {
+	Min, Mean, P50, P90, P99, Max time.Duration
+}
+
+// QueriesPerSecond returns the successful queries per second
+func (r *Result) QueriesPerSecond() float64 {
+	return perSecond(r.Queries, r.Elapsed)
+}
+
+// RowsPerSecond returns the received rows per second
+func (r *Result) RowsPerSecond() float64 {
+	return perSecond(r.Rows, r.Elapsed)
+}
+
+func perSecond(n int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) / elapsed.Seconds()
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%v: %v concurrent, %v, %v queries (%.1f/s), %v rows (%.1f/s), %v errors, latency p50 %v p90 %v p99 %v max %v",
+		r.Mode, r.Concurrency, r.Elapsed.Round(time.Millisecond), r.Queries, r.QueriesPerSecond(), r.Rows, r.RowsPerSecond(),
+		r.Errors, r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+}
+
+// Run runs the load test and returns its result. Failed queries are counted
+// in the result; an error is returned for invalid options only.
+func Run(ctx context.Context, client *ksqldb.KsqldbClient, options Options) (*Result, error) {
+	if options.Sql == "" {
+		return nil, ksqldb.ErrEmptyQuery
+	}
+	if options.Duration <= 0 {
+		return nil, fmt.Errorf("load test duration must be positive")
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, options.Duration)
+	defer cancel()
+
+	run := pullLoop
+	if options.Mode == PUSH {
+		run = pushQuery
+	}
+	workers := make([]worker, options.Concurrency)
+	started := time.Now()
+	var wg sync.WaitGroup
+	for idx := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			run(ctx, client, options, w)
+		}(&workers[idx])
+	}
+	wg.Wait()
+
+	result := Result{Mode: options.Mode, Concurrency: options.Concurrency, Elapsed: time.Since(started)}
+	latencies := []time.Duration{}
+	for _, w := range workers {
+		result.Queries += w.queries
+		result.Rows += w.rows
+		result.Errors += w.errors
+		if w.err != nil {
+			result.Err = w.err
+		}
+		latencies = append(latencies, w.latencies...)
+	}
+	result.Latency = percentiles(latencies)
+	return &result, nil
+}
+
+// worker are the measurements of one pull query loop or push query
+type worker struct {
+	queries   int64
+	rows      int64
+	errors    int64
+	err       error
+	latencies []time.Duration
+}
+
+// failed counts the error unless it is caused by the end of the test
+func (w *worker) failed(ctx context.Context, err error) {
+	if ctx.Err() != nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+		return
+	}
+	w.errors++
+	w.err = err
+}
+
+// pullLoop runs the pull query until the context is done
+func pullLoop(ctx context.Context, client *ksqldb.KsqldbClient, options Options, w *worker) {
+	query := ksqldb.QueryOptions{Sql: options.Sql, Properties: options.Properties, CacheTTL: -1}
+	for ctx.Err() == nil {
+		started := time.Now()
+		_, payload, err := client.Pull(ctx, query)
+		if err != nil && !errors.Is(err, ksqldb.ErrNotFound) {
+			w.failed(ctx, err)
+			continue
+		}
+		w.latencies = append(w.latencies, time.Since(started))
+		w.queries++
+		w.rows += int64(len(payload))
+	}
+}
+
+// pushQuery runs the push query until the context is done
+func pushQuery(ctx context.Context, client *ksqldb.KsqldbClient, options Options, w *worker) {
+	started := time.Now()
+	q, err := client.Subscribe(ctx, options.Sql, ksqldb.SubscribeOptions{BufferSize: 100, Properties: options.Properties})
+	if err != nil {
+		w.failed(ctx, err)
+		return
+	}
+	if _, err := q.Header(); err != nil {
+		w.failed(ctx, err)
+		return
+	}
+	w.latencies = append(w.latencies, time.Since(started))
+	w.queries++
+	for range q.Rows() {
+		w.rows++
+	}
+	if err := q.Err(); err != nil {
+		w.failed(ctx, err)
+	}
+}
+
+// percentiles returns the latency percentiles of the durations
+func percentiles(durations []time.Duration) Latency {
+	if len(durations) == 0 {
+		return Latency{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	// nearest rank percentile
+	rank := func(p float64) time.Duration {
+		return durations[int(math.Ceil(p*float64(len(durations))))-1]
+	}
+	return Latency{
+		Min:  durations[0],
+		Mean: sum / time.Duration(len(durations)),
+		P50:  rank(0.5),
+		P90:  rank(0.9),
+		P99:  rank(0.99),
+		Max:  durations[len(durations)-1],
+	}
+}
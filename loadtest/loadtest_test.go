@@ -0,0 +1,119 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/loadtest"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+const dogsHeader = `{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]}`
+
+func TestRun_Pull(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(func(*http.Request) *http.Response {
+		time.Sleep(time.Millisecond)
+		body := `[` + dogsHeader + `,["1"],["2"]]`
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	result, err := loadtest.Run(context.Background(), &kcl, loadtest.Options{
+		Sql:         "SELECT * FROM DOGS WHERE ID = '1';",
+		Concurrency: 4,
+		Duration:    200 * time.Millisecond,
+	})
+	require.Nil(t, err)
+	require.Equal(t, loadtest.PULL, result.Mode)
+	require.Equal(t, int64(0), result.Errors, result.Err)
+	require.Greater(t, result.Queries, int64(10))
+	require.Equal(t, 2*result.Queries, result.Rows)
+	require.Greater(t, result.QueriesPerSecond(), 0.0)
+	require.GreaterOrEqual(t, result.Latency.Min, time.Millisecond)
+	require.LessOrEqual(t, result.Latency.Min, result.Latency.P50)
+	require.LessOrEqual(t, result.Latency.P50, result.Latency.P99)
+	require.LessOrEqual(t, result.Latency.P99, result.Latency.Max)
+	require.Contains(t, result.String(), "pull: 4 concurrent")
+}
+
+func TestRun_PullErrors(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(func(*http.Request) *http.Response {
+		time.Sleep(time.Millisecond)
+		body := `{"@type":"statement_error","error_code":40001,"message":"DOGS does not exist"}`
+		return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	result, err := loadtest.Run(context.Background(), &kcl, loadtest.Options{Sql: "SELECT * FROM DOGS;", Duration: 50 * time.Millisecond})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), result.Queries)
+	require.Greater(t, result.Errors, int64(0))
+	require.ErrorIs(t, result.Err, ksqldb.ErrStatementError)
+}
+
+func TestRun_Push(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = pw.Write([]byte(dogsHeader + "\n" + `["1"]` + "\n" + `["2"]` + "\n" + `["3"]` + "\n"))
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: http.StatusOK, Body: pr}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	result, err := loadtest.Run(context.Background(), &kcl, loadtest.Options{
+		Mode:        loadtest.PUSH,
+		Sql:         "SELECT * FROM DOGS EMIT CHANGES;",
+		Concurrency: 3,
+		Duration:    100 * time.Millisecond,
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), result.Errors, result.Err)
+	require.Equal(t, int64(3), result.Queries)
+	require.Equal(t, int64(9), result.Rows)
+	require.Greater(t, result.Latency.Max, time.Duration(0))
+}
+
+func TestRun_Options(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(&mocknet.HTTPClient{})
+	_, err := loadtest.Run(context.Background(), &kcl, loadtest.Options{Duration: time.Second})
+	require.ErrorIs(t, err, ksqldb.ErrEmptyQuery)
+	_, err = loadtest.Run(context.Background(), &kcl, loadtest.Options{Sql: "SELECT * FROM DOGS;"})
+	require.NotNil(t, err)
+}
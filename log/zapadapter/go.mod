@@ -0,0 +1,17 @@
+module github.com/thmeitz/ksqldb-go/log/zapadapter
+
+go 1.18
+
+require (
+	github.com/Masterminds/log-go v0.4.0
+	github.com/stretchr/testify v1.8.1
+	go.uber.org/zap v1.24.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
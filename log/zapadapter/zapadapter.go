@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zapadapter adapts zap loggers to the log.Logger of the client:
+//
+//	logger := zapadapter.New(zap.L().With(zap.String("service", "billing")))
+//	http, err := net.NewHTTPClient(options, logger)
+//
+// zap has no Trace level, Trace messages are logged at the Debug level.
+// It is a separate module, so zap is only pulled in if you use it.
+package zapadapter
+
+import (
+	"sort"
+
+	"github.com/Masterminds/log-go"
+	"go.uber.org/zap"
+)
+
+// Logger logs with a zap logger
+type Logger struct {
+	logger *zap.SugaredLogger
+}
+
+// New returns a log.Logger logging with the zap logger
+func New(logger *zap.Logger) *Logger {
+	// the caller is the caller of the adapter
+	return &Logger{logger: logger.WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+// keysAndValues returns the fields as alternating keys and values sorted by key
+func keysAndValues(fields log.Fields) []interface{} {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	kv := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		kv = append(kv, key, fields[key])
+	}
+	return kv
+}
+
+// Trace logs a message at the Debug level
+func (l *Logger) Trace(msg ...interface{}) {
+	l.logger.Debug(msg...)
+}
+
+// Tracef formats a message and logs it at the Debug level
+func (l *Logger) Tracef(template string, args ...interface{}) {
+	l.logger.Debugf(template, args...)
+}
+
+// Tracew logs a message with fields at the Debug level
+func (l *Logger) Tracew(msg string, fields log.Fields) {
+	l.logger.Debugw(msg, keysAndValues(fields)...)
+}
+
+// Debug logs a message at the Debug level
+func (l *Logger) Debug(msg ...interface{}) {
+	l.logger.Debug(msg...)
+}
+
+// Debugf formats a message and logs it at the Debug level
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	l.logger.Debugf(template, args...)
+}
+
+// Debugw logs a message with fields at the Debug level
+func (l *Logger) Debugw(msg string, fields log.Fields) {
+	l.logger.Debugw(msg, keysAndValues(fields)...)
+}
+
+// Info logs a message at the Info level
+func (l *Logger) Info(msg ...interface{}) {
+	l.logger.Info(msg...)
+}
+
+// Infof formats a message and logs it at the Info level
+func (l *Logger) Infof(template string, args ...interface{}) {
+	l.logger.Infof(template, args...)
+}
+
+// Infow logs a message with fields at the Info level
+func (l *Logger) Infow(msg string, fields log.Fields) {
+	l.logger.Infow(msg, keysAndValues(fields)...)
+}
+
+// Warn logs a message at the Warn level
+func (l *Logger) Warn(msg ...interface{}) {
+	l.logger.Warn(msg...)
+}
+
+// Warnf formats a message and logs it at the Warn level
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	l.logger.Warnf(template, args...)
+}
+
+// Warnw logs a message with fields at the Warn level
+func (l *Logger) Warnw(msg string, fields log.Fields) {
+	l.logger.Warnw(msg, keysAndValues(fields)...)
+}
+
+// Error logs a message at the Error level
+func (l *Logger) Error(msg ...interface{}) {
+	l.logger.Error(msg...)
+}
+
+// Errorf formats a message and logs it at the Error level
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	l.logger.Errorf(template, args...)
+}
+
+// Errorw logs a message with fields at the Error level
+func (l *Logger) Errorw(msg string, fields log.Fields) {
+	l.logger.Errorw(msg, keysAndValues(fields)...)
+}
+
+// Panic logs a message at the Panic level and panics
+func (l *Logger) Panic(msg ...interface{}) {
+	l.logger.Panic(msg...)
+}
+
+// Panicf formats a message, logs it at the Panic level and panics
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	l.logger.Panicf(template, args...)
+}
+
+// Panicw logs a message with fields at the Panic level and panics
+func (l *Logger) Panicw(msg string, fields log.Fields) {
+	l.logger.Panicw(msg, keysAndValues(fields)...)
+}
+
+// Fatal logs a message at the Fatal level and exits
+func (l *Logger) Fatal(msg ...interface{}) {
+	l.logger.Fatal(msg...)
+}
+
+// Fatalf formats a message, logs it at the Fatal level and exits
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	l.logger.Fatalf(template, args...)
+}
+
+// Fatalw logs a message with fields at the Fatal level and exits
+func (l *Logger) Fatalw(msg string, fields log.Fields) {
+	l.logger.Fatalw(msg, keysAndValues(fields)...)
+}
+
+var _ log.Logger = &Logger{}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zapadapter_test
+
+import (
+	"testing"
+
+	"github.com/Masterminds/log-go"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/log/zapadapter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	var logger log.Logger = zapadapter.New(zap.New(core).With(zap.String("service", "billing")))
+
+	logger.Debugw("sending ksqlDB request", log.Fields{"method": "POST"})
+	logger.Tracef("%v rows", 3)
+	logger.Info("query ", "closed")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3)
+	require.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	require.Equal(t, "sending ksqlDB request", entries[0].Message)
+	require.Equal(t, map[string]interface{}{"method": "POST", "service": "billing"}, entries[0].ContextMap())
+	require.Equal(t, zapcore.DebugLevel, entries[1].Level)
+	require.Equal(t, "3 rows", entries[1].Message)
+	require.Equal(t, zapcore.InfoLevel, entries[2].Level)
+	require.Equal(t, "query closed", entries[2].Message)
+
+	require.Panics(t, func() { logger.Panicw("broken", log.Fields{"queryId": "abc"}) })
+	require.Equal(t, "abc", logs.All()[3].ContextMap()["queryId"])
+}
@@ -0,0 +1,18 @@
+module github.com/thmeitz/ksqldb-go/log/zerologadapter
+
+go 1.18
+
+require (
+	github.com/Masterminds/log-go v0.4.0
+	github.com/rs/zerolog v1.29.0
+	github.com/stretchr/testify v1.8.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/mattn/go-isatty v0.0.14 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20210927094055-39ccf1dd6fa6 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
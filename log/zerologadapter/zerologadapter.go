@@ -0,0 +1,147 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zerologadapter adapts zerolog loggers to the log.Logger of the client:
+//
+//	logger := zerologadapter.New(zerolog.New(os.Stderr).With().Str("service", "billing").Logger())
+//	http, err := net.NewHTTPClient(options, logger)
+//
+// It is a separate module, so zerolog is only pulled in if you use it.
+package zerologadapter
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/log-go"
+	"github.com/rs/zerolog"
+)
+
+// Logger logs with a zerolog logger
+type Logger struct {
+	logger zerolog.Logger
+}
+
+// New returns a log.Logger logging with the zerolog logger
+func New(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Trace logs a message at the Trace level
+func (l *Logger) Trace(msg ...interface{}) {
+	l.logger.Trace().Msg(fmt.Sprint(msg...))
+}
+
+// Tracef formats a message and logs it at the Trace level
+func (l *Logger) Tracef(template string, args ...interface{}) {
+	l.logger.Trace().Msgf(template, args...)
+}
+
+// Tracew logs a message with fields at the Trace level
+func (l *Logger) Tracew(msg string, fields log.Fields) {
+	l.logger.Trace().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Debug logs a message at the Debug level
+func (l *Logger) Debug(msg ...interface{}) {
+	l.logger.Debug().Msg(fmt.Sprint(msg...))
+}
+
+// Debugf formats a message and logs it at the Debug level
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	l.logger.Debug().Msgf(template, args...)
+}
+
+// Debugw logs a message with fields at the Debug level
+func (l *Logger) Debugw(msg string, fields log.Fields) {
+	l.logger.Debug().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Info logs a message at the Info level
+func (l *Logger) Info(msg ...interface{}) {
+	l.logger.Info().Msg(fmt.Sprint(msg...))
+}
+
+// Infof formats a message and logs it at the Info level
+func (l *Logger) Infof(template string, args ...interface{}) {
+	l.logger.Info().Msgf(template, args...)
+}
+
+// Infow logs a message with fields at the Info level
+func (l *Logger) Infow(msg string, fields log.Fields) {
+	l.logger.Info().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Warn logs a message at the Warn level
+func (l *Logger) Warn(msg ...interface{}) {
+	l.logger.Warn().Msg(fmt.Sprint(msg...))
+}
+
+// Warnf formats a message and logs it at the Warn level
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	l.logger.Warn().Msgf(template, args...)
+}
+
+// Warnw logs a message with fields at the Warn level
+func (l *Logger) Warnw(msg string, fields log.Fields) {
+	l.logger.Warn().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Error logs a message at the Error level
+func (l *Logger) Error(msg ...interface{}) {
+	l.logger.Error().Msg(fmt.Sprint(msg...))
+}
+
+// Errorf formats a message and logs it at the Error level
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	l.logger.Error().Msgf(template, args...)
+}
+
+// Errorw logs a message with fields at the Error level
+func (l *Logger) Errorw(msg string, fields log.Fields) {
+	l.logger.Error().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Panic logs a message at the Panic level and panics
+func (l *Logger) Panic(msg ...interface{}) {
+	l.logger.Panic().Msg(fmt.Sprint(msg...))
+}
+
+// Panicf formats a message, logs it at the Panic level and panics
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	l.logger.Panic().Msgf(template, args...)
+}
+
+// Panicw logs a message with fields at the Panic level and panics
+func (l *Logger) Panicw(msg string, fields log.Fields) {
+	l.logger.Panic().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+// Fatal logs a message at the Fatal level and exits
+func (l *Logger) Fatal(msg ...interface{}) {
+	l.logger.Fatal().Msg(fmt.Sprint(msg...))
+}
+
+// Fatalf formats a message, logs it at the Fatal level and exits
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	l.logger.Fatal().Msgf(template, args...)
+}
+
+// Fatalw logs a message with fields at the Fatal level and exits
+func (l *Logger) Fatalw(msg string, fields log.Fields) {
+	l.logger.Fatal().Fields(map[string]interface{}(fields)).Msg(msg)
+}
+
+var _ log.Logger = &Logger{}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologadapter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Masterminds/log-go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/log/zerologadapter"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	lg := zerolog.New(&buf).Level(zerolog.TraceLevel).With().Str("service", "billing").Logger()
+	var logger log.Logger = zerologadapter.New(lg)
+
+	logger.Debugw("sending ksqlDB request", log.Fields{"method": "POST"})
+	entry := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, map[string]interface{}{
+		"level":   "debug",
+		"message": "sending ksqlDB request",
+		"method":  "POST",
+		"service": "billing",
+	}, entry)
+
+	buf.Reset()
+	logger.Tracef("%v rows", 3)
+	require.Contains(t, buf.String(), `"message":"3 rows"`)
+	require.Contains(t, buf.String(), `"level":"trace"`)
+
+	require.Panics(t, func() { logger.Panicw("broken", log.Fields{"queryId": "abc"}) })
+	require.Contains(t, buf.String(), `"queryId":"abc"`)
+}
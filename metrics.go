@@ -0,0 +1,258 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+type metricsKey struct{}
+
+// QueryMetrics are the counters of a push query.
+// They are safe for concurrent use, so they can be read while the query is running.
+type QueryMetrics struct {
+	// int64 fields first, they must be 64-bit aligned for atomic access
+	rows          int64
+	bytes         int64
+	decodeErrors  int64
+	droppedRows   int64
+	duplicateRows int64
+	// nanoseconds spent in the decode stages, see EnableDecodeProfiling
+	stages [4]int64
+	// unix nanoseconds
+	started int64
+	lastRow int64
+	// labels of the query context, set once at the start
+	labels atomic.Value
+}
+
+// QueryStats is a snapshot of QueryMetrics
+type QueryStats struct {
+	// Rows is the number of data rows received
+	Rows int64
+	// Bytes is the number of bytes read from the response, including the header
+	Bytes int64
+	// DecodeErrors is the number of rows which could not be decoded or checked
+	DecodeErrors int64
+	// DroppedRows is the number of rows dropped by the overflow policy, see SetRowBuffer
+	DroppedRows int64
+	// DuplicateRows is the number of duplicate rows dropped, see SetDedupe
+	DuplicateRows int64
+	// Started is the start time of the query
+	Started time.Time
+	// LastRow is the receive time of the last row; zero if no row was received
+	LastRow time.Time
+	// DecodeTimings are the times of the decode stages, see EnableDecodeProfiling
+	DecodeTimings DecodeTimings
+	// Taken is the time the snapshot was taken
+	Taken time.Time
+	// Labels of the query
+	Labels Labels
+}
+
+// NewQueryMetrics returns empty query metrics
+func NewQueryMetrics() *QueryMetrics {
+	return &QueryMetrics{}
+}
+
+// WithQueryMetrics returns a copy of ctx which makes Push record
+// its counters into m.
+//
+//	metrics := ksqldb.NewQueryMetrics()
+//	go client.Push(ksqldb.WithQueryMetrics(ctx, metrics), sql, rows, headers)
+//	...
+//	if metrics.Stats().Idle() > time.Minute {
+//		// the pipeline is stalled
+//	}
+func WithQueryMetrics(ctx context.Context, m *QueryMetrics) context.Context {
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+// queryMetrics returns the metrics of ctx or new metrics
+func queryMetrics(ctx context.Context) *QueryMetrics {
+	if m, ok := ctx.Value(metricsKey{}).(*QueryMetrics); ok && m != nil {
+		return m
+	}
+	return NewQueryMetrics()
+}
+
+// RowsReceived returns the number of data rows received
+func (m *QueryMetrics) RowsReceived() int64 {
+	return atomic.LoadInt64(&m.rows)
+}
+
+// BytesRead returns the number of bytes read from the response
+func (m *QueryMetrics) BytesRead() int64 {
+	return atomic.LoadInt64(&m.bytes)
+}
+
+// DecodeErrors returns the number of rows which could not be decoded or checked
+func (m *QueryMetrics) DecodeErrors() int64 {
+	return atomic.LoadInt64(&m.decodeErrors)
+}
+
+// DroppedRows returns the number of rows dropped by the overflow policy, see SetRowBuffer
+func (m *QueryMetrics) DroppedRows() int64 {
+	return atomic.LoadInt64(&m.droppedRows)
+}
+
+// DuplicateRows returns the number of duplicate rows dropped, see SetDedupe
+func (m *QueryMetrics) DuplicateRows() int64 {
+	return atomic.LoadInt64(&m.duplicateRows)
+}
+
+// LastRowAt returns the receive time of the last row; zero if no row was received
+func (m *QueryMetrics) LastRowAt() time.Time {
+	return unixTime(atomic.LoadInt64(&m.lastRow))
+}
+
+// Stats returns a snapshot of the metrics
+func (m *QueryMetrics) Stats() QueryStats {
+	return QueryStats{
+		Rows:          m.RowsReceived(),
+		Bytes:         m.BytesRead(),
+		DecodeErrors:  m.DecodeErrors(),
+		DroppedRows:   m.DroppedRows(),
+		DuplicateRows: m.DuplicateRows(),
+		Started:       unixTime(atomic.LoadInt64(&m.started)),
+		LastRow:       m.LastRowAt(),
+		DecodeTimings: m.DecodeTimings(),
+		Taken:         time.Now(),
+		Labels:        m.Labels(),
+	}
+}
+
+// Labels returns the labels of the query; see WithLabels
+func (m *QueryMetrics) Labels() Labels {
+	labels, _ := m.labels.Load().(Labels)
+	return labels
+}
+
+func (m *QueryMetrics) start(ctx context.Context) {
+	atomic.StoreInt64(&m.started, time.Now().UnixNano())
+	if labels := net.LabelsFromContext(ctx); labels != nil {
+		m.labels.Store(labels)
+	}
+}
+
+func (m *QueryMetrics) read(n int) {
+	atomic.AddInt64(&m.bytes, int64(n))
+}
+
+func (m *QueryMetrics) row() {
+	atomic.AddInt64(&m.rows, 1)
+	atomic.StoreInt64(&m.lastRow, time.Now().UnixNano())
+}
+
+func (m *QueryMetrics) decodeError() {
+	atomic.AddInt64(&m.decodeErrors, 1)
+}
+
+func (m *QueryMetrics) droppedRow() {
+	atomic.AddInt64(&m.droppedRows, 1)
+}
+
+func (m *QueryMetrics) duplicateRow() {
+	atomic.AddInt64(&m.duplicateRows, 1)
+}
+
+// recorder records the counters of a query into its metrics and the totals of the client
+type recorder struct {
+	query  *QueryMetrics
+	totals *QueryMetrics
+	// profiler times the decode stages; nil if decode profiling is disabled
+	profiler *profiler
+}
+
+func (r recorder) read(n int) {
+	r.query.read(n)
+	if r.totals != nil {
+		r.totals.read(n)
+	}
+}
+
+func (r recorder) row() {
+	r.query.row()
+	if r.totals != nil {
+		r.totals.row()
+	}
+}
+
+func (r recorder) decodeError() {
+	r.query.decodeError()
+	if r.totals != nil {
+		r.totals.decodeError()
+	}
+}
+
+func (r recorder) droppedRow() {
+	r.query.droppedRow()
+	if r.totals != nil {
+		r.totals.droppedRow()
+	}
+}
+
+func (r recorder) duplicateRow() {
+	r.query.duplicateRow()
+	if r.totals != nil {
+		r.totals.duplicateRow()
+	}
+}
+
+// Elapsed returns the running time of the query at the time of the snapshot
+func (s QueryStats) Elapsed() time.Duration {
+	if s.Started.IsZero() {
+		return 0
+	}
+	return s.Taken.Sub(s.Started)
+}
+
+// Idle returns the time since the last row, or since the start if no row was received
+func (s QueryStats) Idle() time.Duration {
+	if !s.LastRow.IsZero() {
+		return s.Taken.Sub(s.LastRow)
+	}
+	return s.Elapsed()
+}
+
+// RowsPerSecond returns the average row rate since the start of the query
+func (s QueryStats) RowsPerSecond() float64 {
+	return rate(s.Rows, s.Elapsed())
+}
+
+// BytesPerSecond returns the average byte rate since the start of the query
+func (s QueryStats) BytesPerSecond() float64 {
+	return rate(s.Bytes, s.Elapsed())
+}
+
+func rate(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+func unixTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestQueryMetrics_Push(t *testing.T) {
+	header := `{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]}` + "\n"
+	rows := `["a"]` + "\n" + `["b"]` + "\n"
+	res := http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(header + rows)))}
+
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(&res, nil)
+
+	kcl, _ := ksqldb.NewClient(&m)
+	rowChannel := make(chan ksqldb.Row, 2)
+	headerChannel := make(chan ksqldb.Header, 1)
+
+	metrics := ksqldb.NewQueryMetrics()
+	before := time.Now()
+	ctx := ksqldb.WithLabels(ksqldb.WithQueryMetrics(context.TODO(), metrics), ksqldb.Labels{"service": "billing"})
+	err := kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, headerChannel)
+	require.Nil(t, err)
+
+	stats := metrics.Stats()
+	require.Equal(t, int64(2), stats.Rows)
+	require.Equal(t, int64(len(header)+len(rows)), stats.Bytes)
+	require.Equal(t, int64(0), stats.DecodeErrors)
+	require.False(t, stats.Started.Before(before))
+	require.False(t, stats.LastRow.Before(stats.Started))
+	require.Equal(t, stats.LastRow, metrics.LastRowAt())
+	require.True(t, stats.RowsPerSecond() > 0)
+	require.True(t, stats.BytesPerSecond() > 0)
+	require.Equal(t, ksqldb.Labels{"service": "billing"}, stats.Labels)
+}
+
+func TestQueryMetrics_PushDecodeError(t *testing.T) {
+	body := `{"queryId":"abc","columnNames":["ID"],"columnTypes":["INTEGER"]}` + "\n" + `["a"]` + "\n"
+	res := http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(&res, nil)
+
+	kcl, _ := ksqldb.NewClient(&m)
+	kcl.EnableStrictTypes(true)
+	rowChannel := make(chan ksqldb.Row, 1)
+	headerChannel := make(chan ksqldb.Header, 1)
+
+	metrics := ksqldb.NewQueryMetrics()
+	err := kcl.Push(ksqldb.WithQueryMetrics(context.TODO(), metrics), "select * from dogs emit changes;", rowChannel, headerChannel)
+	require.NotNil(t, err)
+	require.Equal(t, int64(1), metrics.DecodeErrors())
+	require.Equal(t, int64(0), metrics.RowsReceived())
+	require.True(t, metrics.LastRowAt().IsZero())
+}
+
+func TestQueryStats(t *testing.T) {
+	now := time.Now()
+	stats := ksqldb.QueryStats{Rows: 10, Bytes: 100, Started: now.Add(-10 * time.Second), Taken: now}
+	require.Equal(t, 10*time.Second, stats.Elapsed())
+	require.Equal(t, 10*time.Second, stats.Idle())
+	require.Equal(t, 1.0, stats.RowsPerSecond())
+	require.Equal(t, 10.0, stats.BytesPerSecond())
+
+	stats.LastRow = now.Add(-2 * time.Second)
+	require.Equal(t, 2*time.Second, stats.Idle())
+
+	require.Equal(t, 0.0, ksqldb.QueryStats{Rows: 1}.RowsPerSecond())
+}
@@ -38,6 +38,15 @@ const (
 	DefaultBaseUrl         = "http://localhost:8088"
 )
 
+const (
+	// VERSION is the version of the library sent in the User-Agent
+	VERSION = "0.0.4"
+	// USER_AGENT is the User-Agent of the requests, Options.AppName is appended
+	USER_AGENT = "ksqldb-go/" + VERSION
+	// CLIENT_ID_HEADER is the header carrying Options.ClientID
+	CLIENT_ID_HEADER = "X-Client-ID"
+)
+
 // NewClient(Options, log.Logger) (*Client, error)
 
 type HTTPClientFactory interface {
@@ -101,11 +110,40 @@ func (c *Client) Close() {
 	c.tr.Close()
 }
 
-// Do delegates the given http.Request to the underlying http.Client
+// Do delegates the given http.Request to the underlying http.Client.
+// The Credentials of the options are sent with basic auth.
+// Requests without a request id get one, see SetRequestID.
+// The User-Agent and the client id of the options are set if the request has none.
+// If a logger is set, the request is logged with its request id and the labels of its context.
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	id := SetRequestID(req)
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.UserAgent())
+	}
+	if c.options.ClientID != "" && req.Header.Get(CLIENT_ID_HEADER) == "" {
+		req.Header.Set(CLIENT_ID_HEADER, c.options.ClientID)
+	}
+	if c.options.Credentials.Username != "" {
+		req.SetBasicAuth(c.options.Credentials.Username, c.options.Credentials.Password)
+	}
+	if c.logger != nil {
+		fields := log.Fields{"method": req.Method, "url": req.URL.String(), REQUEST_ID_TAG: id}
+		for k, v := range LabelsFromContext(req.Context()) {
+			fields[LABEL_TAG_PREFIX+k] = v
+		}
+		c.logger.Debugw("sending ksqlDB request", fields)
+	}
 	return c.client.Do(req)
 }
 
+// UserAgent returns the User-Agent of the requests, ex. ksqldb-go/0.0.4 billing-service/1.2
+func (c *Client) UserAgent() string {
+	if c.options.AppName == "" {
+		return USER_AGENT
+	}
+	return USER_AGENT + " " + c.options.AppName
+}
+
 func (c *Client) Get(url string) (*http.Response, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
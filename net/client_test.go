@@ -17,6 +17,8 @@ limitations under the License.
 package net_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -40,3 +42,48 @@ func TestClient_Nil(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, "invalid host name given", err.Error())
 }
+
+func TestClient_BasicAuth(t *testing.T) {
+	var user, password string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+	}))
+	defer srv.Close()
+
+	client, err := net.NewHTTPClient(net.Options{BaseUrl: srv.URL, Credentials: net.Credentials{Username: "key", Password: "secret"}}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+
+	res, err := client.Get(client.GetUrl("/info"))
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, "key", user)
+	require.Equal(t, "secret", password)
+}
+
+func TestClient_UserAgentAndClientID(t *testing.T) {
+	var userAgent, clientID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		clientID = r.Header.Get(net.CLIENT_ID_HEADER)
+	}))
+	defer srv.Close()
+
+	client, err := net.NewHTTPClient(net.Options{BaseUrl: srv.URL}, nil)
+	require.Nil(t, err)
+	res, err := client.Get(client.GetUrl("/info"))
+	require.Nil(t, err)
+	res.Body.Close()
+	client.Close()
+	require.Equal(t, net.USER_AGENT, userAgent)
+	require.Equal(t, "", clientID)
+
+	client, err = net.NewHTTPClient(net.Options{BaseUrl: srv.URL, AppName: "billing/1.2", ClientID: "billing-7"}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+	res, err = client.Post(client.GetUrl("/ksql"), "application/json", nil)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, "ksqldb-go/"+net.VERSION+" billing/1.2", userAgent)
+	require.Equal(t, "billing-7", clientID)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"sort"
+)
+
+// LABEL_TAG_PREFIX prefixes labels added as tags to tracing spans
+const LABEL_TAG_PREFIX = "ksqldb.label."
+
+type labelsKey struct{}
+
+// Labels are user defined key value pairs attached to queries,
+// like service, feature or owner. They are added to the metrics,
+// log entries and tracing spans of the query, so the load on shared
+// clusters can be attributed.
+type Labels map[string]string
+
+// WithLabels returns a copy of ctx carrying the labels.
+// Labels of ctx are merged, the given labels take precedence.
+func WithLabels(ctx context.Context, labels Labels) context.Context {
+	merged := Labels{}
+	for k, v := range LabelsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, labelsKey{}, merged)
+}
+
+// LabelsFromContext returns the labels of ctx; nil if there are none.
+// The returned labels must not be modified.
+func LabelsFromContext(ctx context.Context) Labels {
+	labels, _ := ctx.Value(labelsKey{}).(Labels)
+	return labels
+}
+
+// Keys returns the sorted label keys
+func (l Labels) Keys() []string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
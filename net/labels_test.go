@@ -0,0 +1,60 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+func TestWithLabels(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, net.LabelsFromContext(ctx))
+
+	ctx = net.WithLabels(ctx, net.Labels{"service": "billing", "owner": "team-a"})
+	ctx = net.WithLabels(ctx, net.Labels{"owner": "team-b", "feature": "invoices"})
+
+	labels := net.LabelsFromContext(ctx)
+	require.Equal(t, net.Labels{"service": "billing", "owner": "team-b", "feature": "invoices"}, labels)
+	require.Equal(t, []string{"feature", "owner", "service"}, labels.Keys())
+}
+
+func TestTransport_LabelsAsSpanTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tracer := mocktracer.New()
+	tr := net.NewTransport(net.Options{Tracer: tracer, OpentracingSpanName: "ksqldb"})
+	defer tr.Close()
+
+	ctx := net.WithLabels(context.Background(), net.Labels{"service": "billing"})
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	require.Nil(t, err)
+	res, err := tr.RoundTrip(req)
+	require.Nil(t, err)
+	res.Body.Close()
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "billing", spans[0].Tag(net.LABEL_TAG_PREFIX+"service"))
+}
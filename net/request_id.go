@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	// REQUEST_ID_HEADER is the header carrying the request id
+	REQUEST_ID_HEADER = "X-Request-ID"
+	// REQUEST_ID_TAG is the tag of the request id in tracing spans and log entries
+	REQUEST_ID_TAG = "ksqldb.request_id"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id.
+// Requests made with ctx send it instead of a generated one, so
+// a request id of an incoming request can be passed on to ksqlDB.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request id of ctx; empty if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request id of 32 hex digits
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// SetRequestID sets the request id header of the request if it isn't set
+// and returns the request id. The id is taken from the context of the
+// request or generated.
+func SetRequestID(req *http.Request) string {
+	if id := req.Header.Get(REQUEST_ID_HEADER); id != "" {
+		return id
+	}
+	id := RequestIDFromContext(req.Context())
+	if id == "" {
+		id = NewRequestID()
+	}
+	req.Header.Set(REQUEST_ID_HEADER, id)
+	return id
+}
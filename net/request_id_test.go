@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+func TestSetRequestID(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://localhost", nil)
+	require.Nil(t, err)
+	id := net.SetRequestID(req)
+	require.Len(t, id, 32)
+	require.Equal(t, id, req.Header.Get(net.REQUEST_ID_HEADER))
+	// an existing request id is kept
+	require.Equal(t, id, net.SetRequestID(req))
+	require.NotEqual(t, id, net.NewRequestID())
+}
+
+func TestSetRequestID_FromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, "", net.RequestIDFromContext(ctx))
+
+	ctx = net.WithRequestID(ctx, "req-1")
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost", nil)
+	require.Nil(t, err)
+	require.Equal(t, "req-1", net.SetRequestID(req))
+	require.Equal(t, "req-1", req.Header.Get(net.REQUEST_ID_HEADER))
+}
+
+func TestClient_SendsRequestID(t *testing.T) {
+	var received, tagged string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(net.REQUEST_ID_HEADER)
+	}))
+	defer server.Close()
+
+	tracer := mocktracer.New()
+	client, err := net.NewHTTPClient(net.Options{BaseUrl: server.URL, Tracer: tracer, OpentracingSpanName: "ksqldb"}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+
+	ctx := net.WithRequestID(context.Background(), "req-2")
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	require.Nil(t, err)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	res.Body.Close()
+
+	require.Equal(t, "req-2", received)
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	tagged, _ = spans[0].Tag(net.REQUEST_ID_TAG).(string)
+	require.Equal(t, "req-2", tagged)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsResolver resolves the hosts of new connections and rotates the
+// connections over the resolved addresses. The addresses of a host are
+// re-resolved once they are older than interval, so scaling events and
+// failovers behind DNS are picked up by new connections.
+type dnsResolver struct {
+	interval   time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	dialer     net.Dialer
+	// changed is called if the addresses of a host changed
+	changed func()
+
+	mu    sync.Mutex
+	hosts map[string]*resolvedHost
+}
+
+type resolvedHost struct {
+	addrs    []string
+	resolved time.Time
+	// next is the index of the address of the next connection
+	next int
+}
+
+func newDNSResolver(options Options) *dnsResolver {
+	lookupHost := options.LookupHost
+	if lookupHost == nil {
+		lookupHost = net.DefaultResolver.LookupHost
+	}
+	return &dnsResolver{
+		interval:   options.DNSRefreshInterval,
+		lookupHost: lookupHost,
+		hosts:      map[string]*resolvedHost{},
+	}
+}
+
+// DialContext dials the resolved addresses of the host of addr in turn until
+// a connection is established
+func (r *dnsResolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return r.dialer.DialContext(ctx, network, addr)
+	}
+	addrs := r.resolve(ctx, host)
+	if len(addrs) == 0 {
+		return r.dialer.DialContext(ctx, network, addr)
+	}
+
+	var conn net.Conn
+	for _, resolved := range addrs {
+		// addresses with a port replace the port of addr
+		target := resolved
+		if _, _, err := net.SplitHostPort(resolved); err != nil {
+			target = net.JoinHostPort(resolved, port)
+		}
+		if conn, err = r.dialer.DialContext(ctx, network, target); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// resolve returns the addresses of host, starting with the address of the next connection.
+// Stale addresses are used if the host can't be resolved again.
+func (r *dnsResolver) resolve(ctx context.Context, host string) []string {
+	r.mu.Lock()
+	resolved, ok := r.hosts[host]
+	r.mu.Unlock()
+
+	if !ok || time.Since(resolved.resolved) >= r.interval {
+		if addrs, err := r.lookupHost(ctx, host); err == nil && len(addrs) > 0 {
+			r.mu.Lock()
+			changed := ok && !sameAddrs(resolved.addrs, addrs)
+			resolved = &resolvedHost{addrs: addrs, resolved: time.Now()}
+			r.hosts[host] = resolved
+			r.mu.Unlock()
+			if changed && r.changed != nil {
+				r.changed()
+			}
+		} else if !ok {
+			return nil
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start := resolved.next % len(resolved.addrs)
+	resolved.next++
+	return append(append([]string{}, resolved.addrs[start:]...), resolved.addrs[:start]...)
+}
+
+func sameAddrs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
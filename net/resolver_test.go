@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+func TestTransport_DNSRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	var mu sync.Mutex
+	var lookups []string
+	// 127.0.0.2 refuses connections, the next address is dialed then
+	addrs := []string{"127.0.0.2", "127.0.0.1"}
+	lookupHost := func(ctx context.Context, host string) ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		lookups = append(lookups, host)
+		return addrs, nil
+	}
+
+	client, err := net.NewHTTPClient(net.Options{
+		BaseUrl:            "http://ksqldb.test:" + u.Port(),
+		DisableKeepAlives:  true,
+		DNSRefreshInterval: 50 * time.Millisecond,
+		LookupHost:         lookupHost,
+	}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+
+	get := func() {
+		res, err := client.Get(client.GetUrl("/info"))
+		require.Nil(t, err)
+		res.Body.Close()
+	}
+	get()
+	get()
+	mu.Lock()
+	require.Equal(t, []string{"ksqldb.test"}, lookups)
+	addrs = []string{"127.0.0.1"}
+	mu.Unlock()
+
+	time.Sleep(60 * time.Millisecond)
+	get()
+	mu.Lock()
+	require.Len(t, lookups, 2)
+	mu.Unlock()
+}
+
+func TestTransport_LookupHostPort(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	// the port of the address replaces the port of the base url
+	client, err := net.NewHTTPClient(net.Options{
+		BaseUrl: "http://ksqldb.test:1",
+		LookupHost: func(ctx context.Context, host string) ([]string, error) {
+			return []string{u.Host}, nil
+		},
+	}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+
+	res, err := client.Get(client.GetUrl("/info"))
+	require.Nil(t, err)
+	res.Body.Close()
+}
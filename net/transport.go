@@ -25,10 +25,12 @@ Next: change opentracing-go to opentelemetry if stable version is released
 package net
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -46,8 +48,43 @@ type Options struct {
 	BaseUrl string
 	// Credentials for BaseAuth remote user
 	Credentials Credentials
+	// AppName is appended to the User-Agent of the requests, ex. billing-service/1.2,
+	// so server operators can attribute the load to applications
+	AppName string
+	// ClientID is sent in the CLIENT_ID_HEADER of all requests if it is set
+	ClientID string
 	// AllowHTTP
 	AllowHTTP bool
+	// ForceHTTP1 forces HTTP/1.1 with chunked streaming responses, also for https
+	// and if AllowHTTP is set. Use it behind proxies which break HTTP/2 (h2c).
+	//
+	// Tradeoffs: every push query occupies its own connection, so MaxConnsPerHost
+	// must leave room for the close-query requests, and proxies buffering chunked
+	// responses delay the rows. Servers before ksqlDB 0.15 need the legacy /query
+	// endpoint for push queries over HTTP/1.1.
+	ForceHTTP1 bool
+	// ReadIdleTimeout see https://pkg.go.dev/golang.org/x/net/http2#Transport.ReadIdleTimeout,
+	// health checks of long-lived HTTP/2 streams; only used with AllowHTTP
+	ReadIdleTimeout time.Duration
+	// PingTimeout see https://pkg.go.dev/golang.org/x/net/http2#Transport.PingTimeout;
+	// only used with AllowHTTP
+	PingTimeout time.Duration
+	// ConfigureHTTP2 is called with the http2.Transport built for AllowHTTP
+	// to tweak it before use
+	ConfigureHTTP2 func(*http2.Transport)
+	// DNSRefreshInterval enables DNS re-resolution: the host is resolved for new
+	// connections, which rotate over its addresses. The addresses are resolved
+	// again once they are older than the interval; if they changed, idle connections
+	// are closed so new requests move to the fresh addresses. Open streams are not affected.
+	DNSRefreshInterval time.Duration
+	// LookupHost resolves the host with DNS re-resolution, ex. the LookupHost of
+	// a net.Resolver with a custom DNS server; defaults to net.DefaultResolver.LookupHost.
+	// Setting it enables DNS re-resolution. Addresses with a port, ex. host:port of
+	// the endpoints of a Kubernetes Service, are dialed on their port instead of the port of BaseUrl.
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+	// RoundTripper replaces the built transport, ex. for custom dialing or proxies.
+	// The transport options above are ignored then, tracing still applies.
+	RoundTripper http.RoundTripper
 	// DisableKeepAlives see https://golang.org/pkg/net/http/#Transport.DisableKeepAlives
 	DisableKeepAlives bool
 	// DisableCompression see https://golang.org/pkg/net/http/#Transport.DisableCompression
@@ -97,10 +134,13 @@ type Options struct {
 // Transport wraps an http.Transport and adds support for tracing and
 // http2.
 type Transport struct {
-	quit          chan struct{}
-	closed        bool
-	tr            *http.Transport
-	tr2           *http2.Transport
+	quit chan struct{}
+	// closeOnce closes quit once, it is shared by the copies of the transport
+	closeOnce *sync.Once
+	tr        *http.Transport
+	tr2       *http2.Transport
+	// rt is the custom RoundTripper of Options
+	rt            http.RoundTripper
 	tracer        opentracing.Tracer
 	spanName      string
 	componentName string
@@ -131,6 +171,15 @@ func NewTransport(options Options) *Transport {
 		options.ExpectContinueTimeout = options.Timeout
 	}
 
+	if options.RoundTripper != nil {
+		return withTracingOptions(&Transport{
+			quit:      make(chan struct{}),
+			closeOnce: &sync.Once{},
+			rt:        options.RoundTripper,
+			tracer:    options.Tracer,
+		}, options)
+	}
+
 	htransport := &http.Transport{
 		DisableKeepAlives:      options.DisableKeepAlives,
 		DisableCompression:     options.DisableCompression,
@@ -146,8 +195,19 @@ func NewTransport(options Options) *Transport {
 		IdleConnTimeout:        options.IdleConnTimeout,
 		ExpectContinueTimeout:  options.ExpectContinueTimeout,
 	}
+	var resolver *dnsResolver
+	if options.DNSRefreshInterval > 0 || options.LookupHost != nil {
+		resolver = newDNSResolver(options)
+		htransport.DialContext = resolver.DialContext
+		resolver.changed = htransport.CloseIdleConnections
+	}
+	if options.ForceHTTP1 {
+		// a non-nil empty map disables HTTP/2
+		htransport.ForceAttemptHTTP2 = false
+		htransport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
 	var htransport2 = &http2.Transport{}
-	if options.AllowHTTP {
+	if options.AllowHTTP && !options.ForceHTTP1 {
 		// ksqlDB uses HTTP2 and if the server is on HTTP then Golang will not
 		// use HTTP2 unless we force it to, thus.
 		// Without this you get the error `http2: unsupported scheme`
@@ -155,21 +215,25 @@ func NewTransport(options Options) *Transport {
 		// Pretend we are dialing a TLS endpoint.
 		// Note, we ignore the passed tls.Config
 		htransport2.DialTLS = func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			if resolver != nil {
+				return resolver.DialContext(context.Background(), network, addr)
+			}
 			return net.Dial(network, addr)
 		}
-		t2 := &Transport{
-			quit:   make(chan struct{}),
-			tr2:    htransport2,
-			tracer: options.Tracer,
+		if resolver != nil {
+			resolver.changed = htransport2.CloseIdleConnections
 		}
-		if t2.tracer != nil {
-			if options.OpentracingComponentTag != "" {
-				t2 = WithComponentTag(t2, options.OpentracingComponentTag)
-			}
-			if options.OpentracingSpanName != "" {
-				t2 = WithSpanName(t2, options.OpentracingSpanName)
-			}
+		htransport2.ReadIdleTimeout = options.ReadIdleTimeout
+		htransport2.PingTimeout = options.PingTimeout
+		if options.ConfigureHTTP2 != nil {
+			options.ConfigureHTTP2(htransport2)
 		}
+		t2 := withTracingOptions(&Transport{
+			quit:      make(chan struct{}),
+			closeOnce: &sync.Once{},
+			tr2:       htransport2,
+			tracer:    options.Tracer,
+		}, options)
 		go func() {
 			for {
 				select {
@@ -184,20 +248,12 @@ func NewTransport(options Options) *Transport {
 		return t2
 
 	} else {
-		t := &Transport{
-			quit:   make(chan struct{}),
-			tr:     htransport,
-			tracer: options.Tracer,
-		}
-
-		if t.tracer != nil {
-			if options.OpentracingComponentTag != "" {
-				t = WithComponentTag(t, options.OpentracingComponentTag)
-			}
-			if options.OpentracingSpanName != "" {
-				t = WithSpanName(t, options.OpentracingSpanName)
-			}
-		}
+		t := withTracingOptions(&Transport{
+			quit:      make(chan struct{}),
+			closeOnce: &sync.Once{},
+			tr:        htransport,
+			tracer:    options.Tracer,
+		}, options)
 
 		go func() {
 			for {
@@ -215,12 +271,24 @@ func NewTransport(options Options) *Transport {
 
 }
 
-// Close the transport
+// withTracingOptions sets the component tag and the span name of the options
+func withTracingOptions(t *Transport, options Options) *Transport {
+	if t.tracer != nil {
+		if options.OpentracingComponentTag != "" {
+			t = WithComponentTag(t, options.OpentracingComponentTag)
+		}
+		if options.OpentracingSpanName != "" {
+			t = WithSpanName(t, options.OpentracingSpanName)
+		}
+	}
+	return t
+}
+
+// Close the transport, it is safe to call Close concurrently and more than once
 func (t *Transport) Close() {
-	if !t.closed {
-		t.closed = true
+	t.closeOnce.Do(func() {
 		close(t.quit)
-	}
+	})
 }
 
 // CloseIdleConnection closes idle connections
@@ -235,7 +303,11 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	var span opentracing.Span
 	var err error
 	var rsp *http.Response
-	if t.tr != nil {
+	if t.tr != nil || t.rt != nil {
+		var tr http.RoundTripper = t.tr
+		if t.rt != nil {
+			tr = t.rt
+		}
 		if t.spanName != "" {
 			req, span = t.injectSpan(req)
 			defer span.Finish()
@@ -243,7 +315,7 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			span.LogKV("http_do", "start")
 		}
 
-		rsp, err = t.tr.RoundTrip(req)
+		rsp, err = tr.RoundTrip(req)
 		if span != nil {
 			span.LogKV("http_do", "stop")
 			if rsp != nil {
@@ -273,6 +345,12 @@ func (t *Transport) injectSpan(req *http.Request) (*http.Request, opentracing.Sp
 	ext.HTTPUrl.Set(span, req.URL.String())
 	ext.HTTPMethod.Set(span, req.Method)
 	ext.SpanKind.Set(span, "client")
+	if id := req.Header.Get(REQUEST_ID_HEADER); id != "" {
+		span.SetTag(REQUEST_ID_TAG, id)
+	}
+	for k, v := range LabelsFromContext(req.Context()) {
+		span.SetTag(LABEL_TAG_PREFIX+k, v)
+	}
 
 	_ = t.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
 
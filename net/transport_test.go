@@ -15,3 +15,74 @@ limitations under the License.
 */
 
 package net_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/net"
+	"golang.org/x/net/http2"
+)
+
+func TestTransport_ForceHTTP1(t *testing.T) {
+	var proto string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto = r.Proto
+	}))
+	defer srv.Close()
+
+	// AllowHTTP alone would speak h2c, which the server doesn't understand
+	client, err := net.NewHTTPClient(net.Options{BaseUrl: srv.URL, AllowHTTP: true, ForceHTTP1: true}, nil)
+	require.Nil(t, err)
+	defer client.Close()
+
+	res, err := client.Get(client.GetUrl("/info"))
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, "HTTP/1.1", proto)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestTransport_RoundTripper(t *testing.T) {
+	var path string
+	tr := net.NewTransport(net.Options{RoundTripper: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	})})
+	defer tr.Close()
+
+	req, _ := http.NewRequest("GET", "http://localhost:8088/info", nil)
+	res, err := tr.RoundTrip(req)
+	require.Nil(t, err)
+	require.Equal(t, 200, res.StatusCode)
+	require.Equal(t, "/info", path)
+}
+
+func TestTransport_ConfigureHTTP2(t *testing.T) {
+	var configured *http2.Transport
+	tr := net.NewTransport(net.Options{
+		AllowHTTP:       true,
+		ReadIdleTimeout: 30 * time.Second,
+		PingTimeout:     5 * time.Second,
+		ConfigureHTTP2: func(tr2 *http2.Transport) {
+			configured = tr2
+			tr2.StrictMaxConcurrentStreams = true
+		},
+	})
+	defer tr.Close()
+
+	require.NotNil(t, configured)
+	require.True(t, configured.AllowHTTP)
+	require.Equal(t, 30*time.Second, configured.ReadIdleTimeout)
+	require.Equal(t, 5*time.Second, configured.PingTimeout)
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// NullString is a STRING column which may be null.
+// It implements sql.Scanner and driver.Valuer, so it can be used
+// with Record.Scan and QuoteLiteral. sql.NullString works as well.
+type NullString struct {
+	String string
+	// Valid is true if String is not NULL
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface
+func (n *NullString) Scan(value interface{}) error {
+	n.String, n.Valid = "", value != nil
+	if value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		n.String = v
+	case []byte:
+		n.String = string(v)
+	default:
+		n.Valid = false
+		return fmt.Errorf("can't scan %T into NullString", value)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (n NullString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.String, nil
+}
+
+// MarshalJSON encodes the value or null
+func (n NullString) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.Valid, n.String)
+}
+
+// NullInt64 is an INTEGER or BIGINT column which may be null
+type NullInt64 struct {
+	Int64 int64
+	// Valid is true if Int64 is not NULL
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface
+func (n *NullInt64) Scan(value interface{}) error {
+	n.Int64, n.Valid = 0, value != nil
+	if value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case int32:
+		n.Int64 = int64(v)
+	case int64:
+		n.Int64 = v
+	case int:
+		n.Int64 = int64(v)
+	case float64:
+		n.Int64 = int64(v)
+	default:
+		n.Valid = false
+		return fmt.Errorf("can't scan %T into NullInt64", value)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (n NullInt64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Int64, nil
+}
+
+// MarshalJSON encodes the value or null
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.Valid, n.Int64)
+}
+
+// NullFloat64 is a DOUBLE or DECIMAL column which may be null
+type NullFloat64 struct {
+	Float64 float64
+	// Valid is true if Float64 is not NULL
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface
+func (n *NullFloat64) Scan(value interface{}) error {
+	n.Float64, n.Valid = 0, value != nil
+	if value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case float64:
+		n.Float64 = v
+	case float32:
+		n.Float64 = float64(v)
+	case int32:
+		n.Float64 = float64(v)
+	case int64:
+		n.Float64 = float64(v)
+	default:
+		n.Valid = false
+		return fmt.Errorf("can't scan %T into NullFloat64", value)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (n NullFloat64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Float64, nil
+}
+
+// MarshalJSON encodes the value or null
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.Valid, n.Float64)
+}
+
+// NullBool is a BOOLEAN column which may be null
+type NullBool struct {
+	Bool bool
+	// Valid is true if Bool is not NULL
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface
+func (n *NullBool) Scan(value interface{}) error {
+	n.Bool, n.Valid = false, value != nil
+	if value == nil {
+		return nil
+	}
+	v, ok := value.(bool)
+	if !ok {
+		n.Valid = false
+		return fmt.Errorf("can't scan %T into NullBool", value)
+	}
+	n.Bool = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (n NullBool) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Bool, nil
+}
+
+// MarshalJSON encodes the value or null
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.Valid, n.Bool)
+}
+
+// NullTime is a TIMESTAMP, DATE or TIME column which may be null
+type NullTime struct {
+	Time time.Time
+	// Valid is true if Time is not NULL
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+// Strings are parsed as TIMESTAMP, DATE or TIME,
+// numbers are epoch milliseconds like ROWTIME or WINDOWSTART.
+func (n *NullTime) Scan(value interface{}) error {
+	n.Time, n.Valid = time.Time{}, value != nil
+	if value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case time.Time:
+		n.Time = v
+		return nil
+	case int32:
+		n.Time = epochMillis(int64(v))
+		return nil
+	case int64:
+		n.Time = epochMillis(v)
+		return nil
+	case float64:
+		n.Time = epochMillis(int64(v))
+		return nil
+	case string:
+		for _, layout := range []string{KSQL_TIMESTAMP_FORMAT, KSQL_DATE_FORMAT, KSQL_TIME_FORMAT} {
+			if t, err := time.Parse(layout, v); err == nil {
+				n.Time = t
+				return nil
+			}
+		}
+	}
+	n.Valid = false
+	return fmt.Errorf("can't scan %T into NullTime", value)
+}
+
+// Value implements the driver.Valuer interface
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
+
+// MarshalJSON encodes the value or null
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	return marshalNull(n.Valid, n.Time)
+}
+
+func marshalNull(valid bool, value interface{}) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(value)
+}
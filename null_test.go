@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+type nullDog struct {
+	Name    ksqldb.NullString  `ksql:"NAME"`
+	Age     ksqldb.NullInt64   `ksql:"AGE"`
+	Weight  ksqldb.NullFloat64 `ksql:"WEIGHT"`
+	Good    ksqldb.NullBool    `ksql:"GOOD"`
+	Born    ksqldb.NullTime    `ksql:"BORN"`
+	Owner   sql.NullString     `ksql:"OWNER"`
+	Visited *ksqldb.NullTime   `ksql:"VISITED"`
+}
+
+func TestNullTypes_Scan(t *testing.T) {
+	born := time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)
+	var dog nullDog
+	err := ksqldb.Record{
+		"NAME":    "Lara",
+		"AGE":     int32(3),
+		"WEIGHT":  12.5,
+		"GOOD":    true,
+		"BORN":    born,
+		"OWNER":   "Tom",
+		"VISITED": "2021-11-16T06:00:00.000",
+	}.Scan(&dog)
+	require.Nil(t, err)
+	require.Equal(t, nullDog{
+		Name:    ksqldb.NullString{String: "Lara", Valid: true},
+		Age:     ksqldb.NullInt64{Int64: 3, Valid: true},
+		Weight:  ksqldb.NullFloat64{Float64: 12.5, Valid: true},
+		Good:    ksqldb.NullBool{Bool: true, Valid: true},
+		Born:    ksqldb.NullTime{Time: born, Valid: true},
+		Owner:   sql.NullString{String: "Tom", Valid: true},
+		Visited: &ksqldb.NullTime{Time: born, Valid: true},
+	}, dog)
+
+	err = ksqldb.Record{"NAME": nil, "AGE": nil, "WEIGHT": nil, "GOOD": nil, "BORN": nil, "OWNER": nil, "VISITED": nil}.Scan(&dog)
+	require.Nil(t, err)
+	require.Equal(t, nullDog{}, dog)
+}
+
+func TestNullTime_ScanEpochMillis(t *testing.T) {
+	rowtime := time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)
+	ms := rowtime.UnixNano() / int64(time.Millisecond)
+	for value, want := range map[interface{}]time.Time{
+		ms:          rowtime,
+		float64(ms): rowtime,
+		int32(1000): time.Unix(1, 0).UTC(),
+	} {
+		var row struct {
+			TS ksqldb.NullTime `ksql:"ROWTIME"`
+		}
+		require.Nil(t, ksqldb.Record{"ROWTIME": value}.Scan(&row))
+		require.Equal(t, ksqldb.NullTime{Time: want, Valid: true}, row.TS)
+	}
+}
+
+func TestNullTypes_ScanError(t *testing.T) {
+	var dog nullDog
+	err := ksqldb.Record{"AGE": "old"}.Scan(&dog)
+	require.NotNil(t, err)
+	require.Equal(t, "can't scan AGE: can't scan string into NullInt64", err.Error())
+	require.False(t, dog.Age.Valid)
+}
+
+func TestNullTypes_QuoteLiteral(t *testing.T) {
+	for value, want := range map[interface{}]string{
+		ksqldb.NullString{String: "it's", Valid: true}: "'it''s'",
+		ksqldb.NullString{}:                            "NULL",
+		ksqldb.NullInt64{Int64: 3, Valid: true}:        "3",
+		ksqldb.NullFloat64{}:                           "NULL",
+		ksqldb.NullBool{Bool: true, Valid: true}:       "true",
+		sql.NullInt64{Int64: 5, Valid: true}:           "5",
+		(*ksqldb.NullTime)(nil):                        "NULL",
+	} {
+		got, err := ksqldb.QuoteLiteral(value)
+		require.Nil(t, err)
+		require.Equal(t, want, got)
+	}
+}
+
+func TestNullTypes_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(nullDog{Name: ksqldb.NullString{String: "Lara", Valid: true}})
+	require.Nil(t, err)
+	require.Equal(t, `{"Name":"Lara","Age":null,"Weight":null,"Good":null,"Born":null,"Owner":{"String":"","Valid":false},"Visited":null}`, string(data))
+}
@@ -0,0 +1,542 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// StatementKind is the kind of a parsed statement
+type StatementKind string
+
+const (
+	KindQuery          StatementKind = "QUERY"
+	KindCreateStream   StatementKind = "CREATE_STREAM"
+	KindCreateStreamAs StatementKind = "CREATE_STREAM_AS_SELECT"
+	KindCreateTable    StatementKind = "CREATE_TABLE"
+	KindCreateTableAs  StatementKind = "CREATE_TABLE_AS_SELECT"
+	KindInsertInto     StatementKind = "INSERT_INTO"
+	KindInsertValues   StatementKind = "INSERT_VALUES"
+	KindDropStream     StatementKind = "DROP_STREAM"
+	KindDropTable      StatementKind = "DROP_TABLE"
+	KindAlterSource    StatementKind = "ALTER_SOURCE"
+	KindDescribe       StatementKind = "DESCRIBE"
+	KindExplain        StatementKind = "EXPLAIN"
+	KindTerminateQuery StatementKind = "TERMINATE"
+	KindCreateType     StatementKind = "CREATE_TYPE"
+	KindDropType       StatementKind = "DROP_TYPE"
+	KindOther          StatementKind = "OTHER"
+)
+
+// StatementNode is the root of the AST of a single statement.
+//
+// Identifiers are normalized like ksqlDB does it: unquoted
+// identifiers are upper cased, quoted identifiers are unquoted.
+// Expressions and types are kept as canonical text (see Format).
+type StatementNode struct {
+	Kind StatementKind
+	// Text is the canonical text of the statement
+	Text string
+	// Target is the source which is created, dropped, altered, described or written to
+	Target *SourceRef
+	// Columns are the table elements of CREATE STREAM|TABLE, the added columns of ALTER
+	// or the column names of INSERT INTO ... VALUES (without types)
+	Columns []*ColumnDef
+	// Properties are the WITH options
+	Properties []*Property
+	// Query is the query of SELECT, CSAS, CTAS and INSERT INTO ... SELECT
+	Query *QueryNode
+	// Values are the value expressions of INSERT INTO ... VALUES
+	Values []string
+	// QueryID of TERMINATE, "ALL" if all queries are terminated
+	QueryID string
+	// TypeName and Type of CREATE TYPE and DROP TYPE
+	TypeName string
+	Type     string
+	// Explained is the statement of EXPLAIN <statement>
+	Explained *StatementNode
+
+	OrReplace   bool
+	IfNotExists bool
+	IfExists    bool
+	DeleteTopic bool
+}
+
+// QueryNode represents a SELECT
+type QueryNode struct {
+	Select      []*SelectItem
+	From        *SourceRef
+	Joins       []*Join
+	Window      string
+	Where       string
+	GroupBy     []string
+	PartitionBy []string
+	Having      string
+	// Emit is CHANGES, FINAL or empty
+	Emit string
+	// Limit is nil without LIMIT clause
+	Limit *int
+}
+
+// SelectItem is a single item of the select list
+type SelectItem struct {
+	Expression string
+	Alias      string
+	// All is true for * and <source>.*
+	All bool
+	// Qualifier is the source of <source>.*
+	Qualifier string
+}
+
+// Join is a joined source of a query
+type Join struct {
+	// Type is INNER, LEFT or FULL
+	Type   string
+	Source *SourceRef
+	Within string
+	On     string
+}
+
+// SourceRef references a stream or table
+type SourceRef struct {
+	Name  string
+	Alias string
+}
+
+// ColumnDef is a column definition
+type ColumnDef struct {
+	Name       string
+	Type       string
+	Key        bool
+	PrimaryKey bool
+}
+
+// Property is a WITH option
+type Property struct {
+	// Name is upper cased
+	Name string
+	// Value is unquoted for string literals
+	Value string
+}
+
+// Sources returns the sources a statement reads from or references
+func (s *StatementNode) Sources() []*SourceRef {
+	switch s.Kind {
+	case KindDropStream, KindDropTable, KindAlterSource, KindDescribe:
+		return []*SourceRef{s.Target}
+	case KindExplain:
+		if s.Explained != nil {
+			return s.Explained.Sources()
+		}
+	}
+	if s.Query != nil {
+		return s.Query.Sources()
+	}
+	return nil
+}
+
+// Sinks returns the sources a statement creates or writes to
+func (s *StatementNode) Sinks() []*SourceRef {
+	switch s.Kind {
+	case KindCreateStream, KindCreateStreamAs, KindCreateTable, KindCreateTableAs, KindInsertInto, KindInsertValues:
+		return []*SourceRef{s.Target}
+	}
+	return nil
+}
+
+// KeyColumns returns the KEY and PRIMARY KEY columns
+func (s *StatementNode) KeyColumns() []*ColumnDef {
+	keys := []*ColumnDef{}
+	for _, col := range s.Columns {
+		if col.Key {
+			keys = append(keys, col)
+		}
+	}
+	return keys
+}
+
+// Property returns the WITH option with the given name (case insensitive)
+func (s *StatementNode) Property(name string) (*Property, bool) {
+	for _, prop := range s.Properties {
+		if strings.EqualFold(prop.Name, name) {
+			return prop, true
+		}
+	}
+	return nil, false
+}
+
+// Sources returns the FROM source and all joined sources
+func (q *QueryNode) Sources() []*SourceRef {
+	sources := []*SourceRef{}
+	if q.From != nil {
+		sources = append(sources, q.From)
+	}
+	for _, join := range q.Joins {
+		sources = append(sources, join.Source)
+	}
+	return sources
+}
+
+// Visitor visits the nodes of an AST, see Walk
+type Visitor interface {
+	VisitStatement(*StatementNode)
+	VisitQuery(*QueryNode)
+	VisitSource(*SourceRef)
+	VisitSink(*SourceRef)
+	VisitColumn(*ColumnDef)
+	VisitProperty(*Property)
+}
+
+// BaseVisitor is a Visitor which does nothing.
+// Embed it to implement only the needed methods.
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitStatement(*StatementNode) {}
+func (BaseVisitor) VisitQuery(*QueryNode)         {}
+func (BaseVisitor) VisitSource(*SourceRef)        {}
+func (BaseVisitor) VisitSink(*SourceRef)          {}
+func (BaseVisitor) VisitColumn(*ColumnDef)        {}
+func (BaseVisitor) VisitProperty(*Property)       {}
+
+// Walk traverses the statement depth first: the statement itself,
+// its sinks, columns, properties, the query and its sources
+// and finally an explained statement.
+func Walk(v Visitor, stmnt *StatementNode) {
+	v.VisitStatement(stmnt)
+	for _, sink := range stmnt.Sinks() {
+		v.VisitSink(sink)
+	}
+	for _, col := range stmnt.Columns {
+		v.VisitColumn(col)
+	}
+	for _, prop := range stmnt.Properties {
+		v.VisitProperty(prop)
+	}
+	if stmnt.Query != nil {
+		v.VisitQuery(stmnt.Query)
+	}
+	switch stmnt.Kind {
+	case KindExplain:
+		if stmnt.Explained != nil {
+			Walk(v, stmnt.Explained)
+		}
+	default:
+		for _, source := range stmnt.Sources() {
+			v.VisitSource(source)
+		}
+	}
+}
+
+// Parse parses the sql and returns the AST of every statement.
+//
+// If the sql contains syntax errors, they are returned as *SqlSyntaxErrorList.
+func Parse(sql string) ([]*StatementNode, error) {
+	tree, errors := parse(sql)
+	if len(errors) > 0 {
+		return nil, &errors
+	}
+
+	nodes := []*StatementNode{}
+	for _, stmnt := range tree.(*StatementsContext).AllSingleStatement() {
+		node := buildStatement(stmnt.(*SingleStatementContext).Statement())
+		node.Text = formatTree(stmnt)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func buildStatement(stmnt IStatementContext) *StatementNode {
+	node := &StatementNode{Kind: KindOther}
+
+	switch ctx := stmnt.(type) {
+	case *QueryStatementContext:
+		node.Kind = KindQuery
+		node.Query = buildQuery(ctx.Query())
+	case *CreateStreamContext:
+		node.Kind = KindCreateStream
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.Columns = buildTableElements(ctx.TableElements())
+		node.Properties = buildProperties(ctx.TableProperties())
+		node.OrReplace = ctx.REPLACE() != nil
+		node.IfNotExists = ctx.EXISTS() != nil
+	case *CreateTableContext:
+		node.Kind = KindCreateTable
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.Columns = buildTableElements(ctx.TableElements())
+		node.Properties = buildProperties(ctx.TableProperties())
+		node.OrReplace = ctx.REPLACE() != nil
+		node.IfNotExists = ctx.EXISTS() != nil
+	case *CreateStreamAsContext:
+		node.Kind = KindCreateStreamAs
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.Properties = buildProperties(ctx.TableProperties())
+		node.Query = buildQuery(ctx.Query())
+		node.OrReplace = ctx.REPLACE() != nil
+		node.IfNotExists = ctx.EXISTS() != nil
+	case *CreateTableAsContext:
+		node.Kind = KindCreateTableAs
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.Properties = buildProperties(ctx.TableProperties())
+		node.Query = buildQuery(ctx.Query())
+		node.OrReplace = ctx.REPLACE() != nil
+		node.IfNotExists = ctx.EXISTS() != nil
+	case *InsertIntoContext:
+		node.Kind = KindInsertInto
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.Properties = buildProperties(ctx.TableProperties())
+		node.Query = buildQuery(ctx.Query())
+	case *InsertValuesContext:
+		node.Kind = KindInsertValues
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		if ctx.Columns() != nil {
+			for _, id := range ctx.Columns().(*ColumnsContext).AllIdentifier() {
+				node.Columns = append(node.Columns, &ColumnDef{Name: identifierName(id)})
+			}
+		}
+		for _, value := range ctx.Values().(*ValuesContext).AllValueExpression() {
+			node.Values = append(node.Values, formatTree(value))
+		}
+	case *DropStreamContext:
+		node.Kind = KindDropStream
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.IfExists = ctx.EXISTS() != nil
+		node.DeleteTopic = ctx.DELETE() != nil
+	case *DropTableContext:
+		node.Kind = KindDropTable
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		node.IfExists = ctx.EXISTS() != nil
+		node.DeleteTopic = ctx.DELETE() != nil
+	case *AlterSourceContext:
+		node.Kind = KindAlterSource
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+		for _, opt := range ctx.AllAlterOption() {
+			option := opt.(*AlterOptionContext)
+			node.Columns = append(node.Columns, &ColumnDef{
+				Name: identifierName(option.Identifier()),
+				Type: formatTree(option.Sqltype()),
+			})
+		}
+	case *ShowColumnsContext:
+		node.Kind = KindDescribe
+		node.Target = buildSourceRef(ctx.SourceName(), nil)
+	case *ExplainContext:
+		node.Kind = KindExplain
+		if ctx.Statement() != nil {
+			node.Explained = buildStatement(ctx.Statement())
+			node.Explained.Text = formatTree(ctx.Statement())
+		} else {
+			node.QueryID = identifierName(ctx.Identifier())
+		}
+	case *TerminateQueryContext:
+		node.Kind = KindTerminateQuery
+		if ctx.ALL() != nil {
+			node.QueryID = "ALL"
+		} else {
+			node.QueryID = identifierName(ctx.Identifier())
+		}
+	case *RegisterTypeContext:
+		node.Kind = KindCreateType
+		node.TypeName = identifierName(ctx.Identifier())
+		node.Type = formatTree(ctx.Sqltype())
+		node.IfNotExists = ctx.EXISTS() != nil
+	case *DropTypeContext:
+		node.Kind = KindDropType
+		node.TypeName = identifierName(ctx.Identifier())
+		node.IfExists = ctx.EXISTS() != nil
+	}
+
+	return node
+}
+
+func buildQuery(query IQueryContext) *QueryNode {
+	ctx := query.(*QueryContext)
+	node := &QueryNode{}
+
+	for _, item := range ctx.AllSelectItem() {
+		switch sel := item.(type) {
+		case *SelectSingleContext:
+			si := &SelectItem{Expression: formatTree(sel.Expression())}
+			if sel.Identifier() != nil {
+				si.Alias = identifierName(sel.Identifier())
+			}
+			node.Select = append(node.Select, si)
+		case *SelectAllContext:
+			si := &SelectItem{Expression: formatTree(sel), All: true}
+			if sel.Identifier() != nil {
+				si.Qualifier = identifierName(sel.Identifier())
+			}
+			node.Select = append(node.Select, si)
+		}
+	}
+
+	switch rel := ctx.Relation().(type) {
+	case *RelationDefaultContext:
+		node.From = buildAliasedRelation(rel.AliasedRelation())
+	case *JoinRelationContext:
+		node.From = buildAliasedRelation(rel.AliasedRelation())
+		for _, js := range rel.AllJoinedSource() {
+			joined := js.(*JoinedSourceContext)
+			join := &Join{
+				Type:   joinType(joined.JoinType()),
+				Source: buildAliasedRelation(joined.AliasedRelation()),
+				On:     formatTree(joined.JoinCriteria().(*JoinCriteriaContext).BooleanExpression()),
+			}
+			if joined.JoinWindow() != nil {
+				join.Within = formatTree(joined.JoinWindow().(*JoinWindowContext).WithinExpression())
+			}
+			node.Joins = append(node.Joins, join)
+		}
+	}
+
+	if ctx.WindowExpression() != nil {
+		node.Window = formatTree(ctx.WindowExpression())
+	}
+	if ctx.GetWhere() != nil {
+		node.Where = formatTree(ctx.GetWhere())
+	}
+	if ctx.GroupBy() != nil {
+		node.GroupBy = formatExpressions(ctx.GroupBy())
+	}
+	if ctx.PartitionBy() != nil {
+		node.PartitionBy = formatExpressions(ctx.PartitionBy())
+	}
+	if ctx.GetHaving() != nil {
+		node.Having = formatTree(ctx.GetHaving())
+	}
+	if ctx.ResultMaterialization() != nil {
+		node.Emit = strings.ToUpper(ctx.ResultMaterialization().GetText())
+	}
+	if ctx.LimitClause() != nil {
+		if limit, err := strconv.Atoi(ctx.LimitClause().(*LimitClauseContext).Number().GetText()); err == nil {
+			node.Limit = &limit
+		}
+	}
+
+	return node
+}
+
+// formatExpressions formats the value expressions of GROUP BY and PARTITION BY
+func formatExpressions(tree antlr.Tree) []string {
+	expressions := []string{}
+	for _, child := range tree.GetChildren() {
+		if expr, ok := child.(IValueExpressionContext); ok {
+			expressions = append(expressions, formatTree(expr))
+		}
+	}
+	return expressions
+}
+
+func buildAliasedRelation(rel IAliasedRelationContext) *SourceRef {
+	ctx := rel.(*AliasedRelationContext)
+	primary := ctx.RelationPrimary().(*TableNameContext)
+	return buildSourceRef(primary.SourceName(), ctx.SourceName())
+}
+
+func buildSourceRef(name ISourceNameContext, alias ISourceNameContext) *SourceRef {
+	ref := &SourceRef{Name: sourceName(name)}
+	if alias != nil {
+		ref.Alias = sourceName(alias)
+	}
+	return ref
+}
+
+func sourceName(name ISourceNameContext) string {
+	return identifierName(name.(*SourceNameContext).Identifier())
+}
+
+func joinType(ctx IJoinTypeContext) string {
+	switch ctx.(type) {
+	case *LeftJoinContext:
+		return "LEFT"
+	case *OuterJoinContext:
+		return "FULL"
+	}
+	return "INNER"
+}
+
+func buildTableElements(elements ITableElementsContext) []*ColumnDef {
+	columns := []*ColumnDef{}
+	if elements == nil {
+		return columns
+	}
+	for _, elem := range elements.(*TableElementsContext).AllTableElement() {
+		ctx := elem.(*TableElementContext)
+		columns = append(columns, &ColumnDef{
+			Name:       identifierName(ctx.Identifier()),
+			Type:       formatTree(ctx.Sqltype()),
+			Key:        ctx.KEY() != nil,
+			PrimaryKey: ctx.PRIMARY() != nil,
+		})
+	}
+	return columns
+}
+
+func buildProperties(props ITablePropertiesContext) []*Property {
+	properties := []*Property{}
+	if props == nil {
+		return properties
+	}
+	for _, p := range props.(*TablePropertiesContext).AllTableProperty() {
+		ctx := p.(*TablePropertyContext)
+		prop := &Property{}
+		if ctx.Identifier() != nil {
+			prop.Name = identifierName(ctx.Identifier())
+		} else {
+			prop.Name = strings.ToUpper(unquote(ctx.STRING().GetText(), '\''))
+		}
+		if str, ok := ctx.Literal().(*StringLiteralContext); ok {
+			prop.Value = unquote(str.GetText(), '\'')
+		} else {
+			prop.Value = ctx.Literal().GetText()
+		}
+		properties = append(properties, prop)
+	}
+	return properties
+}
+
+// identifierName normalizes identifiers: quoted identifiers are unquoted,
+// unquoted identifiers are upper cased
+func identifierName(id IIdentifierContext) string {
+	switch ctx := id.(type) {
+	case *QuotedIdentifierAlternativeContext:
+		return unquote(ctx.GetText(), '"')
+	case *BackQuotedIdentifierContext:
+		return unquote(ctx.GetText(), '`')
+	case *VariableIdentifierContext:
+		return ctx.GetText()
+	}
+	return strings.ToUpper(id.GetText())
+}
+
+// unquote removes the surrounding quotes and unescapes doubled quotes
+func unquote(text string, quote byte) string {
+	if len(text) >= 2 && text[0] == quote && text[len(text)-1] == quote {
+		q := string(quote)
+		return strings.ReplaceAll(text[1:len(text)-1], q+q, q)
+	}
+	return text
+}
+
+// formatTree returns the canonical text of the tree
+func formatTree(tree antlr.Tree) string {
+	f := &formatter{}
+	f.visit(tree, nil)
+	return f.sb.String()
+}
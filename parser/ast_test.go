@@ -0,0 +1,132 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestParse_CreateStream(t *testing.T) {
+	nodes, err := parser.Parse("create stream if not exists dogs (id string key, `Name` string) with (kafka_topic='dogs', 'value_format'='JSON', partitions=1);")
+	require.Nil(t, err)
+	require.Len(t, nodes, 1)
+
+	node := nodes[0]
+	require.Equal(t, parser.KindCreateStream, node.Kind)
+	require.True(t, node.IfNotExists)
+	require.Equal(t, "DOGS", node.Target.Name)
+	require.Equal(t, []*parser.SourceRef{{Name: "DOGS"}}, node.Sinks())
+	require.Len(t, node.Sources(), 0)
+	require.Equal(t, []*parser.ColumnDef{{Name: "ID", Type: "STRING", Key: true}, {Name: "Name", Type: "STRING"}}, node.Columns)
+	require.Equal(t, []*parser.ColumnDef{{Name: "ID", Type: "STRING", Key: true}}, node.KeyColumns())
+
+	topic, ok := node.Property("KAFKA_TOPIC")
+	require.True(t, ok)
+	require.Equal(t, "dogs", topic.Value)
+	format, ok := node.Property("value_format")
+	require.True(t, ok)
+	require.Equal(t, "JSON", format.Value)
+	partitions, _ := node.Property("PARTITIONS")
+	require.Equal(t, "1", partitions.Value)
+}
+
+func TestParse_CreateTableAsSelect(t *testing.T) {
+	nodes, err := parser.Parse(`create table dogs_by_size with (kafka_topic='dogs_by_size') as
+		select d.dogsize as dog_size, count(*) as dogs_ct
+		from dogs d left join owners o on d.owner = o.id
+		window tumbling (size 15 minutes)
+		where d.age > 1
+		group by d.dogsize
+		emit changes limit 5;`)
+	require.Nil(t, err)
+	node := nodes[0]
+	require.Equal(t, parser.KindCreateTableAs, node.Kind)
+	require.Equal(t, []*parser.SourceRef{{Name: "DOGS_BY_SIZE"}}, node.Sinks())
+	require.Equal(t, []*parser.SourceRef{{Name: "DOGS", Alias: "D"}, {Name: "OWNERS", Alias: "O"}}, node.Sources())
+
+	query := node.Query
+	require.Equal(t, &parser.SelectItem{Expression: "D.DOGSIZE", Alias: "DOG_SIZE"}, query.Select[0])
+	require.Equal(t, &parser.SelectItem{Expression: "COUNT(*)", Alias: "DOGS_CT"}, query.Select[1])
+	require.Equal(t, "LEFT", query.Joins[0].Type)
+	require.Equal(t, "D.OWNER = O.ID", query.Joins[0].On)
+	require.Equal(t, "TUMBLING(SIZE 15 MINUTES)", query.Window)
+	require.Equal(t, "D.AGE > 1", query.Where)
+	require.Equal(t, []string{"D.DOGSIZE"}, query.GroupBy)
+	require.Equal(t, "CHANGES", query.Emit)
+	require.Equal(t, 5, *query.Limit)
+}
+
+func TestParse_OtherStatements(t *testing.T) {
+	nodes, err := parser.Parse(`insert into dogs (id, name) values ('1', 'Lara');
+		drop table if exists "dogs_by_size" delete topic;
+		terminate CTAS_DOGS_BY_SIZE_1;
+		explain select * from dogs emit changes;
+		show streams;`)
+	require.Nil(t, err)
+	require.Len(t, nodes, 5)
+
+	require.Equal(t, parser.KindInsertValues, nodes[0].Kind)
+	require.Equal(t, []string{"'1'", "'Lara'"}, nodes[0].Values)
+	require.Equal(t, "NAME", nodes[0].Columns[1].Name)
+
+	require.Equal(t, parser.KindDropTable, nodes[1].Kind)
+	require.True(t, nodes[1].IfExists)
+	require.True(t, nodes[1].DeleteTopic)
+	require.Equal(t, []*parser.SourceRef{{Name: "dogs_by_size"}}, nodes[1].Sources())
+
+	require.Equal(t, parser.KindTerminateQuery, nodes[2].Kind)
+	require.Equal(t, "CTAS_DOGS_BY_SIZE_1", nodes[2].QueryID)
+
+	require.Equal(t, parser.KindExplain, nodes[3].Kind)
+	require.Equal(t, parser.KindQuery, nodes[3].Explained.Kind)
+	require.Equal(t, []*parser.SourceRef{{Name: "DOGS"}}, nodes[3].Sources())
+
+	require.Equal(t, parser.KindOther, nodes[4].Kind)
+	require.Equal(t, "SHOW STREAMS;", nodes[4].Text)
+}
+
+type sourceCollector struct {
+	parser.BaseVisitor
+	sources []string
+	sinks   []string
+	props   int
+}
+
+func (c *sourceCollector) VisitSource(s *parser.SourceRef) { c.sources = append(c.sources, s.Name) }
+func (c *sourceCollector) VisitSink(s *parser.SourceRef)   { c.sinks = append(c.sinks, s.Name) }
+func (c *sourceCollector) VisitProperty(*parser.Property)  { c.props++ }
+
+func TestWalk(t *testing.T) {
+	nodes, err := parser.Parse("insert into all_dogs with (query_id='q1') select * from dogs d join owners o within 1 hour on d.owner = o.id emit changes;")
+	require.Nil(t, err)
+
+	c := &sourceCollector{}
+	parser.Walk(c, nodes[0])
+	require.Equal(t, []string{"DOGS", "OWNERS"}, c.sources)
+	require.Equal(t, []string{"ALL_DOGS"}, c.sinks)
+	require.Equal(t, 1, c.props)
+	require.Equal(t, "1 HOUR", nodes[0].Query.Joins[0].Within)
+}
+
+func TestParse_SyntaxError(t *testing.T) {
+	nodes, err := parser.Parse("select * from dogs")
+	require.Nil(t, nodes)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+const (
+	formatIndent = "  "
+)
+
+// Format reprints the given sql statements in a canonical form.
+//
+// Keywords and unquoted identifiers are upper cased, string literals and
+// quoted identifiers are left untouched. Query clauses, multiple select items
+// and table elements are put on their own lines. Statements are separated
+// by an empty line. Comments are not preserved.
+//
+// If the sql contains syntax errors, they are returned as *SqlSyntaxErrorList.
+func Format(sql string) (string, error) {
+	tree, errors := parse(sql)
+	if len(errors) > 0 {
+		return "", &errors
+	}
+
+	f := &formatter{}
+	for _, stmnt := range tree.(*StatementsContext).AllSingleStatement() {
+		if f.sb.Len() > 0 {
+			f.sb.WriteString("\n\n")
+			f.prev = nil
+		}
+		f.visit(stmnt, tree)
+	}
+	return f.sb.String(), nil
+}
+
+// formatter writes the terminal nodes of a parse tree
+type formatter struct {
+	sb      strings.Builder
+	indent  int
+	newline bool
+	prev    *terminal
+}
+
+// terminal is a terminal node with its enclosing rule context.
+// TerminalNode.GetParent() returns the embedded BaseParserRuleContext
+// and can't be used for type switches.
+type terminal struct {
+	node   antlr.TerminalNode
+	parent antlr.Tree
+}
+
+func (t *terminal) text() string {
+	return t.node.GetText()
+}
+
+func (f *formatter) visit(node antlr.Tree, parent antlr.Tree) {
+	switch n := node.(type) {
+	case antlr.TerminalNode:
+		f.terminal(&terminal{node: n, parent: parent})
+	case *QueryContext:
+		// queries of CSAS, CTAS and INSERT INTO start on their own line
+		if _, ok := n.GetParent().(*QueryStatementContext); !ok {
+			f.breakLine()
+		}
+		f.visitChildren(n)
+	case *JoinedSourceContext:
+		f.breakLine()
+		f.visitChildren(n)
+	default:
+		f.visitChildren(n)
+	}
+}
+
+func (f *formatter) visitChildren(node antlr.Tree) {
+	for _, child := range node.GetChildren() {
+		f.visit(child, node)
+	}
+}
+
+// breakLine starts the next token on a new line
+func (f *formatter) breakLine() {
+	f.newline = true
+}
+
+func (f *formatter) terminal(node *terminal) {
+	token := node.node.GetSymbol()
+	if token.GetTokenType() == antlr.TokenEOF {
+		return
+	}
+
+	switch ctx := node.parent.(type) {
+	case *QueryContext:
+		f.queryTerminal(ctx, node)
+		return
+	case *TableElementsContext:
+		f.tableElementsTerminal(node)
+		return
+	case *LimitClauseContext:
+		if _, ok := ctx.GetParent().(*QueryContext); ok && token.GetTokenType() == KSqlLexerLIMIT {
+			f.breakLine()
+		}
+	}
+	f.write(node)
+}
+
+// queryTerminal handles the clauses of a query
+func (f *formatter) queryTerminal(ctx *QueryContext, node *terminal) {
+	multiline := len(ctx.AllSelectItem()) > 1
+
+	switch node.node.GetSymbol().GetTokenType() {
+	case KSqlLexerSELECT:
+		f.write(node)
+		if multiline {
+			f.indent++
+			f.breakLine()
+		}
+		return
+	case KSqlLexerT__1:
+		// select item separator
+		f.write(node)
+		f.breakLine()
+		return
+	case KSqlLexerFROM:
+		if multiline {
+			f.indent--
+		}
+		f.breakLine()
+	case KSqlLexerWINDOW, KSqlLexerWHERE, KSqlLexerGROUP, KSqlLexerPARTITION, KSqlLexerHAVING, KSqlLexerEMIT:
+		f.breakLine()
+	}
+	f.write(node)
+}
+
+// tableElementsTerminal puts every table element on its own line
+func (f *formatter) tableElementsTerminal(node *terminal) {
+	switch node.node.GetSymbol().GetTokenType() {
+	case KSqlLexerT__2:
+		f.write(node)
+		f.indent++
+		f.breakLine()
+	case KSqlLexerT__1:
+		f.write(node)
+		f.breakLine()
+	case KSqlLexerT__3:
+		f.indent--
+		f.breakLine()
+		f.write(node)
+	default:
+		f.write(node)
+	}
+}
+
+func (f *formatter) write(node *terminal) {
+	if f.newline {
+		f.sb.WriteString("\n")
+		f.sb.WriteString(strings.Repeat(formatIndent, f.indent))
+		f.newline = false
+	} else if f.prev != nil && needSpace(f.prev, node) {
+		f.sb.WriteString(" ")
+	}
+	f.sb.WriteString(canonicalText(node.node.GetSymbol()))
+	f.prev = node
+}
+
+// canonicalText upper cases everything except literals and quoted identifiers
+func canonicalText(token antlr.Token) string {
+	switch token.GetTokenType() {
+	case KSqlLexerSTRING, KSqlLexerQUOTED_IDENTIFIER, KSqlLexerBACKQUOTED_IDENTIFIER, KSqlLexerVARIABLE:
+		return token.GetText()
+	}
+	return strings.ToUpper(token.GetText())
+}
+
+// needSpace returns true if a space is needed between prev and cur
+func needSpace(prev *terminal, cur *terminal) bool {
+	switch prev.text() {
+	case "(", "[", ".", "->":
+		return false
+	case "<":
+		if _, ok := prev.parent.(*SqltypeContext); ok {
+			return false
+		}
+	case "-", "+":
+		switch prev.parent.(type) {
+		case *DecimalLiteralContext, *FloatLiteralContext, *IntegerLiteralContext, *ArithmeticUnaryContext:
+			return false
+		}
+	}
+
+	switch cur.text() {
+	case ",", ")", "]", ";", ".", "->":
+		return false
+	case "(":
+		switch cur.parent.(type) {
+		case *FunctionCallContext, *CastContext, *MapConstructorContext, *StructConstructorContext, *SqltypeContext,
+			*TumblingWindowExpressionContext, *HoppingWindowExpressionContext, *SessionWindowExpressionContext:
+			return false
+		}
+	case "[":
+		switch cur.parent.(type) {
+		case *SubscriptContext, *ArrayConstructorContext:
+			return false
+		}
+	case "<", ">":
+		if _, ok := cur.parent.(*SqltypeContext); ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+var formatTests = []struct {
+	name string
+	sql  string
+	want string
+}{
+	{
+		"simple select",
+		"select * from dogs emit changes;",
+		"SELECT *\nFROM DOGS\nEMIT CHANGES;",
+	},
+	{
+		"select items",
+		"select timestamptostring(windowstart,'yyyy-MM-dd HH:mm:ss','Europe/London') as window_start, dog_size, `My Col`[1] from dogs_by_size where dog_size='medium' and dogs_ct > -1 limit 10;",
+		"SELECT\n  TIMESTAMPTOSTRING(WINDOWSTART, 'yyyy-MM-dd HH:mm:ss', 'Europe/London') AS WINDOW_START,\n  DOG_SIZE,\n  `My Col`[1]\nFROM DOGS_BY_SIZE\nWHERE DOG_SIZE = 'medium' AND DOGS_CT > -1\nLIMIT 10;",
+	},
+	{
+		"create stream",
+		"create stream if not exists dogs (id string key, tags array<string>, props map<string, int>, price decimal(10,2)) with (kafka_topic='dogs', value_format='JSON', partitions=1);",
+		"CREATE STREAM IF NOT EXISTS DOGS (\n  ID STRING KEY,\n  TAGS ARRAY<STRING>,\n  PROPS MAP<STRING, INT>,\n  PRICE DECIMAL(10, 2)\n) WITH (KAFKA_TOPIC = 'dogs', VALUE_FORMAT = 'JSON', PARTITIONS = 1);",
+	},
+	{
+		"create table as select with join",
+		"create table dogs_by_size as select d.dogsize, count(*) as ct from dogs d inner join owners o within 1 hour on d.owner = o.id window tumbling (size 15 minutes) group by d.dogsize emit changes;",
+		"CREATE TABLE DOGS_BY_SIZE AS\nSELECT\n  D.DOGSIZE,\n  COUNT(*) AS CT\nFROM DOGS D\nINNER JOIN OWNERS O WITHIN 1 HOUR ON D.OWNER = O.ID\nWINDOW TUMBLING(SIZE 15 MINUTES)\nGROUP BY D.DOGSIZE\nEMIT CHANGES;",
+	},
+	{
+		"multiple statements",
+		"drop stream dogs; -- comment\nshow streams;",
+		"DROP STREAM DOGS;\n\nSHOW STREAMS;",
+	},
+}
+
+func TestFormat(t *testing.T) {
+	for _, tt := range formatTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Format(tt.sql)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+
+			// formatting is idempotent
+			again, err := parser.Format(got)
+			require.Nil(t, err)
+			require.Equal(t, got, again)
+		})
+	}
+}
+
+func TestFormat_SyntaxError(t *testing.T) {
+	got, err := parser.Format("select * from dogs")
+	require.Equal(t, "", got)
+	require.NotNil(t, err)
+	require.Equal(t, "1 sql syntax error(s) found", err.Error())
+}
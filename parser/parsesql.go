@@ -17,22 +17,48 @@ limitations under the License.
 package parser
 
 import (
+	"sync"
+
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 )
 
+// antlrMu serializes the lexers and parsers: the ANTLR runtime shares the
+// ATN and DFA caches of the grammar between their instances without locking
+var antlrMu sync.Mutex
+
+// Tokenize returns all tokens of the sql including the tokens of the hidden
+// channel, ex. whitespaces and comments. Lexer errors are ignored.
+// Unlike a KSqlLexer it is safe for concurrent use.
+func Tokenize(sql string) []antlr.Token {
+	antlrMu.Lock()
+	defer antlrMu.Unlock()
+	lexer := NewKSqlLexer(NewUpperCaseStream(antlr.NewInputStream(sql)))
+	lexer.RemoveErrorListeners()
+	return lexer.GetAllTokens()
+}
+
 type CanParseSQL interface {
 	ParseSql(string) []error
 }
 
 func ParseSql(sql string) *SqlSyntaxErrorList {
+	tree, errors := parse(sql)
+
+	antlr.ParseTreeWalkerDefault.Walk(&BaseKSqlListener{}, tree)
+
+	if len(errors) > 0 {
+		return &errors
+	}
+	return nil
+}
+
+// parse parses the sql and returns the parse tree with all lexer and parser errors
+func parse(sql string) (IStatementsContext, SqlSyntaxErrorList) {
+	antlrMu.Lock()
+	defer antlrMu.Unlock()
 	errors := SqlSyntaxErrorList{}
 
-	input := antlr.NewInputStream(sql)
-	upper := NewUpperCaseStream(input)
-	lexerErrorListener := &KSqlErrorListener{}
-	lexer := NewKSqlLexer(upper)
-	lexer.RemoveErrorListeners()
-	lexer.AddErrorListener(lexerErrorListener)
+	lexer, lexerErrorListener := newLexer(sql)
 
 	stream := antlr.NewCommonTokenStream(lexer, 0)
 	parserErrorListener := &KSqlErrorListener{}
@@ -40,7 +66,7 @@ func ParseSql(sql string) *SqlSyntaxErrorList {
 	p.RemoveErrorListeners()
 	p.AddErrorListener(parserErrorListener)
 
-	antlr.ParseTreeWalkerDefault.Walk(&BaseKSqlListener{}, p.Statements())
+	tree := p.Statements()
 
 	if lexerErrorListener.HasErrors() {
 		errors = append(errors, lexerErrorListener.Errors...)
@@ -49,8 +75,5 @@ func ParseSql(sql string) *SqlSyntaxErrorList {
 		errors = append(errors, parserErrorListener.Errors...)
 	}
 
-	if lexerErrorListener.HasErrors() || parserErrorListener.HasErrors() {
-		return &errors
-	}
-	return nil
+	return tree, errors
 }
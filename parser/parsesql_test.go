@@ -40,3 +40,13 @@ func TestParseKSQL_NoError(t *testing.T) {
 	err := parser.ParseSql(sql)
 	require.Nil(t, err)
 }
+
+func TestParseKSQL_QuotedIdentifiers(t *testing.T) {
+	sqls := []string{
+		"SELECT \"my col\", `a-b`, `x``y` FROM `my stream` WHERE NAME='it''s';",
+		"CREATE STREAM `weird-name` (`id-1` STRING KEY, \"Name\" STRING) WITH (KAFKA_TOPIC='t', VALUE_FORMAT='JSON');",
+	}
+	for _, sql := range sqls {
+		require.Nil(t, parser.ParseSql(sql))
+	}
+}
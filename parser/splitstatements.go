@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Statement is a single ksql statement found by SplitStatements
+type Statement struct {
+	// Text contains the statement including the terminating semicolon.
+	// Leading and trailing whitespaces and comments are removed.
+	Text string
+	// Line of the first token of the statement (1-based)
+	Line int
+	// Column of the first token of the statement (0-based)
+	Column int
+}
+
+// SplitStatements splits the given sql into single statements.
+//
+// The statements are split on the lexer token stream, so semicolons
+// inside of string literals, quoted identifiers and comments are
+// handled correctly. A trailing statement without a semicolon
+// is returned as it is, empty statements (;;) are skipped.
+//
+// Unterminated string literals, quoted identifiers or comments
+// are returned as *SqlSyntaxErrorList.
+func SplitStatements(sql string) ([]Statement, error) {
+	errors := SqlSyntaxErrorList{}
+	statements := []Statement{}
+
+	antlrMu.Lock()
+	lexer, lexerErrorListener := newLexer(sql)
+	tokens := lexer.GetAllTokens()
+	antlrMu.Unlock()
+
+	var current *Statement
+	var text strings.Builder
+	// pending holds hidden tokens (whitespaces, comments) between
+	// default channel tokens of the current statement
+	var pending strings.Builder
+
+	for idx, token := range tokens {
+		if token.GetChannel() != antlr.TokenDefaultChannel {
+			if current != nil {
+				pending.WriteString(token.GetText())
+			}
+			continue
+		}
+
+		if err := checkUnterminated(token, tokens, idx); err != nil {
+			errors = append(errors, *err)
+		}
+
+		if current == nil {
+			if token.GetTokenType() == KSqlLexerT__0 {
+				// skip empty statements
+				continue
+			}
+			current = &Statement{Line: token.GetLine(), Column: token.GetColumn()}
+		}
+		text.WriteString(pending.String())
+		pending.Reset()
+		text.WriteString(token.GetText())
+
+		if token.GetTokenType() == KSqlLexerT__0 {
+			current.Text = text.String()
+			statements = append(statements, *current)
+			current = nil
+			text.Reset()
+		}
+	}
+
+	if current != nil {
+		current.Text = text.String()
+		statements = append(statements, *current)
+	}
+
+	if lexerErrorListener.HasErrors() {
+		errors = append(errors, lexerErrorListener.Errors...)
+	}
+
+	if len(errors) > 0 {
+		return nil, &errors
+	}
+
+	return statements, nil
+}
+
+// newLexer creates a case insensitive KSqlLexer with an attached error listener
+func newLexer(sql string) (*KSqlLexer, *KSqlErrorListener) {
+	input := antlr.NewInputStream(sql)
+	upper := NewUpperCaseStream(input)
+	lexerErrorListener := &KSqlErrorListener{}
+	lexer := NewKSqlLexer(upper)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(lexerErrorListener)
+	return lexer, lexerErrorListener
+}
+
+// checkUnterminated checks for unrecognized quote characters and
+// unterminated bracketed comments. The lexer does not complain about them,
+// it emits them as single tokens.
+func checkUnterminated(token antlr.Token, tokens []antlr.Token, idx int) *SqlSyntaxError {
+	switch token.GetTokenType() {
+	case KSqlLexerUNRECOGNIZED:
+		switch token.GetText() {
+		case "'":
+			return &SqlSyntaxError{Line: token.GetLine(), Column: token.GetColumn(), Msg: "unterminated string literal"}
+		case "\"", "`":
+			return &SqlSyntaxError{Line: token.GetLine(), Column: token.GetColumn(), Msg: "unterminated quoted identifier"}
+		}
+	case KSqlLexerSLASH:
+		if idx+1 < len(tokens) {
+			next := tokens[idx+1]
+			if next.GetTokenType() == KSqlLexerASTERISK && next.GetStart() == token.GetStop()+1 {
+				return &SqlSyntaxError{Line: token.GetLine(), Column: token.GetColumn(), Msg: "unterminated comment"}
+			}
+		}
+	}
+	return nil
+}
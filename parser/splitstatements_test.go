@@ -0,0 +1,78 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- setup; the dogs
+	CREATE STREAM DOGS (ID STRING KEY, NAME STRING) WITH (KAFKA_TOPIC='dogs;cats', VALUE_FORMAT='JSON');
+	/* comment; with semicolon */
+	SELECT ` + "`my;col`" + ` FROM DOGS -- inline; comment
+	EMIT CHANGES;
+	DROP STREAM DOGS`
+
+	stmnts, err := parser.SplitStatements(sql)
+	require.Nil(t, err)
+	require.Len(t, stmnts, 3)
+	require.Equal(t, "CREATE STREAM DOGS (ID STRING KEY, NAME STRING) WITH (KAFKA_TOPIC='dogs;cats', VALUE_FORMAT='JSON');", stmnts[0].Text)
+	require.Equal(t, 2, stmnts[0].Line)
+	require.Equal(t, "SELECT `my;col` FROM DOGS -- inline; comment\n\tEMIT CHANGES;", stmnts[1].Text)
+	require.Equal(t, 4, stmnts[1].Line)
+	require.Equal(t, "DROP STREAM DOGS", stmnts[2].Text)
+}
+
+func TestSplitStatements_Empty(t *testing.T) {
+	stmnts, err := parser.SplitStatements("  -- only a comment\n")
+	require.Nil(t, err)
+	require.Len(t, stmnts, 0)
+}
+
+func TestSplitStatements_EmptyStatements(t *testing.T) {
+	stmnts, err := parser.SplitStatements("; DROP STREAM DOGS;; -- none\n ;")
+	require.Nil(t, err)
+	require.Len(t, stmnts, 1)
+	require.Equal(t, "DROP STREAM DOGS;", stmnts[0].Text)
+	require.Equal(t, 2, stmnts[0].Column)
+}
+
+func TestSplitStatements_Unterminated(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		msg  string
+	}{
+		{"string literal", "SELECT * FROM DOGS WHERE NAME='abc;", "error on line(1):column(30): unterminated string literal"},
+		{"quoted identifier", "SELECT `abc FROM DOGS;", "error on line(1):column(7): unterminated quoted identifier"},
+		{"comment", "SELECT * FROM DOGS; /* abc;", "error on line(1):column(20): unterminated comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmnts, err := parser.SplitStatements(tt.sql)
+			require.Nil(t, stmnts)
+			require.NotNil(t, err)
+			errs, ok := err.(*parser.SqlSyntaxErrorList)
+			require.True(t, ok)
+			require.Equal(t, tt.msg, (*errs)[0].Error())
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PAUSE_MIN_VERSION is the ksqlDB version which added PAUSE and RESUME
+// (Confluent Platform 7.3)
+const PAUSE_MIN_VERSION = "0.28"
+
+// QUERY_ALL is the query id of PauseQuery and ResumeQuery for all persistent queries
+const QUERY_ALL = "ALL"
+
+// PauseQuery runs PAUSE on the persistent query and waits until the command
+// is completed. A paused query stops processing, but keeps its state and
+// offsets; it continues with ResumeQuery. QUERY_ALL pauses all persistent queries.
+//
+// Servers before PAUSE_MIN_VERSION reject the statement with an
+// *UnsupportedError, which matches ErrUnsupported.
+func (api *KsqldbClient) PauseQuery(ctx context.Context, queryId string) error {
+	return api.controlQuery(ctx, "PAUSE", queryId)
+}
+
+// ResumeQuery runs RESUME on the paused persistent query and waits until the
+// command is completed. QUERY_ALL resumes all paused queries, see PauseQuery.
+func (api *KsqldbClient) ResumeQuery(ctx context.Context, queryId string) error {
+	return api.controlQuery(ctx, "RESUME", queryId)
+}
+
+// controlQuery runs the PAUSE or RESUME command on the query
+func (api *KsqldbClient) controlQuery(ctx context.Context, command string, queryId string) error {
+	action := strings.ToLower(command)
+	if queryId == "" {
+		return fmt.Errorf("can't %v query: %w", action, ErrEmptyQuery)
+	}
+	// the parser doesn't know PAUSE and RESUME
+	response, err := api.execute(ctx, ExecOptions{KSql: fmt.Sprintf("%v %v;", command, queryId), skipParse: true})
+	if err != nil {
+		if rejectedKeyword(err, command) {
+			err = api.unsupported(command, PAUSE_MIN_VERSION, err)
+		}
+		return fmt.Errorf("can't %v query %v: %w", action, queryId, err)
+	}
+	if len(*response) == 0 {
+		return fmt.Errorf("can't %v query %v: %w: no command status", action, queryId, ErrNotFound)
+	}
+	if _, err := api.waitForCommand(ctx, (*response)[0]); err != nil {
+		return fmt.Errorf("can't %v query %v: %w", action, queryId, err)
+	}
+	return nil
+}
+
+// rejectedKeyword returns true if the server failed to parse the statement
+// at the keyword, ex. line 1:1: mismatched input 'PAUSE' expecting ...
+func rejectedKeyword(err error, keyword string) bool {
+	var respErr ResponseError
+	if !errors.As(err, &respErr) || !errors.Is(respErr, ErrStatementError) {
+		return false
+	}
+	return strings.Contains(strings.ToUpper(respErr.Message), "'"+keyword+"'")
+}
+
+// unsupported returns the *UnsupportedError of the statement with the server
+// version; the version is left empty if the server info is not available
+func (api *KsqldbClient) unsupported(statement string, minVersion string, err error) error {
+	unsupported := UnsupportedError{Statement: statement, MinVersion: minVersion, Response: err}
+	if info, infoErr := api.GetServerInfo(); infoErr == nil {
+		unsupported.ServerVersion = info.Version
+	}
+	return &unsupported
+}
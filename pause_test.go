@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestPauseQuery(t *testing.T) {
+	m := mockKsql(t, []string{"PAUSE CSAS_BIG_DOGS_3;", "RESUME ALL;"}, []string{
+		`[{"@type":"currentStatus","statementText":"PAUSE CSAS_BIG_DOGS_3;","commandId":"terminate/CSAS_BIG_DOGS_3/execute","commandStatus":{"status":"QUEUED","message":"Statement written to command topic"}}]`,
+		`[{"@type":"currentStatus","statementText":"RESUME ALL;","commandId":"terminate/ALL/execute","commandStatus":{"status":"SUCCESS","message":"Query resumed."}}]`,
+	})
+	m.Mock.On("Get", "http://localhost/status/terminate/CSAS_BIG_DOGS_3/execute").Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"status":"SUCCESS","message":"Query paused."}`))),
+	}, nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+
+	require.Nil(t, kcl.PauseQuery(context.Background(), "CSAS_BIG_DOGS_3"))
+	require.Nil(t, kcl.ResumeQuery(context.Background(), ksqldb.QUERY_ALL))
+	m.AssertExpectations(t)
+
+	err := kcl.PauseQuery(context.Background(), "")
+	require.True(t, errors.Is(err, ksqldb.ErrEmptyQuery))
+}
+
+func TestPauseQuery_Error(t *testing.T) {
+	m := mockKsql(t, []string{"PAUSE CSAS_BIG_DOGS_3;"}, []string{
+		`[{"@type":"currentStatus","commandId":"terminate/CSAS_BIG_DOGS_3/execute","commandStatus":{"status":"ERROR","message":"Query not found"}}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	err := kcl.PauseQuery(context.Background(), "CSAS_BIG_DOGS_3")
+	require.Equal(t, "can't pause query CSAS_BIG_DOGS_3: statement error: command terminate/CSAS_BIG_DOGS_3/execute failed: Query not found", err.Error())
+	require.False(t, errors.Is(err, ksqldb.ErrUnsupported))
+}
+
+func TestPauseQuery_Unsupported(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.Mock.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"@type":"statement_error","error_code":40001,"message":"line 1:1: mismatched input 'PAUSE' expecting {'SELECT', 'CREATE', 'TERMINATE'}"}`))),
+	}, nil)
+	m.Mock.On("Get", "http://localhost/info").Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"KsqlServerInfo":{"version":"0.23.1","kafkaClusterId":"kgqdUfEoTBSutJd1JWHIyQ","ksqlServiceId":"default_"}}`))),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	err := kcl.PauseQuery(context.Background(), "CSAS_BIG_DOGS_3")
+	require.True(t, errors.Is(err, ksqldb.ErrUnsupported))
+	require.True(t, errors.Is(err, ksqldb.ErrStatementError))
+	var unsupported *ksqldb.UnsupportedError
+	require.True(t, errors.As(err, &unsupported))
+	require.Equal(t, "0.23.1", unsupported.ServerVersion)
+	require.Equal(t, "can't pause query CSAS_BIG_DOGS_3: PAUSE requires ksqlDB 0.28, the server version is 0.23.1", err.Error())
+}
@@ -0,0 +1,42 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Ping checks the connection to the server with a GET of /info and returns
+// the round trip time. It is cheaper than a statement like SHOW STREAMS, ex.
+// for health endpoints or to validate the connection at startup.
+//
+// Ping isn't retried, see SetRetryPolicy; responses other than 200 are
+// returned as ResponseError.
+func (api *KsqldbClient) Ping(ctx context.Context) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.http.GetUrl(INFO_ENDPOINT), nil)
+	if err != nil {
+		return 0, fmt.Errorf("can't create ping request: %w", err)
+	}
+	start := time.Now()
+	if _, err := api.doOnce(req); err != nil {
+		return 0, fmt.Errorf("can't ping server: %w", err)
+	}
+	return time.Since(start), nil
+}
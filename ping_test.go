@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestPing(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.INFO_ENDPOINT).Return("http://localhost/info")
+	m.Mock.On("Do", mock.MatchedBy(func(r *http.Request) bool {
+		return r.Method == http.MethodGet && r.URL.Path == ksqldb.INFO_ENDPOINT
+	})).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"KsqlServerInfo":{"version":"0.22.0"}}`))),
+	}, nil).Once()
+
+	kcl, _ := ksqldb.NewClient(&m)
+	latency, err := kcl.Ping(context.Background())
+	require.Nil(t, err)
+	require.True(t, latency > 0)
+	m.AssertExpectations(t)
+}
+
+func TestPing_Errors(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.INFO_ENDPOINT).Return("http://localhost/info")
+	m.Mock.On("Do", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	m.Mock.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 401,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"@type":"generic_error","error_code":40100,"message":"Unauthorized"}`))),
+	}, nil).Once()
+
+	kcl, _ := ksqldb.NewClient(&m)
+	_, err := kcl.Ping(context.Background())
+	require.EqualError(t, err, "can't ping server: can't do request: connection refused")
+
+	_, err = kcl.Ping(context.Background())
+	require.True(t, errors.Is(err, ksqldb.ErrUnauthorized))
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DecodeStage is a stage of the pipeline of push query rows, see EnableDecodeProfiling
+type DecodeStage int
+
+const (
+	// STAGE_READ reads a line of the response, including the wait for the server and the network
+	STAGE_READ DecodeStage = iota
+	// STAGE_UNMARSHAL decodes the JSON of the line
+	STAGE_UNMARSHAL
+	// STAGE_CONVERT checks the row, ex. the types in strict mode and duplicates with SetDedupe
+	STAGE_CONVERT
+	// STAGE_DELIVER hands the row to the consumer, including the wait for a full row channel
+	STAGE_DELIVER
+)
+
+func (s DecodeStage) String() string {
+	switch s {
+	case STAGE_READ:
+		return "read"
+	case STAGE_UNMARSHAL:
+		return "unmarshal"
+	case STAGE_CONVERT:
+		return "convert"
+	case STAGE_DELIVER:
+		return "deliver"
+	}
+	return "unknown"
+}
+
+// DecodeTimings are the total times of the stages of the push query rows
+type DecodeTimings struct {
+	Read      time.Duration
+	Unmarshal time.Duration
+	Convert   time.Duration
+	Deliver   time.Duration
+}
+
+// EnableDecodeProfiling enables / disables the timing of the stages of push
+// query rows. The times are added up in the QueryMetrics, see
+// QueryMetrics.DecodeTimings, so it can be told if the network, the JSON
+// decoding or the consumer is the bottleneck. Disabled by default, it adds
+// two clock reads per stage.
+func (cl *KsqldbClient) EnableDecodeProfiling(activate bool) {
+	cl.decodeProfiling = activate
+}
+
+// DecodeProfilingEnabled returns true if the stages of push query rows are timed; false otherwise
+func (cl *KsqldbClient) DecodeProfilingEnabled() bool {
+	return cl.decodeProfiling || cl.onDecodeStage != nil
+}
+
+// OnDecodeStage sets the callback of the timed stages of push query rows,
+// ex. to record them in a histogram. It is called from the goroutine of the
+// query and has to return fast. Setting a callback enables decode profiling.
+// Set it before running queries.
+func (cl *KsqldbClient) OnDecodeStage(fn func(handle *QueryHandle, stage DecodeStage, elapsed time.Duration)) {
+	cl.onDecodeStage = fn
+}
+
+// DecodeTimings returns the total times of the stages; zero if decode profiling is disabled
+func (m *QueryMetrics) DecodeTimings() DecodeTimings {
+	return DecodeTimings{
+		Read:      time.Duration(atomic.LoadInt64(&m.stages[STAGE_READ])),
+		Unmarshal: time.Duration(atomic.LoadInt64(&m.stages[STAGE_UNMARSHAL])),
+		Convert:   time.Duration(atomic.LoadInt64(&m.stages[STAGE_CONVERT])),
+		Deliver:   time.Duration(atomic.LoadInt64(&m.stages[STAGE_DELIVER])),
+	}
+}
+
+func (m *QueryMetrics) stage(stage DecodeStage, elapsed time.Duration) {
+	atomic.AddInt64(&m.stages[stage], int64(elapsed))
+}
+
+// profiler times the stages of a push query
+type profiler struct {
+	handle  *QueryHandle
+	onStage func(*QueryHandle, DecodeStage, time.Duration)
+}
+
+// now returns the start of a stage; zero if the stages are not timed
+func (r recorder) now() time.Time {
+	if r.profiler == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// stage records the time of the stage since start and returns the end of the stage
+func (r recorder) stage(stage DecodeStage, start time.Time) time.Time {
+	if r.profiler == nil {
+		return time.Time{}
+	}
+	end := time.Now()
+	elapsed := end.Sub(start)
+	r.query.stage(stage, elapsed)
+	if r.totals != nil {
+		r.totals.stage(stage, elapsed)
+	}
+	if r.profiler.onStage != nil {
+		r.profiler.onStage(r.profiler.handle, stage, elapsed)
+	}
+	return end
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestDecodeStage_String(t *testing.T) {
+	require.Equal(t, "read", ksqldb.STAGE_READ.String())
+	require.Equal(t, "deliver", ksqldb.STAGE_DELIVER.String())
+}
+
+func TestPush_DecodeProfiling(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	require.False(t, kcl.DecodeProfilingEnabled())
+
+	var mu sync.Mutex
+	stages := map[ksqldb.DecodeStage]int{}
+	kcl.OnDecodeStage(func(h *ksqldb.QueryHandle, stage ksqldb.DecodeStage, elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, "select * from dogs emit changes;", h.SQL())
+		stages[stage]++
+	})
+	require.True(t, kcl.DecodeProfilingEnabled())
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	_, err = q.Header()
+	require.Nil(t, err)
+	// the consumer is slow, the time is spent delivering the rows
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, ksqldb.Row{"a"}, <-q.Rows())
+	require.Equal(t, ksqldb.Row{"b"}, <-q.Rows())
+	require.Nil(t, q.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, stages[ksqldb.STAGE_READ], 3)
+	require.GreaterOrEqual(t, stages[ksqldb.STAGE_UNMARSHAL], 3)
+	require.Equal(t, 2, stages[ksqldb.STAGE_CONVERT])
+	require.Equal(t, 2, stages[ksqldb.STAGE_DELIVER])
+
+	timings := q.Metrics().DecodeTimings()
+	require.GreaterOrEqual(t, int64(timings.Deliver), int64(20*time.Millisecond))
+	require.Greater(t, int64(timings.Read), int64(0))
+	require.Equal(t, timings, q.Metrics().Stats().DecodeTimings)
+}
+
+func TestPush_DecodeProfilingDisabled(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	go m.send(streamHeader, `["a"]`)
+	require.Equal(t, ksqldb.Row{"a"}, <-q.Rows())
+	require.Nil(t, q.Stop())
+	require.Equal(t, ksqldb.DecodeTimings{}, q.Metrics().DecodeTimings())
+}
@@ -0,0 +1,179 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package properties provides the names of common ksqlDB properties and
+// validates their values before they are sent to the server.
+//
+//	props := properties.Properties{}
+//	props.Set(properties.AutoOffsetReset, properties.Earliest)
+//	if err := props.Validate(); err != nil { ... }
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidProperty is returned for invalid values of known properties
+var ErrInvalidProperty = errors.New("invalid property")
+
+// Names of common properties of queries and statements
+const (
+	// AutoOffsetReset is the offset new push queries and persistent queries start at, see Earliest and Latest
+	AutoOffsetReset = "ksql.streams.auto.offset.reset"
+	// QueryPullTableScanEnabled permits pull queries without key lookups
+	QueryPullTableScanEnabled = "ksql.query.pull.table.scan.enabled"
+	// QueryPullMaxAllowedOffsetLag is the max offset lag of the hosts serving a pull query
+	QueryPullMaxAllowedOffsetLag = "ksql.query.pull.max.allowed.offset.lag"
+	// QueryPullInterpreterEnabled evaluates pull query expressions with the interpreter
+	QueryPullInterpreterEnabled = "ksql.query.pull.interpreter.enabled"
+	// StreamsCacheMaxBytes is the record cache size of persistent queries in bytes
+	StreamsCacheMaxBytes = "ksql.streams.cache.max.bytes.buffering"
+	// StreamsCommitIntervalMs is the commit interval of persistent queries in milliseconds
+	StreamsCommitIntervalMs = "ksql.streams.commit.interval.ms"
+	// StreamsNumStreamThreads is the number of stream threads of persistent queries
+	StreamsNumStreamThreads = "ksql.streams.num.stream.threads"
+	// StreamsProcessingGuarantee is the processing guarantee of persistent queries, see AtLeastOnce and ExactlyOnceV2
+	StreamsProcessingGuarantee = "ksql.streams.processing.guarantee"
+	// SinkPartitions is the default partition count of sink topics
+	SinkPartitions = "ksql.sink.partitions"
+	// SinkReplicas is the default replication factor of sink topics
+	SinkReplicas = "ksql.sink.replicas"
+	// FailOnDeserializationError fails queries on records which can't be deserialized
+	FailOnDeserializationError = "ksql.fail.on.deserialization.error"
+	// FailOnProductionError fails queries on records which can't be produced
+	FailOnProductionError = "ksql.fail.on.production.error"
+)
+
+// Values of AutoOffsetReset
+const (
+	Earliest = "earliest"
+	Latest   = "latest"
+)
+
+// Values of StreamsProcessingGuarantee
+const (
+	AtLeastOnce   = "at_least_once"
+	ExactlyOnce   = "exactly_once"
+	ExactlyOnceV2 = "exactly_once_v2"
+)
+
+// validators check the values of the known properties
+var validators = map[string]func(string) error{
+	AutoOffsetReset:              oneOf(Earliest, Latest),
+	QueryPullTableScanEnabled:    boolean,
+	QueryPullMaxAllowedOffsetLag: integer(0),
+	QueryPullInterpreterEnabled:  boolean,
+	StreamsCacheMaxBytes:         integer(0),
+	StreamsCommitIntervalMs:      integer(0),
+	StreamsNumStreamThreads:      integer(1),
+	StreamsProcessingGuarantee:   oneOf(AtLeastOnce, ExactlyOnce, ExactlyOnceV2),
+	SinkPartitions:               integer(1),
+	SinkReplicas:                 integer(1),
+	FailOnDeserializationError:   boolean,
+	FailOnProductionError:        boolean,
+}
+
+// Known returns true if the value of the property is validated
+func Known(name string) bool {
+	_, ok := validators[name]
+	return ok
+}
+
+// Validate validates the value of the property. Unknown properties are
+// valid, the server decides about them.
+func Validate(name, value string) error {
+	validate, ok := validators[name]
+	if !ok {
+		return nil
+	}
+	if err := validate(value); err != nil {
+		return fmt.Errorf("%w %v=%q: %v", ErrInvalidProperty, name, value, err)
+	}
+	return nil
+}
+
+// Properties are the properties of a query or statement. They convert
+// to ksqldb.PropertyMap, ex. ksqldb.PropertyMap(props).
+type Properties map[string]string
+
+// Set validates the value and sets the property
+func (p Properties) Set(name, value string) error {
+	if err := Validate(name, value); err != nil {
+		return err
+	}
+	p[name] = value
+	return nil
+}
+
+// SetBool sets a boolean property
+func (p Properties) SetBool(name string, value bool) error {
+	return p.Set(name, strconv.FormatBool(value))
+}
+
+// SetInt sets an integer property
+func (p Properties) SetInt(name string, value int64) error {
+	return p.Set(name, strconv.FormatInt(value, 10))
+}
+
+// Validate validates all properties, the first invalid property in name order is returned
+func (p Properties) Validate() error {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := Validate(name, p[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func oneOf(values ...string) func(string) error {
+	return func(value string) error {
+		for _, v := range values {
+			if strings.EqualFold(value, v) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %v", strings.Join(values, ", "))
+	}
+}
+
+func boolean(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("expected true or false")
+	}
+	return nil
+}
+
+func integer(min int64) func(string) error {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		if n < min {
+			return fmt.Errorf("expected an integer >= %v", min)
+		}
+		return nil
+	}
+}
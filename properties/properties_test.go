@@ -0,0 +1,59 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package properties_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go/properties"
+)
+
+func TestValidate(t *testing.T) {
+	require.Nil(t, properties.Validate(properties.AutoOffsetReset, properties.Earliest))
+	require.Nil(t, properties.Validate(properties.AutoOffsetReset, "LATEST"))
+	require.Nil(t, properties.Validate(properties.StreamsCacheMaxBytes, "10000000"))
+	require.Nil(t, properties.Validate("ksql.unknown.property", "anything"))
+
+	err := properties.Validate(properties.AutoOffsetReset, "beginning")
+	require.True(t, errors.Is(err, properties.ErrInvalidProperty))
+	require.Equal(t, `invalid property ksql.streams.auto.offset.reset="beginning": expected one of earliest, latest`, err.Error())
+
+	require.NotNil(t, properties.Validate(properties.QueryPullTableScanEnabled, "yes"))
+	require.NotNil(t, properties.Validate(properties.StreamsCacheMaxBytes, "10MB"))
+	require.NotNil(t, properties.Validate(properties.StreamsNumStreamThreads, "0"))
+	require.True(t, properties.Known(properties.StreamsProcessingGuarantee))
+	require.False(t, properties.Known("ksql.unknown.property"))
+}
+
+func TestProperties(t *testing.T) {
+	props := properties.Properties{}
+	require.Nil(t, props.Set(properties.AutoOffsetReset, properties.Latest))
+	require.Nil(t, props.SetBool(properties.QueryPullTableScanEnabled, true))
+	require.Nil(t, props.SetInt(properties.StreamsCacheMaxBytes, 0))
+	require.True(t, errors.Is(props.SetInt(properties.SinkReplicas, 0), properties.ErrInvalidProperty))
+	require.Equal(t, properties.Properties{
+		properties.AutoOffsetReset:           "latest",
+		properties.QueryPullTableScanEnabled: "true",
+		properties.StreamsCacheMaxBytes:      "0",
+	}, props)
+	require.Nil(t, props.Validate())
+
+	props[properties.StreamsProcessingGuarantee] = "exactly_twice"
+	require.True(t, errors.Is(props.Validate(), properties.ErrInvalidProperty))
+}
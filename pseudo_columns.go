@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// Pseudo columns of the row metadata
+const (
+	// ROWTIME is the event time of the row in epoch milliseconds
+	ROWTIME = "ROWTIME"
+	// ROWPARTITION is the partition of the row in the topic
+	ROWPARTITION = "ROWPARTITION"
+	// ROWOFFSET is the offset of the row in the partition
+	ROWOFFSET = "ROWOFFSET"
+)
+
+// PSEUDO_COLUMNS are all pseudo columns of the row metadata
+var PSEUDO_COLUMNS = []string{ROWTIME, ROWPARTITION, ROWOFFSET}
+
+// selectKeyword matches the SELECT keyword of a query with leading comments
+var selectKeyword = regexp.MustCompile(`(?is)^((?:\s|--[^\n]*\n|/\*.*?\*/)*SELECT)\s+`)
+
+// SelectPseudoColumns adds the pseudo columns to the select list of the
+// query, ex. SELECT * FROM DOGS becomes SELECT ROWTIME, ROWPARTITION,
+// ROWOFFSET, * FROM DOGS. No columns are all PSEUDO_COLUMNS, columns which
+// are selected already are skipped.
+//
+// The server rejects pseudo columns which aren't supported by the query, ex.
+// ROWPARTITION and ROWOFFSET are supported by queries of streams since ksqlDB 0.24.
+func SelectPseudoColumns(sql string, columns ...string) (string, error) {
+	nodes, err := parser.Parse(sql)
+	if err != nil {
+		return "", err
+	}
+	if len(nodes) != 1 || nodes[0].Kind != parser.KindQuery {
+		return "", fmt.Errorf("pseudo columns can only be added to a single SELECT")
+	}
+	if len(columns) == 0 {
+		columns = PSEUDO_COLUMNS
+	}
+	selected := map[string]bool{}
+	for _, item := range nodes[0].Query.Select {
+		selected[strings.ToUpper(item.Expression)] = true
+	}
+	added := []string{}
+	for _, col := range columns {
+		if !selected[strings.ToUpper(col)] {
+			added = append(added, col)
+		}
+	}
+	if len(added) == 0 {
+		return sql, nil
+	}
+	return selectKeyword.ReplaceAllString(sql, "$1 "+strings.Join(added, ", ")+", "), nil
+}
+
+// RowMetadata is the metadata of a row selected with the pseudo columns
+type RowMetadata struct {
+	// Time is the ROWTIME; zero if not selected
+	Time time.Time
+	// Partition is the ROWPARTITION; -1 if not selected
+	Partition int32
+	// Offset is the ROWOFFSET; -1 if not selected
+	Offset int64
+}
+
+// RowMetadata returns the metadata of the record from its pseudo columns,
+// see SelectPseudoColumns. The pseudo columns stay in the record.
+func (r Record) RowMetadata() RowMetadata {
+	meta := RowMetadata{Partition: -1, Offset: -1}
+	if ms, ok := integer(r[ROWTIME]); ok {
+		meta.Time = epochMillis(ms)
+	}
+	if partition, ok := integer(r[ROWPARTITION]); ok {
+		meta.Partition = int32(partition)
+	}
+	if offset, ok := integer(r[ROWOFFSET]); ok {
+		meta.Offset = offset
+	}
+	return meta
+}
+
+// integer returns the decoded or raw json number as int64
+func integer(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
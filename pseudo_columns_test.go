@@ -0,0 +1,75 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestSelectPseudoColumns(t *testing.T) {
+	sql, err := ksqldb.SelectPseudoColumns("select * from dogs emit changes;")
+	require.Nil(t, err)
+	require.Equal(t, "select ROWTIME, ROWPARTITION, ROWOFFSET, * from dogs emit changes;", sql)
+
+	sql, err = ksqldb.SelectPseudoColumns("-- dogs\nSELECT rowtime, ID FROM DOGS EMIT CHANGES;", ksqldb.ROWTIME, ksqldb.ROWOFFSET)
+	require.Nil(t, err)
+	require.Equal(t, "-- dogs\nSELECT ROWOFFSET, rowtime, ID FROM DOGS EMIT CHANGES;", sql)
+
+	sql, err = ksqldb.SelectPseudoColumns("SELECT ROWTIME, * FROM DOGS;", ksqldb.ROWTIME)
+	require.Nil(t, err)
+	require.Equal(t, "SELECT ROWTIME, * FROM DOGS;", sql)
+
+	_, err = ksqldb.SelectPseudoColumns("DROP STREAM DOGS;")
+	require.Equal(t, "pseudo columns can only be added to a single SELECT", err.Error())
+}
+
+func TestRecord_RowMetadata(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "ROWTIME", Type: "BIGINT"},
+		{Name: "ROWPARTITION", Type: "INTEGER"},
+		{Name: "ROWOFFSET", Type: "BIGINT"},
+		{Name: "ID", Type: "STRING"},
+	})
+	record, err := header.RowToMap(ksqldb.Row{float64(1637042400000), float64(3), float64(42), "1"})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.RowMetadata{
+		Time:      time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC),
+		Partition: 3,
+		Offset:    42,
+	}, record.RowMetadata())
+
+	require.Equal(t, ksqldb.RowMetadata{Partition: -1, Offset: -1}, ksqldb.Record{"ID": "1"}.RowMetadata())
+}
+
+func TestPull_PseudoColumns(t *testing.T) {
+	m := mockKsql(t, []string{"SELECT ROWTIME, * FROM DOGS WHERE ID = '1';"}, []string{
+		`[{"queryId":null,"columnNames":["ROWTIME","ID"],"columnTypes":["BIGINT","STRING"]},[1637042400000,"1"]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	header, payload, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{
+		Sql:           "SELECT * FROM DOGS WHERE ID = '1';",
+		PseudoColumns: []string{ksqldb.ROWTIME},
+	})
+	require.Nil(t, err)
+	record, _ := header.RowToMap(payload[0])
+	require.Equal(t, time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC), record.RowMetadata().Time)
+}
@@ -24,18 +24,29 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/thmeitz/ksqldb-go/internal"
 	"github.com/thmeitz/ksqldb-go/parser"
+	"github.com/thmeitz/ksqldb-go/properties"
 )
 
 const (
-	KSQL_QUERY_PULL_TABLE_SCAN_ENABLED = "ksql.query.pull.table.scan.enabled"
+	KSQL_QUERY_PULL_TABLE_SCAN_ENABLED     = properties.QueryPullTableScanEnabled
+	KSQL_QUERY_PULL_MAX_ALLOWED_OFFSET_LAG = properties.QueryPullMaxAllowedOffsetLag
 )
 
 type QueryOptions struct {
 	Sql        string      `json:"sql"`
 	Properties PropertyMap `json:"properties"`
+	// RoutingKey routes the pull query to a host, see SetPullHosts;
+	// defaults to the WHERE clause of the query
+	RoutingKey string `json:"-"`
+	// CacheTTL overrides the TTL of the pull cache for the query, see SetPullCache;
+	// 0 is the TTL of the cache, < 0 bypasses the cache
+	CacheTTL time.Duration `json:"-"`
+	// PseudoColumns are added to the select list, see SelectPseudoColumns
+	PseudoColumns []string `json:"-"`
 }
 
 /*
@@ -67,6 +78,16 @@ func (q *QueryOptions) EnablePullQueryTableScan(enable bool) *QueryOptions {
 	return q
 }
 
+// SetMaxAllowedOffsetLag sets the max offset lag of the hosts serving the pull query.
+// If no host is within the lag, Pull fails with an error matching ErrReplicaTooFarBehind.
+func (q *QueryOptions) SetMaxAllowedOffsetLag(lag int64) *QueryOptions {
+	if len(q.Properties) == 0 {
+		q.Properties = make(PropertyMap)
+	}
+	q.Properties[KSQL_QUERY_PULL_MAX_ALLOWED_OFFSET_LAG] = strconv.FormatInt(lag, 10)
+	return q
+}
+
 func (q *QueryOptions) SanitizeQuery() {
 	q.Sql = internal.SanitizeQuery(q.Sql)
 }
@@ -85,21 +106,48 @@ func (o *QueryOptions) EmptyQuery() bool {
 // which will hold one or more rows of data. You will need to
 // define variables to hold each column's value. You can adopt
 // this pattern to do this:
-// 		var col1 string
-// 		var col2 float64
-// 		for _, row := range r {
-// 			col1 = row[0].(string)
-// 			col2 = row[1].(float64)
-// 			// Do other stuff with the data here
-// 			}
-// 		}
+//
+//	var col1 string
+//	var col2 float64
+//	for _, row := range r {
+//		col1 = row[0].(string)
+//		col2 = row[1].(float64)
+//		// Do other stuff with the data here
+//		}
+//	}
+//
+// Results are cached if the pull cache is enabled, see SetPullCache.
+// Identical queries are coalesced if enabled, see EnablePullCoalescing.
 func (api *KsqldbClient) Pull(ctx context.Context, options QueryOptions) (header Header, payload Payload, err error) {
+	if api.pullCache == nil || options.CacheTTL < 0 || options.EmptyQuery() {
+		return api.sharedPull(ctx, options)
+	}
+	return api.pullCache.pull(ctx, api, options)
+}
 
+// pull runs the pull query without the cache
+func (api *KsqldbClient) pull(ctx context.Context, options QueryOptions) (header Header, payload Payload, err error) {
 	if options.EmptyQuery() {
-		return header, payload, fmt.Errorf("empty ksql query")
+		return header, payload, ErrEmptyQuery
 	}
 
-	// remove \t \n from query
+	if api.queries != nil && api.queries.isClosed() {
+		return header, payload, ErrClientShutdown
+	}
+	if err := options.Properties.Validate(); err != nil {
+		return header, payload, err
+	}
+	if err := api.checkTableScan(ctx, options); err != nil {
+		return header, payload, err
+	}
+
+	if len(options.PseudoColumns) > 0 {
+		if options.Sql, err = SelectPseudoColumns(options.Sql, options.PseudoColumns...); err != nil {
+			return header, payload, err
+		}
+	}
+
+	// collapse whitespaces and comments outside of literals into single spaces
 	options.SanitizeQuery()
 
 	if api.ParseSQLEnabled() {
@@ -114,26 +162,19 @@ func (api *KsqldbClient) Pull(ctx context.Context, options QueryOptions) (header
 		return header, payload, fmt.Errorf("can't marshal input data")
 	}
 
-	// Create the request
-	req, err := newQueryStreamRequest(api.http, ctx, bytes.NewReader(jsonData))
-	if err != nil {
-		return header, payload, fmt.Errorf("can't create new request with context: %w", err)
-	}
-	req.Header.Add("Accept", "application/json; charset=utf-8")
-
-	res, err := api.http.Do(req)
-	if err != nil {
-		return header, payload, fmt.Errorf("can't do request: %+w", err)
-	}
-	defer res.Body.Close()
-
-	body, err := api.readBody(res.Body)
+	ctx, cancel := withTimeout(ctx, api.timeouts.Pull)
+	defer cancel()
+	body, err := api.do(ctx, func() (*http.Request, error) {
+		req, err := newQueryStreamRequest(api.http, ctx, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("can't create new request with context: %w", err)
+		}
+		req.Header.Add("Accept", "application/json; charset=utf-8")
+		api.routePull(req, options)
+		return req, nil
+	})
 	if err != nil {
-		return header, payload, fmt.Errorf("can't read response body:\n%w", err)
-	}
-
-	if res.StatusCode != http.StatusOK {
-		return header, payload, handleRequestError(res.StatusCode, body)
+		return header, payload, err
 	}
 
 	var result []interface{}
@@ -191,6 +232,12 @@ func (api *KsqldbClient) Pull(ctx context.Context, options QueryOptions) (header
 			}
 		}
 
+		if api.StrictTypesEnabled() {
+			if err := checkPayload(header, payload); err != nil {
+				return header, payload, err
+			}
+		}
+
 		return header, payload, nil
 	}
 }
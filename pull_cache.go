@@ -0,0 +1,281 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/internal"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+const (
+	// DEFAULT_PULL_CACHE_SIZE is the number of results of the default pull cache store
+	DEFAULT_PULL_CACHE_SIZE = 1000
+	// DEFAULT_PULL_CACHE_TTL is the default time to live of cached pull results
+	DEFAULT_PULL_CACHE_TTL = 10 * time.Second
+)
+
+// PullResult is a cached result of a pull query
+type PullResult struct {
+	Header  Header
+	Payload Payload
+	// Versions are the versions of the sources of the query when the result was
+	// pulled. Results with outdated versions are invalidated, see InvalidatePullCache.
+	Versions map[string]uint64
+}
+
+// PullCacheStore stores the results of pull queries by key.
+// Implementations have to be safe for concurrent use.
+type PullCacheStore interface {
+	// Get returns the result of the key; false if there is no result or it is expired
+	Get(key string) (*PullResult, bool)
+	// Set stores the result of the key for the ttl
+	Set(key string, result *PullResult, ttl time.Duration)
+	// Delete removes the result of the key
+	Delete(key string)
+}
+
+// PullCacheOptions of the pull cache, see SetPullCache
+type PullCacheOptions struct {
+	// Store of the results; nil is an in-memory LRU store of Size results
+	Store PullCacheStore
+	// Size of the default store; < 1 is DEFAULT_PULL_CACHE_SIZE
+	Size int
+	// TTL of the results; < 1 is DEFAULT_PULL_CACHE_TTL.
+	// QueryOptions.CacheTTL overrides the TTL per query.
+	TTL time.Duration
+}
+
+// SetPullCache enables the read-through cache of pull queries. Results are
+// cached by the normalized query text and the properties of the query,
+// errors aren't cached. nil disables the cache (default).
+//
+// Cached payloads are shared, the rows must not be modified.
+func (cl *KsqldbClient) SetPullCache(options *PullCacheOptions) {
+	if options == nil {
+		cl.pullCache = nil
+		return
+	}
+	cache := &pullCache{options: *options}
+	if cache.options.Store == nil {
+		cache.options.Store = NewLRUPullCacheStore(options.Size)
+	}
+	if cache.options.TTL < 1 {
+		cache.options.TTL = DEFAULT_PULL_CACHE_TTL
+	}
+	cl.pullCache = cache
+}
+
+// PullCache returns the options of the pull cache; nil if disabled
+func (cl *KsqldbClient) PullCache() *PullCacheOptions {
+	if cl.pullCache == nil {
+		return nil
+	}
+	options := cl.pullCache.options
+	return &options
+}
+
+// InvalidatePullCache invalidates the cached results of pull queries of the
+// sources, ex. after the sources were changed by the application.
+func (cl *KsqldbClient) InvalidatePullCache(sources ...string) {
+	if cl.pullCache != nil {
+		cl.pullCache.invalidate(sources...)
+	}
+}
+
+// InvalidatePullCacheOnChanges subscribes to the changes of the source with a
+// push query and invalidates the cached results of pull queries of the source
+// on every change. This keeps the cache fresh without short TTLs.
+//
+// The subscription runs until the context is cancelled or the returned handle
+// is stopped.
+func (cl *KsqldbClient) InvalidatePullCacheOnChanges(ctx context.Context, source string) (*QueryHandle, error) {
+	if cl.pullCache == nil {
+		return nil, fmt.Errorf("pull cache is disabled")
+	}
+	sql := fmt.Sprintf("SELECT * FROM %v EMIT CHANGES;", quoteFieldName(source))
+	handle, err := cl.Subscribe(ctx, sql, SubscribeOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("can't subscribe to the changes of %v: %w", source, err)
+	}
+	cache := cl.pullCache
+	go func() {
+		for range handle.Rows() {
+			cache.invalidate(source)
+		}
+	}()
+	return handle, nil
+}
+
+// pullCache is the read-through cache of pull queries
+type pullCache struct {
+	options PullCacheOptions
+	mu      sync.Mutex
+	// versions of the sources, incremented by invalidate
+	versions map[string]uint64
+}
+
+// pull returns the cached result of the query or runs the query and caches its result
+func (c *pullCache) pull(ctx context.Context, api *KsqldbClient, options QueryOptions) (Header, Payload, error) {
+	key, sources := pullCacheKey(options)
+	if result, ok := c.options.Store.Get(key); ok {
+		if c.current(result.Versions) {
+			return result.Header, result.Payload, nil
+		}
+		c.options.Store.Delete(key)
+	}
+	// the versions before the pull, changes during the pull invalidate the result
+	versions := c.snapshot(sources)
+	header, payload, err := api.sharedPull(ctx, options)
+	if err != nil {
+		return header, payload, err
+	}
+	ttl := options.CacheTTL
+	if ttl == 0 {
+		ttl = c.options.TTL
+	}
+	c.options.Store.Set(key, &PullResult{Header: header, Payload: payload, Versions: versions}, ttl)
+	return header, payload, nil
+}
+
+func (c *pullCache) invalidate(sources ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.versions == nil {
+		c.versions = map[string]uint64{}
+	}
+	for _, source := range sources {
+		c.versions[strings.ToUpper(source)]++
+	}
+}
+
+// snapshot returns the current versions of the sources
+func (c *pullCache) snapshot(sources []string) map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versions := make(map[string]uint64, len(sources))
+	for _, source := range sources {
+		versions[source] = c.versions[source]
+	}
+	return versions
+}
+
+// current returns true if the versions are the current versions of the sources
+func (c *pullCache) current(versions map[string]uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for source, version := range versions {
+		if c.versions[source] != version {
+			return false
+		}
+	}
+	return true
+}
+
+// pullCacheKey returns the key of the query, the normalized query text, the pseudo
+// columns and the sorted properties, and the upper cased sources of the query
+func pullCacheKey(options QueryOptions) (string, []string) {
+	sql := internal.SanitizeQuery(options.Sql)
+	sources := []string{}
+	if nodes, err := parser.Parse(sql); err == nil {
+		sql = nodes[0].Text
+		for _, source := range nodes[0].Sources() {
+			sources = append(sources, strings.ToUpper(source.Name))
+		}
+	}
+	properties := make([]string, 0, len(options.Properties))
+	for name, value := range options.Properties {
+		properties = append(properties, name+"="+value)
+	}
+	sort.Strings(properties)
+	return sql + "\x00" + strings.Join(options.PseudoColumns, ",") + "\x00" + strings.Join(properties, "\x00"), sources
+}
+
+// lruPullCacheStore is the in-memory PullCacheStore
+type lruPullCacheStore struct {
+	mu   sync.Mutex
+	size int
+	// recent holds the entries, the most recently used first
+	recent  *list.List
+	entries map[string]*list.Element
+}
+
+type lruPullCacheEntry struct {
+	key     string
+	result  *PullResult
+	expires time.Time
+}
+
+// NewLRUPullCacheStore returns an in-memory PullCacheStore which evicts the
+// least recently used results above size; size < 1 is DEFAULT_PULL_CACHE_SIZE
+func NewLRUPullCacheStore(size int) PullCacheStore {
+	if size < 1 {
+		size = DEFAULT_PULL_CACHE_SIZE
+	}
+	return &lruPullCacheStore{size: size, recent: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (s *lruPullCacheStore) Get(key string) (*PullResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruPullCacheEntry)
+	if time.Now().After(entry.expires) {
+		s.remove(elem)
+		return nil, false
+	}
+	s.recent.MoveToFront(elem)
+	return entry.result, true
+}
+
+func (s *lruPullCacheStore) Set(key string, result *PullResult, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &lruPullCacheEntry{key: key, result: result, expires: time.Now().Add(ttl)}
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = entry
+		s.recent.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.recent.PushFront(entry)
+	if s.recent.Len() > s.size {
+		s.remove(s.recent.Back())
+	}
+}
+
+func (s *lruPullCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+}
+
+func (s *lruPullCacheStore) remove(elem *list.Element) {
+	s.recent.Remove(elem)
+	delete(s.entries, elem.Value.(*lruPullCacheEntry).key)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+const dogHeader = `{"queryId":null,"columnNames":["ID","NAME"],"columnTypes":["STRING","STRING"]}`
+
+func TestPullCache(t *testing.T) {
+	pull := "select * from dogs where id = '1';"
+	m := mockKsql(t, []string{pull, pull, pull}, []string{
+		`[` + dogHeader + `,["1","Rex"]]`,
+		`[` + dogHeader + `,["1","Pluto"]]`,
+		`[` + dogHeader + `,["1","Goofy"]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableParseSQL(false)
+	require.Nil(t, kcl.PullCache())
+	kcl.SetPullCache(&ksqldb.PullCacheOptions{TTL: time.Hour})
+	require.Equal(t, time.Hour, kcl.PullCache().TTL)
+
+	_, payload, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: pull})
+	require.Nil(t, err)
+	require.Equal(t, "Rex", payload[0][1])
+
+	// the normalized query text hits the cache
+	header, payload, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "SELECT *\n FROM DOGS WHERE ID = '1';"})
+	require.Nil(t, err)
+	require.Equal(t, "Rex", payload[0][1])
+	require.Equal(t, []ksqldb.Column{{Name: "ID", Type: "STRING"}, {Name: "NAME", Type: "STRING"}}, header.Columns())
+
+	// bypassed cache
+	_, payload, err = kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: pull, CacheTTL: -1})
+	require.Nil(t, err)
+	require.Equal(t, "Pluto", payload[0][1])
+
+	// other properties are another key, the TTL is overridden
+	options := ksqldb.QueryOptions{Sql: pull, CacheTTL: time.Millisecond}
+	options.EnablePullQueryTableScan(true)
+	_, payload, _ = kcl.Pull(context.Background(), options)
+	require.Equal(t, "Goofy", payload[0][1])
+	m.AssertExpectations(t)
+
+	kcl.SetPullCache(nil)
+	require.Nil(t, kcl.PullCache())
+}
+
+func TestPullCache_Invalidate(t *testing.T) {
+	pull := "SELECT * FROM DOGS WHERE ID = '1';"
+	m := mockKsql(t, []string{pull, pull, pull}, []string{
+		`[` + dogHeader + `,["1","Rex"]]`,
+		`[` + dogHeader + `,["1","Pluto"]]`,
+		`[` + dogHeader + `,["1","Goofy"]]`,
+	})
+	changes, stream := io.Pipe()
+	m.Mock.On("Do", matchStatement(t, "SELECT * FROM DOGS EMIT CHANGES;")).Return(&http.Response{StatusCode: http.StatusOK, Body: changes}, nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.InvalidatePullCacheOnChanges(context.Background(), "DOGS")
+	require.Equal(t, "pull cache is disabled", err.Error())
+	kcl.SetPullCache(&ksqldb.PullCacheOptions{TTL: time.Hour})
+
+	name := func() string {
+		_, payload, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: pull})
+		require.Nil(t, err)
+		return payload[0][1].(string)
+	}
+	require.Equal(t, "Rex", name())
+	require.Equal(t, "Rex", name())
+	kcl.InvalidatePullCache("dogs")
+	require.Equal(t, "Pluto", name())
+
+	go func() { _, _ = stream.Write([]byte(streamHeader + "\n")) }()
+	handle, err := kcl.InvalidatePullCacheOnChanges(context.Background(), "DOGS")
+	require.Nil(t, err)
+	require.Equal(t, "Pluto", name())
+	_, _ = stream.Write([]byte(`["1"]` + "\n"))
+	require.Eventually(t, func() bool { return name() == "Goofy" }, time.Second, time.Millisecond)
+
+	stream.Close()
+	<-handle.Done()
+	m.AssertExpectations(t)
+}
+
+func TestLRUPullCacheStore(t *testing.T) {
+	store := ksqldb.NewLRUPullCacheStore(2)
+	result := &ksqldb.PullResult{Payload: ksqldb.Payload{{"1"}}}
+
+	store.Set("a", result, time.Hour)
+	store.Set("b", result, time.Hour)
+	_, ok := store.Get("a")
+	require.True(t, ok)
+	// b is the least recently used
+	store.Set("c", result, time.Hour)
+	_, ok = store.Get("b")
+	require.False(t, ok)
+	_, ok = store.Get("c")
+	require.True(t, ok)
+
+	store.Delete("c")
+	_, ok = store.Get("c")
+	require.False(t, ok)
+
+	store.Set("d", result, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = store.Get("d")
+	require.False(t, ok)
+	got, ok := store.Get("a")
+	require.True(t, ok)
+	require.Equal(t, result, got)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// DEFAULT_PAGE_SIZE is the default number of rows of pages of PullPaged
+const DEFAULT_PAGE_SIZE = 100
+
+// PageOptions of PullPaged
+type PageOptions struct {
+	// PageSize is the number of rows of a page; < 1 is DEFAULT_PAGE_SIZE
+	PageSize int
+	// KeyColumn enables the key range continuation: the rows are sorted by the
+	// key column and the next page is pulled with a key > the last key of the
+	// page. Empty uses the LIMIT continuation.
+	KeyColumn string
+}
+
+// PageIterator iterates over the pages of a pull query, see PullPaged
+//
+//	pages := client.PullPaged(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS;"}, ksqldb.PageOptions{KeyColumn: "ID"})
+//	for pages.Next() {
+//		for _, row := range pages.Page() {
+//			...
+//		}
+//	}
+//	if err := pages.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	ctx     context.Context
+	api     *KsqldbClient
+	options QueryOptions
+	page    PageOptions
+	query   *parser.QueryNode
+
+	header  Header
+	payload Payload
+	// offset are the rows of the previous pages
+	offset int
+	// last is the key of the last row of the previous page
+	last interface{}
+	done bool
+	err  error
+}
+
+// PullPaged returns an iterator over the pages of the pull query. ksqlDB has no
+// pagination of pull queries, it is emulated:
+//
+// With PageOptions.KeyColumn every page pulls the rows with a key > the last
+// key of the previous page, sorts them by the key and returns the first
+// PageSize rows. Pages are stable, but every page pulls the rest of the table.
+// Table scans are enabled for the range queries on the key.
+//
+// Without KeyColumn every page pulls the rows of the previous pages and the
+// page with LIMIT and skips the rows of the previous pages. ksqlDB doesn't
+// guarantee the order of the rows, so rows may be missed or repeated if the
+// table changes.
+//
+// Only pull queries without joins, windows and aggregations are supported. An
+// existing LIMIT of the query is replaced.
+func (api *KsqldbClient) PullPaged(ctx context.Context, options QueryOptions, page PageOptions) *PageIterator {
+	it := &PageIterator{ctx: ctx, api: api, options: options, page: page}
+	if it.page.PageSize < 1 {
+		it.page.PageSize = DEFAULT_PAGE_SIZE
+	}
+	nodes, err := parser.Parse(options.Sql)
+	if err != nil {
+		it.err = err
+		return it
+	}
+	if len(nodes) != 1 || nodes[0].Kind != parser.KindQuery || nodes[0].Query.From == nil {
+		it.err = fmt.Errorf("paged pull queries need a single SELECT")
+		return it
+	}
+	q := nodes[0].Query
+	if len(q.Joins) > 0 || q.Window != "" || len(q.GroupBy) > 0 || len(q.PartitionBy) > 0 || q.Having != "" || q.Emit != "" {
+		it.err = fmt.Errorf("paged pull queries don't support joins, windows, aggregations and push queries")
+		return it
+	}
+	it.query = q
+	if page.KeyColumn != "" {
+		it.options.EnablePullQueryTableScan(true)
+	}
+	return it
+}
+
+// Next pulls the next page; false if there are no more pages or on errors
+func (it *PageIterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+	if it.page.KeyColumn != "" {
+		it.err = it.nextByKey()
+	} else {
+		it.err = it.nextByLimit()
+	}
+	if it.err != nil || len(it.payload) == 0 {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Page returns the rows of the current page
+func (it *PageIterator) Page() Payload {
+	return it.payload
+}
+
+// Header returns the header of the current page
+func (it *PageIterator) Header() Header {
+	return it.header
+}
+
+// Err returns the error of the iteration; nil if all pages were pulled
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+func (it *PageIterator) nextByLimit() error {
+	limit := it.offset + it.page.PageSize
+	header, payload, err := it.pull(pagedQuery(it.query, "", limit))
+	if err != nil {
+		return err
+	}
+	if len(payload) < limit {
+		it.done = true
+	}
+	if len(payload) > it.offset {
+		payload = payload[it.offset:]
+	} else {
+		payload = nil
+	}
+	it.header, it.payload = header, payload
+	it.offset += len(payload)
+	return nil
+}
+
+func (it *PageIterator) nextByKey() error {
+	where := ""
+	if it.last != nil {
+		literal, err := QuoteLiteral(it.last)
+		if err != nil {
+			return err
+		}
+		where = fmt.Sprintf("%v > %v", quoteFieldName(it.page.KeyColumn), literal)
+	}
+	header, payload, err := it.pull(pagedQuery(it.query, where, 0))
+	if err != nil {
+		return err
+	}
+	key := -1
+	for idx, col := range header.Columns() {
+		if col.Name == it.page.KeyColumn {
+			key = idx
+		}
+	}
+	if key < 0 {
+		return fmt.Errorf("%w: key column %v not selected", ErrMissingKey, it.page.KeyColumn)
+	}
+	sort.SliceStable(payload, func(i, j int) bool {
+		return lessKey(payload[i][key], payload[j][key])
+	})
+	if len(payload) <= it.page.PageSize {
+		it.done = true
+	} else {
+		payload = payload[:it.page.PageSize]
+	}
+	it.header, it.payload = header, payload
+	if len(payload) > 0 {
+		it.last = payload[len(payload)-1][key]
+	}
+	return nil
+}
+
+// pull runs the query; empty results aren't an error
+func (it *PageIterator) pull(sql string) (Header, Payload, error) {
+	options := it.options
+	options.Sql = sql
+	header, payload, err := it.api.Pull(it.ctx, options)
+	if errors.Is(err, ErrNotFound) {
+		return header, nil, nil
+	}
+	return header, payload, err
+}
+
+// pagedQuery returns the query with the additional where condition and the limit; 0 is no limit
+func pagedQuery(q *parser.QueryNode, where string, limit int) string {
+	items := make([]string, len(q.Select))
+	for idx, item := range q.Select {
+		switch {
+		case item.All && item.Qualifier != "":
+			items[idx] = QuoteIdentifier(item.Qualifier) + ".*"
+		case item.All:
+			items[idx] = "*"
+		case item.Alias != "":
+			items[idx] = item.Expression + " AS " + QuoteIdentifier(item.Alias)
+		default:
+			items[idx] = item.Expression
+		}
+	}
+	sql := "SELECT " + strings.Join(items, ", ") + " FROM " + QuoteIdentifier(q.From.Name)
+	if q.From.Alias != "" {
+		sql += " " + QuoteIdentifier(q.From.Alias)
+	}
+	switch {
+	case q.Where != "" && where != "":
+		sql += " WHERE (" + q.Where + ") AND " + where
+	case q.Where != "":
+		sql += " WHERE " + q.Where
+	case where != "":
+		sql += " WHERE " + where
+	}
+	if limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %v", limit)
+	}
+	return sql + ";"
+}
+
+// lessKey compares keys of the same type, other keys are compared by their string representation
+func lessKey(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return x < y
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return x < y
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestPullPaged_Limit(t *testing.T) {
+	m := mockKsql(t, []string{"SELECT * FROM `DOGS` LIMIT 2;", "SELECT * FROM `DOGS` LIMIT 4;"}, []string{
+		`[` + dogHeader + `,["1","Rex"],["2","Pluto"]]`,
+		`[` + dogHeader + `,["1","Rex"],["2","Pluto"],["3","Goofy"]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	pages := kcl.PullPaged(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs limit 10;"}, ksqldb.PageOptions{PageSize: 2})
+	names := [][]interface{}{}
+	for pages.Next() {
+		page := []interface{}{}
+		for _, row := range pages.Page() {
+			page = append(page, row[1])
+		}
+		names = append(names, page)
+	}
+	require.Nil(t, pages.Err())
+	require.Equal(t, [][]interface{}{{"Rex", "Pluto"}, {"Goofy"}}, names)
+	m.AssertExpectations(t)
+}
+
+func TestPullPaged_Key(t *testing.T) {
+	m := mockKsql(t, []string{
+		"SELECT ID, NAME AS `Name` FROM `DOGS` WHERE NAME <> 'Odie';",
+		"SELECT ID, NAME AS `Name` FROM `DOGS` WHERE (NAME <> 'Odie') AND ID > '2';",
+	}, []string{
+		`[` + dogHeader + `,["3","Goofy"],["1","Rex"],["4","Snoopy"],["2","Pluto"]]`,
+		`[` + dogHeader + `,["4","Snoopy"],["3","Goofy"]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	pages := kcl.PullPaged(context.Background(), ksqldb.QueryOptions{Sql: "SELECT ID, NAME AS `Name` FROM DOGS WHERE NAME <> 'Odie';"},
+		ksqldb.PageOptions{PageSize: 2, KeyColumn: "ID"})
+	ids := [][]interface{}{}
+	for pages.Next() {
+		page := []interface{}{}
+		for _, row := range pages.Page() {
+			page = append(page, row[0])
+		}
+		ids = append(ids, page)
+	}
+	require.Nil(t, pages.Err())
+	require.Equal(t, [][]interface{}{{"1", "2"}, {"3", "4"}}, ids)
+	m.AssertExpectations(t)
+}
+
+func TestPullPaged_Errors(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockKsql(t, nil, nil))
+	pages := kcl.PullPaged(context.Background(), ksqldb.QueryOptions{Sql: "DROP STREAM DOGS;"}, ksqldb.PageOptions{})
+	require.False(t, pages.Next())
+	require.Equal(t, "paged pull queries need a single SELECT", pages.Err().Error())
+
+	pages = kcl.PullPaged(context.Background(), ksqldb.QueryOptions{Sql: "SELECT DOG_SIZE, COUNT(*) FROM DOGS GROUP BY DOG_SIZE;"}, ksqldb.PageOptions{})
+	require.False(t, pages.Next())
+	require.Equal(t, "paged pull queries don't support joins, windows, aggregations and push queries", pages.Err().Error())
+}
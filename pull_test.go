@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thmeitz/ksqldb-go"
 	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+	"github.com/thmeitz/ksqldb-go/properties"
 )
 
 // var data = `[
@@ -62,6 +63,32 @@ func TestQueryOptions_SanitizeQuery(t *testing.T) {
 	require.Equal(t, "select * from bla", o.Sql)
 }
 
+func TestPull_ReplicaTooFarBehind(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	kcl, _ := ksqldb.NewClient(&m)
+
+	json := `{"@type":"generic_error","error_code":50000,"message":"Unable to execute pull query: select * from dogs where id = '1';. All nodes are dead or exceed max allowed lag. Hosts scanned: [KsqlNode{host=http://ksqldb-0:8088} Host excluded because lag 512 exceeds maximum allowed lag 100.]"}`
+	var req *http.Request
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(func(r *http.Request) *http.Response {
+		req = r
+		return &http.Response{StatusCode: 500, Body: ioutil.NopCloser(bytes.NewReader([]byte(json)))}
+	}, nil)
+
+	options := ksqldb.QueryOptions{Sql: "select * from dogs where id = '1';"}
+	options.SetMaxAllowedOffsetLag(100)
+	require.Equal(t, "100", options.Properties[ksqldb.KSQL_QUERY_PULL_MAX_ALLOWED_OFFSET_LAG])
+	_, _, err := kcl.Pull(context.TODO(), options)
+	require.True(t, errors.Is(err, ksqldb.ErrReplicaTooFarBehind))
+	var responseErr ksqldb.ResponseError
+	require.True(t, errors.As(err, &responseErr))
+	require.Equal(t, 50000, responseErr.ErrCode)
+
+	body, _ := ioutil.ReadAll(req.Body)
+	require.Contains(t, string(body), `"ksql.query.pull.max.allowed.offset.lag":"100"`)
+	require.False(t, errors.Is(ksqldb.ResponseError{Message: "boom"}, ksqldb.ErrReplicaTooFarBehind))
+}
+
 func TestQueryOptions_TestEmptyQuery(t *testing.T) {
 	o := ksqldb.QueryOptions{Sql: ""}
 	require.True(t, o.EmptyQuery())
@@ -127,3 +154,20 @@ func TestPull_UnmarshallError(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, "could not parse the response:\njson: cannot unmarshal object into Go value of type []interface {}", err.Error())
 }
+
+func TestPull_InvalidProperty(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	kcl, _ := ksqldb.NewClient(&m)
+
+	options := ksqldb.QueryOptions{Sql: "select * from dogs;", Properties: ksqldb.PropertyMap{properties.QueryPullTableScanEnabled: "yes"}}
+	_, _, err := kcl.Pull(context.TODO(), options)
+	require.True(t, errors.Is(err, properties.ErrInvalidProperty))
+
+	_, err = kcl.Execute(ksqldb.ExecOptions{
+		KSql:              "CREATE TABLE DOGS_BY_SIZE AS SELECT DOGSIZE, COUNT(*) FROM DOGS GROUP BY DOGSIZE;",
+		StreamsProperties: ksqldb.PropertyMap{properties.StreamsCacheMaxBytes: "10MB"},
+	})
+	require.True(t, errors.Is(err, properties.ErrInvalidProperty))
+	m.AssertNotCalled(t, "Do", mock.Anything)
+	require.Nil(t, ksqldb.PropertyMap{properties.AutoOffsetReset: "latest"}.Validate())
+}
@@ -18,14 +18,17 @@ limitations under the License.
 package ksqldb
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/thmeitz/ksqldb-go/internal"
+	"github.com/thmeitz/ksqldb-go/net"
 	"github.com/thmeitz/ksqldb-go/parser"
 )
 
@@ -33,6 +36,9 @@ const (
 	HEARTBEAT_TRESHOLD = 9 // After 9 minutes the connection will be closed
 )
 
+// stopTimeout is the time the server has to end the response of a stopped query
+const stopTimeout = 10 * time.Second
+
 // Push queries are continuous queries in which new events
 // or changes to a table's state are pushed to the client.
 // You can think of them as subscribing to a stream of changes.
@@ -59,8 +65,27 @@ const (
 // 			if row != nil {
 //				DATA_TS = row[0].(float64)
 // 				ID = row[1].(string)
-func (api *KsqldbClient) Push(ctx context.Context, sql string, rowChannel chan<- Row, headerChannel chan<- Header) (err error) {
+//
+// A panic while decoding or delivering rows, ex. a send on a channel
+// closed by the consumer, is recovered and returned as *PanicError.
+//
+// The returned error is also available as Err() of the query handle
+// and is passed to the OnQueryError callback of the client, so it is not
+// lost if Push runs in its own goroutine.
+//
+// Both channels are closed when Push returns after the query was started.
+// Use Subscribe to let the client manage the goroutine and the channels.
+func (api *KsqldbClient) Push(ctx context.Context, sql string, rowChannel chan<- Row, headerChannel chan<- Header) error {
+	// https://docs.confluent.io/5.0.4/ksql/docs/installation/server-config/config-reference.html#ksql-streams-auto-offset-reset
+	return api.push(ctx, sql, PropertyMap{"ksql.streams.auto.offset.reset": "latest"}, rowChannel, headerChannel, nil)
+}
 
+// push runs the push query. started is called with the handle of the query
+// once it is registered; headerChannel may be nil.
+func (api *KsqldbClient) push(ctx context.Context, sql string, properties PropertyMap, rowChannel chan<- Row, headerChannel chan<- Header, started func(*QueryHandle)) (err error) {
+	if err := properties.Validate(); err != nil {
+		return err
+	}
 	// first sanitize the query
 	query := internal.SanitizeQuery(sql)
 
@@ -71,117 +96,292 @@ func (api *KsqldbClient) Push(ctx context.Context, sql string, rowChannel chan<-
 		}
 	}
 
-	// https://docs.confluent.io/5.0.4/ksql/docs/installation/server-config/config-reference.html#ksql-streams-auto-offset-reset
-	payload := strings.NewReader(`{"properties":{"ksql.streams.auto.offset.reset": "latest"},"sql":"` + query + `"}`)
+	// the query can be stopped by the client, ex. on Shutdown
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	metrics := queryMetrics(ctx)
+	metrics.start(ctx)
+	record := recorder{query: metrics, totals: api.totals}
+	var buffer *rowBuffer
+	if size, policy := api.RowBuffer(); size > 0 {
+		buffer = newRowBuffer(size, policy)
+	}
+	var dedupe *deduper
+	if options := api.Dedupe(); options != nil {
+		dedupe = newDeduper(*options)
+	}
+	handle, err := api.register(query, metrics, cancel, func() int {
+		if buffer != nil {
+			return len(rowChannel) + buffer.len()
+		}
+		return len(rowChannel)
+	})
+	if err != nil {
+		return err
+	}
+	if api.DecodeProfilingEnabled() {
+		record.profiler = &profiler{handle: handle, onStage: api.onDecodeStage}
+	}
+	defer func() { api.unregister(handle, err) }()
+	defer closeHeaderChannel(headerChannel)
+	defer closeRowChannel(rowChannel)
+	// panics while decoding or delivering rows are returned as *PanicError
+	defer recoverPanic(&err)
+	if buffer != nil {
+		forwarded := make(chan struct{})
+		var forwardErr error
+		go func() {
+			defer close(forwarded)
+			defer recoverPanic(&forwardErr)
+			buffer.forward(ctx, handle.stopping, rowChannel)
+		}()
+		// deliver the buffered rows before the row channel is closed
+		defer func() {
+			buffer.close()
+			<-forwarded
+			if err == nil {
+				err = forwardErr
+			}
+		}()
+	}
+	// the legacy endpoint has its own request and response format
+	legacy := api.LegacyQueryEndpointEnabled()
+	handle.setEndpoint(api.QueryEndpoint())
+	if started != nil {
+		started(handle)
+	}
+
+	decode := decodeLine
+	var options interface{} = QueryOptions{Sql: query, Properties: properties}
+	newRequest := newQueryStreamRequest
+	closeQuery, abortQuery := api.closeQueryOrRetry, api.abortQuery
+	if legacy {
+		decode = decodeLegacyLine
+		options = legacyQueryOptions{Ksql: query, StreamsProperties: properties}
+		newRequest = newQueryRequest
+		// legacy queries end with their connection
+		closeQuery = func(context.Context, string) error { return nil }
+		abortQuery = func(_ context.Context, _ string, cause error) error { return cause }
+	}
+	jsonData, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("can't marshal input data")
+	}
 
-	req, err := newQueryStreamRequest(api.http, ctx, payload)
+	req, err := newRequest(api.http, ctx, bytes.NewReader(jsonData))
 	if err != nil {
-		return fmt.Errorf("error creating new request with context: %v", err)
+		return fmt.Errorf("error creating new request with context: %w", err)
+	}
+	if !legacy {
+		req.Header.Set("Accept", DELIMITED_CONTENT_TYPE)
 	}
 
 	// don't know if we are needing this stuff in the new client
 	// go cl.heartbeat(&cl.client, &ctx)
 
 	//  make the request
+	id := net.SetRequestID(req)
 	res, err := api.http.Do(req)
-
+	api.state.report(res, err)
 	if err != nil {
-		return fmt.Errorf("%v", err)
+		return withRequestID(err, id)
 	}
 	defer res.Body.Close()
 
-	reader := bufio.NewReader(res.Body)
+	decoder := api.newStreamDecoder(res.Body)
+
+	// stopped is closed when the query was closed by Stop
+	stopped := make(chan struct{})
+	finished := make(chan struct{})
+	// the error of the close by Stop is returned if the query ended without one
+	stopErr := make(chan error, 1)
+	defer func() {
+		close(finished)
+		if closeErr := <-stopErr; err == nil {
+			err = closeErr
+		}
+	}()
+	go func() { stopErr <- api.watchStop(ctx, cancel, handle, stopped, finished) }()
+	idle := newIdleWatch(api.timeouts.PushIdle, cancel)
+	defer idle.received()
 
 	doThis := true
-	var row interface{}
 	var header Header
+	// plan checks the rows in strict type mode
+	var plan *DecodePlan
 
 	for doThis {
 		select {
 		case <-ctx.Done():
-			// close the channels and terminate the loop regardless
-			defer close(rowChannel)
-			defer close(headerChannel)
-			defer func() { doThis = false }()
-			// Try to close the query
-			payload := strings.NewReader(`{"queryId":"` + header.queryId + `"}`)
-			// cl.log("payload: %v", *payload)
-			req, err := newCloseQueryRequest(api.http, ctx, payload)
-
-			// api.logger.Debugw("closing ksqlDB query", log.Fields{"queryId": header.queryId})
-			if err != nil {
-				return fmt.Errorf("failed to construct http request to cancel query\n%w", err)
+			select {
+			case <-stopped:
+				// already closed by Stop
+				return nil
+			default:
 			}
-
-			res, err := api.http.Do(req)
-			if err != nil {
-				return fmt.Errorf("failed to execute http request to cancel query\n%w", err)
+			if idle.timedOut() {
+				return abortQuery(ctx, header.queryId, ErrIdleTimeout)
 			}
-			defer res.Body.Close()
-
-			if res.StatusCode != http.StatusOK {
-				return fmt.Errorf("close query failed:\n%v", res)
-			}
-			// api.logger.Info("query closed.")
+			// Try to close the query
+			return closeQuery(ctx, header.queryId)
 		default:
 
 			// Read the next chunk
-			body, err := reader.ReadBytes('\n')
+			idle.waiting()
+			start := record.now()
+			body, err := decoder.readLine()
+			start = record.stage(STAGE_READ, start)
+			idle.received()
+			if errors.Is(err, ErrRowTooLarge) {
+				record.decodeError()
+				return abortQuery(ctx, header.queryId, err)
+			}
+			if err != nil && ctx.Err() != nil {
+				// the query was stopped while reading, close it
+				continue
+			}
 			if err != nil {
 				doThis = false
 			}
+			record.read(len(body))
 			if res.StatusCode != http.StatusOK {
-				return handleRequestError(res.StatusCode, body)
+				return withRequestID(responseError(res, body), id)
+			}
+
+			// Parse the output
+			newHeader, row, err := decode(api.Decoder(), body, len(header.columns), api.RowPoolEnabled())
+			if err != nil {
+				record.decodeError()
+				return err
 			}
+			start = record.stage(STAGE_UNMARSHAL, start)
 
-			if len(body) > 0 {
-				// Parse the output
-				if err := json.Unmarshal(body, &row); err != nil {
-					return fmt.Errorf("could not parse the response: %w\n%v", err, string(body))
+			if newHeader != nil {
+				// It's a header row
+				header = *newHeader
+				if header.queryId != "" {
+					handle.setQueryId(header.queryId)
 				}
+				if api.StrictTypesEnabled() {
+					if plan, err = NewDecodePlan(header); err != nil {
+						return abortQuery(ctx, header.queryId, err)
+					}
+				}
+				handle.setHeader(header)
+				if headerChannel != nil {
+					deliverHeader(ctx, handle.stopping, headerChannel, header)
+				}
+			}
 
-				switch zz := row.(type) {
-				case map[string]interface{}:
-					// It's a header row, so extract the data
-					// {"queryId":null,"columnNames":["WINDOW_START","WINDOW_END","DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","STRING","STRING","BIGINT"]}
-					if _, ok := zz["queryId"].(string); ok {
-						header.queryId = zz["queryId"].(string)
-					} /*else {
-						// api.logger.Debug("query id not found - this is expected for a pull query")
-					}*/
-
-					names, okn := zz["columnNames"].([]interface{})
-					types, okt := zz["columnTypes"].([]interface{})
-					if okn && okt {
-						for col := range names {
-							if n, ok := names[col].(string); n != "" && ok {
-								if t, ok := types[col].(string); t != "" && ok {
-									a := Column{Name: n, Type: t}
-									header.columns = append(header.columns, a)
-								} /*else {
-									// api.logger.Infof("nil type found for column %v", col)
-								}*/
-							} /*else {
-								// api.logger.Infof("Nil name found for column %v", col)
-							}*/
-						}
-					} /*else {
-						api.logger.Infof("Column names/types not found in header:\n%v", zz)
-					}*/
-					// api.logger.Debugf("Header: %v", header)
-					headerChannel <- header
-
-				case []interface{}:
-					// It's a row of data
-					// api.logger.Debugf("Row: %v", zz)
-					rowChannel <- zz
+			if row != nil {
+				// It's a row of data
+				if plan != nil {
+					if err := plan.Check(row); err != nil {
+						record.decodeError()
+						return abortQuery(ctx, header.queryId, err)
+					}
 				}
+				record.row()
+				duplicate := dedupe != nil && dedupe.duplicate(row)
+				start = record.stage(STAGE_CONVERT, start)
+				if duplicate {
+					record.duplicateRow()
+					if api.RowPoolEnabled() {
+						row.Release()
+					}
+					continue
+				}
+				if buffer == nil {
+					deliverRow(ctx, handle.stopping, rowChannel, row)
+				} else if buffer.put(ctx, handle.stopping, row) {
+					record.droppedRow()
+				}
+				record.stage(STAGE_DELIVER, start)
 			}
 		}
 	}
 	return nil
 }
 
+// watchStop closes the query on the server when it is stopped with Stop.
+// The server ends the response then; if not within stopTimeout, or if
+// the close fails, the query is cancelled. The error of the close is returned.
+func (api *KsqldbClient) watchStop(ctx context.Context, cancel context.CancelFunc, handle *QueryHandle, stopped chan<- struct{}, finished <-chan struct{}) error {
+	select {
+	case <-handle.stopping:
+	case <-finished:
+		return nil
+	}
+	defer cancel()
+	queryId := handle.QueryId()
+	if queryId == "" {
+		return nil
+	}
+	if err := api.closeQueryOrRetry(ctx, queryId); err != nil {
+		return err
+	}
+	close(stopped)
+	select {
+	case <-time.After(stopTimeout):
+	case <-finished:
+	}
+	return nil
+}
+
+// closeQuery closes the push query on the server
+func (api *KsqldbClient) closeQuery(ctx context.Context, queryId string) error {
+	payload, err := json.Marshal(RequestParams{"queryId": queryId})
+	if err != nil {
+		return fmt.Errorf("can't marshal close query data")
+	}
+	// cl.log("payload: %v", *payload)
+	// ctx may be done, the close request would be cancelled right away
+	req, err := newCloseQueryRequest(api.http, detachedContext{ctx}, bytes.NewReader(payload))
+
+	// api.logger.Debugw("closing ksqlDB query", log.Fields{"queryId": queryId})
+	if err != nil {
+		return fmt.Errorf("failed to construct http request to cancel query\n%w", err)
+	}
+
+	res, err := api.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute http request to cancel query\n%w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("close query failed: %w", responseError(res, body))
+	}
+	// api.logger.Info("query closed.")
+	return nil
+}
+
+// abortQuery closes the query on the server after a failure while reading.
+// The cause is returned; a failing close request is appended to it.
+func (api *KsqldbClient) abortQuery(ctx context.Context, queryId string, cause error) error {
+	if err := api.closeQueryOrRetry(ctx, queryId); err != nil {
+		return fmt.Errorf("%w (%v)", cause, err)
+	}
+	return cause
+}
+
+// closeRowChannel closes the channel. A channel already closed by the consumer is ignored.
+func closeRowChannel(ch chan<- Row) {
+	defer func() { _ = recover() }()
+	close(ch)
+}
+
+// closeHeaderChannel closes the channel if it is not nil.
+// A channel already closed by the consumer is ignored.
+func closeHeaderChannel(ch chan<- Header) {
+	if ch == nil {
+		return
+	}
+	defer func() { _ = recover() }()
+	close(ch)
+}
+
 // heartbeat sends a heartbeat to the server
 //
 // The default for KSQL server is a 10 minute timeout, which is a problem on low volume connections.
@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+)
+
+// PushFunc runs the push query and calls onHeader with the header and onRow
+// with every row, in the goroutine of the caller. It is an alternative to the
+// channels of Push; onHeader may be nil.
+//
+// If onRow returns an error, the query is stopped and the error is returned.
+// Otherwise PushFunc returns like Push, ex. when ctx is done or the query is
+// stopped with its handle, see ActiveQueries.
+//
+//	err := client.PushFunc(ctx, "SELECT * FROM DOGS EMIT CHANGES;", nil, func(row ksqldb.Row) error {
+//		return store.Save(row)
+//	})
+func (api *KsqldbClient) PushFunc(ctx context.Context, sql string, onHeader func(Header), onRow func(Row) error) error {
+	rows := make(chan Row)
+	headers := make(chan Header)
+	handles := make(chan *QueryHandle, 1)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- api.push(ctx, sql, PropertyMap{"ksql.streams.auto.offset.reset": "latest"}, rows, headers, func(h *QueryHandle) {
+			handles <- h
+		})
+	}()
+
+	// the header is delivered before the rows, both channels are closed when
+	// the query is finished; queries failing before they start close none
+	select {
+	case header, ok := <-headers:
+		if ok && onHeader != nil {
+			onHeader(header)
+		}
+	case err := <-failed:
+		return err
+	}
+	for row := range rows {
+		if err := onRow(row); err != nil {
+			// the handle is sent before the rows
+			_ = (<-handles).Stop()
+			for range rows {
+			}
+			<-failed
+			return err
+		}
+	}
+	return <-failed
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestPushFunc(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	go m.send(streamHeader, `["a"]`, `["b"]`, `["c"]`)
+	var header ksqldb.Header
+	rows := []ksqldb.Row{}
+	errEnough := errors.New("enough")
+	err := kcl.PushFunc(context.TODO(), "select * from dogs emit changes;", func(h ksqldb.Header) {
+		header = h
+	}, func(row ksqldb.Row) error {
+		rows = append(rows, row)
+		if len(rows) == 2 {
+			return errEnough
+		}
+		return nil
+	})
+	require.Equal(t, errEnough, err)
+	require.Equal(t, "abc", header.QueryId())
+	require.Equal(t, []ksqldb.Row{{"a"}, {"b"}}, rows)
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+}
+
+func TestPushFunc_Errors(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	err := kcl.PushFunc(context.TODO(), "select * from;", nil, func(ksqldb.Row) error { return nil })
+	require.IsType(t, &parser.SqlSyntaxErrorList{}, err)
+
+	// the query ends regularly with the context, like Push
+	ctx, cancel := context.WithCancel(context.Background())
+	go m.send(streamHeader, `["a"]`)
+	err = kcl.PushFunc(ctx, "select * from dogs emit changes;", nil, func(ksqldb.Row) error {
+		cancel()
+		return nil
+	})
+	require.Nil(t, err)
+	require.Empty(t, kcl.ActiveQueries())
+}
@@ -15,3 +15,41 @@ limitations under the License.
 */
 
 package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestPush_PayloadEscaping(t *testing.T) {
+	query := "select \"my col\", `x\\y` from dogs where name='it''s' emit changes;"
+	body := `{"queryId":"abc","columnNames":["my col"],"columnTypes":["STRING"]}` + "\n" + `["Lara"]` + "\n"
+	res := http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+
+	var options ksqldb.QueryOptions
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		b, _ := ioutil.ReadAll(req.Body)
+		return json.Unmarshal(b, &options) == nil
+	})).Return(&res, nil)
+
+	kcl, _ := ksqldb.NewClient(&m)
+	rowChannel := make(chan ksqldb.Row, 1)
+	headerChannel := make(chan ksqldb.Header, 1)
+
+	err := kcl.Push(context.TODO(), query, rowChannel, headerChannel)
+	require.Nil(t, err)
+	require.Equal(t, query, options.Sql)
+	require.Equal(t, "latest", options.Properties["ksql.streams.auto.offset.reset"])
+	require.Equal(t, ksqldb.Row{"Lara"}, <-rowChannel)
+}
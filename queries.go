@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// States of the queries of ListQueries and ListQueriesExtended
+const (
+	QUERY_STATE_RUNNING = "RUNNING"
+	QUERY_STATE_PAUSED  = "PAUSED"
+)
+
+// ListQueries runs SHOW QUERIES and returns the persistent and the push
+// queries running on the server
+func (api *KsqldbClient) ListQueries(ctx context.Context) ([]RunningQuery, error) {
+	var response []struct {
+		Queries []RunningQuery `json:"queries"`
+	}
+	if err := api.executeInto(ctx, ExecOptions{KSql: "SHOW QUERIES;"}, &response); err != nil {
+		return nil, fmt.Errorf("can't list queries: %w", err)
+	}
+	for _, item := range response {
+		if item.Queries != nil {
+			return item.Queries, nil
+		}
+	}
+	return []RunningQuery{}, nil
+}
+
+// ListQueriesExtended runs SHOW QUERIES EXTENDED and returns the descriptions
+// of the queries including their state on each host, their errors and their
+// consumer group ids
+func (api *KsqldbClient) ListQueriesExtended(ctx context.Context) ([]QueryDescription, error) {
+	response, err := api.execute(ctx, ExecOptions{KSql: "SHOW QUERIES EXTENDED;"})
+	if err != nil {
+		return nil, fmt.Errorf("can't list queries: %w", err)
+	}
+	for _, item := range *response {
+		if item.QueryDescriptions != nil {
+			return *item.QueryDescriptions, nil
+		}
+	}
+	return []QueryDescription{}, nil
+}
+
+// FailedHosts returns the sorted hosts on which the query is not running
+func (q QueryDescription) FailedHosts() []string {
+	hosts := []string{}
+	for host, state := range q.KsqlHostQueryStatus {
+		if state != QUERY_STATE_RUNNING {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// PartiallyFailed returns true if the query is running on some hosts and
+// not running on others
+func (q QueryDescription) PartiallyFailed() bool {
+	failed := len(q.FailedHosts())
+	return failed > 0 && failed < len(q.KsqlHostQueryStatus)
+}
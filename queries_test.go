@@ -0,0 +1,52 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestListQueriesExtended(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW QUERIES EXTENDED;"}, []string{`[{"@type":"query_descriptions","statementText":"SHOW QUERIES EXTENDED;","queryDescriptions":[
+{"id":"CSAS_BIG_DOGS_3","statementText":"CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS EMIT CHANGES;","windowType":null,
+"fields":[{"name":"ID","schema":{"type":"STRING","fields":null,"memberSchema":null}}],"sources":["DOGS"],"sinks":["BIG_DOGS"],
+"topology":"","executionPlan":"","overriddenProperties":{},"queryType":"PERSISTENT","state":"ERROR",
+"ksqlHostQueryStatus":{"ksqldb-0:8088":"RUNNING","ksqldb-1:8088":"ERROR"},
+"queryErrors":[{"timestamp":1636000000000,"errorMessage":"Error deserializing JSON","type":"USER"}],
+"consumerGroupId":"_confluent-ksql-default_query_CSAS_BIG_DOGS_3"}],"warnings":[]}]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	queries, err := kcl.ListQueriesExtended(context.Background())
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	q := queries[0]
+	require.Equal(t, "CSAS_BIG_DOGS_3", q.ID)
+	require.Equal(t, "PERSISTENT", q.QueryType)
+	require.Equal(t, "ERROR", q.State)
+	require.Equal(t, []string{"DOGS"}, q.Sources)
+	require.Equal(t, "_confluent-ksql-default_query_CSAS_BIG_DOGS_3", q.ConsumerGroupId)
+	require.Equal(t, []ksqldb.QueryError{{Timestamp: 1636000000000, ErrorMessage: "Error deserializing JSON", Type: "USER"}}, q.QueryErrors)
+	require.Equal(t, []string{"ksqldb-1:8088"}, q.FailedHosts())
+	require.True(t, q.PartiallyFailed())
+
+	q.KsqlHostQueryStatus["ksqldb-0:8088"] = "ERROR"
+	require.False(t, q.PartiallyFailed())
+}
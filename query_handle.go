@@ -0,0 +1,306 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryState is the state of a push query
+type QueryState string
+
+const (
+	// QUERY_RUNNING is the state of a query receiving rows
+	QUERY_RUNNING QueryState = "running"
+	// QUERY_CLOSED is the state of a finished query
+	QUERY_CLOSED QueryState = "closed"
+)
+
+// QueryHandle describes a push query started by a KsqldbClient.
+// It is safe for concurrent use.
+type QueryHandle struct {
+	mu      sync.RWMutex
+	queryId string
+	sql     string
+	// endpoint of the query, QUERY_STREAM_ENDPOINT or QUERY_ENDPOINT
+	endpoint string
+	started  time.Time
+	state   QueryState
+	metrics *QueryMetrics
+	// cancel stops the query
+	cancel context.CancelFunc
+	// pending returns the number of rows not yet received by the consumer
+	pending func() int
+	// done is closed when the query is finished
+	done chan struct{}
+	// err is the error the query finished with
+	err error
+	// header is set once headerReady is closed
+	header      Header
+	headerReady chan struct{}
+	// rows of queries started with Subscribe
+	rows <-chan Row
+	// stopping is closed by Stop
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+func newQueryHandle(sql string, metrics *QueryMetrics, cancel context.CancelFunc, pending func() int) *QueryHandle {
+	return &QueryHandle{
+		sql:         sql,
+		started:     time.Now(),
+		state:       QUERY_RUNNING,
+		metrics:     metrics,
+		cancel:      cancel,
+		pending:     pending,
+		done:        make(chan struct{}),
+		headerReady: make(chan struct{}),
+		stopping:    make(chan struct{}),
+	}
+}
+
+// QueryId returns the server side query id; empty until the header is received
+func (h *QueryHandle) QueryId() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.queryId
+}
+
+// SQL returns the sanitized sql of the query
+func (h *QueryHandle) SQL() string {
+	return h.sql
+}
+
+// Started returns the start time of the query
+func (h *QueryHandle) Started() time.Time {
+	return h.started
+}
+
+// State returns the current state of the query
+func (h *QueryHandle) State() QueryState {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state
+}
+
+// Metrics returns the counters of the query
+func (h *QueryHandle) Metrics() *QueryMetrics {
+	return h.metrics
+}
+
+// Done returns a channel which is closed when the query is finished
+func (h *QueryHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error the query finished with; nil while the query
+// is running or if it was stopped regularly
+func (h *QueryHandle) Err() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.err
+}
+
+// Header waits for the header of the query. If the query finishes without
+// a header, its error or ErrQueryClosed is returned.
+func (h *QueryHandle) Header() (Header, error) {
+	select {
+	case <-h.headerReady:
+		return h.header, nil
+	case <-h.done:
+	}
+	select {
+	case <-h.headerReady:
+		return h.header, nil
+	default:
+	}
+	if err := h.Err(); err != nil {
+		return Header{}, err
+	}
+	return Header{}, ErrQueryClosed
+}
+
+// Rows returns the row channel of a query started with Subscribe; nil for
+// queries started with Push. The channel is closed when the query is finished.
+func (h *QueryHandle) Rows() <-chan Row {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.rows
+}
+
+// Stop stops the query without cancelling the context it was started with,
+// so other queries sharing the context keep running. It returns the error the
+// query finished with; calling Stop again is a no-op returning the same error.
+//
+// The query is closed on the server first. Rows the server sent before
+// the close are delivered while there is room in the row channel, the rest
+// is dropped, so Stop can be called from the consuming goroutine.
+// Then the channels are closed. If the server does not end the response
+// within 10 seconds, the request is cancelled.
+func (h *QueryHandle) Stop() error {
+	h.stopOnce.Do(func() { close(h.stopping) })
+	<-h.done
+	return h.Err()
+}
+
+// Endpoint returns the endpoint of the query, QUERY_STREAM_ENDPOINT or the
+// legacy QUERY_ENDPOINT; see NegotiateQueryEndpoint
+func (h *QueryHandle) Endpoint() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.endpoint
+}
+
+// Labels returns the labels of the query; see WithLabels
+func (h *QueryHandle) Labels() Labels {
+	return h.metrics.Labels()
+}
+
+// setHeader sets the header once
+func (h *QueryHandle) setHeader(header Header) {
+	select {
+	case <-h.headerReady:
+	default:
+		h.header = header
+		close(h.headerReady)
+	}
+}
+
+func (h *QueryHandle) setEndpoint(endpoint string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.endpoint = endpoint
+}
+
+func (h *QueryHandle) setQueryId(queryId string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queryId = queryId
+}
+
+// queryRegistry holds the active queries of a client
+type queryRegistry struct {
+	mu      sync.Mutex
+	queries []*QueryHandle
+	// closed rejects new queries after Shutdown
+	closed bool
+}
+
+func newQueryRegistry() *queryRegistry {
+	return &queryRegistry{}
+}
+
+func (r *queryRegistry) add(h *QueryHandle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClientShutdown
+	}
+	r.queries = append(r.queries, h)
+	return nil
+}
+
+// close rejects new queries and returns the active ones
+func (r *queryRegistry) close() []*QueryHandle {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	return r.list()
+}
+
+func (r *queryRegistry) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+func (r *queryRegistry) remove(h *QueryHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, q := range r.queries {
+		if q == h {
+			r.queries = append(r.queries[:i], r.queries[i+1:]...)
+			return
+		}
+	}
+}
+
+// list returns the active queries in start order
+func (r *queryRegistry) list() []*QueryHandle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queries := make([]*QueryHandle, len(r.queries))
+	copy(queries, r.queries)
+	return queries
+}
+
+// ActiveQueries returns the handles of the running push queries of the client
+// in start order. The handles can be used to build admin endpoints:
+//
+//	for _, q := range client.ActiveQueries() {
+//		fmt.Println(q.QueryId(), q.SQL(), q.Started(), q.Metrics().RowsReceived(), q.State())
+//	}
+func (api *KsqldbClient) ActiveQueries() []*QueryHandle {
+	if api.queries == nil {
+		return []*QueryHandle{}
+	}
+	return api.queries.list()
+}
+
+// register adds a new handle for the query to the registry.
+// pending returns the number of rows buffered for the consumer.
+// It returns ErrClientShutdown after Shutdown.
+func (api *KsqldbClient) register(sql string, metrics *QueryMetrics, cancel context.CancelFunc, pending func() int) (*QueryHandle, error) {
+	h := newQueryHandle(sql, metrics, cancel, pending)
+	if api.queries != nil {
+		if err := api.queries.add(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// unregister closes the handle with the error of the query and removes it
+// from the registry. Errors are passed to the OnQueryError callback.
+func (api *KsqldbClient) unregister(h *QueryHandle, err error) {
+	h.mu.Lock()
+	h.state = QUERY_CLOSED
+	h.err = err
+	h.mu.Unlock()
+	if api.queries != nil {
+		api.queries.remove(h)
+	}
+	close(h.done)
+	if err != nil && api.onQueryError != nil {
+		api.onQueryError(h, err)
+	}
+}
+
+// OnQueryError sets a callback which is called with the handle and the
+// error of every push query failing after it was started, ex. on a broken
+// connection, an error response or a type mismatch in strict mode.
+// Set it before starting queries. The callback runs on the goroutine of the
+// query, after Done() of the handle is closed.
+//
+//	client.OnQueryError(func(q *ksqldb.QueryHandle, err error) {
+//		log.Printf("query %v failed: %v", q.QueryId(), err)
+//	})
+func (api *KsqldbClient) OnQueryError(fn func(*QueryHandle, error)) {
+	api.onQueryError = fn
+}
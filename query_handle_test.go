@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestActiveQueries(t *testing.T) {
+	pr, pw := io.Pipe()
+	res := http.Response{StatusCode: 200, Body: pr}
+
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(&res, nil)
+
+	kcl, _ := ksqldb.NewClient(&m)
+	require.Empty(t, kcl.ActiveQueries())
+
+	rowChannel := make(chan ksqldb.Row)
+	headerChannel := make(chan ksqldb.Header)
+	done := make(chan error)
+	ctx := ksqldb.WithLabels(context.TODO(), ksqldb.Labels{"owner": "team-a"})
+	go func() {
+		done <- kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+
+	go func() {
+		_, _ = pw.Write([]byte(`{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]}` + "\n"))
+		_, _ = pw.Write([]byte(`["a"]` + "\n"))
+	}()
+	<-headerChannel
+	<-rowChannel
+
+	queries := kcl.ActiveQueries()
+	require.Len(t, queries, 1)
+	q := queries[0]
+	require.Equal(t, "abc", q.QueryId())
+	require.Equal(t, "select * from dogs emit changes;", q.SQL())
+	require.Equal(t, ksqldb.QUERY_RUNNING, q.State())
+	require.False(t, q.Started().IsZero())
+	require.Equal(t, int64(1), q.Metrics().RowsReceived())
+	require.Equal(t, ksqldb.Labels{"owner": "team-a"}, q.Labels())
+
+	pw.Close()
+	require.Nil(t, <-done)
+	require.Empty(t, kcl.ActiveQueries())
+	require.Equal(t, ksqldb.QUERY_CLOSED, q.State())
+	require.Nil(t, q.Err())
+}
+
+func TestOnQueryError(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	failed := make(chan *ksqldb.QueryHandle, 1)
+	var failure error
+	kcl.OnQueryError(func(q *ksqldb.QueryHandle, err error) {
+		failure = err
+		failed <- q
+	})
+
+	rowChannel := make(chan ksqldb.Row, 1)
+	headerChannel := make(chan ksqldb.Header, 1)
+	go func() {
+		_ = kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+	// a broken row ends the query
+	go m.send(streamHeader, `["a"`)
+
+	q := <-failed
+	<-q.Done()
+	require.NotNil(t, q.Err())
+	require.Equal(t, failure, q.Err())
+	require.Contains(t, q.Err().Error(), "could not parse the response")
+	require.Equal(t, ksqldb.QUERY_CLOSED, q.State())
+}
+
+func TestActiveQueries_ZeroClient(t *testing.T) {
+	kcl := ksqldb.KsqldbClient{}
+	require.Empty(t, kcl.ActiveQueries())
+}
@@ -19,6 +19,9 @@ package ksqldb
 import (
 	"fmt"
 	"strings"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/thmeitz/ksqldb-go/parser"
 )
 
 const (
@@ -65,75 +68,61 @@ func QueryBuilder(stmnt string, params ...interface{}) (*string, error) {
 // }
 
 // bind parameters to QueryBuilder
+//
+// The statement is tokenized first, so a ? inside of string literals,
+// quoted identifiers or comments is not treated as placeholder.
 func bind(stmnt string, params ...interface{}) (*string, error) {
+	tokens := tokenize(stmnt)
 	paramCount := len(params)
-	count := strings.Count(stmnt, "?")
+	count := 0
+	for _, token := range tokens {
+		if isPlaceholder(token) {
+			count++
+		}
+	}
 	if paramCount < count {
 		return nil, fmt.Errorf("%v: %v", QBErr, "to few params")
 	} else if paramCount > count {
 		return nil, fmt.Errorf("%v: %v", QBErr, "to many params")
 	}
 
-	for _, param := range params {
-		replace, err := getReplacement(param)
+	var sb strings.Builder
+	idx := 0
+	for _, token := range tokens {
+		if !isPlaceholder(token) {
+			sb.WriteString(token.GetText())
+			continue
+		}
+		replace, err := getReplacement(params[idx])
 		if err != nil {
 			return nil, fmt.Errorf("%v", err)
 		}
-		stmnt = strings.Replace(stmnt, "?", *replace, 1)
+		sb.WriteString(*replace)
+		idx++
 	}
-	return &stmnt, nil
+	result := sb.String()
+	return &result, nil
+}
+
+// tokenize returns all tokens of the statement including hidden channel tokens
+func tokenize(stmnt string) []antlr.Token {
+	return parser.Tokenize(stmnt)
+}
+
+// isPlaceholder returns true if the token is a ? on the default channel
+func isPlaceholder(token antlr.Token) bool {
+	return token.GetChannel() == antlr.TokenDefaultChannel &&
+		token.GetTokenType() == parser.KSqlLexerUNRECOGNIZED &&
+		token.GetText() == "?"
 }
 
+// getReplacement encodes the param with QuoteLiteral
 func getReplacement(param interface{}) (*string, error) {
-	switch param := param.(type) {
-	case int:
-		n := fmt.Sprintf("%v", int(param))
-		return &n, nil
-	case int8:
-		n := fmt.Sprintf("%v", int8(param))
-		return &n, nil
-	case int16:
-		n := fmt.Sprintf("%v", int16(param))
-		return &n, nil
-	case int32:
-		n := fmt.Sprintf("%v", int32(param))
-		return &n, nil
-	case int64:
-		n := fmt.Sprintf("%v", int64(param))
-		return &n, nil
-	case uint:
-		n := fmt.Sprintf("%v", uint(param))
-		return &n, nil
-	case uint8:
-		n := fmt.Sprintf("%v", uint8(param))
-		return &n, nil
-	case uint16:
-		n := fmt.Sprintf("%v", uint16(param))
-		return &n, nil
-	case uint32:
-		n := fmt.Sprintf("%v", uint32(param))
-		return &n, nil
-	case uint64:
-		n := fmt.Sprintf("%v", uint64(param))
-		return &n, nil
-	case float32:
-		n := fmt.Sprintf("%v", float32(param))
-		return &n, nil
-	case float64:
-		n := fmt.Sprintf("%v", float64(param))
-		return &n, nil
-	case nil:
-		n := "NULL"
-		return &n, nil
-	case string:
-		n := fmt.Sprintf("'%v'", param)
-		return &n, nil
-	case bool:
-		n := fmt.Sprintf("%v", param)
-		return &n, nil
-	default:
+	n, err := QuoteLiteral(param)
+	if err != nil {
 		return nil, fmt.Errorf("%v: %v :%v", QBErr, QBUnsupportedType, param)
 	}
+	return &n, nil
 }
 
 // checkEmptyStatement to keep the Factories dry
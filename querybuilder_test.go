@@ -36,6 +36,7 @@ var qbtests = []struct {
 	message string
 }{
 	{"string", select1Param, "Lara", "select * from bla where column='Lara'"},
+	{"string with quote", select1Param, "Lara's", "select * from bla where column='Lara''s'"},
 	{"nil", select1Param, nil, "select * from bla where column=NULL"},
 	{"int", select1Param, 15235, "select * from bla where column=15235"},
 	{"hex int", select1Param, 0xff, "select * from bla where column=255"},
@@ -109,7 +110,20 @@ func TestQueryBuilder_Bind_MultiParams(t *testing.T) {
 }
 
 func TestQueryBuilder_UnsupportedType(t *testing.T) {
-	stmnt, err := ksqldb.QueryBuilder(select1Param, map[string]interface{}{"test": 5})
+	stmnt, err := ksqldb.QueryBuilder(select1Param, complex(1, 2))
 	require.Nil(t, stmnt)
-	require.Equal(t, "qbErr: unsupported param type :map[test:5]", err.Error())
+	require.Equal(t, "qbErr: unsupported param type :(1+2i)", err.Error())
+}
+
+func TestQueryBuilder_ComplexTypes(t *testing.T) {
+	stmnt, err := ksqldb.QueryBuilder("insert into bla values(?,?)", []string{"a", "b"}, map[string]interface{}{"test": 5})
+	require.Nil(t, err)
+	require.Equal(t, "insert into bla values(ARRAY['a', 'b'],MAP('test' := 5))", *stmnt)
+}
+
+func TestQueryBuilder_Bind_IgnoresQuotedPlaceholders(t *testing.T) {
+	stmnt, err := ksqldb.QueryBuilder("select `what?`, \"who?\" from bla where a='?' and b=? -- c=?\n;", 5)
+	require.Nil(t, err)
+	require.NotNil(t, stmnt)
+	require.Equal(t, "select `what?`, \"who?\" from bla where a='?' and b=5 -- c=?\n;", *stmnt)
 }
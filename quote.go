@@ -0,0 +1,217 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// KSQL_TAG is the struct tag used to map struct fields to ksql columns and struct fields
+	KSQL_TAG = "ksql"
+	// KSQL_TIMESTAMP_FORMAT is the format of timestamp literals
+	KSQL_TIMESTAMP_FORMAT = "2006-01-02T15:04:05.000"
+)
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteIdentifier returns the name as back quoted identifier.
+// Back quotes inside of the name are escaped.
+//
+// Quoted identifiers are case sensitive, so QuoteIdentifier("dogs") references
+// a source named dogs and not DOGS.
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteString returns the value as string literal.
+// Single quotes inside of the value are escaped.
+func QuoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// QuoteLiteral encodes the Go value as ksql literal.
+//
+// Supported are nil, bool, all int, uint and float types, strings, time.Time
+// (as UTC timestamp string), []byte (as TO_BYTES), slices and arrays (as ARRAY),
+// maps (as MAP) and structs (as STRUCT). Pointers are dereferenced, nil pointers
+// are encoded as NULL. Values implementing driver.Valuer, like NullString,
+// are encoded by their Value.
+//
+// Struct fields are named by their `ksql` tag or by the field name.
+// Fields tagged with `ksql:"-"` and unexported fields are skipped.
+func QuoteLiteral(value interface{}) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "NULL", nil
+	}
+	switch v := value.(type) {
+	case driver.Valuer:
+		// NullString, sql.NullString, ...
+		dv, err := v.Value()
+		if err != nil {
+			return "", err
+		}
+		return QuoteLiteral(dv)
+	case string:
+		return QuoteString(v), nil
+	case bool:
+		return fmt.Sprintf("%v", v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%v", v), nil
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", fmt.Errorf("%v: %v", QBUnsupportedType, v)
+		}
+		return fmt.Sprintf("%v", v), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("%v: %v", QBUnsupportedType, v)
+		}
+		return fmt.Sprintf("%v", v), nil
+	case time.Time:
+		return QuoteString(v.UTC().Format(KSQL_TIMESTAMP_FORMAT)), nil
+	case []byte:
+		return fmt.Sprintf("TO_BYTES(%v, 'base64')", QuoteString(base64.StdEncoding.EncodeToString(v))), nil
+	}
+
+	return quoteValue(reflect.ValueOf(value))
+}
+
+func quoteValue(rv reflect.Value) (string, error) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return QuoteLiteral(rv.Elem().Interface())
+	case reflect.String:
+		return QuoteString(rv.String()), nil
+	case reflect.Bool:
+		return QuoteLiteral(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return QuoteLiteral(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return QuoteLiteral(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return QuoteLiteral(rv.Float())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "NULL", nil
+		}
+		items := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := QuoteLiteral(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "ARRAY[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		entries := []string{}
+		for _, key := range rv.MapKeys() {
+			k, err := QuoteLiteral(key.Interface())
+			if err != nil {
+				return "", err
+			}
+			v, err := QuoteLiteral(rv.MapIndex(key).Interface())
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, k+" := "+v)
+		}
+		// map keys have no order
+		sort.Strings(entries)
+		return "MAP(" + strings.Join(entries, ", ") + ")", nil
+	case reflect.Struct:
+		fields := []string{}
+		for _, field := range structFields(rv.Type()) {
+			v, err := QuoteLiteral(rv.FieldByIndex(field.index).Interface())
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, quoteFieldName(field.name)+" := "+v)
+		}
+		return "STRUCT(" + strings.Join(fields, ", ") + ")", nil
+	}
+	return "", fmt.Errorf("%v: %v", QBUnsupportedType, rv.Type())
+}
+
+// quoteFieldName quotes names which are not plain identifiers
+func quoteFieldName(name string) string {
+	if plainIdentifier.MatchString(name) {
+		return name
+	}
+	return QuoteIdentifier(name)
+}
+
+// structField is an exported struct field with its ksql name
+type structField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+	// options of the ksql tag, ex. `ksql:"ID,key"`
+	options []string
+}
+
+// hasOption returns true if the ksql tag of the field has the option
+func (f structField) hasOption(option string) bool {
+	for _, o := range f.options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// structFields returns the exported fields of the struct type.
+// The ksql tag has the form `ksql:"NAME,option,..."`.
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag := f.Tag.Get(KSQL_TAG)
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{name: name, index: f.Index, typ: f.Type, options: parts[1:]})
+	}
+	return fields
+}
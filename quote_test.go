@@ -0,0 +1,77 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+type quoteAddress struct {
+	Street  string `ksql:"STREET"`
+	ZipCode *int   `ksql:"zip code"`
+	Ignored string `ksql:"-"`
+	secret  string
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	require.Equal(t, "`dogs`", ksqldb.QuoteIdentifier("dogs"))
+	require.Equal(t, "`my``dogs`", ksqldb.QuoteIdentifier("my`dogs"))
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	zip := 12345
+	name := "Lara"
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "NULL"},
+		{"string", "it's", "'it''s'"},
+		{"bool", false, "false"},
+		{"int", 42, "42"},
+		{"float", 1.5, "1.5"},
+		{"pointer", &name, "'Lara'"},
+		{"nil pointer", (*string)(nil), "NULL"},
+		{"time", time.Date(2021, 11, 16, 6, 0, 0, 0, time.FixedZone("CET", 3600)), "'2021-11-16T05:00:00.000'"},
+		{"bytes", []byte("hi"), "TO_BYTES('aGk=', 'base64')"},
+		{"array", []interface{}{1, "a", nil}, "ARRAY[1, 'a', NULL]"},
+		{"map", map[string]int{"b": 2, "a": 1}, "MAP('a' := 1, 'b' := 2)"},
+		{"struct", quoteAddress{Street: "Main", ZipCode: &zip, Ignored: "x", secret: "y"}, "STRUCT(STREET := 'Main', `zip code` := 12345)"},
+		{"named string type", time.Month(1).String(), "'January'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ksqldb.QuoteLiteral(tt.value)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuoteLiteral_Unsupported(t *testing.T) {
+	_, err := ksqldb.QuoteLiteral(math.NaN())
+	require.NotNil(t, err)
+	_, err = ksqldb.QuoteLiteral(make(chan int))
+	require.NotNil(t, err)
+	require.Equal(t, "unsupported param type: chan int", err.Error())
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/thmeitz/ksqldb-go/internal"
+	"github.com/thmeitz/ksqldb-go/net"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// RawStream is the response of a push query which is read by the caller,
+// see PushRaw. Read returns the rows as they are sent by the server, one
+// JSON array per line, ex. ["1","Rover"]. It is safe to call Close
+// concurrently with Read.
+type RawStream struct {
+	api    *KsqldbClient
+	ctx    context.Context
+	cancel context.CancelFunc
+	header Header
+	handle *QueryHandle
+	body   io.Closer
+	// reader holds the bytes read after the header
+	reader    *bufio.Reader
+	closeOnce sync.Once
+	closeErr  error
+	closed    chan struct{}
+}
+
+// PushRaw starts the push query, reads its header and returns the rest of
+// the response unread. It bypasses the decoding of the client, ex. for
+// custom parsers or zero-copy processing; the query is still registered, so
+// it is closed on the server by Close, by Stop of its handle, when ctx is
+// done or when the client is closed.
+//
+// The row decoder, the row buffer, deduplication and strict types of the
+// client don't apply; PushRaw always uses QUERY_STREAM_ENDPOINT.
+//
+//	stream, err := client.PushRaw(ctx, "SELECT * FROM DOGS EMIT CHANGES;")
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	scanner := bufio.NewScanner(stream)
+//	for scanner.Scan() {
+//		// parse scanner.Bytes()
+//	}
+func (api *KsqldbClient) PushRaw(ctx context.Context, sql string) (*RawStream, error) {
+	properties := PropertyMap{"ksql.streams.auto.offset.reset": "latest"}
+	query := internal.SanitizeQuery(sql)
+	if api.ParseSQLEnabled() {
+		if err := parser.ParseSql(query); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	metrics := queryMetrics(ctx)
+	metrics.start(ctx)
+	handle, err := api.register(query, metrics, cancel, func() int { return 0 })
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	handle.setEndpoint(QUERY_STREAM_ENDPOINT)
+	stream, err := api.startRaw(ctx, query, properties, handle)
+	if err != nil {
+		cancel()
+		api.unregister(handle, err)
+		return nil, err
+	}
+	stream.cancel = cancel
+	go stream.watch()
+	return stream, nil
+}
+
+// startRaw sends the query request and reads the header of the response
+func (api *KsqldbClient) startRaw(ctx context.Context, query string, properties PropertyMap, handle *QueryHandle) (*RawStream, error) {
+	jsonData, err := json.Marshal(QueryOptions{Sql: query, Properties: properties})
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal input data")
+	}
+	req, err := newQueryStreamRequest(api.http, ctx, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating new request with context: %w", err)
+	}
+	req.Header.Set("Accept", DELIMITED_CONTENT_TYPE)
+
+	id := net.SetRequestID(req)
+	res, err := api.http.Do(req)
+	api.state.report(res, err)
+	if err != nil {
+		return nil, withRequestID(err, id)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, err := api.readResponse(res)
+		if err != nil {
+			return nil, withRequestID(err, id)
+		}
+		return nil, withRequestID(responseError(res, body), id)
+	}
+
+	decoder := api.newStreamDecoder(res.Body)
+	line, err := decoder.readLine()
+	if err != nil && len(line) == 0 {
+		res.Body.Close()
+		return nil, fmt.Errorf("can't read the header: %w", withRequestID(err, id))
+	}
+	header, _, err := decodeLine(api.Decoder(), line, 0, false)
+	if err == nil && header == nil {
+		err = fmt.Errorf("could not parse the response: header expected\n%v", string(bytes.TrimSpace(line)))
+	}
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	handle.setQueryId(header.queryId)
+	handle.setHeader(*header)
+	return &RawStream{
+		api:    api,
+		ctx:    ctx,
+		header: *header,
+		handle: handle,
+		body:   res.Body,
+		reader: decoder.reader,
+		closed: make(chan struct{}),
+	}, nil
+}
+
+// Header returns the header of the query
+func (s *RawStream) Header() Header {
+	return s.header
+}
+
+// Handle returns the handle of the query
+func (s *RawStream) Handle() *QueryHandle {
+	return s.handle
+}
+
+// Read reads the rows of the response
+func (s *RawStream) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	recorder{query: s.handle.metrics, totals: s.api.totals}.read(n)
+	return n, err
+}
+
+// Close closes the query on the server and the response. It returns the
+// error of the close request; calling Close again returns the same error.
+func (s *RawStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.closeErr = s.api.closeQueryOrRetry(s.ctx, s.header.queryId)
+		s.cancel()
+		s.body.Close()
+		s.api.unregister(s.handle, nil)
+	})
+	return s.closeErr
+}
+
+// watch closes the stream when its handle is stopped or its context is done
+func (s *RawStream) watch() {
+	select {
+	case <-s.handle.stopping:
+	case <-s.ctx.Done():
+	case <-s.closed:
+		return
+	}
+	s.Close()
+}
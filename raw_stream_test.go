@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bufio"
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestPushRaw(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	stream, err := kcl.PushRaw(context.TODO(), "select * from dogs emit changes;")
+	require.Nil(t, err)
+	require.Equal(t, "abc", stream.Header().QueryId())
+	require.Equal(t, []*ksqldb.QueryHandle{stream.Handle()}, kcl.ActiveQueries())
+
+	scanner := bufio.NewScanner(stream)
+	require.True(t, scanner.Scan())
+	require.Equal(t, `["a"]`, scanner.Text())
+	require.True(t, scanner.Scan())
+	require.Equal(t, `["b"]`, scanner.Text())
+
+	require.Nil(t, stream.Close())
+	require.Nil(t, stream.Close())
+	require.False(t, scanner.Scan())
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+	require.Equal(t, ksqldb.QUERY_CLOSED, stream.Handle().State())
+}
+
+func TestPushRaw_Stop(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	go m.send(streamHeader)
+	stream, err := kcl.PushRaw(context.TODO(), "select * from dogs emit changes;")
+	require.Nil(t, err)
+
+	// the handle closes the stream
+	require.Nil(t, stream.Handle().Stop())
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	_, err = stream.Read(make([]byte, 10))
+	require.NotNil(t, err)
+	require.Empty(t, kcl.ActiveQueries())
+}
+
+func TestPushRaw_Errors(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.PushRaw(context.TODO(), "select * from;")
+	require.IsType(t, &parser.SqlSyntaxErrorList{}, err)
+
+	go m.send(`["a"]`)
+	_, err = kcl.PushRaw(context.TODO(), "select * from dogs emit changes;")
+	require.EqualError(t, err, "could not parse the response: header expected\n[\"a\"]")
+	require.Empty(t, kcl.ActiveQueries())
+}
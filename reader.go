@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DEFAULT_READ_BUFFER_SIZE is the default size of the read buffer of push query responses
+const DEFAULT_READ_BUFFER_SIZE = 4096
+
+// SetReadBufferSize sets the size of the read buffer of push query responses.
+// Rows larger than the buffer are read in several chunks. Sizes < 1 reset
+// the size to DEFAULT_READ_BUFFER_SIZE.
+func (api *KsqldbClient) SetReadBufferSize(size int) {
+	api.readBufferSize = size
+}
+
+// ReadBufferSize returns the size of the read buffer of push query responses
+func (api *KsqldbClient) ReadBufferSize() int {
+	if api.readBufferSize < 1 {
+		return DEFAULT_READ_BUFFER_SIZE
+	}
+	return api.readBufferSize
+}
+
+// SetMaxRowSize sets the maximum size of a row of push query responses in bytes.
+// Larger rows fail the query with ErrRowTooLarge instead of growing the memory
+// without bounds. Sizes < 1 remove the limit, which is the default.
+func (api *KsqldbClient) SetMaxRowSize(size int) {
+	api.maxRowSize = size
+}
+
+// MaxRowSize returns the maximum size of a row of push query responses; 0 if unlimited
+func (api *KsqldbClient) MaxRowSize() int {
+	if api.maxRowSize < 1 {
+		return 0
+	}
+	return api.maxRowSize
+}
+
+// SetMaxResponseSize sets the maximum size of the response bodies of statements
+// and pull queries in bytes. Larger responses, ex. of a SELECT * over a huge
+// table, fail with ErrResponseTooLarge instead of being read into memory.
+// Sizes < 1 remove the limit, which is the default. Push queries are limited
+// by SetMaxRowSize.
+func (api *KsqldbClient) SetMaxResponseSize(size int) {
+	api.maxResponseSize = size
+}
+
+// MaxResponseSize returns the maximum size of the response bodies of statements and pull queries; 0 if unlimited
+func (api *KsqldbClient) MaxResponseSize() int {
+	if api.maxResponseSize < 1 {
+		return 0
+	}
+	return api.maxResponseSize
+}
+
+// readResponse reads the response body of a statement or pull query,
+// bodies larger than MaxResponseSize fail with ErrResponseTooLarge
+func (api *KsqldbClient) readResponse(res *http.Response) ([]byte, error) {
+	max := api.MaxResponseSize()
+	if max == 0 {
+		return api.readBody(res.Body)
+	}
+	tooLarge := fmt.Errorf("%w: more than %v bytes", ErrResponseTooLarge, max)
+	if res.ContentLength > int64(max) {
+		return nil, tooLarge
+	}
+	body, err := api.readBody(io.LimitReader(res.Body, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > max {
+		return nil, tooLarge
+	}
+	return body, nil
+}
+
+// newResponseReader returns a buffered reader for the response body
+func (api *KsqldbClient) newResponseReader(body io.Reader) *bufio.Reader {
+	return bufio.NewReaderSize(body, api.ReadBufferSize())
+}
+
+// readLine reads the next line including the delimiter into buf, which is
+// reused if it is large enough.
+// Lines longer than max bytes (without the delimiter) fail with ErrRowTooLarge;
+// max < 1 means unlimited.
+func readLine(reader *bufio.Reader, max int, buf []byte) ([]byte, error) {
+	line := buf[:0]
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		size := len(line) + len(chunk)
+		if err == nil {
+			size--
+		}
+		if max > 0 && size > max {
+			return nil, fmt.Errorf("%w: more than %v bytes", ErrRowTooLarge, max)
+		}
+		// the chunk is only valid until the next read
+		line = append(line, chunk...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
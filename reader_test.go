@@ -0,0 +1,103 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestReadLine(t *testing.T) {
+	// the buffer is smaller than the lines
+	reader := bufio.NewReaderSize(strings.NewReader("0123456789abcdefghij\n0123456789abcdefghijk\nend"), 16)
+
+	line, err := ksqldb.ReadLine(reader, 20, nil)
+	require.Nil(t, err)
+	require.Equal(t, "0123456789abcdefghij\n", string(line))
+
+	_, err = ksqldb.ReadLine(reader, 20, nil)
+	require.True(t, errors.Is(err, ksqldb.ErrRowTooLarge))
+	require.Equal(t, "row too large: more than 20 bytes", err.Error())
+
+	reader = bufio.NewReaderSize(strings.NewReader("0123456789abcdefghijk\nend"), 16)
+	line, err = ksqldb.ReadLine(reader, 0, nil)
+	require.Nil(t, err)
+	require.Equal(t, "0123456789abcdefghijk\n", string(line))
+	line, err = ksqldb.ReadLine(reader, 0, nil)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "end", string(line))
+}
+
+func TestClient_ReaderOptions(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(newStreamMock())
+	require.Equal(t, ksqldb.DEFAULT_READ_BUFFER_SIZE, kcl.ReadBufferSize())
+	require.Equal(t, 0, kcl.MaxRowSize())
+	kcl.SetReadBufferSize(64 * 1024)
+	kcl.SetMaxRowSize(1024 * 1024)
+	require.Equal(t, 64*1024, kcl.ReadBufferSize())
+	require.Equal(t, 1024*1024, kcl.MaxRowSize())
+	kcl.SetReadBufferSize(0)
+	require.Equal(t, ksqldb.DEFAULT_READ_BUFFER_SIZE, kcl.ReadBufferSize())
+}
+
+func TestPush_RowTooLarge(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetMaxRowSize(10)
+
+	rowChannel := make(chan ksqldb.Row, 1)
+	headerChannel := make(chan ksqldb.Header, 1)
+	go m.send(`{"queryId":"abc"}`)
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	require.True(t, errors.Is(err, ksqldb.ErrRowTooLarge))
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+}
+
+func TestPull_ResponseTooLarge(t *testing.T) {
+	response := `[{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]},["1"],["2"]]`
+	m := mockKsql(t, []string{"select * from dogs;", "select * from dogs;"}, []string{response, response})
+	kcl, _ := ksqldb.NewClient(m)
+	require.Equal(t, 0, kcl.MaxResponseSize())
+
+	kcl.SetMaxResponseSize(len(response))
+	_, rows, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.Nil(t, err)
+	require.Len(t, rows, 2)
+
+	kcl.SetMaxResponseSize(len(response) - 1)
+	_, _, err = kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.True(t, errors.Is(err, ksqldb.ErrResponseTooLarge))
+	require.Contains(t, err.Error(), fmt.Sprintf("response too large: more than %v bytes", len(response)-1))
+}
+
+func TestExecute_ResponseTooLarge(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW STREAMS;"}, []string{`[{"@type":"streams","streams":[]}]`})
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetMaxResponseSize(10)
+
+	_, err := kcl.Execute(ksqldb.ExecOptions{KSql: "SHOW STREAMS;"})
+	require.True(t, errors.Is(err, ksqldb.ErrResponseTooLarge))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// READY_POLL_INTERVAL is the wait before the second healthcheck of WaitForReady, it is doubled with every poll
+	READY_POLL_INTERVAL = 100 * time.Millisecond
+	// READY_POLL_MAX_INTERVAL is the maximum wait between the healthchecks of WaitForReady
+	READY_POLL_MAX_INTERVAL = 2 * time.Second
+)
+
+// WaitForReady polls the healthcheck of the server until the server, its
+// Kafka cluster and its metastore are healthy, ex. at startup or in
+// integration tests against a booting ksqlDB. The polls back off from
+// READY_POLL_INTERVAL up to READY_POLL_MAX_INTERVAL.
+//
+// If the context expires first, the error wraps the error of the context
+// and tells why the last healthcheck failed.
+func (api *KsqldbClient) WaitForReady(ctx context.Context) error {
+	wait := READY_POLL_INTERVAL
+	for {
+		err := api.checkHealth(ctx)
+		if err == nil {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("server not ready: %w (%v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		if wait *= 2; wait > READY_POLL_MAX_INTERVAL {
+			wait = READY_POLL_MAX_INTERVAL
+		}
+	}
+}
+
+// checkHealth returns nil if the healthcheck reports the server as healthy
+func (api *KsqldbClient) checkHealth(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.http.GetUrl(HEALTHCHECK_ENDPOINT), nil)
+	if err != nil {
+		return err
+	}
+	res, err := api.http.Do(req)
+	api.state.report(res, err)
+	if err != nil {
+		return fmt.Errorf("can't get healthcheck informations: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := api.readResponse(res)
+	if err != nil {
+		return fmt.Errorf("could not read response body: %w", err)
+	}
+
+	// unhealthy servers answer with 503 and the status
+	status := ServerStatusResponse{}
+	if err := api.unMarshalResp(body, &status); err != nil || status.IsHealthy == nil {
+		return fmt.Errorf("unexpected healthcheck response %v", res.Status)
+	}
+	switch {
+	case !isHealthy(status.Details.Kafka.IsHealthy):
+		return fmt.Errorf("kafka is not healthy")
+	case !isHealthy(status.Details.Metastore.IsHealthy):
+		return fmt.Errorf("metastore is not healthy")
+	case !*status.IsHealthy:
+		return fmt.Errorf("server is not healthy")
+	}
+	return nil
+}
+
+func isHealthy(healthy *bool) bool {
+	return healthy != nil && *healthy
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func healthResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.HEALTHCHECK_ENDPOINT).Return("http://localhost/healthcheck")
+	m.Mock.On("Do", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	m.Mock.On("Do", mock.Anything).Return(healthResponse(503,
+		`{"isHealthy":false,"details":{"metastore":{"isHealthy":true},"kafka":{"isHealthy":false}}}`), nil).Once()
+	m.Mock.On("Do", mock.Anything).Return(healthResponse(200,
+		`{"isHealthy":true,"details":{"metastore":{"isHealthy":true},"kafka":{"isHealthy":true}}}`), nil).Once()
+
+	kcl, _ := ksqldb.NewClient(&m)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Nil(t, kcl.WaitForReady(ctx))
+	m.AssertExpectations(t)
+}
+
+func TestWaitForReady_ContextExpires(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", ksqldb.HEALTHCHECK_ENDPOINT).Return("http://localhost/healthcheck")
+	m.Mock.On("Do", mock.Anything).Return(func(r *http.Request) *http.Response {
+		return healthResponse(503, `{"isHealthy":false,"details":{"metastore":{"isHealthy":false},"kafka":{"isHealthy":true}}}`)
+	}, nil)
+
+	kcl, _ := ksqldb.NewClient(&m)
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	err := kcl.WaitForReady(ctx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.EqualError(t, err, "server not ready: context deadline exceeded (metastore is not healthy)")
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// QUERY_TYPE_PUSH is the query type of transient push queries in SHOW QUERIES
+const QUERY_TYPE_PUSH = "PUSH"
+
+// ReapOptions configures ReapOrphans
+type ReapOptions struct {
+	// Match selects the push queries of the application, ex. with MatchSQLPrefix.
+	// It is required, the queries of other applications on a shared cluster
+	// must not be closed; MatchAll matches all push queries.
+	Match func(q RunningQuery) bool
+	// Live returns true for the queries of other live clients, ex. looked up in a
+	// shared registry of the application instances. The active queries of the
+	// client itself are always live.
+	Live func(q RunningQuery) bool
+	// DryRun returns the orphaned queries without closing them
+	DryRun bool
+}
+
+// ReapOrphans closes the transient push queries left running on the server
+// by crashed instances of the application. It lists the queries with SHOW
+// QUERIES and closes the push queries which are matched by options.Match and
+// are neither active queries of the client nor live by options.Live.
+//
+//	reaped, err := client.ReapOrphans(ctx, ksqldb.ReapOptions{
+//		Match: ksqldb.MatchSQLPrefix("SELECT * FROM ORDERS"),
+//		Live:  func(q ksqldb.RunningQuery) bool { return registry.Owned(q.ID) },
+//	})
+//
+// The closed queries are returned; if closes fail, the queries closed before
+// are returned with the error.
+func (api *KsqldbClient) ReapOrphans(ctx context.Context, options ReapOptions) ([]RunningQuery, error) {
+	if options.Match == nil {
+		return nil, fmt.Errorf("can't reap orphans: no Match, use MatchAll to reap all push queries")
+	}
+	queries, err := api.ListQueries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owned := map[string]bool{}
+	for _, h := range api.ActiveQueries() {
+		owned[h.QueryId()] = true
+	}
+
+	reaped := []RunningQuery{}
+	failed := 0
+	var firstErr error
+	for _, q := range queries {
+		if !isTransient(q) || owned[q.ID] {
+			continue
+		}
+		if !options.Match(q) {
+			continue
+		}
+		if options.Live != nil && options.Live(q) {
+			continue
+		}
+		if options.DryRun {
+			reaped = append(reaped, q)
+			continue
+		}
+		if err := api.closeQuery(ctx, q.ID); err != nil && !queryGone(err) {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		reaped = append(reaped, q)
+	}
+	if firstErr != nil {
+		return reaped, fmt.Errorf("can't close %v orphaned queries: %w", failed, firstErr)
+	}
+	return reaped, nil
+}
+
+// isTransient returns true for push queries; servers without query types
+// name them transient_...
+func isTransient(q RunningQuery) bool {
+	if q.QueryType != "" {
+		return q.QueryType == QUERY_TYPE_PUSH
+	}
+	return strings.HasPrefix(strings.ToLower(q.ID), "transient_")
+}
+
+// MatchSQLPrefix returns a ReapOptions.Match of the queries starting with the
+// prefix, ignoring case and leading white space. Comments are removed from
+// the sql before queries are sent, so they can't be matched.
+func MatchSQLPrefix(prefix string) func(q RunningQuery) bool {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	return func(q RunningQuery) bool {
+		return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(q.QueryString)), prefix)
+	}
+}
+
+// MatchAll is a ReapOptions.Match of all push queries, ex. of a cluster which
+// is used by a single application
+func MatchAll(q RunningQuery) bool {
+	return true
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+const orphansResponse = `[{"@type":"queries","statementText":"SHOW QUERIES;","queries":[
+{"queryString":"SELECT * FROM ORDERS EMIT CHANGES;","sinks":[],"id":"transient_ORDERS_1","queryType":"PUSH","state":"RUNNING"},
+{"queryString":"select * from orders where id = '1' emit changes;","sinks":[],"id":"transient_ORDERS_2","queryType":"PUSH","state":"RUNNING"},
+{"queryString":"SELECT * FROM ORDERS EMIT CHANGES;","sinks":[],"id":"transient_ORDERS_3","queryType":"PUSH","state":"RUNNING"},
+{"queryString":"SELECT * FROM DOGS EMIT CHANGES;","sinks":[],"id":"transient_DOGS_4","queryType":"PUSH","state":"RUNNING"},
+{"queryString":"CREATE STREAM BIG_ORDERS AS SELECT * FROM ORDERS EMIT CHANGES;","sinks":["BIG_ORDERS"],"id":"CSAS_BIG_ORDERS_5","queryType":"PERSISTENT","state":"RUNNING"}],"warnings":[]}]`
+
+// reaperMock lists the orphans and records the closed query ids
+func reaperMock(t *testing.T, closeStatus int) (*mocknet.HTTPClient, func() []string) {
+	var mu sync.Mutex
+	closed := []string{}
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			body, _ := ioutil.ReadAll(req.Body)
+			mu.Lock()
+			closed = append(closed, string(body))
+			mu.Unlock()
+			return &http.Response{StatusCode: closeStatus, Body: ioutil.NopCloser(strings.NewReader(`{"message":"failed"}`))}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(orphansResponse))}
+	}, nil)
+	return &m, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return closed
+	}
+}
+
+func reapedIds(queries []ksqldb.RunningQuery) []string {
+	ids := []string{}
+	for _, q := range queries {
+		ids = append(ids, q.ID)
+	}
+	return ids
+}
+
+func TestReapOrphans(t *testing.T) {
+	m, closed := reaperMock(t, http.StatusOK)
+	kcl, _ := ksqldb.NewClient(m)
+
+	reaped, err := kcl.ReapOrphans(context.Background(), ksqldb.ReapOptions{
+		Match: ksqldb.MatchSQLPrefix("select * from orders"),
+		Live:  func(q ksqldb.RunningQuery) bool { return q.ID == "transient_ORDERS_3" },
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"transient_ORDERS_1", "transient_ORDERS_2"}, reapedIds(reaped))
+	require.Equal(t, []string{`{"queryId":"transient_ORDERS_1"}`, `{"queryId":"transient_ORDERS_2"}`}, closed())
+}
+
+func TestReapOrphans_DryRun(t *testing.T) {
+	m, closed := reaperMock(t, http.StatusOK)
+	kcl, _ := ksqldb.NewClient(m)
+
+	reaped, err := kcl.ReapOrphans(context.Background(), ksqldb.ReapOptions{Match: ksqldb.MatchAll, DryRun: true})
+	require.Nil(t, err)
+	// persistent queries are never reaped
+	require.Equal(t, []string{"transient_ORDERS_1", "transient_ORDERS_2", "transient_ORDERS_3", "transient_DOGS_4"}, reapedIds(reaped))
+	require.Empty(t, closed())
+}
+
+func TestReapOrphans_Errors(t *testing.T) {
+	m, closed := reaperMock(t, http.StatusServiceUnavailable)
+	kcl, _ := ksqldb.NewClient(m)
+
+	reaped, err := kcl.ReapOrphans(context.Background(), ksqldb.ReapOptions{Match: ksqldb.MatchSQLPrefix("SELECT * FROM DOGS")})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "can't close 1 orphaned queries: close query failed")
+	require.Empty(t, reaped)
+	require.Len(t, closed(), 1)
+	// failed reaps are not retried in the background
+	require.Empty(t, kcl.PendingCloses())
+}
+
+func TestReapOrphans_NoMatch(t *testing.T) {
+	m, closed := reaperMock(t, http.StatusOK)
+	kcl, _ := ksqldb.NewClient(m)
+
+	reaped, err := kcl.ReapOrphans(context.Background(), ksqldb.ReapOptions{})
+	require.Equal(t, "can't reap orphans: no Match, use MatchAll to reap all push queries", err.Error())
+	require.Nil(t, reaped)
+	require.Empty(t, closed())
+	m.AssertNotCalled(t, "Do", mock.Anything)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestPush_RecoversPanic(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	// the consumer closed its channel too early
+	rowChannel := make(chan ksqldb.Row)
+	close(rowChannel)
+	headerChannel := make(chan ksqldb.Header, 1)
+	go m.send(streamHeader, `["a"]`)
+
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	var perr *ksqldb.PanicError
+	require.True(t, errors.As(err, &perr))
+	require.Equal(t, "recovered panic: send on closed channel", err.Error())
+	require.NotEmpty(t, perr.Stack)
+	require.NotNil(t, errors.Unwrap(err))
+	require.Empty(t, kcl.ActiveQueries())
+}
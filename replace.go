@@ -0,0 +1,262 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// Kinds of SchemaChange
+const (
+	COLUMN_ADDED        = "ADDED"
+	COLUMN_REMOVED      = "REMOVED"
+	COLUMN_TYPE_CHANGED = "TYPE_CHANGED"
+	COLUMN_KEY_CHANGED  = "KEY_CHANGED"
+)
+
+// SchemaChange is a changed column of a source, see DiffSchema
+type SchemaChange struct {
+	Kind   string
+	Column string
+	// Old and New are the types of the column, KEY is added for key columns;
+	// Old is empty for added columns, New for removed columns
+	Old string
+	New string
+	// Compatible is true for changes which CREATE OR REPLACE accepts,
+	// ex. added value columns
+	Compatible bool
+}
+
+// String returns the change like a diff line, ex. + AGE INTEGER or ~ AGE INTEGER -> BIGINT
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case COLUMN_ADDED:
+		return fmt.Sprintf("+ %v %v", c.Column, c.New)
+	case COLUMN_REMOVED:
+		return fmt.Sprintf("- %v %v", c.Column, c.Old)
+	}
+	return fmt.Sprintf("~ %v %v -> %v", c.Column, c.Old, c.New)
+}
+
+// DiffSchema returns the changes of the columns of a source from old to new.
+// Pseudo columns are ignored. Value columns can be added; removed columns,
+// changed types and added or changed key columns are incompatible.
+func DiffSchema(old []SchemaColumn, new []SchemaColumn) []SchemaChange {
+	changes := []SchemaChange{}
+	newColumns := map[string]SchemaColumn{}
+	for _, col := range new {
+		newColumns[col.Name] = col
+	}
+	oldColumns := map[string]bool{}
+	for _, col := range old {
+		if isPseudoColumn(col.Name) {
+			continue
+		}
+		oldColumns[col.Name] = true
+		oldType := columnType(col)
+		newCol, ok := newColumns[col.Name]
+		switch {
+		case !ok:
+			changes = append(changes, SchemaChange{Kind: COLUMN_REMOVED, Column: col.Name, Old: oldType})
+		case col.Key != newCol.Key:
+			changes = append(changes, SchemaChange{Kind: COLUMN_KEY_CHANGED, Column: col.Name, Old: oldType, New: columnType(newCol)})
+		case normalizeSchema(col.Schema).String() != normalizeSchema(newCol.Schema).String():
+			changes = append(changes, SchemaChange{Kind: COLUMN_TYPE_CHANGED, Column: col.Name, Old: oldType, New: columnType(newCol)})
+		}
+	}
+	for _, col := range new {
+		if !oldColumns[col.Name] && !isPseudoColumn(col.Name) {
+			changes = append(changes, SchemaChange{Kind: COLUMN_ADDED, Column: col.Name, New: columnType(col), Compatible: !col.Key})
+		}
+	}
+	return changes
+}
+
+// columnType returns the type of the column for SchemaChange, ex. STRING KEY
+func columnType(col SchemaColumn) string {
+	if col.Key {
+		return normalizeSchema(col.Schema).String() + " KEY"
+	}
+	return normalizeSchema(col.Schema).String()
+}
+
+func isPseudoColumn(name string) bool {
+	for _, pseudo := range PSEUDO_COLUMNS {
+		if name == pseudo {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAliases are the alternative names of ksql types
+var typeAliases = map[string]string{
+	"VARCHAR": "STRING",
+	"INT":     "INTEGER",
+}
+
+// normalizeSchema replaces the type aliases of the schema
+func normalizeSchema(schema Schema) Schema {
+	if typ, ok := typeAliases[schema.Type]; ok {
+		schema.Type = typ
+	}
+	if schema.MemberSchema != nil {
+		member := normalizeSchema(*schema.MemberSchema)
+		schema.MemberSchema = &member
+	}
+	if schema.Fields != nil {
+		fields := make([]Field, len(schema.Fields))
+		for idx, field := range schema.Fields {
+			fields[idx] = Field{Name: field.Name, Schema: normalizeSchema(field.Schema), Type: field.Type}
+		}
+		schema.Fields = fields
+	}
+	return schema
+}
+
+// IncompatibleSchemaError is returned by ReplaceSource for statements which
+// change the schema of the source in a way CREATE OR REPLACE doesn't support
+type IncompatibleSchemaError struct {
+	Source string
+	// Changes are all changes of the schema, compatible and incompatible ones
+	Changes []SchemaChange
+}
+
+func (e *IncompatibleSchemaError) Error() string {
+	lines := []string{}
+	for _, change := range e.Changes {
+		if !change.Compatible {
+			lines = append(lines, change.String())
+		}
+	}
+	return fmt.Sprintf("incompatible schema change of %v: %v", e.Source, strings.Join(lines, "; "))
+}
+
+// Is matches ErrIncompatibleSchema
+func (e *IncompatibleSchemaError) Is(target error) bool {
+	return target == ErrIncompatibleSchema
+}
+
+// createKeyword matches CREATE of a statement with the leading comments
+var createKeyword = regexp.MustCompile(`(?is)^((?:\s|--[^\n]*\n|/\*.*?\*/)*CREATE)\s+`)
+
+// replacedTypes are the source types of the statements of ReplaceSource
+var replacedTypes = map[parser.StatementKind]string{
+	parser.KindCreateStream:   "STREAM",
+	parser.KindCreateStreamAs: "STREAM",
+	parser.KindCreateTable:    "TABLE",
+	parser.KindCreateTableAs:  "TABLE",
+}
+
+// ReplaceSource replaces the existing stream or table with the CREATE STREAM
+// or TABLE statement; OR REPLACE is added if the statement doesn't have it.
+//
+// Before the statement is executed, the schema of the source is compared
+// with the schema of the statement, see DiffSchema. The column types of
+// CREATE ... AS SELECT are explained by the server; statements without
+// columns, which infer them from the schema registry, are not compared.
+// Incompatible changes are returned as *IncompatibleSchemaError with the
+// diff, which matches ErrIncompatibleSchema. Otherwise the statement is
+// executed, ReplaceSource waits until the command is completed and returns
+// the new schema.
+func (api *KsqldbClient) ReplaceSource(ctx context.Context, stmnt string) (*SourceSchema, error) {
+	nodes, err := parser.Parse(stmnt)
+	if err != nil {
+		return nil, fmt.Errorf("can't replace source: %w", err)
+	}
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("can't replace source: %v statements, one expected", len(nodes))
+	}
+	node := nodes[0]
+	typ, ok := replacedTypes[node.Kind]
+	if !ok {
+		return nil, fmt.Errorf("can't replace source: %v is not a CREATE STREAM or TABLE statement", node.Kind)
+	}
+	name := node.Target.Name
+	if node.IfNotExists {
+		return nil, fmt.Errorf("can't replace %v: IF NOT EXISTS can't be replaced", name)
+	}
+	if !node.OrReplace {
+		stmnt = createKeyword.ReplaceAllString(stmnt, "$1 OR REPLACE ")
+	}
+
+	existing, err := api.SourceSchema(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("can't replace %v: %w", name, err)
+	}
+	if existing.Type != typ {
+		return nil, fmt.Errorf("can't replace %v: %w: %v can't be replaced by a %v", name, ErrIncompatibleSchema, existing.Type, typ)
+	}
+	columns, err := api.statementColumns(ctx, node, stmnt)
+	if err != nil {
+		return nil, fmt.Errorf("can't replace %v: %w", name, err)
+	}
+	if columns != nil {
+		changes := DiffSchema(existing.Columns, columns)
+		for _, change := range changes {
+			if !change.Compatible {
+				return nil, &IncompatibleSchemaError{Source: name, Changes: changes}
+			}
+		}
+	}
+
+	response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+	if err != nil {
+		return nil, fmt.Errorf("can't replace %v: %w", name, err)
+	}
+	if len(*response) == 0 {
+		return nil, fmt.Errorf("can't replace %v: %w: no command status", name, ErrNotFound)
+	}
+	if _, err := api.waitForCommand(ctx, (*response)[0]); err != nil {
+		return nil, fmt.Errorf("can't replace %v: %w", name, err)
+	}
+	return api.SourceSchema(ctx, name)
+}
+
+// statementColumns returns the columns of the source created by the
+// statement, the columns of CREATE ... AS SELECT are explained by the server.
+// It returns nil for statements without columns, their schema is inferred
+// from the schema registry.
+func (api *KsqldbClient) statementColumns(ctx context.Context, node *parser.StatementNode, stmnt string) ([]SchemaColumn, error) {
+	columns := []SchemaColumn{}
+	if node.Query != nil {
+		explained, err := api.explain(ctx, ExecOptions{}, stmnt)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range explained.QueryDescription.Fields {
+			columns = append(columns, SchemaColumn{Name: field.Name, Key: field.Type == "KEY", Schema: field.Schema})
+		}
+		return columns, nil
+	}
+	if len(node.Columns) == 0 {
+		return nil, nil
+	}
+	for _, col := range node.Columns {
+		schema, err := ParseSchema(col.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %w", col.Name, err)
+		}
+		columns = append(columns, SchemaColumn{Name: col.Name, Key: col.Key, Schema: schema})
+	}
+	return columns, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestReplaceSource(t *testing.T) {
+	replaced := "CREATE OR REPLACE STREAM DOGS (ID STRING KEY, AGE INT, TAGS ARRAY<VARCHAR>, NAME STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');"
+	m := mockKsql(t, []string{"DESCRIBE DOGS;", replaced, "DESCRIBE DOGS;"}, []string{
+		describeDogs,
+		`[{"@type":"currentStatus","commandId":"stream/DOGS/create","commandStatus":{"status":"SUCCESS","message":"Stream created"}}]`,
+		describeDogs,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	schema, err := kcl.ReplaceSource(context.Background(), "CREATE STREAM DOGS (ID STRING KEY, AGE INT, TAGS ARRAY<VARCHAR>, NAME STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');")
+	require.Nil(t, err)
+	require.Equal(t, "DOGS", schema.Name)
+	m.AssertExpectations(t)
+}
+
+func TestReplaceSource_Incompatible(t *testing.T) {
+	m := mockKsql(t, []string{"DESCRIBE DOGS;"}, []string{describeDogs})
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.ReplaceSource(context.Background(), "CREATE OR REPLACE STREAM DOGS (ID STRING KEY, AGE BIGINT, NAME STRING KEY, BREED STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');")
+	require.True(t, errors.Is(err, ksqldb.ErrIncompatibleSchema))
+	require.Equal(t, "incompatible schema change of DOGS: ~ AGE INTEGER -> BIGINT; - TAGS ARRAY<STRING>; + NAME STRING KEY", err.Error())
+	var incompatible *ksqldb.IncompatibleSchemaError
+	require.True(t, errors.As(err, &incompatible))
+	require.Equal(t, []ksqldb.SchemaChange{
+		{Kind: ksqldb.COLUMN_TYPE_CHANGED, Column: "AGE", Old: "INTEGER", New: "BIGINT"},
+		{Kind: ksqldb.COLUMN_REMOVED, Column: "TAGS", Old: "ARRAY<STRING>"},
+		{Kind: ksqldb.COLUMN_ADDED, Column: "NAME", New: "STRING KEY"},
+		{Kind: ksqldb.COLUMN_ADDED, Column: "BREED", New: "STRING", Compatible: true},
+	}, incompatible.Changes)
+	m.AssertExpectations(t)
+}
+
+func TestReplaceSource_AsSelect(t *testing.T) {
+	stmnt := "CREATE OR REPLACE TABLE DOGS_BY_SIZE AS SELECT DOG_SIZE, COUNT(*) AS DOGS_CT, AVG(AGE) AS AGE FROM DOGS GROUP BY DOG_SIZE EMIT CHANGES;"
+	m := mockKsql(t, []string{"DESCRIBE DOGS_BY_SIZE;", "EXPLAIN " + stmnt, stmnt, "DESCRIBE DOGS_BY_SIZE;"}, []string{
+		describeDogsBySize,
+		`[{"@type":"queryDescription","queryDescription":{"fields":[{"name":"DOG_SIZE","schema":{"type":"STRING"},"type":"KEY"},` +
+			`{"name":"DOGS_CT","schema":{"type":"BIGINT"}},{"name":"AGE","schema":{"type":"DOUBLE"}}],"sources":["DOGS"],"sinks":["DOGS_BY_SIZE"]}}]`,
+		`[{"@type":"currentStatus","commandId":"table/DOGS_BY_SIZE/create","commandStatus":{"status":"SUCCESS","message":"Created query with ID CTAS_DOGS_BY_SIZE_9"}}]`,
+		describeDogsBySize,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.ReplaceSource(context.Background(), stmnt)
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+}
+
+func TestReplaceSource_Invalid(t *testing.T) {
+	m := mockKsql(t, []string{"DESCRIBE DOGS;"}, []string{describeDogs})
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.ReplaceSource(context.Background(), "DROP STREAM DOGS;")
+	require.Equal(t, "can't replace source: DROP_STREAM is not a CREATE STREAM or TABLE statement", err.Error())
+
+	_, err = kcl.ReplaceSource(context.Background(), "CREATE TABLE DOGS (ID STRING PRIMARY KEY) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='JSON');")
+	require.True(t, errors.Is(err, ksqldb.ErrIncompatibleSchema))
+	require.Equal(t, "can't replace DOGS: incompatible schema: STREAM can't be replaced by a TABLE", err.Error())
+}
@@ -1,10 +1,34 @@
 package ksqldb
 
+import "context"
+
 var (
 	NewKsqlRequest        = newKsqlRequest
 	NewQueryStreamRequest = newQueryStreamRequest
+	NewQueryRequest       = newQueryRequest
 	NewCloseQueryRequest  = newCloseQueryRequest
 	HandleRequestError    = handleRequestError
 	HandleGetRequest      = handleGetRequest
 	NewPostRequest        = newPostRequest
 )
+
+var ConvertValue = convertValue
+var ReadLine = readLine
+var ParseRetryAfter = parseRetryAfter
+var DecodeLine = func(line []byte, columns int, pooled bool) (*Header, Row, error) {
+	return decodeLine(defaultDecoder{}, line, columns, pooled)
+}
+
+// RowBuffer exports the ring buffer of push queries
+type RowBuffer struct {
+	b *rowBuffer
+}
+
+func NewRowBuffer(size int, policy OverflowPolicy) RowBuffer {
+	return RowBuffer{newRowBuffer(size, policy)}
+}
+
+func (b RowBuffer) Put(ctx context.Context, row Row) bool { return b.b.put(ctx, nil, row) }
+func (b RowBuffer) Get() (Row, bool)                      { return b.b.get() }
+func (b RowBuffer) Len() int                              { return b.b.len() }
+func (b RowBuffer) Close()                                { b.b.close() }
@@ -0,0 +1,71 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+func TestExecute_ResponseErrorRequestID(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/ksql")
+	m.Mock.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"@type":"statement_error","error_code":40001,"message":"boom"}`))),
+		}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+
+	ctx := net.WithRequestID(context.Background(), "req-1")
+	_, err := kcl.SourceSchema(ctx, "DOGS")
+	require.EqualError(t, err, "can't describe DOGS: boom")
+	require.Equal(t, "req-1", ksqldb.RequestID(err))
+
+	req := m.Calls[len(m.Calls)-1].Arguments.Get(0).(*http.Request)
+	require.Equal(t, "req-1", req.Header.Get(net.REQUEST_ID_HEADER))
+}
+
+func TestPull_RequestErrorRequestID(t *testing.T) {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.Mock.On("Do", mock.Anything).Return(nil, errors.New("connection refused"))
+	kcl, _ := ksqldb.NewClient(&m)
+
+	_, _, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.EqualError(t, err, "can't do request: connection refused")
+
+	req := m.Calls[len(m.Calls)-1].Arguments.Get(0).(*http.Request)
+	id := req.Header.Get(net.REQUEST_ID_HEADER)
+	require.Len(t, id, 32)
+	require.Equal(t, id, ksqldb.RequestID(err))
+
+	var reqErr *ksqldb.RequestError
+	require.True(t, errors.As(err, &reqErr))
+	require.Equal(t, "", ksqldb.RequestID(errors.New("other")))
+}
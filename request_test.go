@@ -73,7 +73,14 @@ func TestNewPostRequest_Successful(t *testing.T) {
 }
 
 func TestNewQueryRequest(t *testing.T) {
-
+	postFn := ksqldb.NewQueryRequest
+	client, _ := net.NewHTTPClient(net.Options{}, nil)
+	r := ioutil.NopCloser(bytes.NewReader([]byte("hallo")))
+	req, err := postFn(&client, context.TODO(), r)
+	require.NotNil(t, req)
+	require.Nil(t, err)
+	require.Equal(t, "/query", req.URL.Path)
+	require.Equal(t, "application/vnd.ksql.v1+json", req.Header.Get("Accept"))
 }
 
 func TestNewQueryStreamRequest(t *testing.T) {
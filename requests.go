@@ -39,30 +39,46 @@ func newQueryStreamRequest(api net.HTTPClient, ctx context.Context, payload io.R
 	return req, err
 }
 
+// newQueryRequest returns a request of the legacy /query endpoint
+func newQueryRequest(api net.HTTPClient, ctx context.Context, payload io.Reader) (*http.Request, error) {
+	req, err := newPostRequest(api, ctx, QUERY_ENDPOINT, payload)
+	if err != nil {
+		return req, err
+	}
+	req.Header.Set("Accept", "application/vnd.ksql.v1+json")
+	return req, nil
+}
+
 func newCloseQueryRequest(api net.HTTPClient, ctx context.Context, payload io.Reader) (*http.Request, error) {
 	return newPostRequest(api, ctx, CLOSE_QUERY_ENDPOINT, payload)
 }
 
+// handleRequestError returns the ResponseError of the response with the status code.
+// Responses without an error body, ex. of proxies, get the status code only.
 func handleRequestError(code int, buf []byte) error {
 	ksqlError := ResponseError{}
 	if err := json.Unmarshal(buf, &ksqlError); err != nil {
-		return fmt.Errorf("ksqldb error: %w", err)
+		return ResponseError{StatusCode: code, Message: fmt.Sprintf("ksqldb error: %v", err)}
 	}
+	ksqlError.StatusCode = code
 	fmt.Printf("ksql: %+v\n", ksqlError)
 
+	if err := sourceInUseError(ksqlError); err != nil {
+		return err
+	}
 	return ksqlError
 }
 
 func handleGetRequest(httpClient net.HTTPClient, url string) (*[]byte, error) {
 	res, err := httpClient.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("ksqldb get request failed: %v", err)
+		return nil, fmt.Errorf("ksqldb get request failed: %w", err)
 	}
 	defer res.Body.Close()
 
 	body, readErr := ioutil.ReadAll(res.Body)
 	if readErr != nil {
-		return nil, fmt.Errorf("could not read response body: %v", readErr)
+		return nil, fmt.Errorf("could not read response body: %w", readErr)
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -3,6 +3,8 @@ package ksqldb
 type CommandStatus struct {
 	Message string
 	Status  string
+	// QueryId of the query created by the command, if any
+	QueryId string `json:"queryId,omitempty"`
 }
 
 type Stream struct {
@@ -26,24 +28,76 @@ type Query struct {
 	ID          string // The query ID
 }
 
-// this is not complete yet
+// Schema describes the type of a field
 type Schema struct {
 	Type string
-	// Fields
+	// Fields of a STRUCT
+	Fields []Field `json:"fields,omitempty"`
+	// MemberSchema of an ARRAY or the value schema of a MAP
+	MemberSchema *Schema `json:"memberSchema,omitempty"`
+	// Parameters of the type, ex. precision and scale of a DECIMAL
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
 }
 
 type Field struct {
 	Name   string
 	Schema Schema
+	// Type is KEY for key columns
+	Type string `json:"type,omitempty"`
+}
+
+// SourceDescription is returned by DESCRIBE
+type SourceDescription struct {
+	Name        string
+	Type        string
+	WindowType  string `json:"windowType,omitempty"`
+	Fields      []Field
+	KeyFormat   string
+	ValueFormat string
+	Topic       string
+	Partitions  int
+	Replication int
+	Extended    bool
+	// ReadQueries and WriteQueries are the queries reading from and writing into the source
+	ReadQueries  []RunningQuery `json:"readQueries,omitempty"`
+	WriteQueries []RunningQuery `json:"writeQueries,omitempty"`
+}
+
+// RunningQuery is a query reading from or writing into a source
+type RunningQuery struct {
+	ID          string `json:"id"`
+	QueryString string `json:"queryString"`
+	Sinks       []string
+	// QueryType is PERSISTENT or PUSH
+	QueryType string `json:"queryType,omitempty"`
+	State     string `json:"state,omitempty"`
 }
 
 type QueryDescription struct {
+	ID            string `json:"id,omitempty"`
 	StatementText string
 	Fields        []Field
 	Sources       []string
 	Sinks         []string
 	ExecutionPlan string
 	Topology      string
+	// QueryType is PERSISTENT or PUSH
+	QueryType string `json:"queryType,omitempty"`
+	// State is the aggregated state of the query, ex. RUNNING or ERROR
+	State           string `json:"state,omitempty"`
+	ConsumerGroupId string `json:"consumerGroupId,omitempty"`
+	// KsqlHostQueryStatus is the state of the query by host, ex. {"ksqldb-0:8088": "RUNNING"}
+	KsqlHostQueryStatus map[string]string `json:"ksqlHostQueryStatus,omitempty"`
+	QueryErrors         []QueryError      `json:"queryErrors,omitempty"`
+}
+
+// QueryError is an error of a persistent query
+type QueryError struct {
+	// Timestamp in epoch milliseconds
+	Timestamp    int64
+	ErrorMessage string
+	// Type is USER, SYSTEM or UNKNOWN
+	Type string
 }
 
 type KsqlResponseSlice []KsqlResponse
@@ -54,12 +108,15 @@ type QuerySlice []Query
 type KsqlResponse struct {
 	StatementText         string
 	Warnings              []string
-	Type                  string            `json:"@type"`
-	CommandId             string            `json:"commandId,omitempty"`
-	CommandSequenceNumber int64             `json:"commandSequenceNumber,omitempty"` // -1 if the operation was unsuccessful
-	CommandStatus         CommandStatus     `json:"commandStatus,omitempty"`
-	Stream                *StreamSlice      `json:"streams,omitempty"`
-	Tables                *TableSlice       `json:"tables,omitempty"`
-	Queries               *QuerySlice       `json:"queries,omitempty"`
-	QueryDescription      *QueryDescription `json:"queryDescription,omitempty"`
+	Type                  string              `json:"@type"`
+	CommandId             string              `json:"commandId,omitempty"`
+	CommandSequenceNumber int64               `json:"commandSequenceNumber,omitempty"` // -1 if the operation was unsuccessful
+	CommandStatus         CommandStatus       `json:"commandStatus,omitempty"`
+	Stream                *StreamSlice        `json:"streams,omitempty"`
+	Tables                *TableSlice         `json:"tables,omitempty"`
+	Topics                *TopicSlice         `json:"topics,omitempty"`
+	Queries               *QuerySlice         `json:"queries,omitempty"`
+	QueryDescription      *QueryDescription   `json:"queryDescription,omitempty"`
+	QueryDescriptions     *[]QueryDescription `json:"queryDescriptions,omitempty"`
+	SourceDescription     *SourceDescription  `json:"sourceDescription,omitempty"`
 }
@@ -0,0 +1,162 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+// DEFAULT_RETRY_BACKOFF is the default wait before the first retry of responses without Retry-After
+const DEFAULT_RETRY_BACKOFF = 100 * time.Millisecond
+
+// RetryPolicy retries statements and pull queries which were rejected by the
+// server or a proxy with the status 429 (too many requests) or 503 (service
+// unavailable), see SetRetryPolicy. The Retry-After of the response is honored.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a request; 0 disables retries
+	MaxRetries int
+	// Backoff is the wait before the first retry of responses without Retry-After,
+	// it is doubled with every retry; defaults to DEFAULT_RETRY_BACKOFF
+	Backoff time.Duration
+	// MaxWait is the maximum wait before a retry; responses asking to wait longer
+	// aren't retried. 0 is unlimited, the context of the request still applies.
+	MaxWait time.Duration
+}
+
+// SetRetryPolicy sets the retry policy of statements and pull queries, they
+// aren't retried by default. Rejected requests aren't applied by the server,
+// so statements are retried too. The timeouts of the client, see SetTimeouts,
+// include the retries.
+//
+// The ResponseError of the last response has the Retry-After in RetryAfter.
+func (cl *KsqldbClient) SetRetryPolicy(policy RetryPolicy) {
+	cl.retryPolicy = policy
+}
+
+// RetryPolicy returns the retry policy of the client
+func (cl *KsqldbClient) RetryPolicy() RetryPolicy {
+	return cl.retryPolicy
+}
+
+// wait returns the wait before the retry of the failed attempt; false if the request isn't retried
+func (p RetryPolicy) wait(attempt int, err error) (time.Duration, bool) {
+	var respErr ResponseError
+	if attempt >= p.MaxRetries || !errors.As(err, &respErr) {
+		return 0, false
+	}
+	if respErr.StatusCode != http.StatusTooManyRequests && respErr.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	wait := respErr.RetryAfter
+	if wait == 0 {
+		wait = p.Backoff
+		if wait <= 0 {
+			wait = DEFAULT_RETRY_BACKOFF
+		}
+		wait <<= uint(attempt)
+	}
+	if p.MaxWait > 0 && wait > p.MaxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// parseRetryAfter parses the Retry-After header, ex. 120 or Wed, 21 Oct 2015 07:28:00 GMT;
+// 0 if the header is missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
+
+// do sends the requests built by newRequest until it succeeds or the retry policy
+// gives up and returns the body of the response
+func (api *KsqldbClient) do(ctx context.Context, newRequest func() (*http.Request, error)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		body, err := api.doOnce(req)
+		wait, retry := api.retryPolicy.wait(attempt, err)
+		if !retry {
+			return body, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce sends the request and returns the body of the response
+func (api *KsqldbClient) doOnce(req *http.Request) ([]byte, error) {
+	id := net.SetRequestID(req)
+	res, err := api.http.Do(req)
+	api.state.report(res, err)
+	if err != nil {
+		return nil, fmt.Errorf("can't do request: %w", withRequestID(err, id))
+	}
+	defer res.Body.Close()
+
+	body, err := api.readResponse(res)
+	if err != nil {
+		return nil, fmt.Errorf("can't read response body: %w", withRequestID(err, id))
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, withRequestID(responseError(res, body), id)
+	}
+	return body, nil
+}
+
+// responseError returns the error of the failed response with its Retry-After
+func responseError(res *http.Response, body []byte) error {
+	err := handleRequestError(res.StatusCode, body)
+	retryAfter := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	switch e := err.(type) {
+	case ResponseError:
+		e.RetryAfter = retryAfter
+		return e
+	case *SourceInUseError:
+		e.Response.RetryAfter = retryAfter
+	}
+	return err
+}
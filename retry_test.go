@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// unavailable returns a 503 response with the Retry-After
+func unavailable(retryAfter string) func(*http.Request) *http.Response {
+	return func(*http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Retry-After": []string{retryAfter}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"@type":"generic_error","error_code":50300,"message":"busy"}`)),
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	require.Equal(t, 120*time.Second, ksqldb.ParseRetryAfter("120", now))
+	require.Equal(t, 30*time.Second, ksqldb.ParseRetryAfter("Wed, 21 Oct 2015 07:28:30 GMT", now))
+	require.Equal(t, time.Duration(0), ksqldb.ParseRetryAfter("Wed, 21 Oct 2015 07:27:00 GMT", now))
+	require.Equal(t, time.Duration(0), ksqldb.ParseRetryAfter("", now))
+	require.Equal(t, time.Duration(0), ksqldb.ParseRetryAfter("soon", now))
+}
+
+func TestPull_RetryAfter(t *testing.T) {
+	m := mockKsql(t, []string{}, []string{})
+	m.Mock.On("Do", matchStatement(t, "select * from dogs;")).Return(unavailable("0"), nil).Once()
+	m.Mock.On("Do", matchStatement(t, "select * from dogs;")).Return(pullResponse(`[{"queryId":null,"columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`), nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetRetryPolicy(ksqldb.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+	require.Equal(t, 2, kcl.RetryPolicy().MaxRetries)
+
+	_, rows, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.Nil(t, err)
+	require.Len(t, rows, 1)
+	m.AssertExpectations(t)
+}
+
+func TestExecute_RetryAfterExceedsMaxWait(t *testing.T) {
+	m := mockKsql(t, []string{}, []string{})
+	m.Mock.On("Do", mock.Anything).Return(unavailable("60"), nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetRetryPolicy(ksqldb.RetryPolicy{MaxRetries: 3, MaxWait: time.Second})
+
+	_, err := kcl.Execute(ksqldb.ExecOptions{KSql: "SHOW STREAMS;"})
+	var respErr ksqldb.ResponseError
+	require.True(t, errors.As(err, &respErr))
+	require.Equal(t, http.StatusServiceUnavailable, respErr.StatusCode)
+	require.Equal(t, time.Minute, respErr.RetryAfter)
+	m.AssertExpectations(t)
+}
+
+func TestExecute_RetriesExhausted(t *testing.T) {
+	m := mockKsql(t, []string{}, []string{})
+	m.Mock.On("Do", mock.Anything).Return(unavailable(""), nil).Times(3)
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetRetryPolicy(ksqldb.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+
+	_, err := kcl.Execute(ksqldb.ExecOptions{KSql: "SHOW STREAMS;"})
+	require.EqualError(t, err, "busy")
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,269 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go/internal"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// SetPullHosts sets the base urls of the hosts of the ksqlDB cluster for pull query routing.
+// Pull queries with a WHERE clause are routed by their key predicate with
+// rendezvous hashing: lookups of the same key always go to the same host,
+// which improves the state store locality and reduces forwarding inside the
+// cluster. Other pull queries use the base url of the client.
+// No hosts disable the routing.
+func (cl *KsqldbClient) SetPullHosts(hosts ...string) error {
+	var urls []*url.URL
+	for _, host := range hosts {
+		u, err := internal.GetUrl(host)
+		if err != nil {
+			return fmt.Errorf("invalid pull host %v: %w", host, err)
+		}
+		urls = append(urls, u)
+	}
+	cl.pullHosts = urls
+	return nil
+}
+
+// PullHosts returns the base urls of the pull query hosts
+func (cl *KsqldbClient) PullHosts() []string {
+	var hosts []string
+	for _, u := range cl.pullHosts {
+		hosts = append(hosts, u.String())
+	}
+	return hosts
+}
+
+// DEFAULT_LAG_REFRESH_INTERVAL is the default refresh interval of the cluster status of lag-aware routing
+const DEFAULT_LAG_REFRESH_INTERVAL = 10 * time.Second
+
+// DEFAULT_LAG_REFRESH_TIMEOUT is the default timeout of the cluster status of lag-aware routing
+const DEFAULT_LAG_REFRESH_TIMEOUT = 2 * time.Second
+
+// LagRoutingOptions configures lag-aware pull query routing, see SetLagRouting
+type LagRoutingOptions struct {
+	// MaxAllowedOffsetLag excludes hosts with a larger state store offset lag,
+	// like ksql.query.pull.max.allowed.offset.lag; 0 means no limit
+	MaxAllowedOffsetLag uint64
+	// RefreshInterval of the cluster status; defaults to DEFAULT_LAG_REFRESH_INTERVAL
+	RefreshInterval time.Duration
+	// RefreshTimeout of the cluster status; defaults to DEFAULT_LAG_REFRESH_TIMEOUT
+	RefreshTimeout time.Duration
+}
+
+// SetLagRouting enables lag-aware routing of pull queries over the pull hosts, see SetPullHosts.
+// The offset lags of the hosts are taken from the cluster status, which is refreshed
+// in the background by pull queries after the RefreshInterval, a single refresh at a
+// time bound to the RefreshTimeout. Dead hosts and hosts lagging more than
+// MaxAllowedOffsetLag are skipped, unless all hosts would be skipped. Queries without
+// routing key go to the least lagging host. nil disables lag-aware routing.
+func (cl *KsqldbClient) SetLagRouting(options *LagRoutingOptions) {
+	if options == nil {
+		cl.lagRouting = nil
+		return
+	}
+	if options.RefreshInterval <= 0 {
+		options.RefreshInterval = DEFAULT_LAG_REFRESH_INTERVAL
+	}
+	if options.RefreshTimeout <= 0 {
+		options.RefreshTimeout = DEFAULT_LAG_REFRESH_TIMEOUT
+	}
+	cl.lagRouting = &lagCache{options: *options}
+}
+
+// hostLag is the state of a host in the cluster status
+type hostLag struct {
+	alive bool
+	// lag is the max offset lag of the state stores of the host
+	lag uint64
+}
+
+// lagCache caches the host lags of the cluster status
+type lagCache struct {
+	options LagRoutingOptions
+
+	mu      sync.Mutex
+	lags    map[string]hostLag
+	fetched time.Time
+	// refreshing is closed when the running refresh of the cluster status is done
+	refreshing chan struct{}
+}
+
+// hostLags returns the lags by host:port; the last known lags if the cluster status fails.
+// Stale lags are returned while the cluster status is refreshed, only the first
+// queries wait for it, at most until ctx is done.
+func (c *lagCache) hostLags(ctx context.Context, api *KsqldbClient) map[string]hostLag {
+	c.mu.Lock()
+	lags := c.lags
+	if lags != nil && time.Since(c.fetched) < c.options.RefreshInterval {
+		c.mu.Unlock()
+		return lags
+	}
+	done := c.refreshing
+	if done == nil {
+		done = make(chan struct{})
+		c.refreshing = done
+		go c.refresh(api, done)
+	}
+	c.mu.Unlock()
+
+	if lags != nil {
+		return lags
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lags
+}
+
+// refresh gets the cluster status, it is shared by all queries and bound to the RefreshTimeout
+func (c *lagCache) refresh(api *KsqldbClient, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.options.RefreshTimeout)
+	defer cancel()
+	status, err := api.getClusterStatus(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetched = time.Now()
+	c.refreshing = nil
+	close(done)
+	if err != nil {
+		return
+	}
+	lags := map[string]hostLag{}
+	for host, node := range status.ClusterStatus.Host {
+		hl := hostLag{alive: node.HostAlive}
+		for _, store := range node.HostStoreLags.StateStoreLags {
+			for _, partition := range store.LagByPartition {
+				if partition.Partition.OffsetLag > hl.lag {
+					hl.lag = partition.Partition.OffsetLag
+				}
+			}
+		}
+		lags[host] = hl
+	}
+	c.lags = lags
+}
+
+// eligibleHosts returns the alive hosts within the max allowed lag; all hosts if none is.
+// Hosts missing in the cluster status are eligible.
+func (c *lagCache) eligibleHosts(hosts []*url.URL, lags map[string]hostLag) []*url.URL {
+	var eligible []*url.URL
+	for _, host := range hosts {
+		hl, ok := lags[host.Host]
+		if !ok || hl.alive && (c.options.MaxAllowedOffsetLag == 0 || hl.lag <= c.options.MaxAllowedOffsetLag) {
+			eligible = append(eligible, host)
+		}
+	}
+	if len(eligible) == 0 {
+		return hosts
+	}
+	return eligible
+}
+
+// leastLagging returns the host with the smallest known lag
+func leastLagging(hosts []*url.URL, lags map[string]hostLag) *url.URL {
+	best := hosts[0]
+	for _, host := range hosts[1:] {
+		hl, ok := lags[host.Host]
+		bestLag, bestOk := lags[best.Host]
+		if ok && (!bestOk || hl.lag < bestLag.lag) {
+			best = host
+		}
+	}
+	return best
+}
+
+// routePull sends the pull query request to the host of its routing key
+func (api *KsqldbClient) routePull(req *http.Request, options QueryOptions) {
+	if len(api.pullHosts) == 0 {
+		return
+	}
+	key := options.RoutingKey
+	if key == "" {
+		key = pullRoutingKey(options.Sql)
+	}
+
+	hosts := api.pullHosts
+	var lags map[string]hostLag
+	if api.lagRouting != nil {
+		lags = api.lagRouting.hostLags(req.Context(), api)
+		hosts = api.lagRouting.eligibleHosts(hosts, lags)
+	}
+
+	var host *url.URL
+	switch {
+	case key != "":
+		host = affinityHost(hosts, key)
+	case api.lagRouting != nil:
+		host = leastLagging(hosts, lags)
+	default:
+		return
+	}
+	req.URL.Scheme = host.Scheme
+	req.URL.Host = host.Host
+	req.Host = host.Host
+}
+
+// pullRoutingKey returns the canonical WHERE clause of the query; empty if it has none
+func pullRoutingKey(sql string) string {
+	stmnts, err := parser.Parse(sql)
+	if err != nil || len(stmnts) != 1 || stmnts[0].Query == nil {
+		return ""
+	}
+	return stmnts[0].Query.Where
+}
+
+// affinityHost returns the host with the highest rendezvous hash of the key,
+// so only the keys of a removed host move to other hosts
+func affinityHost(hosts []*url.URL, key string) *url.URL {
+	var best *url.URL
+	var bestScore uint64
+	for _, host := range hosts {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(host.Host))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key))
+		if score := mix64(h.Sum64()); best == nil || score > bestScore {
+			best, bestScore = host, score
+		}
+	}
+	return best
+}
+
+// mix64 is the murmur3 finalizer, fnv alone spreads similar keys badly
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestPull_HostAffinity(t *testing.T) {
+	var hosts []string
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return("http://localhost:8088/query-stream")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		hosts = append(hosts, req.URL.Host)
+		body := `[{"queryId":null,"columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	require.Nil(t, kcl.SetPullHosts("http://ksqldb-0:8088", "http://ksqldb-1:8088", "http://ksqldb-2:8088"))
+	require.Equal(t, []string{"http://ksqldb-0:8088", "http://ksqldb-1:8088", "http://ksqldb-2:8088"}, kcl.PullHosts())
+
+	pull := func(sql string, routingKey string) string {
+		_, _, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: sql, RoutingKey: routingKey})
+		require.Nil(t, err)
+		return hosts[len(hosts)-1]
+	}
+
+	// the same key goes to the same host
+	host := pull("select * from dogs where id = '1';", "")
+	require.Equal(t, host, pull("SELECT *   FROM dogs WHERE ID = '1';", ""))
+	require.Equal(t, host, pull("select name from dogs where id = '1';", ""))
+
+	// keys are spread over the hosts
+	used := map[string]bool{}
+	for i := 0; i < 30; i++ {
+		used[pull(fmt.Sprintf("select * from dogs where id = '%v';", i), "")] = true
+	}
+	require.Len(t, used, 3)
+
+	// queries without key use the base url
+	require.Equal(t, "localhost:8088", pull("select * from dogs;", ""))
+	require.Equal(t, host, pull("select * from dogs;", "ID = '1'"))
+
+	require.NotNil(t, kcl.SetPullHosts("ksqldb-0"))
+	require.Nil(t, kcl.SetPullHosts())
+	require.Equal(t, "localhost:8088", pull("select * from dogs where id = '1';", ""))
+}
+
+const lagClusterStatus = `{"clusterStatus":{
+	"ksqldb-0:8088":{"hostAlive":true,"hostStoreLags":{"stateStoreLags":{"store":{"lagByPartition":{"0":{"offsetLag":0},"1":{"offsetLag":3}}}}}},
+	"ksqldb-1:8088":{"hostAlive":true,"hostStoreLags":{"stateStoreLags":{"store":{"lagByPartition":{"0":{"offsetLag":500}}}}}},
+	"ksqldb-2:8088":{"hostAlive":false,"hostStoreLags":{"stateStoreLags":{}}}
+}}`
+
+func TestPull_LagRouting(t *testing.T) {
+	var hosts []string
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return("http://localhost:8088/query-stream")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(lagClusterStatus))}
+		}
+		hosts = append(hosts, req.URL.Host)
+		body := `[{"queryId":null,"columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	require.Nil(t, kcl.SetPullHosts("http://ksqldb-0:8088", "http://ksqldb-1:8088", "http://ksqldb-2:8088"))
+
+	used := func(n int) map[string]bool {
+		hosts = nil
+		for i := 0; i < n; i++ {
+			_, _, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: fmt.Sprintf("select * from dogs where id = '%v';", i)})
+			require.Nil(t, err)
+		}
+		_, _, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+		require.Nil(t, err)
+		used := map[string]bool{}
+		for _, host := range hosts {
+			used[host] = true
+		}
+		return used
+	}
+
+	// the dead host is skipped, queries without key go to the least lagging host
+	kcl.SetLagRouting(&ksqldb.LagRoutingOptions{})
+	require.Equal(t, map[string]bool{"ksqldb-0:8088": true, "ksqldb-1:8088": true}, used(30))
+	require.Equal(t, "ksqldb-0:8088", hosts[len(hosts)-1])
+
+	// the lagging host is skipped
+	kcl.SetLagRouting(&ksqldb.LagRoutingOptions{MaxAllowedOffsetLag: 100})
+	require.Equal(t, map[string]bool{"ksqldb-0:8088": true}, used(30))
+
+	// the cluster status is cached
+	gets := 0
+	for _, call := range m.Calls {
+		if call.Method == "Do" && call.Arguments.Get(0).(*http.Request).Method == http.MethodGet {
+			gets++
+		}
+	}
+	require.Equal(t, 2, gets)
+
+	// all hosts and the base url without lag routing
+	kcl.SetLagRouting(nil)
+	require.Len(t, used(30), 4)
+}
+
+func TestPull_LagRoutingStalledStatus(t *testing.T) {
+	var gets int32
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return("http://localhost:8088/query-stream")
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+			<-req.Context().Done()
+			return nil
+		}
+		body := `[{"queryId":null,"columnNames":["ID"],"columnTypes":["STRING"]},["1"]]`
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, func(req *http.Request) error {
+		return req.Context().Err()
+	})
+	kcl, _ := ksqldb.NewClient(&m)
+	require.Nil(t, kcl.SetPullHosts("http://ksqldb-0:8088", "http://ksqldb-1:8088"))
+	kcl.SetLagRouting(&ksqldb.LagRoutingOptions{RefreshTimeout: 100 * time.Millisecond})
+
+	// concurrent queries share a single refresh, which is given up after the timeout
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: fmt.Sprintf("select * from dogs where id = '%v';", i)})
+			require.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	require.Equal(t, int32(1), atomic.LoadInt32(&gets))
+
+	// a query doesn't wait for the refresh longer than its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	kcl.SetLagRouting(&ksqldb.LagRoutingOptions{RefreshTimeout: time.Minute})
+	start = time.Now()
+	_, _, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "select * from dogs where id = '1';"})
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	require.NotNil(t, err)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync"
+)
+
+// OverflowPolicy is applied if the row buffer of a push query is full
+type OverflowPolicy int
+
+const (
+	// OVERFLOW_BLOCK stops reading the response until the consumer catches up
+	OVERFLOW_BLOCK OverflowPolicy = iota
+	// OVERFLOW_DROP_OLDEST drops the oldest buffered row
+	OVERFLOW_DROP_OLDEST
+	// OVERFLOW_DROP_NEWEST drops the received row
+	OVERFLOW_DROP_NEWEST
+)
+
+// SetRowBuffer buffers up to size rows of push queries between the response
+// reader and the row channel, so a slow consumer doesn't stall the stream
+// immediately. If the buffer is full the policy is applied; dropped rows are
+// counted in QueryMetrics.DroppedRows. size < 1 disables the buffer (default).
+func (cl *KsqldbClient) SetRowBuffer(size int, policy OverflowPolicy) {
+	cl.rowBufferSize = size
+	cl.overflowPolicy = policy
+}
+
+// RowBuffer returns the row buffer size and the overflow policy of push queries
+func (cl *KsqldbClient) RowBuffer() (int, OverflowPolicy) {
+	return cl.rowBufferSize, cl.overflowPolicy
+}
+
+// rowBuffer is a bounded ring buffer of rows
+type rowBuffer struct {
+	mu     sync.Mutex
+	rows   []Row
+	head   int
+	count  int
+	closed bool
+	policy OverflowPolicy
+	// added and removed signal waiting readers and writers, capacity 1
+	added   chan struct{}
+	removed chan struct{}
+}
+
+func newRowBuffer(size int, policy OverflowPolicy) *rowBuffer {
+	return &rowBuffer{
+		rows:    make([]Row, size),
+		policy:  policy,
+		added:   make(chan struct{}, 1),
+		removed: make(chan struct{}, 1),
+	}
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// len returns the number of buffered rows
+func (b *rowBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// put adds the row to the buffer. It returns true if a row was dropped.
+// With OVERFLOW_BLOCK it waits for free space unless the query is cancelled or stopping.
+func (b *rowBuffer) put(ctx context.Context, stopping <-chan struct{}, row Row) bool {
+	for {
+		b.mu.Lock()
+		if b.count < len(b.rows) {
+			b.rows[(b.head+b.count)%len(b.rows)] = row
+			b.count++
+			b.mu.Unlock()
+			signal(b.added)
+			return false
+		}
+		switch b.policy {
+		case OVERFLOW_DROP_NEWEST:
+			b.mu.Unlock()
+			return true
+		case OVERFLOW_DROP_OLDEST:
+			b.rows[b.head] = row
+			b.head = (b.head + 1) % len(b.rows)
+			b.mu.Unlock()
+			return true
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-b.removed:
+		case <-ctx.Done():
+			return false
+		case <-stopping:
+			return false
+		}
+	}
+}
+
+// get removes the oldest row from the buffer. It waits for a row until the
+// buffer is closed; ok is false if the buffer is closed and empty.
+func (b *rowBuffer) get() (row Row, ok bool) {
+	for {
+		b.mu.Lock()
+		if b.count > 0 {
+			row = b.rows[b.head]
+			b.rows[b.head] = nil
+			b.head = (b.head + 1) % len(b.rows)
+			b.count--
+			b.mu.Unlock()
+			signal(b.removed)
+			return row, true
+		}
+		closed := b.closed
+		b.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+		<-b.added
+	}
+}
+
+// close closes the buffer, the buffered rows can still be read
+func (b *rowBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	signal(b.added)
+}
+
+// forward delivers the buffered rows to the row channel until the buffer is closed
+func (b *rowBuffer) forward(ctx context.Context, stopping <-chan struct{}, rowChannel chan<- Row) {
+	for {
+		row, ok := b.get()
+		if !ok {
+			return
+		}
+		deliverRow(ctx, stopping, rowChannel, row)
+	}
+}
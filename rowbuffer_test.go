@@ -0,0 +1,119 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestRowBuffer_Policies(t *testing.T) {
+	tests := []struct {
+		policy  ksqldb.OverflowPolicy
+		dropped []bool
+		want    []ksqldb.Row
+	}{
+		{ksqldb.OVERFLOW_DROP_OLDEST, []bool{false, false, true}, []ksqldb.Row{{"b"}, {"c"}}},
+		{ksqldb.OVERFLOW_DROP_NEWEST, []bool{false, false, true}, []ksqldb.Row{{"a"}, {"b"}}},
+	}
+	for _, tt := range tests {
+		b := ksqldb.NewRowBuffer(2, tt.policy)
+		for idx, row := range []ksqldb.Row{{"a"}, {"b"}, {"c"}} {
+			require.Equal(t, tt.dropped[idx], b.Put(context.Background(), row))
+		}
+		require.Equal(t, 2, b.Len())
+		b.Close()
+		for _, want := range tt.want {
+			row, ok := b.Get()
+			require.True(t, ok)
+			require.Equal(t, want, row)
+		}
+		_, ok := b.Get()
+		require.False(t, ok)
+	}
+}
+
+func TestRowBuffer_Block(t *testing.T) {
+	b := ksqldb.NewRowBuffer(1, ksqldb.OVERFLOW_BLOCK)
+	require.False(t, b.Put(context.Background(), ksqldb.Row{"a"}))
+
+	put := make(chan bool)
+	go func() { put <- b.Put(context.Background(), ksqldb.Row{"b"}) }()
+	select {
+	case <-put:
+		t.Fatal("put doesn't block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	row, _ := b.Get()
+	require.Equal(t, ksqldb.Row{"a"}, row)
+	require.False(t, <-put)
+	row, _ = b.Get()
+	require.Equal(t, ksqldb.Row{"b"}, row)
+
+	// cancelled queries don't block
+	require.False(t, b.Put(context.Background(), ksqldb.Row{"c"}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, b.Put(ctx, ksqldb.Row{"d"}))
+	require.Equal(t, 1, b.Len())
+}
+
+func TestPush_RowBuffer(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetRowBuffer(2, ksqldb.OVERFLOW_DROP_NEWEST)
+	size, policy := kcl.RowBuffer()
+	require.Equal(t, 2, size)
+	require.Equal(t, ksqldb.OVERFLOW_DROP_NEWEST, policy)
+
+	metrics := ksqldb.NewQueryMetrics()
+	ctx, cancel := context.WithCancel(ksqldb.WithQueryMetrics(context.Background(), metrics))
+	defer cancel()
+	rowChannel := make(chan ksqldb.Row)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, nil)
+	}()
+
+	m.send(streamHeader)
+	for i := 0; i < 10; i++ {
+		m.send(fmt.Sprintf(`["%v"]`, i))
+	}
+	// nobody receives, the rows above the buffer are dropped
+	require.Eventually(t, func() bool { return metrics.RowsReceived() == 10 }, time.Second, time.Millisecond)
+	require.True(t, metrics.DroppedRows() >= 7)
+
+	// the buffered rows are delivered in order
+	var received int64
+	last := -1
+	go m.stream.Close()
+	for row := range rowChannel {
+		var n int
+		fmt.Sscan(row[0].(string), &n)
+		require.True(t, n > last)
+		last = n
+		received++
+	}
+	require.Nil(t, <-done)
+	require.Equal(t, int64(10), received+metrics.DroppedRows())
+	require.Equal(t, metrics.DroppedRows(), metrics.Stats().DroppedRows)
+}
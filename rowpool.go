@@ -0,0 +1,66 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import "sync"
+
+// rowPool holds released rows for reuse
+var rowPool = sync.Pool{}
+
+// EnableRowPool enables / disables pooled rows.
+// With pooled rows Push and Subscribe take the rows from a pool; the consumer
+// must call Row.Release when done with a row and must not use it afterwards.
+// Disabled by default, every row is a new slice then.
+func (cl *KsqldbClient) EnableRowPool(activate bool) {
+	cl.rowPool = activate
+}
+
+// RowPoolEnabled returns true if pooled rows are enabled; false otherwise
+func (cl *KsqldbClient) RowPoolEnabled() bool {
+	return cl.rowPool
+}
+
+// newRow returns an empty row with the capacity for the given columns.
+// The row is taken from the pool if pooled is true.
+func newRow(columns int, pooled bool) Row {
+	if pooled {
+		if row, ok := rowPool.Get().(*Row); ok && cap(*row) >= columns {
+			return (*row)[:0]
+		}
+	}
+	return make(Row, 0, columns)
+}
+
+// copyRow returns a copy of the row, it is taken from the pool if pooled is true
+func copyRow(row Row, pooled bool) Row {
+	return append(newRow(len(row), pooled), row...)
+}
+
+// Release returns the row to the pool for reuse by pooled push queries,
+// see EnableRowPool. The values of the row are cleared, so neither the row
+// nor slices of it may be used after Release.
+func (r Row) Release() {
+	if cap(r) == 0 {
+		return
+	}
+	r = r[:cap(r)]
+	for idx := range r {
+		r[idx] = nil
+	}
+	r = r[:0]
+	rowPool.Put(&r)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+func TestClient_EnableRowPool(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(&mocknet.HTTPClient{})
+	require.False(t, kcl.RowPoolEnabled())
+	kcl.EnableRowPool(true)
+	require.True(t, kcl.RowPoolEnabled())
+}
+
+func TestRow_Release(t *testing.T) {
+	row := ksqldb.Row{"a", 1.5}
+	row.Release()
+	require.Equal(t, ksqldb.Row{nil, nil}, row)
+
+	// no-op
+	ksqldb.Row(nil).Release()
+}
+
+func TestPush_RowPool(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableRowPool(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rowChannel := make(chan ksqldb.Row)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(ctx, "select * from dogs emit changes;", rowChannel, nil)
+	}()
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+
+	row := <-rowChannel
+	require.Equal(t, ksqldb.Row{"a"}, row)
+	row.Release()
+	require.Equal(t, ksqldb.Row{"b"}, <-rowChannel)
+
+	cancel()
+	require.Nil(t, <-done)
+}
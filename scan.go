@@ -0,0 +1,178 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Scan copies the record into the struct pointed to by dest.
+//
+// Columns are mapped to struct fields by their `ksql` tag or by the field name.
+// Columns without a matching field are ignored, fields without a matching
+// column are left untouched. Null values set the field to its zero value.
+//
+// Numeric values are converted to the type of the field, ARRAY, MAP and STRUCT
+// values are copied into slices, maps and nested structs. Pointer fields are
+// allocated as needed. Numbers are scanned into time.Time fields as epoch
+// milliseconds. Fields implementing sql.Scanner, like NullString or
+// sql.NullString, scan the value themselves. A field of type Window without
+// a matching column gets the window of windowed results.
+func (r Record) Scan(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can't scan into %T: dest must be a non nil pointer to a struct", dest)
+	}
+	if err := scanStruct(rv.Elem(), r); err != nil {
+		return err
+	}
+	return scanWindow(rv.Elem(), r)
+}
+
+var (
+	windowType = reflect.TypeOf(Window{})
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// scanWindow sets fields of type Window or *Window without a column
+// to the window of the record
+func scanWindow(dst reflect.Value, r Record) error {
+	var window *Window
+	for _, field := range structFields(dst.Type()) {
+		if _, ok := r[field.name]; ok {
+			continue
+		}
+		if field.typ != windowType && field.typ != reflect.PtrTo(windowType) {
+			continue
+		}
+		if window == nil {
+			w, err := r.Window()
+			if err != nil || w == nil {
+				return err
+			}
+			window = w
+		}
+		if field.typ == windowType {
+			dst.FieldByIndex(field.index).Set(reflect.ValueOf(*window))
+		} else {
+			dst.FieldByIndex(field.index).Set(reflect.ValueOf(window))
+		}
+	}
+	return nil
+}
+
+func scanStruct(dst reflect.Value, values map[string]interface{}) error {
+	for _, field := range structFields(dst.Type()) {
+		value, ok := values[field.name]
+		if !ok {
+			continue
+		}
+		if err := scanValue(dst.FieldByIndex(field.index), value); err != nil {
+			return fmt.Errorf("can't scan %v: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+func scanValue(dst reflect.Value, value interface{}) error {
+	// NullString, sql.NullString, ...
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(dst.Type().Elem())
+		if err := scanValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		dst.Set(ptr)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if isNumber(src.Kind()) {
+			dst.Set(src.Convert(dst.Type()))
+			return nil
+		}
+	case reflect.Slice:
+		if src.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+			for i := 0; i < src.Len(); i++ {
+				if err := scanValue(slice.Index(i), src.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+	case reflect.Map:
+		if src.Kind() == reflect.Map && src.Type().Key().Kind() == reflect.String && dst.Type().Key().Kind() == reflect.String {
+			m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+			for _, key := range src.MapKeys() {
+				item := reflect.New(dst.Type().Elem()).Elem()
+				if err := scanValue(item, src.MapIndex(key).Interface()); err != nil {
+					return err
+				}
+				m.SetMapIndex(key.Convert(dst.Type().Key()), item)
+			}
+			dst.Set(m)
+			return nil
+		}
+	case reflect.Struct:
+		if dst.Type() == timeType && isNumber(src.Kind()) {
+			// epoch millis like ROWTIME or WINDOWSTART
+			dst.Set(reflect.ValueOf(epochMillis(int64(src.Convert(reflect.TypeOf(float64(0))).Float()))))
+			return nil
+		}
+		if values, ok := value.(map[string]interface{}); ok {
+			return scanStruct(dst, values)
+		}
+	}
+
+	if src.Type().ConvertibleTo(dst.Type()) && src.Kind() == dst.Kind() {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("can't scan %T into %v", value, dst.Type())
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
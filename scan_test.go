@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+type scanAddress struct {
+	Street string `ksql:"STREET"`
+	Zip    int    `ksql:"ZIP"`
+}
+
+type scanDog struct {
+	ID      string `ksql:"ID,key"`
+	Age     int64  `ksql:"AGE"`
+	Weight  *float64
+	Born    time.Time            `ksql:"BORN"`
+	Tags    []string             `ksql:"TAGS"`
+	Scores  map[string]int       `ksql:"SCORES"`
+	Address scanAddress          `ksql:"ADDRESS"`
+	Owners  []scanAddress        `ksql:"OWNERS"`
+	Extra   map[string]time.Time `ksql:"-"`
+}
+
+func TestRecord_Scan(t *testing.T) {
+	born := time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)
+	record := ksqldb.Record{
+		"ID":      "1",
+		"AGE":     int32(3),
+		"Weight":  12.5,
+		"BORN":    born,
+		"TAGS":    []interface{}{"small", "brown"},
+		"SCORES":  map[string]interface{}{"a": int64(1)},
+		"ADDRESS": map[string]interface{}{"STREET": "Main", "ZIP": int32(123)},
+		"OWNERS":  []interface{}{map[string]interface{}{"STREET": "Side"}},
+		"UNKNOWN": "ignored",
+	}
+
+	var dog scanDog
+	require.Nil(t, record.Scan(&dog))
+	weight := 12.5
+	require.Equal(t, scanDog{
+		ID:      "1",
+		Age:     3,
+		Weight:  &weight,
+		Born:    born,
+		Tags:    []string{"small", "brown"},
+		Scores:  map[string]int{"a": 1},
+		Address: scanAddress{Street: "Main", Zip: 123},
+		Owners:  []scanAddress{{Street: "Side"}},
+	}, dog)
+}
+
+func TestRecord_Scan_Null(t *testing.T) {
+	dog := scanDog{ID: "1", Tags: []string{"a"}}
+	require.Nil(t, ksqldb.Record{"ID": nil, "TAGS": nil}.Scan(&dog))
+	require.Equal(t, "", dog.ID)
+	require.Nil(t, dog.Tags)
+}
+
+func TestRecord_Scan_Errors(t *testing.T) {
+	var dog scanDog
+	err := ksqldb.Record{}.Scan(dog)
+	require.NotNil(t, err)
+	require.Equal(t, "can't scan into ksqldb_test.scanDog: dest must be a non nil pointer to a struct", err.Error())
+
+	err = ksqldb.Record{"AGE": "old"}.Scan(&dog)
+	require.NotNil(t, err)
+	require.Equal(t, "can't scan AGE: can't scan string into int64", err.Error())
+}
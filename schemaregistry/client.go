@@ -0,0 +1,141 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schemaregistry talks to the Confluent Schema Registry.
+//
+// It fetches the schemas of AVRO, PROTOBUF and JSON_SR topics, infers
+// their ksql columns, validates struct mappings against them and builds
+// CREATE STREAM statements with the inferred columns.
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/thmeitz/ksqldb-go/net"
+)
+
+const (
+	FORMAT_AVRO     = "AVRO"
+	FORMAT_PROTOBUF = "PROTOBUF"
+	FORMAT_JSON_SR  = "JSON_SR"
+)
+
+// Client is a Schema Registry client
+type Client struct {
+	http net.HTTPClient
+}
+
+// ResponseError is returned if the Schema Registry responds with an error
+type ResponseError struct {
+	ErrCode int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e ResponseError) Error() string {
+	return fmt.Sprintf("schema registry error %v: %v", e.ErrCode, e.Message)
+}
+
+// NewClient returns a new Schema Registry client with the given net.HTTPClient.
+// The base url of the net.HTTPClient must point to the Schema Registry.
+func NewClient(http net.HTTPClient) *Client {
+	return &Client{http: http}
+}
+
+// Close closes the underlying http transport
+func (c *Client) Close() {
+	c.http.Close()
+}
+
+// ValueSubject returns the value subject of the topic (TopicNameStrategy)
+func ValueSubject(topic string) string {
+	return topic + "-value"
+}
+
+// KeySubject returns the key subject of the topic (TopicNameStrategy)
+func KeySubject(topic string) string {
+	return topic + "-key"
+}
+
+// LatestSchema returns the latest schema version of the subject
+func (c *Client) LatestSchema(ctx context.Context, subject string) (*Schema, error) {
+	return c.SchemaVersion(ctx, subject, "latest")
+}
+
+// SchemaVersion returns the given schema version of the subject
+func (c *Client) SchemaVersion(ctx context.Context, subject string, version string) (*Schema, error) {
+	schema := Schema{}
+	endpoint := fmt.Sprintf("/subjects/%v/versions/%v", url.PathEscape(subject), url.PathEscape(version))
+	if err := c.get(ctx, endpoint, &schema); err != nil {
+		return nil, fmt.Errorf("can't get schema of %v: %w", subject, err)
+	}
+	return &schema, nil
+}
+
+// SchemaByID returns the schema with the given id
+func (c *Client) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	schema := Schema{}
+	if err := c.get(ctx, fmt.Sprintf("/schemas/ids/%v", id), &schema); err != nil {
+		return nil, fmt.Errorf("can't get schema %v: %w", id, err)
+	}
+	schema.ID = id
+	return &schema, nil
+}
+
+// Subjects returns all registered subjects
+func (c *Client) Subjects(ctx context.Context) ([]string, error) {
+	subjects := []string{}
+	if err := c.get(ctx, "/subjects", &subjects); err != nil {
+		return nil, fmt.Errorf("can't get subjects: %w", err)
+	}
+	return subjects, nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.http.GetUrl(endpoint), nil)
+	if err != nil {
+		return fmt.Errorf("can't create new request with context: %w", err)
+	}
+	req.Header.Add("Accept", "application/vnd.schemaregistry.v1+json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't do request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("can't read response body: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		respErr := ResponseError{}
+		if err := json.Unmarshal(body, &respErr); err != nil {
+			return fmt.Errorf("schema registry error: %v", res.Status)
+		}
+		return respErr
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("could not parse the response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+	"github.com/thmeitz/ksqldb-go/schemaregistry"
+)
+
+func mockRegistry(endpoint string, status int, body string) *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", endpoint).Return("http://localhost:8081" + endpoint)
+	m.Mock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "http://localhost:8081"+endpoint
+	})).Return(&http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil)
+	return &m
+}
+
+func TestClient_LatestSchema(t *testing.T) {
+	m := mockRegistry("/subjects/dogs-value/versions/latest", http.StatusOK,
+		`{"subject":"dogs-value","version":2,"id":7,"schema":"{\"type\":\"record\",\"name\":\"Dog\",\"fields\":[]}"}`)
+	c := schemaregistry.NewClient(m)
+	schema, err := c.LatestSchema(context.Background(), schemaregistry.ValueSubject("dogs"))
+	require.Nil(t, err)
+	require.Equal(t, "dogs-value", schema.Subject)
+	require.Equal(t, 2, schema.Version)
+	require.Equal(t, 7, schema.ID)
+	require.Equal(t, schemaregistry.FORMAT_AVRO, schema.Format())
+}
+
+func TestClient_SchemaByID(t *testing.T) {
+	m := mockRegistry("/schemas/ids/7", http.StatusOK, `{"schemaType":"JSON","schema":"{}"}`)
+	c := schemaregistry.NewClient(m)
+	schema, err := c.SchemaByID(context.Background(), 7)
+	require.Nil(t, err)
+	require.Equal(t, 7, schema.ID)
+	require.Equal(t, schemaregistry.FORMAT_JSON_SR, schema.Format())
+}
+
+func TestClient_Subjects(t *testing.T) {
+	m := mockRegistry("/subjects", http.StatusOK, `["dogs-value","dogs-key"]`)
+	c := schemaregistry.NewClient(m)
+	subjects, err := c.Subjects(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []string{"dogs-value", "dogs-key"}, subjects)
+}
+
+func TestClient_ResponseError(t *testing.T) {
+	m := mockRegistry("/subjects/cats-value/versions/latest", http.StatusNotFound, `{"error_code":40401,"message":"Subject 'cats-value' not found."}`)
+	c := schemaregistry.NewClient(m)
+	schema, err := c.LatestSchema(context.Background(), "cats-value")
+	require.Nil(t, schema)
+	require.Equal(t, "can't get schema of cats-value: schema registry error 40401: Subject 'cats-value' not found.", err.Error())
+
+	var respErr schemaregistry.ResponseError
+	require.True(t, errors.As(err, &respErr))
+	require.Equal(t, 40401, respErr.ErrCode)
+}
+
+func TestClient_CreateStream(t *testing.T) {
+	m := mockRegistry("/subjects/dogs-value/versions/latest", http.StatusOK,
+		`{"subject":"dogs-value","version":1,"id":1,"schema":"{\"type\":\"record\",\"name\":\"Dog\",\"fields\":[{\"name\":\"NAME\",\"type\":\"string\"}]}"}`)
+	c := schemaregistry.NewClient(m)
+	stmnt, err := c.CreateStream(context.Background(), schemaregistry.StreamOptions{Name: "DOGS", Topic: "dogs"})
+	require.Nil(t, err)
+	require.Equal(t, "CREATE STREAM `DOGS` (`NAME` STRING) WITH (KAFKA_TOPIC='dogs', VALUE_FORMAT='AVRO');", stmnt)
+}
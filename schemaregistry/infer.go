@@ -0,0 +1,556 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// avroFields returns the fields of an AVRO record schema
+func avroFields(definition string) ([]ksqldb.Field, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(definition), &schema); err != nil {
+		return nil, fmt.Errorf("can't parse schema: %w", err)
+	}
+	a := avroParser{named: map[string]ksqldb.Schema{}}
+	root, err := a.schema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if root.Type != "STRUCT" {
+		return nil, fmt.Errorf("schema is not a record but %v", root.Type)
+	}
+	return root.Fields, nil
+}
+
+// avroParser keeps track of named types, they can be referenced by name
+type avroParser struct {
+	named map[string]ksqldb.Schema
+}
+
+func (a *avroParser) schema(schema interface{}) (ksqldb.Schema, error) {
+	switch s := schema.(type) {
+	case string:
+		return a.primitive(s)
+	case []interface{}:
+		// unions with null are nullable types
+		types := []interface{}{}
+		for _, t := range s {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		if len(types) != 1 {
+			return ksqldb.Schema{}, fmt.Errorf("unsupported union %v", s)
+		}
+		return a.schema(types[0])
+	case map[string]interface{}:
+		return a.complex(s)
+	}
+	return ksqldb.Schema{}, fmt.Errorf("unsupported schema %v", schema)
+}
+
+func (a *avroParser) primitive(name string) (ksqldb.Schema, error) {
+	switch name {
+	case "boolean":
+		return ksqldb.Schema{Type: "BOOLEAN"}, nil
+	case "int":
+		return ksqldb.Schema{Type: "INTEGER"}, nil
+	case "long":
+		return ksqldb.Schema{Type: "BIGINT"}, nil
+	case "float", "double":
+		return ksqldb.Schema{Type: "DOUBLE"}, nil
+	case "bytes":
+		return ksqldb.Schema{Type: "BYTES"}, nil
+	case "string":
+		return ksqldb.Schema{Type: "STRING"}, nil
+	}
+	if named, ok := a.named[name]; ok {
+		return named, nil
+	}
+	// names may be referenced without namespace
+	for fullname, named := range a.named {
+		if strings.HasSuffix(fullname, "."+name) {
+			return named, nil
+		}
+	}
+	return ksqldb.Schema{}, fmt.Errorf("unsupported type %v", name)
+}
+
+func (a *avroParser) complex(s map[string]interface{}) (ksqldb.Schema, error) {
+	switch s["logicalType"] {
+	case "timestamp-millis", "timestamp-micros":
+		return ksqldb.Schema{Type: "TIMESTAMP"}, nil
+	case "date":
+		return ksqldb.Schema{Type: "DATE"}, nil
+	case "time-millis", "time-micros":
+		return ksqldb.Schema{Type: "TIME"}, nil
+	case "decimal":
+		return ksqldb.Schema{Type: "DECIMAL", Parameters: map[string]interface{}{
+			"precision": s["precision"], "scale": defaultValue(s["scale"], float64(0)),
+		}}, nil
+	}
+
+	switch t := s["type"].(type) {
+	case string:
+		switch t {
+		case "record":
+			return a.record(s)
+		case "enum":
+			return a.register(s, ksqldb.Schema{Type: "STRING"}), nil
+		case "fixed":
+			return a.register(s, ksqldb.Schema{Type: "BYTES"}), nil
+		case "array":
+			items, err := a.schema(s["items"])
+			if err != nil {
+				return ksqldb.Schema{}, err
+			}
+			return ksqldb.Schema{Type: "ARRAY", MemberSchema: &items}, nil
+		case "map":
+			values, err := a.schema(s["values"])
+			if err != nil {
+				return ksqldb.Schema{}, err
+			}
+			return ksqldb.Schema{Type: "MAP", MemberSchema: &values}, nil
+		}
+		return a.primitive(t)
+	default:
+		return a.schema(t)
+	}
+}
+
+func (a *avroParser) record(s map[string]interface{}) (ksqldb.Schema, error) {
+	fields, _ := s["fields"].([]interface{})
+	record := ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{}}
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			return ksqldb.Schema{}, fmt.Errorf("invalid record field %v", f)
+		}
+		name, _ := field["name"].(string)
+		schema, err := a.schema(field["type"])
+		if err != nil {
+			return ksqldb.Schema{}, fmt.Errorf("field %v: %w", name, err)
+		}
+		record.Fields = append(record.Fields, ksqldb.Field{Name: name, Schema: schema})
+	}
+	return a.register(s, record), nil
+}
+
+// register stores the named type for later references
+func (a *avroParser) register(s map[string]interface{}, schema ksqldb.Schema) ksqldb.Schema {
+	name, _ := s["name"].(string)
+	if namespace, ok := s["namespace"].(string); ok && namespace != "" {
+		name = namespace + "." + name
+	}
+	if name != "" {
+		a.named[name] = schema
+	}
+	return schema
+}
+
+func defaultValue(value interface{}, def interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	return value
+}
+
+// jsonFields returns the properties of a JSON schema object in the order of their definition
+func jsonFields(definition string) ([]ksqldb.Field, error) {
+	root, err := jsonSchema([]byte(definition))
+	if err != nil {
+		return nil, err
+	}
+	if root.Type != "STRUCT" {
+		return nil, fmt.Errorf("schema is not an object but %v", root.Type)
+	}
+	return root.Fields, nil
+}
+
+func jsonSchema(definition json.RawMessage) (ksqldb.Schema, error) {
+	var s struct {
+		Type                 interface{}       `json:"type"`
+		Format               string            `json:"format"`
+		Items                json.RawMessage   `json:"items"`
+		Properties           json.RawMessage   `json:"properties"`
+		AdditionalProperties json.RawMessage   `json:"additionalProperties"`
+		OneOf                []json.RawMessage `json:"oneOf"`
+		ConnectType          string            `json:"connect.type"`
+	}
+	if err := json.Unmarshal(definition, &s); err != nil {
+		return ksqldb.Schema{}, fmt.Errorf("can't parse schema: %w", err)
+	}
+
+	t, err := jsonType(s.Type)
+	if err != nil {
+		return ksqldb.Schema{}, err
+	}
+	if t == "" && len(s.OneOf) > 0 {
+		// oneOf with null is a nullable type
+		for _, one := range s.OneOf {
+			schema, err := jsonSchema(one)
+			if err != nil {
+				return ksqldb.Schema{}, err
+			}
+			if schema.Type != "" {
+				return schema, nil
+			}
+		}
+	}
+
+	switch t {
+	case "boolean":
+		return ksqldb.Schema{Type: "BOOLEAN"}, nil
+	case "integer":
+		if s.ConnectType == "int32" || s.ConnectType == "int16" || s.ConnectType == "int8" {
+			return ksqldb.Schema{Type: "INTEGER"}, nil
+		}
+		return ksqldb.Schema{Type: "BIGINT"}, nil
+	case "number":
+		return ksqldb.Schema{Type: "DOUBLE"}, nil
+	case "string":
+		if s.ConnectType == "bytes" {
+			return ksqldb.Schema{Type: "BYTES"}, nil
+		}
+		return ksqldb.Schema{Type: "STRING"}, nil
+	case "array":
+		items := ksqldb.Schema{Type: "STRING"}
+		if len(s.Items) > 0 {
+			if items, err = jsonSchema(s.Items); err != nil {
+				return ksqldb.Schema{}, err
+			}
+		}
+		return ksqldb.Schema{Type: "ARRAY", MemberSchema: &items}, nil
+	case "object":
+		if len(s.Properties) == 0 && len(s.AdditionalProperties) > 0 && !bytes.Equal(s.AdditionalProperties, []byte("true")) {
+			values, err := jsonSchema(s.AdditionalProperties)
+			if err != nil {
+				return ksqldb.Schema{}, err
+			}
+			return ksqldb.Schema{Type: "MAP", MemberSchema: &values}, nil
+		}
+		fields, err := jsonProperties(s.Properties)
+		if err != nil {
+			return ksqldb.Schema{}, err
+		}
+		return ksqldb.Schema{Type: "STRUCT", Fields: fields}, nil
+	case "", "null":
+		return ksqldb.Schema{}, nil
+	}
+	return ksqldb.Schema{}, fmt.Errorf("unsupported type %v", t)
+}
+
+// jsonType returns the type of a JSON schema; ["null", "string"] is a nullable string
+func jsonType(t interface{}) (string, error) {
+	switch tt := t.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return tt, nil
+	case []interface{}:
+		types := []string{}
+		for _, item := range tt {
+			if s, ok := item.(string); ok && s != "null" {
+				types = append(types, s)
+			}
+		}
+		if len(types) != 1 {
+			return "", fmt.Errorf("unsupported type %v", tt)
+		}
+		return types[0], nil
+	}
+	return "", fmt.Errorf("unsupported type %v", t)
+}
+
+// jsonProperties decodes the properties keeping the order of their definition
+func jsonProperties(properties json.RawMessage) ([]ksqldb.Field, error) {
+	fields := []ksqldb.Field{}
+	if len(properties) == 0 {
+		return fields, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(properties))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("can't parse properties: %w", err)
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("can't parse properties: %w", err)
+		}
+		name, _ := token.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("can't parse property %v: %w", name, err)
+		}
+		schema, err := jsonSchema(raw)
+		if err != nil {
+			return nil, fmt.Errorf("property %v: %w", name, err)
+		}
+		fields = append(fields, ksqldb.Field{Name: name, Schema: schema})
+	}
+	return fields, nil
+}
+
+var (
+	protoComments = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	protoTokens   = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*|\d+|"[^"]*"|[{}<>;=,\[\]()]`)
+)
+
+// protobufFields returns the fields of the first message of a proto3 schema
+func protobufFields(definition string) ([]ksqldb.Field, error) {
+	tokens := protoTokens.FindAllString(protoComments.ReplaceAllString(definition, " "), -1)
+	p := protoParser{tokens: tokens, messages: map[string][]protoField{}}
+	first, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if first == "" {
+		return nil, fmt.Errorf("no message found")
+	}
+	schema, err := p.message(first, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return schema.Fields, nil
+}
+
+type protoField struct {
+	name     string
+	typ      string
+	repeated bool
+	// key type of map fields
+	key   string
+	scope string
+}
+
+type protoParser struct {
+	tokens   []string
+	pos      int
+	messages map[string][]protoField
+}
+
+// parse collects all messages; it returns the name of the first top level message
+func (p *protoParser) parse() (string, error) {
+	first := ""
+	for p.pos < len(p.tokens) {
+		if p.tokens[p.pos] == "message" {
+			name, err := p.parseMessage("")
+			if err != nil {
+				return "", err
+			}
+			if first == "" {
+				first = name
+			}
+			continue
+		}
+		if p.next() == "enum" {
+			p.messages[p.next()] = nil
+			p.next() // {
+			p.skipBlock()
+		}
+	}
+	return first, nil
+}
+
+func (p *protoParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *protoParser) parseMessage(scope string) (string, error) {
+	p.next() // message
+	name := p.next()
+	if scope != "" {
+		name = scope + "." + name
+	}
+	if p.next() != "{" {
+		return "", fmt.Errorf("message %v: { expected", name)
+	}
+	fields := []protoField{}
+	// oneof blocks contain regular fields
+	oneof := 0
+	for {
+		token := p.next()
+		switch token {
+		case "":
+			return "", fmt.Errorf("message %v: unexpected end", name)
+		case "}":
+			if oneof > 0 {
+				oneof--
+				continue
+			}
+			p.messages[name] = fields
+			return name, nil
+		case "message":
+			p.pos--
+			if _, err := p.parseMessage(name); err != nil {
+				return "", err
+			}
+		case "enum":
+			// enums are strings
+			p.messages[scoped(name, p.next())] = nil
+			p.next() // {
+			p.skipBlock()
+		case "oneof":
+			p.next() // name
+			p.next() // {
+			oneof++
+		case "option", "reserved", "extensions":
+			p.skipStatement()
+		case ";":
+		default:
+			field := protoField{scope: name}
+			if token == "repeated" || token == "optional" {
+				field.repeated = token == "repeated"
+				token = p.next()
+			}
+			if token == "map" {
+				p.next() // <
+				field.key = p.next()
+				p.next() // ,
+				field.typ = p.next()
+				p.next() // >
+			} else {
+				field.typ = token
+			}
+			field.name = p.next()
+			p.skipStatement()
+			fields = append(fields, field)
+		}
+	}
+}
+
+func (p *protoParser) skipStatement() {
+	depth := 0
+	for {
+		switch p.next() {
+		case "", ";":
+			if depth == 0 {
+				return
+			}
+		case "[", "(", "{":
+			depth++
+		case "]", ")", "}":
+			depth--
+		}
+	}
+}
+
+func (p *protoParser) skipBlock() {
+	depth := 1
+	for depth > 0 {
+		switch p.next() {
+		case "":
+			return
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+	}
+}
+
+func scoped(scope, name string) string {
+	return scope + "." + name
+}
+
+func (p *protoParser) message(name string, visiting map[string]bool) (ksqldb.Schema, error) {
+	if visiting[name] {
+		return ksqldb.Schema{}, fmt.Errorf("recursive message %v", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	schema := ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{}}
+	for _, field := range p.messages[name] {
+		fs, err := p.fieldSchema(field, visiting)
+		if err != nil {
+			return ksqldb.Schema{}, fmt.Errorf("field %v: %w", field.name, err)
+		}
+		schema.Fields = append(schema.Fields, ksqldb.Field{Name: field.name, Schema: fs})
+	}
+	return schema, nil
+}
+
+func (p *protoParser) fieldSchema(field protoField, visiting map[string]bool) (ksqldb.Schema, error) {
+	schema, err := p.typeSchema(field.typ, field.scope, visiting)
+	if err != nil {
+		return schema, err
+	}
+	if field.key != "" {
+		return ksqldb.Schema{Type: "MAP", MemberSchema: &schema}, nil
+	}
+	if field.repeated {
+		return ksqldb.Schema{Type: "ARRAY", MemberSchema: &schema}, nil
+	}
+	return schema, nil
+}
+
+func (p *protoParser) typeSchema(typ string, scope string, visiting map[string]bool) (ksqldb.Schema, error) {
+	switch typ {
+	case "bool":
+		return ksqldb.Schema{Type: "BOOLEAN"}, nil
+	case "int32", "sint32", "sfixed32", "uint32", "fixed32":
+		return ksqldb.Schema{Type: "INTEGER"}, nil
+	case "int64", "sint64", "sfixed64", "uint64", "fixed64":
+		return ksqldb.Schema{Type: "BIGINT"}, nil
+	case "float", "double":
+		return ksqldb.Schema{Type: "DOUBLE"}, nil
+	case "string":
+		return ksqldb.Schema{Type: "STRING"}, nil
+	case "bytes":
+		return ksqldb.Schema{Type: "BYTES"}, nil
+	case "google.protobuf.Timestamp":
+		return ksqldb.Schema{Type: "TIMESTAMP"}, nil
+	}
+
+	// resolve the type from the innermost scope to the top level
+	for s := scope; ; {
+		candidate := typ
+		if s != "" {
+			candidate = scoped(s, typ)
+		}
+		if fields, ok := p.messages[candidate]; ok {
+			if fields == nil {
+				// enum
+				return ksqldb.Schema{Type: "STRING"}, nil
+			}
+			return p.message(candidate, visiting)
+		}
+		if s == "" {
+			break
+		}
+		if idx := strings.LastIndex(s, "."); idx >= 0 {
+			s = s[:idx]
+		} else {
+			s = ""
+		}
+	}
+	return ksqldb.Schema{}, fmt.Errorf("unsupported type %v", typ)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// Schema is a schema registered in the Schema Registry
+type Schema struct {
+	Subject string `json:"subject,omitempty"`
+	Version int    `json:"version,omitempty"`
+	ID      int    `json:"id,omitempty"`
+	// SchemaType is AVRO, PROTOBUF or JSON; empty means AVRO
+	SchemaType string `json:"schemaType,omitempty"`
+	// Schema is the schema definition
+	Schema string `json:"schema"`
+}
+
+// Format returns the ksql format of the schema (AVRO, PROTOBUF or JSON_SR)
+func (s *Schema) Format() string {
+	switch s.SchemaType {
+	case "", FORMAT_AVRO:
+		return FORMAT_AVRO
+	case "JSON", FORMAT_JSON_SR:
+		return FORMAT_JSON_SR
+	}
+	return s.SchemaType
+}
+
+// Columns returns the ksql columns inferred from the schema
+func (s *Schema) Columns() ([]ksqldb.SchemaColumn, error) {
+	var fields []ksqldb.Field
+	var err error
+
+	switch s.Format() {
+	case FORMAT_AVRO:
+		fields, err = avroFields(s.Schema)
+	case FORMAT_JSON_SR:
+		fields, err = jsonFields(s.Schema)
+	case FORMAT_PROTOBUF:
+		fields, err = protobufFields(s.Schema)
+	default:
+		return nil, fmt.Errorf("unsupported schema type: %v", s.SchemaType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't infer columns of %v schema: %w", s.Format(), err)
+	}
+
+	columns := make([]ksqldb.SchemaColumn, len(fields))
+	for idx, field := range fields {
+		columns[idx] = ksqldb.SchemaColumn{Name: field.Name, Schema: field.Schema}
+	}
+	return columns, nil
+}
+
+// StreamOptions configures CreateStreamStatement
+type StreamOptions struct {
+	// Name of the stream
+	Name string
+	// Topic of the stream
+	Topic string
+	// KeyColumns are prepended to the value columns, ex. `ID STRING KEY`
+	KeyColumns []ksqldb.SchemaColumn
+	// KeyFormat is added to the WITH clause if not empty
+	KeyFormat string
+}
+
+// CreateStreamStatement returns a CREATE STREAM statement with the columns
+// inferred from the value schema.
+func CreateStreamStatement(options StreamOptions, value *Schema) (string, error) {
+	columns, err := value.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	elements := []string{}
+	for _, col := range options.KeyColumns {
+		elements = append(elements, fmt.Sprintf("%v %v KEY", ksqldb.QuoteIdentifier(col.Name), col.Schema))
+	}
+	for _, col := range columns {
+		elements = append(elements, fmt.Sprintf("%v %v", ksqldb.QuoteIdentifier(col.Name), col.Schema))
+	}
+
+	with := ksqldb.SourceOptions{KafkaTopic: options.Topic, KeyFormat: options.KeyFormat, ValueFormat: value.Format()}
+	return fmt.Sprintf("CREATE STREAM %v (%v) %v;",
+		ksqldb.QuoteIdentifier(options.Name), strings.Join(elements, ", "), with), nil
+}
+
+// CreateStream fetches the latest value schema of the topic and returns
+// a CREATE STREAM statement with the inferred columns.
+func (c *Client) CreateStream(ctx context.Context, options StreamOptions) (string, error) {
+	schema, err := c.LatestSchema(ctx, ValueSubject(options.Topic))
+	if err != nil {
+		return "", err
+	}
+	return CreateStreamStatement(options, schema)
+}
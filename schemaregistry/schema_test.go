@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/schemaregistry"
+)
+
+const dogAvro = `{
+	"type": "record", "name": "Dog", "namespace": "io.dogs",
+	"fields": [
+		{"name": "NAME", "type": "string"},
+		{"name": "AGE", "type": ["null", "int"]},
+		{"name": "WEIGHT", "type": "double"},
+		{"name": "BORN", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "PRICE", "type": {"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}},
+		{"name": "TAGS", "type": {"type": "array", "items": "string"}},
+		{"name": "SCORES", "type": {"type": "map", "values": "long"}},
+		{"name": "SIZE", "type": {"type": "enum", "name": "Size", "symbols": ["SMALL", "LARGE"]}},
+		{"name": "ADDRESS", "type": {"type": "record", "name": "Address", "fields": [{"name": "STREET", "type": "string"}]}},
+		{"name": "FORMER", "type": ["null", "Address"]}
+	]
+}`
+
+func nameTypes(columns []ksqldb.SchemaColumn) []string {
+	types := []string{}
+	for _, col := range columns {
+		types = append(types, col.Name+" "+col.Schema.String())
+	}
+	return types
+}
+
+func TestSchema_Columns_Avro(t *testing.T) {
+	schema := schemaregistry.Schema{Schema: dogAvro}
+	columns, err := schema.Columns()
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"NAME STRING",
+		"AGE INTEGER",
+		"WEIGHT DOUBLE",
+		"BORN TIMESTAMP",
+		"PRICE DECIMAL(4, 2)",
+		"TAGS ARRAY<STRING>",
+		"SCORES MAP<STRING, BIGINT>",
+		"SIZE STRING",
+		"ADDRESS STRUCT<`STREET` STRING>",
+		"FORMER STRUCT<`STREET` STRING>",
+	}, nameTypes(columns))
+}
+
+func TestSchema_Columns_JsonSchema(t *testing.T) {
+	schema := schemaregistry.Schema{SchemaType: "JSON", Schema: `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer", "connect.type": "int32"},
+			"weight": {"oneOf": [{"type": "null"}, {"type": "number"}]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"scores": {"type": "object", "additionalProperties": {"type": "integer"}},
+			"address": {"type": "object", "properties": {"street": {"type": ["null", "string"]}}}
+		}
+	}`}
+	columns, err := schema.Columns()
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"name STRING",
+		"age INTEGER",
+		"weight DOUBLE",
+		"tags ARRAY<STRING>",
+		"scores MAP<STRING, BIGINT>",
+		"address STRUCT<`street` STRING>",
+	}, nameTypes(columns))
+}
+
+func TestSchema_Columns_Protobuf(t *testing.T) {
+	schema := schemaregistry.Schema{SchemaType: "PROTOBUF", Schema: `
+		syntax = "proto3";
+		package dogs;
+
+		import "google/protobuf/timestamp.proto";
+
+		enum Size { SMALL = 0; LARGE = 1; }
+
+		// a dog
+		message Dog {
+			string name = 1;
+			int32 age = 2 [deprecated = true];
+			repeated string tags = 3;
+			map<string, int64> scores = 4;
+			Address address = 5;
+			google.protobuf.Timestamp born = 6;
+			Size size = 7;
+			oneof owner {
+				string person = 8;
+				string company = 9;
+			}
+
+			message Address {
+				string street = 1;
+			}
+		}
+
+		message Other {
+			bool ok = 1;
+		}
+	`}
+	columns, err := schema.Columns()
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"name STRING",
+		"age INTEGER",
+		"tags ARRAY<STRING>",
+		"scores MAP<STRING, BIGINT>",
+		"address STRUCT<`street` STRING>",
+		"born TIMESTAMP",
+		"size STRING",
+		"person STRING",
+		"company STRING",
+	}, nameTypes(columns))
+}
+
+func TestSchema_Columns_Errors(t *testing.T) {
+	_, err := (&schemaregistry.Schema{Schema: `"string"`}).Columns()
+	require.NotNil(t, err)
+	require.Equal(t, "can't infer columns of AVRO schema: schema is not a record but STRING", err.Error())
+
+	_, err = (&schemaregistry.Schema{SchemaType: "XML"}).Columns()
+	require.NotNil(t, err)
+
+	_, err = (&schemaregistry.Schema{SchemaType: "PROTOBUF", Schema: `message A { Unknown u = 1; }`}).Columns()
+	require.NotNil(t, err)
+	require.Equal(t, "can't infer columns of PROTOBUF schema: field u: unsupported type Unknown", err.Error())
+}
+
+func TestCreateStreamStatement(t *testing.T) {
+	schema := schemaregistry.Schema{SchemaType: "JSON", Schema: `{"type":"object","properties":{"name":{"type":"string"}}}`}
+	stmnt, err := schemaregistry.CreateStreamStatement(schemaregistry.StreamOptions{
+		Name:       "dogs",
+		Topic:      "dogs",
+		KeyColumns: []ksqldb.SchemaColumn{{Name: "ID", Schema: ksqldb.Schema{Type: "STRING"}}},
+		KeyFormat:  "KAFKA",
+	}, &schema)
+	require.Nil(t, err)
+	require.Equal(t, "CREATE STREAM `dogs` (`ID` STRING KEY, `name` STRING) WITH (KAFKA_TOPIC='dogs', KEY_FORMAT='KAFKA', VALUE_FORMAT='JSON_SR');", stmnt)
+}
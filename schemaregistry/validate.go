@@ -0,0 +1,190 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thmeitz/ksqldb-go"
+)
+
+// MappingError is a mismatch between a struct field and a column
+type MappingError struct {
+	// Path of the column, ex. ADDRESS.STREET
+	Path string
+	Msg  string
+}
+
+func (e MappingError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Path, e.Msg)
+}
+
+// MappingErrorList is returned by Validate
+type MappingErrorList []MappingError
+
+func (l MappingErrorList) Error() string {
+	return fmt.Sprintf("%v mapping error(s) found", len(l))
+}
+
+// Validate checks the struct mapping of v against the columns of the schema.
+//
+// Struct fields are mapped by their `ksql` tag or by their name like
+// ksqldb.Record.Scan does. Every column needs a field of a compatible type
+// and every field needs a column. Mismatches are returned as MappingErrorList.
+func (s *Schema) Validate(v interface{}) error {
+	columns, err := s.Columns()
+	if err != nil {
+		return err
+	}
+	return ValidateColumns(columns, v)
+}
+
+// ValidateColumns checks the struct mapping of v against the columns
+func ValidateColumns(columns []ksqldb.SchemaColumn, v interface{}) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("can't validate %T: not a struct", v)
+	}
+
+	fields := make([]ksqldb.Field, len(columns))
+	for idx, col := range columns {
+		fields[idx] = ksqldb.Field{Name: col.Name, Schema: col.Schema}
+	}
+
+	errors := MappingErrorList{}
+	validateStruct("", fields, t, &errors)
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+func validateStruct(path string, fields []ksqldb.Field, t reflect.Type, errors *MappingErrorList) {
+	goFields := mappedFields(t)
+	for _, field := range fields {
+		fieldPath := join(path, field.Name)
+		goField, ok := goFields[field.Name]
+		if !ok {
+			*errors = append(*errors, MappingError{Path: fieldPath, Msg: "no struct field found"})
+			continue
+		}
+		delete(goFields, field.Name)
+		validateType(fieldPath, field.Schema, goField.Type, errors)
+	}
+	names := []string{}
+	for name := range goFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		*errors = append(*errors, MappingError{Path: join(path, name), Msg: fmt.Sprintf("struct field %v has no column", goFields[name].Name)})
+	}
+}
+
+func validateType(path string, schema ksqldb.Schema, t reflect.Type, errors *MappingErrorList) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || reflect.PtrTo(t).Implements(scannerType) {
+		// ksqldb.NullString, sql.NullString, ... scan their values themselves
+		return
+	}
+
+	ok := true
+	switch schema.Type {
+	case "BOOLEAN":
+		ok = t.Kind() == reflect.Bool
+	case "INTEGER", "BIGINT":
+		ok = isInt(t.Kind())
+	case "DOUBLE", "DECIMAL":
+		ok = t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+	case "STRING":
+		ok = t.Kind() == reflect.String
+	case "BYTES":
+		ok = t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+	case "TIMESTAMP", "DATE", "TIME":
+		ok = t == timeType
+	case "ARRAY":
+		ok = t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+		if ok && schema.MemberSchema != nil {
+			validateType(path+"[]", *schema.MemberSchema, t.Elem(), errors)
+		}
+	case "MAP":
+		ok = t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+		if ok && schema.MemberSchema != nil {
+			validateType(path+"{}", *schema.MemberSchema, t.Elem(), errors)
+		}
+	case "STRUCT":
+		ok = t.Kind() == reflect.Struct
+		if ok {
+			validateStruct(path, schema.Fields, t, errors)
+		}
+	}
+	if !ok {
+		*errors = append(*errors, MappingError{Path: path, Msg: fmt.Sprintf("can't map %v to %v", schema, t)})
+	}
+}
+
+// mappedFields returns the exported struct fields by their column name
+func mappedFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get(ksqldb.KSQL_TAG)
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func isInt(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
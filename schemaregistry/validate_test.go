@@ -0,0 +1,85 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaregistry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/schemaregistry"
+)
+
+type validAddress struct {
+	Street string `ksql:"STREET"`
+}
+
+type validDog struct {
+	Name    string           `ksql:"NAME"`
+	Age     ksqldb.NullInt64 `ksql:"AGE"`
+	Weight  float64          `ksql:"WEIGHT"`
+	Born    time.Time        `ksql:"BORN"`
+	Price   float64          `ksql:"PRICE"`
+	Tags    []string         `ksql:"TAGS"`
+	Scores  map[string]int64 `ksql:"SCORES"`
+	Size    string           `ksql:"SIZE"`
+	Address validAddress     `ksql:"ADDRESS"`
+	Former  *validAddress    `ksql:"FORMER"`
+	Ignored string           `ksql:"-"`
+}
+
+type invalidDog struct {
+	Name    int          `ksql:"NAME"`
+	Tags    []int        `ksql:"TAGS"`
+	Address validAddress `ksql:"ADDRESS"`
+	Extra   string
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := schemaregistry.Schema{Schema: dogAvro}
+	require.Nil(t, schema.Validate(&validDog{}))
+}
+
+func TestSchema_Validate_Errors(t *testing.T) {
+	schema := schemaregistry.Schema{Schema: dogAvro}
+	err := schema.Validate(invalidDog{})
+	require.NotNil(t, err)
+
+	var list schemaregistry.MappingErrorList
+	require.True(t, errors.As(err, &list))
+	messages := []string{}
+	for _, e := range list {
+		messages = append(messages, e.Error())
+	}
+	require.Equal(t, []string{
+		"NAME: can't map STRING to int",
+		"AGE: no struct field found",
+		"WEIGHT: no struct field found",
+		"BORN: no struct field found",
+		"PRICE: no struct field found",
+		"TAGS[]: can't map STRING to int",
+		"SCORES: no struct field found",
+		"SIZE: no struct field found",
+		"FORMER: no struct field found",
+		"Extra: struct field Extra has no column",
+	}, messages)
+	require.Equal(t, "10 mapping error(s) found", err.Error())
+
+	require.NotNil(t, schema.Validate("dog"))
+}
@@ -0,0 +1,297 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JoinType is the type of a join, see SelectBuilder.Join
+type JoinType string
+
+const (
+	INNER_JOIN JoinType = "INNER JOIN"
+	LEFT_JOIN  JoinType = "LEFT JOIN"
+	RIGHT_JOIN JoinType = "RIGHT JOIN"
+	FULL_JOIN  JoinType = "FULL OUTER JOIN"
+)
+
+// SelectBuilder builds SELECT statements with joins.
+//
+//	sql, err := ksqldb.Select("o.ID", "c.NAME").
+//		From("ORDERS", "o").
+//		Join(ksqldb.INNER_JOIN, "SHIPMENTS", "s").On("o.ID", "s.ORDER_ID").Within(time.Hour).Grace(10 * time.Minute).
+//		Join(ksqldb.LEFT_JOIN, "CUSTOMERS", "c").On("o.CUSTOMER_ID", "c.ID").
+//		Where("o.TOTAL > ?", 100).
+//		EmitChanges().
+//		Build()
+//
+// Sources which are not plain identifiers are back quoted, so they are case
+// sensitive. Columns and conditions are expressions and are not quoted.
+// The first error of the chain is returned by Build.
+type SelectBuilder struct {
+	columns     []string
+	from        string
+	alias       string
+	joins       []join
+	where       string
+	groupBy     []string
+	having      string
+	emitChanges bool
+	limit       int
+	err         error
+}
+
+// join is a join clause of a SelectBuilder
+type join struct {
+	typ    JoinType
+	source string
+	alias  string
+	on     string
+	// within is the WITHIN clause of stream-stream joins
+	within string
+	grace  string
+}
+
+// Select starts a SELECT statement with the columns; no columns selects *
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns}
+}
+
+// From sets the source of the statement; alias may be empty
+func (b *SelectBuilder) From(source string, alias string) *SelectBuilder {
+	b.from, b.alias = source, alias
+	return b
+}
+
+// Join adds a join with the source; alias may be empty.
+// On, Within and Grace apply to the last join.
+func (b *SelectBuilder) Join(typ JoinType, source string, alias string) *SelectBuilder {
+	b.joins = append(b.joins, join{typ: typ, source: source, alias: alias})
+	return b
+}
+
+// lastJoin returns the last join or records an error
+func (b *SelectBuilder) lastJoin(clause string) *join {
+	if len(b.joins) == 0 {
+		b.fail(fmt.Errorf("%v without join", clause))
+		return nil
+	}
+	return &b.joins[len(b.joins)-1]
+}
+
+func (b *SelectBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// On sets the join condition of the last join to left = right,
+// ex. On("o.CUSTOMER_ID", "c.ID")
+func (b *SelectBuilder) On(left string, right string) *SelectBuilder {
+	return b.OnExpr(left + " = " + right)
+}
+
+// OnExpr sets the join condition of the last join to the expression,
+// ex. OnExpr("o.ID = CAST(s.ORDER_ID AS STRING)")
+func (b *SelectBuilder) OnExpr(condition string) *SelectBuilder {
+	if j := b.lastJoin("ON"); j != nil {
+		j.on = condition
+	}
+	return b
+}
+
+// Within sets the join window of the last join, ex. WITHIN 1 HOUR.
+// It is required for stream-stream joins.
+func (b *SelectBuilder) Within(size time.Duration) *SelectBuilder {
+	if j := b.lastJoin("WITHIN"); j != nil {
+		within, err := ksqlDuration(size)
+		b.setWithin(j, within, err)
+	}
+	return b
+}
+
+// WithinBeforeAfter sets a join window of the last join with different
+// sizes before and after the left record, ex. WITHIN (1 HOUR, 2 HOURS)
+func (b *SelectBuilder) WithinBeforeAfter(before time.Duration, after time.Duration) *SelectBuilder {
+	if j := b.lastJoin("WITHIN"); j != nil {
+		beforeSize, err := ksqlDuration(before)
+		afterSize, afterErr := ksqlDuration(after)
+		if err == nil {
+			err = afterErr
+		}
+		b.setWithin(j, "("+beforeSize+", "+afterSize+")", err)
+	}
+	return b
+}
+
+func (b *SelectBuilder) setWithin(j *join, within string, err error) {
+	if err != nil {
+		b.fail(fmt.Errorf("invalid WITHIN of %v: %w", j.source, err))
+		return
+	}
+	j.within = within
+}
+
+// Grace sets the grace period of the join window of the last join
+func (b *SelectBuilder) Grace(period time.Duration) *SelectBuilder {
+	if j := b.lastJoin("GRACE PERIOD"); j != nil {
+		grace, err := ksqlDuration(period)
+		if err != nil {
+			b.fail(fmt.Errorf("invalid GRACE PERIOD of %v: %w", j.source, err))
+			return b
+		}
+		j.grace = grace
+	}
+	return b
+}
+
+// Where sets the WHERE condition; ? are replaced with the params like with QueryBuilder
+func (b *SelectBuilder) Where(condition string, params ...interface{}) *SelectBuilder {
+	return b.setCondition(&b.where, "WHERE", condition, params)
+}
+
+// GroupBy sets the GROUP BY expressions
+func (b *SelectBuilder) GroupBy(expressions ...string) *SelectBuilder {
+	b.groupBy = expressions
+	return b
+}
+
+// Having sets the HAVING condition; ? are replaced with the params like with QueryBuilder
+func (b *SelectBuilder) Having(condition string, params ...interface{}) *SelectBuilder {
+	return b.setCondition(&b.having, "HAVING", condition, params)
+}
+
+func (b *SelectBuilder) setCondition(dst *string, clause string, condition string, params []interface{}) *SelectBuilder {
+	if len(params) == 0 {
+		*dst = condition
+		return b
+	}
+	bound, err := QueryBuilder(condition, params...)
+	if err != nil {
+		b.fail(fmt.Errorf("invalid %v: %w", clause, err))
+		return b
+	}
+	*dst = *bound
+	return b
+}
+
+// EmitChanges makes the statement a push query
+func (b *SelectBuilder) EmitChanges() *SelectBuilder {
+	b.emitChanges = true
+	return b
+}
+
+// Limit sets the LIMIT of the statement; 0 is no limit
+func (b *SelectBuilder) Limit(limit int) *SelectBuilder {
+	b.limit = limit
+	return b
+}
+
+// Build returns the statement
+func (b *SelectBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	if b.from == "" {
+		return "", errors.New("SELECT without FROM")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if len(b.columns) == 0 {
+		sb.WriteString("*")
+	} else {
+		sb.WriteString(strings.Join(b.columns, ", "))
+	}
+	sb.WriteString(" FROM " + aliased(b.from, b.alias))
+	for _, j := range b.joins {
+		if j.on == "" {
+			return "", fmt.Errorf("join of %v without ON", j.source)
+		}
+		if j.grace != "" && j.within == "" {
+			return "", fmt.Errorf("join of %v with GRACE PERIOD without WITHIN", j.source)
+		}
+		fmt.Fprintf(&sb, " %v %v", j.typ, aliased(j.source, j.alias))
+		if j.within != "" {
+			sb.WriteString(" WITHIN " + j.within)
+			if j.grace != "" {
+				sb.WriteString(" GRACE PERIOD " + j.grace)
+			}
+		}
+		sb.WriteString(" ON " + j.on)
+	}
+	if b.where != "" {
+		sb.WriteString(" WHERE " + b.where)
+	}
+	if len(b.groupBy) > 0 {
+		sb.WriteString(" GROUP BY " + strings.Join(b.groupBy, ", "))
+	}
+	if b.having != "" {
+		sb.WriteString(" HAVING " + b.having)
+	}
+	if b.emitChanges {
+		sb.WriteString(" EMIT CHANGES")
+	}
+	if b.limit > 0 {
+		fmt.Fprintf(&sb, " LIMIT %v", b.limit)
+	}
+	sb.WriteString(";")
+	return sb.String(), nil
+}
+
+// aliased returns the quoted source with its alias
+func aliased(source string, alias string) string {
+	if alias == "" {
+		return quoteFieldName(source)
+	}
+	return quoteFieldName(source) + " " + alias
+}
+
+// ksqlDurationUnits are the time units of ksql in descending order
+var ksqlDurationUnits = []struct {
+	size time.Duration
+	name string
+}{
+	{24 * time.Hour, "DAY"},
+	{time.Hour, "HOUR"},
+	{time.Minute, "MINUTE"},
+	{time.Second, "SECOND"},
+	{time.Millisecond, "MILLISECOND"},
+}
+
+// ksqlDuration returns the duration in the largest ksql time unit which
+// represents it exactly, ex. 2 HOURS
+func ksqlDuration(d time.Duration) (string, error) {
+	if d < 0 || d%time.Millisecond != 0 {
+		return "", fmt.Errorf("%v is no positive number of milliseconds", d)
+	}
+	for _, unit := range ksqlDurationUnits {
+		if d%unit.size == 0 {
+			n := int64(d / unit.size)
+			if n == 1 {
+				return "1 " + unit.name, nil
+			}
+			return fmt.Sprintf("%v %vS", n, unit.name), nil
+		}
+	}
+	// unreachable, d is a multiple of a millisecond
+	return "", nil
+}
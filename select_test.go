@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestSelect_Join(t *testing.T) {
+	sql, err := ksqldb.Select("o.ID", "c.NAME").
+		From("ORDERS", "o").
+		Join(ksqldb.INNER_JOIN, "SHIPMENTS", "s").On("o.ID", "s.ORDER_ID").Within(time.Hour).Grace(10*time.Minute).
+		Join(ksqldb.LEFT_JOIN, "CUSTOMERS", "c").On("o.CUSTOMER_ID", "c.ID").
+		Where("o.TOTAL > ? AND c.NAME = ?", 100, "O'Hara").
+		EmitChanges().
+		Build()
+	require.Nil(t, err)
+	require.Equal(t, "SELECT o.ID, c.NAME FROM ORDERS o"+
+		" INNER JOIN SHIPMENTS s WITHIN 1 HOUR GRACE PERIOD 10 MINUTES ON o.ID = s.ORDER_ID"+
+		" LEFT JOIN CUSTOMERS c ON o.CUSTOMER_ID = c.ID"+
+		" WHERE o.TOTAL > 100 AND c.NAME = 'O''Hara' EMIT CHANGES;", sql)
+	require.Nil(t, parser.ParseSql(sql))
+
+	sql, err = ksqldb.Select().
+		From("my orders", "").
+		Join(ksqldb.FULL_JOIN, "SHIPMENTS", "").OnExpr("`my orders`.ID = CAST(SHIPMENTS.ORDER_ID AS STRING)").
+		WithinBeforeAfter(90*time.Second, 2*24*time.Hour).
+		GroupBy("`my orders`.ID").
+		Having("COUNT(*) > ?", 1).
+		Limit(10).
+		Build()
+	require.Nil(t, err)
+	require.Equal(t, "SELECT * FROM `my orders` FULL OUTER JOIN SHIPMENTS WITHIN (90 SECONDS, 2 DAYS)"+
+		" ON `my orders`.ID = CAST(SHIPMENTS.ORDER_ID AS STRING) GROUP BY `my orders`.ID HAVING COUNT(*) > 1 LIMIT 10;", sql)
+}
+
+func TestSelect_Errors(t *testing.T) {
+	for msg, builder := range map[string]*ksqldb.SelectBuilder{
+		"SELECT without FROM":                        ksqldb.Select(),
+		"ON without join":                            ksqldb.Select().From("A", "").On("A.ID", "B.ID"),
+		"join of B without ON":                       ksqldb.Select().From("A", "").Join(ksqldb.INNER_JOIN, "B", ""),
+		"join of B with GRACE PERIOD without WITHIN": ksqldb.Select().From("A", "").Join(ksqldb.INNER_JOIN, "B", "").On("A.ID", "B.ID").Grace(time.Minute),
+		"invalid WITHIN of B: 1µs is no positive number of milliseconds": ksqldb.Select().From("A", "").Join(ksqldb.INNER_JOIN, "B", "").Within(time.Microsecond),
+		"invalid WHERE: qbErr: unsupported param type":                   ksqldb.Select().From("A", "").Where("ID = ?", make(chan int)),
+	} {
+		_, err := builder.Build()
+		require.NotNil(t, err, msg)
+		require.True(t, strings.HasPrefix(err.Error(), msg), err.Error())
+	}
+}
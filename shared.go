@@ -0,0 +1,289 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync"
+)
+
+// SharedSubscription is a consumer of a push query shared with other
+// consumers of the same client, see SubscribeShared. Every subscription has
+// its own row channel and receives the rows arriving after it joined.
+// It is safe for concurrent use.
+type SharedSubscription struct {
+	query *sharedQuery
+	rows  chan Row
+	// mu guards the row channel against sends after it is closed
+	mu     sync.Mutex
+	closed bool
+	// done is closed by Unsubscribe or when the query is finished
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Header waits for the header of the shared query, see QueryHandle.Header
+func (s *SharedSubscription) Header() (Header, error) {
+	return s.query.handle.Header()
+}
+
+// Rows returns the row channel of the subscription. It is closed by
+// Unsubscribe or when the shared query is finished.
+func (s *SharedSubscription) Rows() <-chan Row {
+	return s.rows
+}
+
+// Done returns a channel which is closed when the subscription ends
+func (s *SharedSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error the shared query finished with
+func (s *SharedSubscription) Err() error {
+	return s.query.handle.Err()
+}
+
+// Query returns the handle of the shared query. Stopping it ends all of its subscriptions.
+func (s *SharedSubscription) Query() *QueryHandle {
+	return s.query.handle
+}
+
+// Unsubscribe ends the subscription and closes its row channel. The query is
+// stopped on the server when its last subscription ends. Calling Unsubscribe
+// again is a no-op.
+func (s *SharedSubscription) Unsubscribe() {
+	if s.close() {
+		s.query.leave(s)
+	}
+}
+
+// close closes the subscription; false if it was closed already
+func (s *SharedSubscription) close() bool {
+	closed := false
+	s.closeOnce.Do(func() {
+		// done unblocks a pending send, so the lock is released
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		close(s.rows)
+		s.mu.Unlock()
+		closed = true
+	})
+	return closed
+}
+
+// send delivers the row, it blocks until there is room in the row channel or
+// the subscription ends; false if the row was not delivered
+func (s *SharedSubscription) send(row Row) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.rows <- row:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// sharedQuery is a push query with its subscriptions
+type sharedQuery struct {
+	key     string
+	queries *sharedQueries
+	// pooled is true if the rows of the query are pooled, see EnableRowPool
+	pooled bool
+	// started is closed when the query is started, handle and err are set then
+	started chan struct{}
+	handle  *QueryHandle
+	err     error
+	mu      sync.Mutex
+	subs    map[*SharedSubscription]struct{}
+}
+
+// subscribers returns the current subscriptions
+func (q *sharedQuery) subscribers() []*SharedSubscription {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	subs := make([]*SharedSubscription, 0, len(q.subs))
+	for s := range q.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// fanOut delivers the rows of the query to the subscriptions and closes them when the query is finished
+func (q *sharedQuery) fanOut() {
+	for row := range q.handle.Rows() {
+		subs := q.subscribers()
+		for idx, s := range subs {
+			// every subscription gets its own pooled row to release, the
+			// last one gets the row of the query
+			r := row
+			if q.pooled && idx < len(subs)-1 {
+				r = copyRow(row, true)
+			}
+			if !s.send(r) && q.pooled {
+				r.Release()
+			}
+		}
+		if q.pooled && len(subs) == 0 {
+			row.Release()
+		}
+	}
+	q.queries.remove(q)
+	for _, s := range q.subscribers() {
+		s.close()
+	}
+}
+
+// subscribe adds a new subscription with the buffer size, it ends when ctx is done
+func (q *sharedQuery) subscribe(ctx context.Context, bufferSize int) *SharedSubscription {
+	s := &SharedSubscription{
+		query: q,
+		rows:  make(chan Row, bufferSize),
+		done:  make(chan struct{}),
+	}
+	q.mu.Lock()
+	q.subs[s] = struct{}{}
+	q.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Unsubscribe()
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+// join subscribes to the running query; nil if the query was stopped by its
+// last subscription or is finished
+func (q *sharedQuery) join(ctx context.Context, bufferSize int) *SharedSubscription {
+	q.queries.mu.Lock()
+	defer q.queries.mu.Unlock()
+	if q.queries.queries[q.key] != q {
+		return nil
+	}
+	return q.subscribe(ctx, bufferSize)
+}
+
+// leave removes the subscription and stops the query if it was the last one
+func (q *sharedQuery) leave(s *SharedSubscription) {
+	// new subscriptions can't join while the last one leaves
+	q.queries.mu.Lock()
+	q.mu.Lock()
+	delete(q.subs, s)
+	last := len(q.subs) == 0
+	q.mu.Unlock()
+	if last && q.queries.queries[q.key] == q {
+		delete(q.queries.queries, q.key)
+	}
+	q.queries.mu.Unlock()
+	if last {
+		_ = q.handle.Stop()
+	}
+}
+
+// sharedQueries holds the shared push queries of a client by key
+type sharedQueries struct {
+	mu      sync.Mutex
+	queries map[string]*sharedQuery
+}
+
+func (r *sharedQueries) remove(q *sharedQuery) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.queries[q.key] == q {
+		delete(r.queries, q.key)
+	}
+}
+
+// SubscribeShared subscribes to a push query which is shared by all
+// subscriptions of the client with the same sql and options, so many
+// consumers of the same stream start only one query on the server. The
+// query is started by the first subscription and stopped when the last one
+// ends; late subscriptions receive the rows arriving after they joined.
+//
+// Every subscription has a row channel with options.BufferSize. Rows are
+// delivered to the subscriptions one after another, so a slow consumer
+// delays the others; unsubscribe or cancel ctx to leave the query. With
+// EnableRowPool every subscription gets its own row, which it has to release.
+//
+// The shared query doesn't end with the context of the first subscription,
+// the subscription ends when its ctx is done.
+//
+//	sub, err := client.SubscribeShared(ctx, "SELECT * FROM DOGS EMIT CHANGES;", ksqldb.SubscribeOptions{BufferSize: 100})
+//	if err != nil {
+//		return err
+//	}
+//	defer sub.Unsubscribe()
+//	for row := range sub.Rows() {
+//		// process the row
+//	}
+func (api *KsqldbClient) SubscribeShared(ctx context.Context, sql string, options SubscribeOptions) (*SharedSubscription, error) {
+	key, _ := pullCacheKey(QueryOptions{Sql: sql, Properties: options.Properties, PseudoColumns: options.PseudoColumns})
+
+	for {
+		// the query is registered before it is started, so the lock isn't
+		// held during the request and the other subscriptions wait for it
+		api.shared.mu.Lock()
+		q, ok := api.shared.queries[key]
+		if !ok {
+			q = &sharedQuery{key: key, queries: api.shared, pooled: api.rowPool, started: make(chan struct{}), subs: map[*SharedSubscription]struct{}{}}
+			api.shared.queries[key] = q
+		}
+		api.shared.mu.Unlock()
+		if !ok {
+			return api.startShared(ctx, q, sql, options)
+		}
+
+		select {
+		case <-q.started:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if q.err != nil {
+			return nil, q.err
+		}
+		if s := q.join(ctx, options.BufferSize); s != nil {
+			return s, nil
+		}
+		// the query is finished, the next round starts a new one
+	}
+}
+
+// startShared starts the registered query with its first subscription
+func (api *KsqldbClient) startShared(ctx context.Context, q *sharedQuery, sql string, options SubscribeOptions) (*SharedSubscription, error) {
+	// the rows are buffered by the subscriptions
+	shared := options
+	shared.BufferSize = 0
+	handle, err := api.Subscribe(detachedContext{ctx}, sql, shared)
+	if err != nil {
+		q.err = err
+		q.queries.remove(q)
+		close(q.started)
+		return nil, err
+	}
+	q.handle = handle
+	s := q.subscribe(ctx, options.BufferSize)
+	close(q.started)
+	go q.fanOut()
+	return s, nil
+}
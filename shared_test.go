@@ -0,0 +1,121 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestSubscribeShared(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	sub1, err := kcl.SubscribeShared(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 2})
+	require.Nil(t, err)
+	sub2, err := kcl.SubscribeShared(context.TODO(), "SELECT *  FROM dogs EMIT CHANGES;", ksqldb.SubscribeOptions{BufferSize: 2})
+	require.Nil(t, err)
+	require.Len(t, kcl.ActiveQueries(), 1)
+	require.Equal(t, sub1.Query(), sub2.Query())
+
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	for _, sub := range []*ksqldb.SharedSubscription{sub1, sub2} {
+		header, err := sub.Header()
+		require.Nil(t, err)
+		require.Equal(t, "abc", header.QueryId())
+		require.Equal(t, ksqldb.Row{"a"}, <-sub.Rows())
+		require.Equal(t, ksqldb.Row{"b"}, <-sub.Rows())
+	}
+
+	// the query keeps running for the other subscription
+	sub1.Unsubscribe()
+	sub1.Unsubscribe()
+	_, ok := <-sub1.Rows()
+	require.False(t, ok)
+	require.Equal(t, int32(0), atomic.LoadInt32(&m.closed))
+	go m.send(`["c"]`)
+	require.Equal(t, ksqldb.Row{"c"}, <-sub2.Rows())
+
+	// the last subscription stops the query
+	sub2.Unsubscribe()
+	<-sub2.Query().Done()
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+	require.Nil(t, sub2.Err())
+}
+
+func TestSubscribeShared_ContextAndQueryEnd(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub1, err := kcl.SubscribeShared(ctx, "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	sub2, err := kcl.SubscribeShared(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	go m.send(streamHeader)
+	_, err = sub2.Header()
+	require.Nil(t, err)
+
+	// the context of the first subscription ends only the subscription
+	cancel()
+	<-sub1.Done()
+	_, ok := <-sub1.Rows()
+	require.False(t, ok)
+	require.Equal(t, ksqldb.QUERY_RUNNING, sub2.Query().State())
+
+	// the end of the query ends the subscriptions
+	kcl.Close()
+	select {
+	case <-sub2.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not ended")
+	}
+	_, ok = <-sub2.Rows()
+	require.False(t, ok)
+
+	// new subscriptions don't join the finished query
+	_, err = kcl.SubscribeShared(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.ErrorIs(t, err, ksqldb.ErrClientShutdown)
+}
+
+func TestSubscribeShared_RowPool(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableRowPool(true)
+
+	sub1, err := kcl.SubscribeShared(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 2})
+	require.Nil(t, err)
+	defer sub1.Unsubscribe()
+	sub2, err := kcl.SubscribeShared(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 2})
+	require.Nil(t, err)
+	defer sub2.Unsubscribe()
+
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	row1, row2 := <-sub1.Rows(), <-sub2.Rows()
+	// the release of one subscription doesn't clear the row of the other one
+	row1.Release()
+	require.Equal(t, ksqldb.Row{"a"}, row2)
+	row2.Release()
+	require.Equal(t, ksqldb.Row{"b"}, <-sub1.Rows())
+	require.Equal(t, ksqldb.Row{"b"}, <-sub2.Rows())
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"time"
+)
+
+// drainInterval is the polling interval of Shutdown while waiting for drained row channels
+const drainInterval = 10 * time.Millisecond
+
+// Shutdown gracefully shuts the client down.
+//
+// It stops accepting new queries (they return ErrClientShutdown), stops
+// the active push queries and closes them on the server, and waits
+// until the consumers have received the rows buffered in the row channels
+// and the failed close requests are retried successfully, see PendingCloses.
+// When ctx is done before, the remaining queries are abandoned and ctx.Err()
+// is returned. Finally the underlying http transport is closed.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := client.Shutdown(ctx); err != nil {
+//		log.Printf("shutdown: %v", err)
+//	}
+func (api *KsqldbClient) Shutdown(ctx context.Context) error {
+	defer api.Close()
+	if api.queries == nil {
+		return nil
+	}
+
+	queries := api.queries.close()
+	for _, q := range queries {
+		q.cancel()
+	}
+
+	// wait for the queries to be closed on the server
+	for _, q := range queries {
+		select {
+		case <-q.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// wait for the retried close requests
+	if api.closer != nil {
+		if err := api.closer.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	// wait for the consumers
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+	for !drained(queries) {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// drained returns true if the row channels of all queries are empty
+func drained(queries []*QueryHandle) bool {
+	for _, q := range queries {
+		if q.pending() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// deliverRow sends the row to the channel. A cancelled or stopping query
+// does not block on a slow consumer, the row is dropped.
+func deliverRow(ctx context.Context, stopping <-chan struct{}, rowChannel chan<- Row, row Row) {
+	// prefer delivery, the select below chooses randomly if both are ready
+	select {
+	case rowChannel <- row:
+		return
+	default:
+	}
+	select {
+	case rowChannel <- row:
+	case <-ctx.Done():
+	case <-stopping:
+	}
+}
+
+// deliverHeader sends the header to the channel unless the query is cancelled or stopping
+func deliverHeader(ctx context.Context, stopping <-chan struct{}, headerChannel chan<- Header, header Header) {
+	select {
+	case headerChannel <- header:
+		return
+	default:
+	}
+	select {
+	case headerChannel <- header:
+	case <-ctx.Done():
+	case <-stopping:
+	}
+}
+
+// detachedContext keeps the values of its parent, but not its
+// cancellation and deadline. Used to close stopped queries on the server.
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline returns no deadline
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns nil, the context is never done
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil, the context is never done
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Value returns the value of the parent context
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
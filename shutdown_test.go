@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// streamMock is a http client mock with an endless query stream.
+// The stream ends when the request context is done, like a real response body,
+// or when the query is closed.
+type streamMock struct {
+	mocknet.HTTPClient
+	stream *io.PipeWriter
+	// closed counts the close-query requests
+	closed int32
+}
+
+func newStreamMock() *streamMock {
+	m := &streamMock{}
+	pr, pw := io.Pipe()
+	m.stream = pw
+	m.On("GetUrl", ksqldb.QUERY_STREAM_ENDPOINT).Return("http://localhost/query-stream")
+	m.On("GetUrl", ksqldb.CLOSE_QUERY_ENDPOINT).Return("http://localhost/close-query")
+	m.On("Close").Return()
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		if req.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT {
+			atomic.AddInt32(&m.closed, 1)
+			pw.Close()
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(""))}
+		}
+		go func() {
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: 200, Body: pr}
+	}, nil)
+	return m
+}
+
+func (m *streamMock) send(lines ...string) {
+	for _, line := range lines {
+		_, _ = m.stream.Write([]byte(line + "\n"))
+	}
+}
+
+const streamHeader = `{"queryId":"abc","columnNames":["ID"],"columnTypes":["STRING"]}`
+
+func TestShutdown(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	rowChannel := make(chan ksqldb.Row, 2)
+	headerChannel := make(chan ksqldb.Header, 1)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	<-headerChannel
+	require.Eventually(t, func() bool { return len(rowChannel) == 2 }, time.Second, time.Millisecond)
+
+	shutdown := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- kcl.Shutdown(ctx)
+	}()
+
+	require.Nil(t, <-done)
+	// the buffered rows are still delivered
+	require.Equal(t, ksqldb.Row{"a"}, <-rowChannel)
+	require.Equal(t, ksqldb.Row{"b"}, <-rowChannel)
+	require.Nil(t, <-shutdown)
+	_, ok := <-rowChannel
+	require.False(t, ok)
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+	m.AssertCalled(t, "Close")
+
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	require.Equal(t, ksqldb.ErrClientShutdown, err)
+	_, _, err = kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs where id = 1;"})
+	require.Equal(t, ksqldb.ErrClientShutdown, err)
+}
+
+func TestShutdown_DrainTimeout(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	rowChannel := make(chan ksqldb.Row, 1)
+	headerChannel := make(chan ksqldb.Header, 1)
+	go func() {
+		_ = kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+	go m.send(streamHeader, `["a"]`)
+	<-headerChannel
+	require.Eventually(t, func() bool { return len(rowChannel) == 1 }, time.Second, time.Millisecond)
+
+	// nobody receives the buffered row
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, kcl.Shutdown(ctx))
+	m.AssertCalled(t, "Close")
+}
+
+func TestShutdown_NoQueries(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	require.Nil(t, kcl.Shutdown(context.Background()))
+	m.AssertCalled(t, "Close")
+}
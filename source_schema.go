@@ -0,0 +1,384 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Record is a decoded row keyed by column names
+type Record map[string]interface{}
+
+// SourceSchema is the schema of a stream or table returned by DESCRIBE
+type SourceSchema struct {
+	Name        string
+	Type        string
+	Topic       string
+	KeyFormat   string
+	ValueFormat string
+	// WindowType is empty for non windowed sources
+	WindowType string
+	Columns    []SchemaColumn
+}
+
+// SchemaColumn is a column of a SourceSchema
+type SchemaColumn struct {
+	Name   string
+	Key    bool
+	Schema Schema
+}
+
+// SourceSchema runs DESCRIBE on the stream or table with the given name
+// and returns its schema.
+//
+// Names which are not plain identifiers are back quoted, so they are
+// case sensitive.
+func (api *KsqldbClient) SourceSchema(ctx context.Context, name string) (*SourceSchema, error) {
+	desc, err := api.describe(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewSourceSchema(*desc), nil
+}
+
+// describe runs DESCRIBE on the source and returns its description
+func (api *KsqldbClient) describe(ctx context.Context, name string) (*SourceDescription, error) {
+	stmnt := fmt.Sprintf("DESCRIBE %v;", quoteFieldName(name))
+	response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+	if err != nil {
+		return nil, fmt.Errorf("can't describe %v: %w", name, err)
+	}
+
+	for _, item := range *response {
+		if item.SourceDescription != nil {
+			return item.SourceDescription, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no source description for %v", ErrNotFound, name)
+}
+
+// NewSourceSchema creates a SourceSchema from a SourceDescription
+func NewSourceSchema(desc SourceDescription) *SourceSchema {
+	schema := SourceSchema{
+		Name:        desc.Name,
+		Type:        desc.Type,
+		Topic:       desc.Topic,
+		KeyFormat:   desc.KeyFormat,
+		ValueFormat: desc.ValueFormat,
+		WindowType:  desc.WindowType,
+	}
+	for _, field := range desc.Fields {
+		schema.Columns = append(schema.Columns, SchemaColumn{
+			Name:   field.Name,
+			Key:    field.Type == "KEY",
+			Schema: field.Schema,
+		})
+	}
+	return &schema
+}
+
+// Windowed returns true if the source is windowed
+func (s *SourceSchema) Windowed() bool {
+	return s.WindowType != ""
+}
+
+// Column returns the column with the given name
+func (s *SourceSchema) Column(name string) (SchemaColumn, bool) {
+	for _, col := range s.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return SchemaColumn{}, false
+}
+
+// KeyColumns returns the key columns of the source
+func (s *SourceSchema) KeyColumns() []SchemaColumn {
+	keys := []SchemaColumn{}
+	for _, col := range s.Columns {
+		if col.Key {
+			keys = append(keys, col)
+		}
+	}
+	return keys
+}
+
+// NewRecord returns a record with all columns of the source set to nil
+func (s *SourceSchema) NewRecord() Record {
+	record := make(Record, len(s.Columns))
+	for _, col := range s.Columns {
+		record[col.Name] = nil
+	}
+	return record
+}
+
+// String returns the ksql type of the schema, ex. MAP<STRING, ARRAY<INTEGER>>
+func (s Schema) String() string {
+	switch s.Type {
+	case "ARRAY":
+		if s.MemberSchema != nil {
+			return fmt.Sprintf("ARRAY<%v>", s.MemberSchema)
+		}
+	case "MAP":
+		if s.MemberSchema != nil {
+			return fmt.Sprintf("MAP<STRING, %v>", s.MemberSchema)
+		}
+	case "STRUCT":
+		fields := make([]string, len(s.Fields))
+		for idx, field := range s.Fields {
+			fields[idx] = QuoteIdentifier(field.Name) + " " + field.Schema.String()
+		}
+		return "STRUCT<" + strings.Join(fields, ", ") + ">"
+	case "DECIMAL":
+		precision, okp := s.Parameters["precision"]
+		scale, oks := s.Parameters["scale"]
+		if okp && oks {
+			return fmt.Sprintf("DECIMAL(%v, %v)", precision, scale)
+		}
+	}
+	return s.Type
+}
+
+// ParseSchema parses a ksql type like it is returned in the column types
+// of a query header, ex. STRUCT<`NAME` STRING, `TAGS` ARRAY<STRING>>.
+// It is the inverse of Schema.String.
+func ParseSchema(typ string) (Schema, error) {
+	p := typeParser{input: typ}
+	schema, err := p.parse()
+	if err != nil {
+		return Schema{}, fmt.Errorf("can't parse type %v: %w", typ, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return Schema{}, fmt.Errorf("can't parse type %v: unexpected %q", typ, p.input[p.pos:])
+	}
+	return schema, nil
+}
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// expect consumes the character c
+func (p *typeParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.pos >= len(p.input) || p.input[p.pos] != c {
+		return fmt.Errorf("%q expected at %v", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// peek returns true if the next character is c
+func (p *typeParser) peek(c byte) bool {
+	p.skipSpaces()
+	return p.pos < len(p.input) && p.input[p.pos] == c
+}
+
+// ident reads a plain or back quoted identifier
+func (p *typeParser) ident() (string, error) {
+	p.skipSpaces()
+	if p.peek('`') {
+		p.pos++
+		var sb strings.Builder
+		for p.pos < len(p.input) {
+			c := p.input[p.pos]
+			p.pos++
+			if c == '`' {
+				if p.pos < len(p.input) && p.input[p.pos] == '`' {
+					sb.WriteByte('`')
+					p.pos++
+					continue
+				}
+				return sb.String(), nil
+			}
+			sb.WriteByte(c)
+		}
+		return "", fmt.Errorf("unterminated quoted identifier")
+	}
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if !(c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		return "", fmt.Errorf("identifier expected at %v", start)
+	}
+	return p.input[start:p.pos], nil
+}
+
+func (p *typeParser) parse() (Schema, error) {
+	name, err := p.ident()
+	if err != nil {
+		return Schema{}, err
+	}
+	schema := Schema{Type: strings.ToUpper(name)}
+
+	switch schema.Type {
+	case "ARRAY", "MAP":
+		if err := p.expect('<'); err != nil {
+			return Schema{}, err
+		}
+		member, err := p.parse()
+		if err != nil {
+			return Schema{}, err
+		}
+		if schema.Type == "MAP" {
+			// the key type is dropped, the member schema is the value schema
+			if err := p.expect(','); err != nil {
+				return Schema{}, err
+			}
+			if member, err = p.parse(); err != nil {
+				return Schema{}, err
+			}
+		}
+		schema.MemberSchema = &member
+		return schema, p.expect('>')
+	case "STRUCT":
+		if err := p.expect('<'); err != nil {
+			return Schema{}, err
+		}
+		schema.Fields = []Field{}
+		for !p.peek('>') {
+			if len(schema.Fields) > 0 {
+				if err := p.expect(','); err != nil {
+					return Schema{}, err
+				}
+			}
+			fieldName, err := p.ident()
+			if err != nil {
+				return Schema{}, err
+			}
+			fieldSchema, err := p.parse()
+			if err != nil {
+				return Schema{}, err
+			}
+			schema.Fields = append(schema.Fields, Field{Name: fieldName, Schema: fieldSchema})
+		}
+		return schema, p.expect('>')
+	case "DECIMAL":
+		if !p.peek('(') {
+			return schema, nil
+		}
+		p.pos++
+		precision, err := p.ident()
+		if err != nil {
+			return Schema{}, err
+		}
+		if err := p.expect(','); err != nil {
+			return Schema{}, err
+		}
+		scale, err := p.ident()
+		if err != nil {
+			return Schema{}, err
+		}
+		pv, perr := strconv.Atoi(precision)
+		sv, serr := strconv.Atoi(scale)
+		if perr != nil || serr != nil {
+			return Schema{}, fmt.Errorf("invalid precision or scale %v, %v", precision, scale)
+		}
+		schema.Parameters = map[string]interface{}{"precision": pv, "scale": sv}
+		return schema, p.expect(')')
+	}
+	return schema, nil
+}
+
+// DecodePlan returns a plan to decode the rows of a query with the given header.
+//
+// Header columns not found in the source, ex. expressions or WINDOWSTART,
+// are decoded as they are.
+func (s *SourceSchema) DecodePlan(header Header) *DecodePlan {
+	plan := DecodePlan{}
+	for _, col := range header.columns {
+		pc := planColumn{name: col.Name}
+		if sc, ok := s.Column(col.Name); ok {
+			schema := sc.Schema
+			pc.schema = &schema
+		}
+		plan.columns = append(plan.columns, pc)
+	}
+	return &plan
+}
+
+// NewDecodePlan returns a plan to decode rows with the column types of the header.
+//
+// BOOLEAN values are decoded as bool, INTEGER as int32, BIGINT as int64, DOUBLE and
+// DECIMAL as float64, BYTES as []byte, TIMESTAMP, DATE and TIME as time.Time, ARRAY
+// as []interface{} and MAP and STRUCT as map[string]interface{}.
+func NewDecodePlan(header Header) (*DecodePlan, error) {
+	plan := DecodePlan{}
+	for _, col := range header.columns {
+		schema, err := ParseSchema(col.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %w", col.Name, err)
+		}
+		plan.columns = append(plan.columns, planColumn{name: col.Name, schema: &schema})
+	}
+	return &plan, nil
+}
+
+// DecodePlan decodes rows into records
+type DecodePlan struct {
+	columns []planColumn
+}
+
+type planColumn struct {
+	name   string
+	schema *Schema
+}
+
+// Columns returns the column names of the records
+func (p *DecodePlan) Columns() []string {
+	names := make([]string, len(p.columns))
+	for idx, col := range p.columns {
+		names[idx] = col.name
+	}
+	return names
+}
+
+// Decode converts the row into a record
+func (p *DecodePlan) Decode(row Row) (Record, error) {
+	if len(row) != len(p.columns) {
+		return nil, fmt.Errorf("can't decode row: got %v values for %v columns", len(row), len(p.columns))
+	}
+	record := make(Record, len(row))
+	for idx, col := range p.columns {
+		if col.schema == nil {
+			record[col.name] = row[idx]
+			continue
+		}
+		v, err := convertValue(*col.schema, row[idx])
+		if err != nil {
+			return nil, fmt.Errorf("can't decode column %v: %w", col.name, err)
+		}
+		record[col.name] = v
+	}
+	return record, nil
+}
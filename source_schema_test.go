@@ -0,0 +1,135 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+const describeDogs = `[{"@type":"sourceDescription","statementText":"DESCRIBE DOGS;","warnings":[],
+"sourceDescription":{"name":"DOGS","windowType":null,"readQueries":[],"writeQueries":[],
+"fields":[{"name":"ID","schema":{"type":"STRING","fields":null,"memberSchema":null},"type":"KEY"},
+{"name":"AGE","schema":{"type":"INTEGER","fields":null,"memberSchema":null}},
+{"name":"TAGS","schema":{"type":"ARRAY","fields":null,"memberSchema":{"type":"STRING","fields":null,"memberSchema":null}}}],
+"type":"STREAM","timestamp":"","statistics":"","errorStats":"","extended":false,
+"keyFormat":"KAFKA","valueFormat":"JSON","topic":"dogs","partitions":0,"replication":0}}]`
+
+func mockDescribe(t *testing.T, response string) *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/ksql")
+	m.Mock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		var options ksqldb.ExecOptions
+		body, _ := ioutil.ReadAll(req.Body)
+		require.Nil(t, json.Unmarshal(body, &options))
+		require.Equal(t, "DESCRIBE DOGS;", options.KSql)
+		return true
+	})).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(response))),
+	}, nil)
+	return &m
+}
+
+func TestSourceSchema(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockDescribe(t, describeDogs))
+	schema, err := kcl.SourceSchema(context.Background(), "DOGS")
+	require.Nil(t, err)
+	require.Equal(t, "DOGS", schema.Name)
+	require.Equal(t, "STREAM", schema.Type)
+	require.Equal(t, "dogs", schema.Topic)
+	require.False(t, schema.Windowed())
+	require.Len(t, schema.Columns, 3)
+	require.Equal(t, []ksqldb.SchemaColumn{{Name: "ID", Key: true, Schema: ksqldb.Schema{Type: "STRING"}}}, schema.KeyColumns())
+	require.Equal(t, ksqldb.Record{"ID": nil, "AGE": nil, "TAGS": nil}, schema.NewRecord())
+
+	_, ok := schema.Column("NAME")
+	require.False(t, ok)
+}
+
+func TestSourceSchema_NotFound(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockDescribe(t, `[]`))
+	schema, err := kcl.SourceSchema(context.Background(), "DOGS")
+	require.Nil(t, schema)
+	require.True(t, errors.Is(err, ksqldb.ErrNotFound))
+}
+
+func TestSourceSchema_DecodePlan(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockDescribe(t, describeDogs))
+	schema, err := kcl.SourceSchema(context.Background(), "DOGS")
+	require.Nil(t, err)
+
+	header := ksqldb.NewHeader("", []ksqldb.Column{{Name: "ID", Type: "STRING"}, {Name: "AGE", Type: "INTEGER"}, {Name: "CNT", Type: "BIGINT"}})
+	plan := schema.DecodePlan(header)
+	require.Equal(t, []string{"ID", "AGE", "CNT"}, plan.Columns())
+
+	record, err := plan.Decode(ksqldb.Row{"1", float64(3), float64(7)})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Record{"ID": "1", "AGE": int32(3), "CNT": float64(7)}, record)
+
+	_, err = plan.Decode(ksqldb.Row{"1"})
+	require.NotNil(t, err)
+
+	_, err = plan.Decode(ksqldb.Row{"1", "3", nil})
+	require.NotNil(t, err)
+	require.Equal(t, "can't decode column AGE: can't convert string to INTEGER", err.Error())
+}
+
+func TestSchema_String(t *testing.T) {
+	schema := ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{
+		{Name: "Tags", Schema: ksqldb.Schema{Type: "ARRAY", MemberSchema: &ksqldb.Schema{Type: "STRING"}}},
+		{Name: "SCORES", Schema: ksqldb.Schema{Type: "MAP", MemberSchema: &ksqldb.Schema{Type: "BIGINT"}}},
+		{Name: "PRICE", Schema: ksqldb.Schema{Type: "DECIMAL", Parameters: map[string]interface{}{"precision": 4, "scale": 2}}},
+	}}
+	require.Equal(t, "STRUCT<`Tags` ARRAY<STRING>, `SCORES` MAP<STRING, BIGINT>, `PRICE` DECIMAL(4, 2)>", schema.String())
+}
+
+func TestParseSchema(t *testing.T) {
+	for _, typ := range []string{
+		"STRING",
+		"DECIMAL(4, 2)",
+		"ARRAY<STRUCT<`A` INTEGER>>",
+		"MAP<STRING, ARRAY<BIGINT>>",
+		"STRUCT<`NAME` STRING, `my``field` MAP<STRING, DOUBLE>>",
+		"STRUCT<>",
+	} {
+		schema, err := ksqldb.ParseSchema(typ)
+		require.Nil(t, err, typ)
+		require.Equal(t, typ, schema.String())
+	}
+
+	schema, err := ksqldb.ParseSchema("struct<name varchar>")
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Schema{Type: "STRUCT", Fields: []ksqldb.Field{{Name: "name", Schema: ksqldb.Schema{Type: "VARCHAR"}}}}, schema)
+}
+
+func TestParseSchema_Errors(t *testing.T) {
+	for _, typ := range []string{"", "ARRAY<STRING", "MAP<STRING>", "STRUCT<`A STRING>", "DECIMAL(a, 2)", "STRING STRING"} {
+		_, err := ksqldb.ParseSchema(typ)
+		require.NotNil(t, err, typ)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListStreams runs SHOW STREAMS and returns the streams
+func (api *KsqldbClient) ListStreams(ctx context.Context) ([]Stream, error) {
+	response, err := api.execute(ctx, ExecOptions{KSql: "SHOW STREAMS;"})
+	if err != nil {
+		return nil, fmt.Errorf("can't list streams: %w", err)
+	}
+	for _, item := range *response {
+		if item.Stream != nil {
+			return *item.Stream, nil
+		}
+	}
+	return []Stream{}, nil
+}
+
+// ListTables runs SHOW TABLES and returns the tables
+func (api *KsqldbClient) ListTables(ctx context.Context) ([]Table, error) {
+	response, err := api.execute(ctx, ExecOptions{KSql: "SHOW TABLES;"})
+	if err != nil {
+		return nil, fmt.Errorf("can't list tables: %w", err)
+	}
+	for _, item := range *response {
+		if item.Tables != nil {
+			return *item.Tables, nil
+		}
+	}
+	return []Table{}, nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestListStreamsAndTables(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW STREAMS;", "SHOW TABLES;"}, []string{
+		`[{"@type":"streams","statementText":"SHOW STREAMS;","streams":[{"type":"STREAM","name":"DOGS","topic":"dogs","keyFormat":"KAFKA","valueFormat":"JSON","isWindowed":false}],"warnings":[]}]`,
+		`[{"@type":"tables","statementText":"SHOW TABLES;","tables":[{"type":"TABLE","name":"DOGS_BY_SIZE","topic":"DOGS_BY_SIZE","keyFormat":"KAFKA","valueFormat":"JSON","isWindowed":true}],"warnings":[]}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	streams, err := kcl.ListStreams(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []ksqldb.Stream{{Name: "DOGS", Topic: "dogs", Type: "STREAM"}}, streams)
+
+	tables, err := kcl.ListTables(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []ksqldb.Table{{Name: "DOGS_BY_SIZE", Topic: "DOGS_BY_SIZE", Type: "TABLE", IsWindowed: true}}, tables)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"time"
+)
+
+// TypeMismatchError is returned in strict type mode if a value
+// doesn't match the declared type of its column
+type TypeMismatchError struct {
+	// Column of the value, ex. ADDRESS.ZIP or TAGS[1] for nested values
+	Column string
+	// Expected is the declared ksql type
+	Expected string
+	// Got is the Go type of the json decoded value
+	Got string
+	// Value is the json decoded value
+	Value interface{}
+}
+
+func (e TypeMismatchError) Error() string {
+	return fmt.Sprintf("column %v: expected %v, got %v (%v)", e.Column, e.Expected, e.Got, e.Value)
+}
+
+// EnableStrictTypes enables / disables strict type checking.
+// In strict mode all rows of Pull and Push are checked against the column types
+// of the header. The first mismatch is returned as TypeMismatchError.
+func (cl *KsqldbClient) EnableStrictTypes(activate bool) {
+	cl.strictTypes = activate
+}
+
+// StrictTypesEnabled returns true if strict type checking is enabled; false otherwise
+func (cl *KsqldbClient) StrictTypesEnabled() bool {
+	return cl.strictTypes
+}
+
+// CheckRow checks the values of the row against the column types of the header
+func (h Header) CheckRow(row Row) error {
+	plan, err := NewDecodePlan(h)
+	if err != nil {
+		return err
+	}
+	return plan.Check(row)
+}
+
+// Check checks the json decoded values of the row against the column types.
+// Columns without a known type are not checked.
+func (p *DecodePlan) Check(row Row) error {
+	if len(row) != len(p.columns) {
+		return fmt.Errorf("can't check row: got %v values for %v columns", len(row), len(p.columns))
+	}
+	for idx, col := range p.columns {
+		if col.schema == nil {
+			continue
+		}
+		if err := checkValue(col.name, *col.schema, row[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkValue(path string, schema Schema, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	ok := true
+	switch schema.Type {
+	case "BOOLEAN":
+		_, ok = value.(bool)
+	case "INTEGER", "INT":
+		v, isNumber := value.(float64)
+		ok = isNumber && v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32
+	case "BIGINT":
+		v, isNumber := value.(float64)
+		ok = isNumber && v == math.Trunc(v)
+	case "DOUBLE", "DECIMAL":
+		_, ok = value.(float64)
+	case "STRING", "VARCHAR":
+		_, ok = value.(string)
+	case "BYTES":
+		v, isString := value.(string)
+		if ok = isString; ok {
+			_, err := base64.StdEncoding.DecodeString(v)
+			ok = err == nil
+		}
+	case "TIMESTAMP", "DATE", "TIME":
+		ok = checkTime(schema.Type, value)
+	case "ARRAY":
+		items, isArray := value.([]interface{})
+		if ok = isArray; ok && schema.MemberSchema != nil {
+			for idx, item := range items {
+				if err := checkValue(fmt.Sprintf("%v[%v]", path, idx), *schema.MemberSchema, item); err != nil {
+					return err
+				}
+			}
+		}
+	case "MAP":
+		items, isMap := value.(map[string]interface{})
+		if ok = isMap; ok && schema.MemberSchema != nil {
+			for key, item := range items {
+				if err := checkValue(fmt.Sprintf("%v[%v]", path, key), *schema.MemberSchema, item); err != nil {
+					return err
+				}
+			}
+		}
+	case "STRUCT":
+		fields, isMap := value.(map[string]interface{})
+		if ok = isMap; ok {
+			for _, field := range schema.Fields {
+				if err := checkValue(path+"."+field.Name, field.Schema, fields[field.Name]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !ok {
+		return TypeMismatchError{Column: path, Expected: schema.String(), Got: fmt.Sprintf("%T", value), Value: value}
+	}
+	return nil
+}
+
+func checkTime(typ string, value interface{}) bool {
+	v, ok := value.(string)
+	if !ok {
+		return false
+	}
+	layout := KSQL_TIMESTAMP_FORMAT
+	switch typ {
+	case "DATE":
+		layout = KSQL_DATE_FORMAT
+	case "TIME":
+		layout = KSQL_TIME_FORMAT
+	}
+	_, err := time.Parse(layout, v)
+	return err == nil
+}
+
+// checkPayload checks all rows of the payload
+func checkPayload(header Header, payload Payload) error {
+	plan, err := NewDecodePlan(header)
+	if err != nil {
+		return err
+	}
+	for _, row := range payload {
+		if err := plan.Check(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+var strictHeader = ksqldb.NewHeader("", []ksqldb.Column{
+	{Name: "ID", Type: "STRING"},
+	{Name: "AGE", Type: "INTEGER"},
+	{Name: "BORN", Type: "TIMESTAMP"},
+	{Name: "TAGS", Type: "ARRAY<STRING>"},
+	{Name: "ADDRESS", Type: "STRUCT<`ZIP` BIGINT>"},
+})
+
+func TestHeader_CheckRow(t *testing.T) {
+	require.Nil(t, strictHeader.CheckRow(ksqldb.Row{"1", float64(3), "2021-11-16T06:00:00.000", []interface{}{"a"}, map[string]interface{}{"ZIP": float64(1)}}))
+	require.Nil(t, strictHeader.CheckRow(ksqldb.Row{nil, nil, nil, nil, nil}))
+
+	tests := []struct {
+		name string
+		row  ksqldb.Row
+		want ksqldb.TypeMismatchError
+	}{
+		{"string", ksqldb.Row{float64(1), nil, nil, nil, nil}, ksqldb.TypeMismatchError{Column: "ID", Expected: "STRING", Got: "float64", Value: float64(1)}},
+		{"fraction", ksqldb.Row{nil, 3.5, nil, nil, nil}, ksqldb.TypeMismatchError{Column: "AGE", Expected: "INTEGER", Got: "float64", Value: 3.5}},
+		{"overflow", ksqldb.Row{nil, float64(1 << 40), nil, nil, nil}, ksqldb.TypeMismatchError{Column: "AGE", Expected: "INTEGER", Got: "float64", Value: float64(1 << 40)}},
+		{"timestamp", ksqldb.Row{nil, nil, "yesterday", nil, nil}, ksqldb.TypeMismatchError{Column: "BORN", Expected: "TIMESTAMP", Got: "string", Value: "yesterday"}},
+		{"array item", ksqldb.Row{nil, nil, nil, []interface{}{"a", true}, nil}, ksqldb.TypeMismatchError{Column: "TAGS[1]", Expected: "STRING", Got: "bool", Value: true}},
+		{"struct field", ksqldb.Row{nil, nil, nil, nil, map[string]interface{}{"ZIP": "1"}}, ksqldb.TypeMismatchError{Column: "ADDRESS.ZIP", Expected: "BIGINT", Got: "string", Value: "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := strictHeader.CheckRow(tt.row)
+			var mismatch ksqldb.TypeMismatchError
+			require.True(t, errors.As(err, &mismatch))
+			require.Equal(t, tt.want, mismatch)
+		})
+	}
+
+	err := strictHeader.CheckRow(ksqldb.Row{"1", "3", nil, nil, nil})
+	require.Equal(t, "column AGE: expected INTEGER, got string (3)", err.Error())
+}
+
+func TestClient_EnableStrictTypes(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(&mocknet.HTTPClient{})
+	require.False(t, kcl.StrictTypesEnabled())
+	kcl.EnableStrictTypes(true)
+	require.True(t, kcl.StrictTypesEnabled())
+}
+
+func TestPull_StrictTypes(t *testing.T) {
+	body := `[{"queryId":null,"columnNames":["ID","AGE"],"columnTypes":["STRING","INTEGER"]},["1",3],["2","old"]]`
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return("http://localhost/query-stream")
+	m.On("Do", mock.Anything).Return(func(*http.Request) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}
+	}, nil)
+	kcl, _ := ksqldb.NewClient(&m)
+	kcl.EnableParseSQL(false)
+
+	_, payload, err := kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.Nil(t, err)
+	require.Len(t, payload, 2)
+
+	kcl.EnableStrictTypes(true)
+	_, _, err = kcl.Pull(context.TODO(), ksqldb.QueryOptions{Sql: "select * from dogs;"})
+	require.NotNil(t, err)
+	require.Equal(t, "column AGE: expected INTEGER, got string (old)", err.Error())
+}
+
+func TestPush_StrictTypesClosesQuery(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableStrictTypes(true)
+
+	rowChannel := make(chan ksqldb.Row, 2)
+	headerChannel := make(chan ksqldb.Header, 1)
+	go m.send(streamHeader, `["a"]`, `[1]`)
+
+	err := kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	var mismatch ksqldb.TypeMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+
+	require.Equal(t, ksqldb.Row{"a"}, <-rowChannel)
+	_, ok := <-rowChannel
+	require.False(t, ok)
+	_, ok = <-headerChannel
+	require.True(t, ok)
+	_, ok = <-headerChannel
+	require.False(t, ok)
+}
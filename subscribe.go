@@ -0,0 +1,86 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+)
+
+// SubscribeOptions configures a push query started with Subscribe
+type SubscribeOptions struct {
+	// BufferSize is the capacity of the row channel
+	BufferSize int
+	// Properties of the query; ksql.streams.auto.offset.reset defaults to latest
+	Properties PropertyMap
+	// PseudoColumns are added to the select list, see SelectPseudoColumns
+	PseudoColumns []string
+}
+
+// Subscribe starts the push query in its own goroutine and returns its handle.
+// Errors before the query is started, ex. sql syntax errors, are returned directly.
+//
+// The rows are received from handle.Rows(), which is closed when the query is
+// finished. handle.Err() returns the error the query finished with.
+//
+//	q, err := client.Subscribe(ctx, "SELECT * FROM DOGS EMIT CHANGES;", ksqldb.SubscribeOptions{BufferSize: 100})
+//	if err != nil {
+//		return err
+//	}
+//	header, err := q.Header()
+//	...
+//	for row := range q.Rows() {
+//		// process the row
+//	}
+//	return q.Err()
+func (api *KsqldbClient) Subscribe(ctx context.Context, sql string, options SubscribeOptions) (*QueryHandle, error) {
+	properties := PropertyMap{"ksql.streams.auto.offset.reset": "latest"}
+	for k, v := range options.Properties {
+		properties[k] = v
+	}
+
+	if len(options.PseudoColumns) > 0 {
+		var err error
+		if sql, err = SelectPseudoColumns(sql, options.PseudoColumns...); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := make(chan Row, options.BufferSize)
+	started := make(chan *QueryHandle, 1)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- api.push(ctx, sql, properties, rows, nil, func(h *QueryHandle) {
+			h.mu.Lock()
+			h.rows = rows
+			h.mu.Unlock()
+			started <- h
+		})
+	}()
+
+	select {
+	case h := <-started:
+		return h, nil
+	case err := <-failed:
+		// the query may have been started and finished already
+		select {
+		case h := <-started:
+			return h, nil
+		default:
+		}
+		return nil, err
+	}
+}
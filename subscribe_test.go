@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestSubscribe(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 1})
+	require.Nil(t, err)
+	require.Equal(t, []*ksqldb.QueryHandle{q}, kcl.ActiveQueries())
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+
+	header, err := q.Header()
+	require.Nil(t, err)
+	require.Equal(t, "abc", header.QueryId())
+	require.Equal(t, []ksqldb.Column{{Name: "ID", Type: "STRING"}}, header.Columns())
+	require.Equal(t, ksqldb.Row{"a"}, <-q.Rows())
+	require.Equal(t, ksqldb.Row{"b"}, <-q.Rows())
+
+	require.Nil(t, q.Stop())
+	_, ok := <-q.Rows()
+	require.False(t, ok)
+	<-q.Done()
+	require.Equal(t, ksqldb.QUERY_CLOSED, q.State())
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+	require.Empty(t, kcl.ActiveQueries())
+}
+
+func TestQueryHandle_Stop(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	// the context is shared with other queries
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q, err := kcl.Subscribe(ctx, "select * from dogs emit changes;", ksqldb.SubscribeOptions{BufferSize: 1})
+	require.Nil(t, err)
+	go m.send(streamHeader, `["a"]`, `["b"]`)
+	_, err = q.Header()
+	require.Nil(t, err)
+	require.Eventually(t, func() bool { return q.Metrics().RowsReceived() == 2 }, time.Second, time.Millisecond)
+
+	// the consumer stops while the channel is full
+	require.Nil(t, q.Stop())
+	require.Nil(t, q.Stop())
+	require.Nil(t, ctx.Err())
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+
+	// the buffered row is flushed, the one not fitting is dropped
+	require.Equal(t, ksqldb.Row{"a"}, <-q.Rows())
+	_, ok := <-q.Rows()
+	require.False(t, ok)
+}
+
+func TestQueryHandle_StopPush(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	rowChannel := make(chan ksqldb.Row)
+	headerChannel := make(chan ksqldb.Header)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(context.TODO(), "select * from dogs emit changes;", rowChannel, headerChannel)
+	}()
+	go m.send(streamHeader)
+	<-headerChannel
+
+	queries := kcl.ActiveQueries()
+	require.Len(t, queries, 1)
+	require.Nil(t, queries[0].Stop())
+	require.Nil(t, <-done)
+	_, ok := <-rowChannel
+	require.False(t, ok)
+	require.Equal(t, int32(1), atomic.LoadInt32(&m.closed))
+}
+
+func TestSubscribe_Error(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from;", ksqldb.SubscribeOptions{})
+	require.Nil(t, q)
+	require.IsType(t, &parser.SqlSyntaxErrorList{}, err)
+
+	q, err = kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	// the stream breaks before the header
+	go m.send(`{"queryId"`)
+	_, err = q.Header()
+	require.NotNil(t, err)
+	require.Equal(t, err, q.Err())
+	_, ok := <-q.Rows()
+	require.False(t, ok)
+}
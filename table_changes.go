@@ -0,0 +1,184 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeOp is the operation of a ChangeEvent
+type ChangeOp string
+
+const (
+	// CHANGE_INSERT is the first row of a key
+	CHANGE_INSERT ChangeOp = "INSERT"
+	// CHANGE_UPDATE is a row of a key with a previous row
+	CHANGE_UPDATE ChangeOp = "UPDATE"
+	// CHANGE_DELETE is a tombstone of a key, see IsTombstone
+	CHANGE_DELETE ChangeOp = "DELETE"
+)
+
+// ChangeEvent is a change of a row of a table
+type ChangeEvent struct {
+	// Key are the values of the key columns, for windowed tables
+	// followed by WINDOWSTART and WINDOWEND
+	Key Row
+	// Before is the previous row of the key; nil for CHANGE_INSERT
+	Before Record
+	// After is the new row of the key; nil for CHANGE_DELETE
+	After Record
+	Op    ChangeOp
+}
+
+// TableChangesOptions configures TableChanges
+type TableChangesOptions struct {
+	// KeyColumns of the table; empty runs DESCRIBE to find the key columns
+	KeyColumns []string
+	// BufferSize is the capacity of the event channel
+	BufferSize int
+	// Properties of the push query, see SubscribeOptions
+	Properties PropertyMap
+}
+
+// TableChanges subscribes to the changes of the table with
+// SELECT * FROM <table> EMIT CHANGES and delivers them as ChangeEvent with
+// the previous and the new row of the key, like change data capture.
+//
+// The previous rows are kept in memory for every key of the table. The event
+// channel is closed when the query is finished, handle.Err() returns the error
+// the query finished with. Rows which can't be decoded are skipped.
+// The events have to be received until the channel is closed or the
+// context is cancelled.
+//
+//	events, handle, err := client.TableChanges(ctx, "DOGS_BY_SIZE", ksqldb.TableChangesOptions{})
+//	...
+//	for event := range events {
+//		fmt.Println(event.Op, event.Key, event.Before["DOGS_CT"], "->", event.After["DOGS_CT"])
+//	}
+func (api *KsqldbClient) TableChanges(ctx context.Context, table string, options TableChangesOptions) (<-chan ChangeEvent, *QueryHandle, error) {
+	keyColumns := options.KeyColumns
+	if len(keyColumns) == 0 {
+		desc, err := api.describe(ctx, table)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, field := range desc.Fields {
+			if field.Type == "KEY" {
+				keyColumns = append(keyColumns, field.Name)
+			}
+		}
+		if len(keyColumns) == 0 {
+			return nil, nil, fmt.Errorf("%w: %v has no key columns", ErrMissingKey, table)
+		}
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %v EMIT CHANGES;", quoteFieldName(table))
+	handle, err := api.Subscribe(ctx, sql, SubscribeOptions{Properties: options.Properties})
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := handle.Header()
+	if err != nil {
+		_ = handle.Stop()
+		return nil, nil, err
+	}
+	tracker, err := newChangeTracker(header, keyColumns)
+	if err != nil {
+		_ = handle.Stop()
+		return nil, nil, err
+	}
+
+	events := make(chan ChangeEvent, options.BufferSize)
+	go func() {
+		defer close(events)
+		for row := range handle.Rows() {
+			event, err := tracker.change(row)
+			if api.rowPool {
+				row.Release()
+			}
+			if err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, handle, nil
+}
+
+// changeTracker keeps the previous row of every key
+type changeTracker struct {
+	plan *DecodePlan
+	// keys are the indexes of the key columns
+	keys     []int
+	previous map[string]Record
+}
+
+func newChangeTracker(header Header, keyColumns []string) (*changeTracker, error) {
+	plan, err := NewDecodePlan(header)
+	if err != nil {
+		return nil, err
+	}
+	t := &changeTracker{plan: plan, previous: map[string]Record{}}
+	index := map[string]int{}
+	for idx, col := range header.Columns() {
+		index[col.Name] = idx
+	}
+	for _, name := range append(keyColumns, "WINDOWSTART", "WINDOWEND") {
+		idx, ok := index[name]
+		if !ok {
+			if name == "WINDOWSTART" || name == "WINDOWEND" {
+				continue
+			}
+			return nil, fmt.Errorf("%w: key column %v not found in %v", ErrMissingKey, name, header.Columns())
+		}
+		t.keys = append(t.keys, idx)
+	}
+	return t, nil
+}
+
+// change returns the change event of the row and remembers the row as previous row of its key.
+// Tombstones remove the previous row.
+func (t *changeTracker) change(row Row) (ChangeEvent, error) {
+	event := ChangeEvent{Key: make(Row, len(t.keys)), Op: CHANGE_INSERT}
+	for idx, col := range t.keys {
+		event.Key[idx] = row[col]
+	}
+	key := fmt.Sprint([]interface{}(event.Key))
+	before, exists := t.previous[key]
+	if exists {
+		event.Before = before
+		event.Op = CHANGE_UPDATE
+	}
+
+	if IsTombstone(row, t.keys...) {
+		event.Op = CHANGE_DELETE
+		delete(t.previous, key)
+		return event, nil
+	}
+	after, err := t.plan.Decode(row)
+	if err != nil {
+		return ChangeEvent{}, err
+	}
+	event.After = after
+	t.previous[key] = after
+	return event, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestTableChanges(t *testing.T) {
+	m := mockKsql(t, []string{"DESCRIBE DOGS_BY_SIZE;"}, []string{
+		`[{"@type":"sourceDescription","sourceDescription":{"name":"DOGS_BY_SIZE","type":"TABLE","fields":[` +
+			`{"name":"DOG_SIZE","schema":{"type":"STRING"},"type":"KEY"},{"name":"DOGS_CT","schema":{"type":"BIGINT"}}]}}]`,
+	})
+	changes, stream := io.Pipe()
+	m.Mock.On("Do", matchStatement(t, "SELECT * FROM DOGS_BY_SIZE EMIT CHANGES;")).Return(&http.Response{StatusCode: http.StatusOK, Body: changes}, nil).Once()
+	kcl, _ := ksqldb.NewClient(m)
+
+	go func() {
+		_, _ = stream.Write([]byte(`{"queryId":"abc","columnNames":["WINDOWSTART","WINDOWEND","DOG_SIZE","DOGS_CT"],"columnTypes":["BIGINT","BIGINT","STRING","BIGINT"]}` + "\n"))
+		_, _ = stream.Write([]byte(`[0,10,"small",1]` + "\n"))
+		_, _ = stream.Write([]byte(`[0,10,"large",1]` + "\n"))
+		_, _ = stream.Write([]byte(`[0,10,"small",2]` + "\n"))
+		_, _ = stream.Write([]byte(`[10,20,"small",1]` + "\n"))
+		_, _ = stream.Write([]byte(`[0,10,"small",null]` + "\n"))
+		_, _ = stream.Write([]byte(`[0,10,"small",3]` + "\n"))
+		stream.Close()
+	}()
+	events, handle, err := kcl.TableChanges(context.Background(), "DOGS_BY_SIZE", ksqldb.TableChangesOptions{})
+	require.Nil(t, err)
+
+	received := []ksqldb.ChangeEvent{}
+	for event := range events {
+		received = append(received, event)
+	}
+	require.Nil(t, handle.Err())
+	require.Len(t, received, 6)
+
+	require.Equal(t, ksqldb.CHANGE_INSERT, received[0].Op)
+	require.Equal(t, ksqldb.Row{"small", float64(0), float64(10)}, received[0].Key)
+	require.Nil(t, received[0].Before)
+	require.Equal(t, ksqldb.CHANGE_INSERT, received[1].Op)
+
+	require.Equal(t, ksqldb.CHANGE_UPDATE, received[2].Op)
+	require.Equal(t, int64(1), received[2].Before["DOGS_CT"])
+	require.Equal(t, int64(2), received[2].After["DOGS_CT"])
+
+	// another window is another key
+	require.Equal(t, ksqldb.CHANGE_INSERT, received[3].Op)
+
+	// tombstones delete the key
+	require.Equal(t, ksqldb.CHANGE_DELETE, received[4].Op)
+	require.Equal(t, int64(2), received[4].Before["DOGS_CT"])
+	require.Nil(t, received[4].After)
+	require.Equal(t, ksqldb.CHANGE_INSERT, received[5].Op)
+	m.AssertExpectations(t)
+}
+
+func TestTableChanges_MissingKey(t *testing.T) {
+	m := mockKsql(t, []string{"DESCRIBE DOGS;"}, []string{
+		`[{"@type":"sourceDescription","sourceDescription":{"name":"DOGS","type":"STREAM","fields":[{"name":"ID","schema":{"type":"STRING"}}]}}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	_, _, err := kcl.TableChanges(context.Background(), "DOGS", ksqldb.TableChangesOptions{})
+	require.Equal(t, "missing key column: DOGS has no key columns", err.Error())
+
+	changes, stream := io.Pipe()
+	m.Mock.On("Do", matchStatement(t, "SELECT * FROM DOGS EMIT CHANGES;")).Return(&http.Response{StatusCode: http.StatusOK, Body: changes}, nil).Once()
+	m.Mock.On("Do", mock.MatchedBy(func(r *http.Request) bool { return r.URL.Path == ksqldb.CLOSE_QUERY_ENDPOINT })).
+		Return(pullResponse(""), nil).Maybe()
+	go func() {
+		_, _ = stream.Write([]byte(streamHeader + "\n"))
+		stream.Close()
+	}()
+	_, _, err = kcl.TableChanges(context.Background(), "DOGS", ksqldb.TableChangesOptions{KeyColumns: []string{"NAME"}})
+	require.Equal(t, "missing key column: key column NAME not found in [{ID STRING}]", err.Error())
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// TableScanPolicy is applied to pull queries which scan the whole table, see SetTableScanPolicy
+type TableScanPolicy int
+
+const (
+	// TABLE_SCAN_ALLOW sends pull queries without checking them (default)
+	TABLE_SCAN_ALLOW TableScanPolicy = iota
+	// TABLE_SCAN_WARN calls the OnTableScan callback and sends the query
+	TABLE_SCAN_WARN
+	// TABLE_SCAN_REJECT fails the query with ErrTableScan without sending it
+	TABLE_SCAN_REJECT
+)
+
+// SetTableScanPolicy checks pull queries for a predicate on every key column
+// of the table before they are sent. Queries without one scan the whole
+// table, which is a common cause of production incidents. The key columns are
+// looked up with DESCRIBE once per table.
+//
+// Queries which enable table scans explicitly with EnablePullQueryTableScan(true)
+// are not checked. The check is a heuristic: it doesn't prove the server uses a
+// key lookup, ex. for keys compared with other operators than = and IN.
+func (cl *KsqldbClient) SetTableScanPolicy(policy TableScanPolicy) {
+	cl.tableScanPolicy = policy
+	if cl.tableKeys == nil {
+		cl.tableKeys = &tableKeys{keys: map[string][]string{}}
+	}
+}
+
+// TableScanPolicy returns the table scan policy of pull queries
+func (cl *KsqldbClient) TableScanPolicy() TableScanPolicy {
+	return cl.tableScanPolicy
+}
+
+// OnTableScan sets the callback of TABLE_SCAN_WARN, it is called with the
+// pull query and the reason, ex. no WHERE clause. Set it before running queries.
+func (cl *KsqldbClient) OnTableScan(fn func(sql string, reason string)) {
+	cl.onTableScan = fn
+}
+
+// tableKeys caches the key columns of tables by name
+type tableKeys struct {
+	mu   sync.Mutex
+	keys map[string][]string
+}
+
+// checkTableScan applies the table scan policy to the pull query
+func (api *KsqldbClient) checkTableScan(ctx context.Context, options QueryOptions) error {
+	if api.tableScanPolicy == TABLE_SCAN_ALLOW || options.Properties[KSQL_QUERY_PULL_TABLE_SCAN_ENABLED] == "true" {
+		return nil
+	}
+	reason := api.tableScanReason(ctx, options.Sql)
+	if reason == "" {
+		return nil
+	}
+	if api.tableScanPolicy == TABLE_SCAN_REJECT {
+		return fmt.Errorf("%w: %v", ErrTableScan, reason)
+	}
+	if api.onTableScan != nil {
+		api.onTableScan(options.Sql, reason)
+	}
+	return nil
+}
+
+// tableScanReason returns why the pull query scans the table; empty if it doesn't
+// or if it can't be decided, the server reports those queries
+func (api *KsqldbClient) tableScanReason(ctx context.Context, sql string) string {
+	nodes, err := parser.Parse(sql)
+	if err != nil || len(nodes) != 1 || nodes[0].Query == nil || nodes[0].Query.From == nil {
+		return ""
+	}
+	query := nodes[0].Query
+	if query.Where == "" {
+		return "no WHERE clause"
+	}
+	or, compared := wherePredicates(query.Where)
+	if or {
+		return "OR in the WHERE clause"
+	}
+	keys, err := api.tableKeys.lookup(ctx, api, query.From.Name)
+	if err != nil {
+		return ""
+	}
+	for _, key := range keys {
+		if !compared[key] {
+			return fmt.Sprintf("no key predicate on %v", key)
+		}
+	}
+	return ""
+}
+
+// wherePredicates returns whether the WHERE clause has an OR and the names of the
+// columns compared with = or IN. The clause is tokenized, so OR and column names
+// inside of string literals don't count.
+func wherePredicates(where string) (or bool, compared map[string]bool) {
+	var tokens []antlr.Token
+	for _, token := range parser.Tokenize(where) {
+		if token.GetChannel() == antlr.TokenDefaultChannel {
+			tokens = append(tokens, token)
+		}
+	}
+	compared = map[string]bool{}
+	for idx, token := range tokens {
+		switch token.GetTokenType() {
+		case parser.KSqlLexerOR:
+			or = true
+		case parser.KSqlLexerEQ, parser.KSqlLexerIN:
+			if idx > 0 {
+				compared[columnName(tokens[idx-1])] = true
+			}
+		}
+	}
+	return or, compared
+}
+
+// columnName returns the column name of the identifier token; empty for string literals
+func columnName(token antlr.Token) string {
+	text := token.GetText()
+	switch token.GetTokenType() {
+	case parser.KSqlLexerSTRING:
+		return ""
+	case parser.KSqlLexerBACKQUOTED_IDENTIFIER:
+		return strings.ReplaceAll(text[1:len(text)-1], "``", "`")
+	case parser.KSqlLexerQUOTED_IDENTIFIER:
+		return strings.ReplaceAll(text[1:len(text)-1], `""`, `"`)
+	}
+	return strings.ToUpper(text)
+}
+
+// lookup returns the key columns of the table
+func (t *tableKeys) lookup(ctx context.Context, api *KsqldbClient, table string) ([]string, error) {
+	t.mu.Lock()
+	keys, ok := t.keys[table]
+	t.mu.Unlock()
+	if ok {
+		return keys, nil
+	}
+	desc, err := api.describe(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range NewSourceSchema(*desc).KeyColumns() {
+		keys = append(keys, col.Name)
+	}
+	t.mu.Lock()
+	t.keys[table] = keys
+	t.mu.Unlock()
+	return keys, nil
+}
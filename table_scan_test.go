@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+const describeDogsBySize = `[{"@type":"sourceDescription","sourceDescription":{"name":"DOGS_BY_SIZE","type":"TABLE","fields":[` +
+	`{"name":"DOG_SIZE","schema":{"type":"STRING"},"type":"KEY"},{"name":"DOGS_CT","schema":{"type":"BIGINT"}}]}}]`
+
+func TestPull_TableScanReject(t *testing.T) {
+	// the key columns are described once
+	m := mockKsql(t, []string{"DESCRIBE DOGS_BY_SIZE;", "SELECT * FROM DOGS_BY_SIZE WHERE DOG_SIZE = 'small';"}, []string{
+		describeDogsBySize,
+		`[{"queryId":null,"columnNames":["DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","BIGINT"]},["small",2]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetTableScanPolicy(ksqldb.TABLE_SCAN_REJECT)
+	require.Equal(t, ksqldb.TABLE_SCAN_REJECT, kcl.TableScanPolicy())
+	ctx := context.Background()
+
+	_, _, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE;"})
+	require.True(t, errors.Is(err, ksqldb.ErrTableScan))
+	require.Equal(t, "pull query scans the table: no WHERE clause", err.Error())
+
+	_, _, err = kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE WHERE DOGS_CT > 1;"})
+	require.Equal(t, "pull query scans the table: no key predicate on DOG_SIZE", err.Error())
+
+	_, _, err = kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE WHERE DOG_SIZE = 'small' OR DOGS_CT > 1;"})
+	require.Equal(t, "pull query scans the table: OR in the WHERE clause", err.Error())
+
+	_, rows, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE WHERE DOG_SIZE = 'small';"})
+	require.Nil(t, err)
+	require.Len(t, rows, 1)
+
+	// explicitly enabled table scans are not checked
+	m.Mock.On("Do", matchStatement(t, "SELECT * FROM DOGS_BY_SIZE;")).Return(pullResponse(`[{"queryId":null,"columnNames":["DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","BIGINT"]},["small",2]]`), nil).Once()
+	options := ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE;"}
+	options.EnablePullQueryTableScan(true)
+	_, _, err = kcl.Pull(ctx, options)
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+}
+
+func TestPull_TableScanWarn(t *testing.T) {
+	m := mockKsql(t, []string{"SELECT * FROM DOGS_BY_SIZE;"}, []string{
+		`[{"queryId":null,"columnNames":["DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","BIGINT"]},["small",2]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetTableScanPolicy(ksqldb.TABLE_SCAN_WARN)
+	var warnings []string
+	kcl.OnTableScan(func(sql string, reason string) {
+		warnings = append(warnings, sql+": "+reason)
+	})
+
+	_, _, err := kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE;"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"SELECT * FROM DOGS_BY_SIZE;: no WHERE clause"}, warnings)
+}
+
+func TestPull_TableScanLiterals(t *testing.T) {
+	stmnt := "SELECT * FROM DOGS_BY_SIZE WHERE DOG_SIZE = 'OR' AND DOGS_CT > 1;"
+	m := mockKsql(t, []string{"DESCRIBE DOGS_BY_SIZE;", stmnt}, []string{
+		describeDogsBySize,
+		`[{"queryId":null,"columnNames":["DOG_SIZE","DOGS_CT"],"columnTypes":["STRING","BIGINT"]},["OR",2]]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetTableScanPolicy(ksqldb.TABLE_SCAN_REJECT)
+	ctx := context.Background()
+
+	// OR in string literals isn't a predicate
+	_, rows, err := kcl.Pull(ctx, ksqldb.QueryOptions{Sql: stmnt})
+	require.Nil(t, err)
+	require.Len(t, rows, 1)
+
+	// nor is a key predicate in a string literal
+	_, _, err = kcl.Pull(ctx, ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS_BY_SIZE WHERE DOGS_CT > 1 AND NAME = 'DOG_SIZE = 1';"})
+	require.Equal(t, "pull query scans the table: no key predicate on DOG_SIZE", err.Error())
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// TeardownOptions of TeardownSource
+type TeardownOptions struct {
+	// DeleteTopic deletes the topics of the dropped sources
+	DeleteTopic bool
+	// Cascade tears down the sinks of the queries reading from the source first
+	Cascade bool
+	// DryRun only returns the planned actions
+	DryRun bool
+}
+
+// TeardownAction is a statement of a teardown
+type TeardownAction struct {
+	// Kind is TERMINATE or DROP
+	Kind string
+	// Target is the query id or the source name
+	Target    string
+	Statement string
+}
+
+// TeardownSource drops the stream or table after terminating the queries
+// reading from and writing into it, which are discovered with DESCRIBE.
+// With options.Cascade the sinks of the reading queries are torn down first,
+// in dependency order.
+//
+// The executed actions are returned, with options.DryRun the planned actions
+// are returned without executing them. If an action fails, the actions
+// executed before are returned with the error.
+func (api *KsqldbClient) TeardownSource(ctx context.Context, name string, options TeardownOptions) ([]TeardownAction, error) {
+	p := teardownPlan{api: api, options: options, terminated: map[string]bool{}, visited: map[string]bool{}}
+	if err := p.plan(ctx, name); err != nil {
+		return nil, err
+	}
+	if options.DryRun {
+		return p.actions, nil
+	}
+
+	for idx, action := range p.actions {
+		if _, err := api.execute(ctx, ExecOptions{KSql: action.Statement}); err != nil {
+			return p.actions[:idx], fmt.Errorf("can't tear down %v: %w", name, err)
+		}
+	}
+	return p.actions, nil
+}
+
+// teardownPlan collects the actions of a teardown
+type teardownPlan struct {
+	api        *KsqldbClient
+	options    TeardownOptions
+	actions    []TeardownAction
+	terminated map[string]bool
+	// visited are the planned sources
+	visited map[string]bool
+}
+
+func (p *teardownPlan) plan(ctx context.Context, name string) error {
+	if p.visited[name] {
+		return nil
+	}
+	p.visited[name] = true
+
+	desc, err := p.api.describe(ctx, name)
+	if err != nil {
+		return err
+	}
+	if p.options.Cascade {
+		for _, q := range desc.ReadQueries {
+			for _, sink := range q.Sinks {
+				if err := p.plan(ctx, sink); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, q := range append(append([]RunningQuery{}, desc.ReadQueries...), desc.WriteQueries...) {
+		if p.terminated[q.ID] {
+			continue
+		}
+		p.terminated[q.ID] = true
+		p.actions = append(p.actions, TeardownAction{Kind: "TERMINATE", Target: q.ID, Statement: fmt.Sprintf("TERMINATE %v;", q.ID)})
+	}
+
+	stmnt := fmt.Sprintf("DROP %v %v", desc.Type, quoteFieldName(desc.Name))
+	if p.options.DeleteTopic {
+		stmnt += " DELETE TOPIC"
+	}
+	p.actions = append(p.actions, TeardownAction{Kind: "DROP", Target: desc.Name, Statement: stmnt + ";"})
+	return nil
+}
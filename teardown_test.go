@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// describeSource returns a DESCRIBE response of the source with the read and write queries
+func describeSource(name string, typ string, readQueries string, writeQueries string) string {
+	return fmt.Sprintf(`[{"@type":"sourceDescription","statementText":"DESCRIBE %v;","sourceDescription":{"name":"%v","type":"%v",
+"readQueries":[%v],"writeQueries":[%v],"fields":[],"keyFormat":"KAFKA","valueFormat":"JSON","topic":"%v"}}]`,
+		name, name, typ, readQueries, writeQueries, name)
+}
+
+func TestTeardownSource(t *testing.T) {
+	dogs := describeSource("DOGS", "STREAM",
+		`{"queryString":"CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS;","sinks":["BIG_DOGS"],"id":"CSAS_BIG_DOGS_3","queryType":"PERSISTENT","state":"RUNNING"},
+{"queryString":"CREATE TABLE DOGS_BY_SIZE AS SELECT ...;","sinks":["DOGS_BY_SIZE"],"id":"CTAS_DOGS_BY_SIZE_7"}`,
+		`{"queryString":"INSERT INTO DOGS SELECT * FROM PUPPIES;","sinks":["DOGS"],"id":"INSERTQUERY_5"}`)
+	bigDogs := describeSource("BIG_DOGS", "STREAM", ``, `{"sinks":["BIG_DOGS"],"id":"CSAS_BIG_DOGS_3"}`)
+	dogsBySize := describeSource("DOGS_BY_SIZE", "TABLE", `{"sinks":["BIG_SIZES"],"id":"CTAS_BIG_SIZES_9"}`, `{"sinks":["DOGS_BY_SIZE"],"id":"CTAS_DOGS_BY_SIZE_7"}`)
+	bigSizes := describeSource("BIG_SIZES", "TABLE", ``, `{"sinks":["BIG_SIZES"],"id":"CTAS_BIG_SIZES_9"}`)
+
+	m := mockKsql(t,
+		[]string{"DESCRIBE DOGS;", "DESCRIBE BIG_DOGS;", "DESCRIBE DOGS_BY_SIZE;", "DESCRIBE BIG_SIZES;"},
+		[]string{dogs, bigDogs, dogsBySize, bigSizes})
+	kcl, _ := ksqldb.NewClient(m)
+
+	actions, err := kcl.TeardownSource(context.Background(), "DOGS", ksqldb.TeardownOptions{Cascade: true, DeleteTopic: true, DryRun: true})
+	require.Nil(t, err)
+	statements := []string{}
+	for _, action := range actions {
+		require.Nil(t, parser.ParseSql(action.Statement))
+		statements = append(statements, action.Statement)
+	}
+	require.Equal(t, []string{
+		"TERMINATE CSAS_BIG_DOGS_3;",
+		"DROP STREAM BIG_DOGS DELETE TOPIC;",
+		"TERMINATE CTAS_BIG_SIZES_9;",
+		"DROP TABLE BIG_SIZES DELETE TOPIC;",
+		"TERMINATE CTAS_DOGS_BY_SIZE_7;",
+		"DROP TABLE DOGS_BY_SIZE DELETE TOPIC;",
+		"TERMINATE INSERTQUERY_5;",
+		"DROP STREAM DOGS DELETE TOPIC;",
+	}, statements)
+	require.Equal(t, ksqldb.TeardownAction{Kind: "DROP", Target: "DOGS", Statement: "DROP STREAM DOGS DELETE TOPIC;"}, actions[7])
+	m.AssertExpectations(t)
+}
+
+func TestTeardownSource_Execute(t *testing.T) {
+	dogs := describeSource("DOGS", "STREAM", `{"sinks":["BIG_DOGS"],"id":"CSAS_BIG_DOGS_3"}`, ``)
+	m := mockKsql(t,
+		[]string{"DESCRIBE DOGS;", "TERMINATE CSAS_BIG_DOGS_3;", "DROP STREAM DOGS;"},
+		[]string{dogs, `[]`, `[]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	actions, err := kcl.TeardownSource(context.Background(), "DOGS", ksqldb.TeardownOptions{})
+	require.Nil(t, err)
+	require.Len(t, actions, 2)
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,216 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Identifier is a template variable which is rendered as identifier,
+// ex. the name of a stream. Plain identifiers are rendered as they are,
+// other names are back quoted.
+type Identifier string
+
+// StatementTemplate is a ksql statement with {{ }} actions of text/template.
+//
+// Unlike text/template the output of the actions is escaped by the ksql
+// context of the action:
+//
+//	CREATE STREAM {{.Name}} ...          Identifier as identifier, other values as literal (see QuoteLiteral)
+//	... KAFKA_TOPIC='{{.Tenant}}_dogs'   inside of a string literal, single quotes are escaped
+//	... `{{.Column}}` ...                inside of a quoted identifier, back quotes are escaped
+//
+// Actions inside of comments are not allowed.
+type StatementTemplate struct {
+	tmpl *template.Template
+	err  error
+}
+
+// Template parses the statement template, errors are returned by Render
+//
+//	stmnt, err := ksqldb.Template("CREATE STREAM {{.Name}} WITH (KAFKA_TOPIC='{{.Topic}}');").
+//		Render(map[string]interface{}{"Name": ksqldb.Identifier("DOGS"), "Topic": "dogs"})
+func Template(text string) *StatementTemplate {
+	t := StatementTemplate{}
+	t.tmpl, t.err = template.New("ksql").Funcs(template.FuncMap{
+		"_ksqlValue":      escapeValue,
+		"_ksqlString":     escapeString,
+		"_ksqlIdentifier": escapeIdentifier,
+	}).Parse(text)
+	if t.err != nil {
+		return &t
+	}
+	if t.tmpl.Tree == nil || t.tmpl.Tree.Root == nil {
+		return &t
+	}
+	end, err := escapeList(t.tmpl.Tree.Root, ksqlText)
+	if err == nil && end != ksqlText && end != ksqlLineComment {
+		err = fmt.Errorf("unterminated %v", end)
+	}
+	t.err = err
+	return &t
+}
+
+// Render renders the template with the params, ex. a map or a struct
+func (t *StatementTemplate) Render(params interface{}) (string, error) {
+	if t.err != nil {
+		return "", fmt.Errorf("invalid template: %w", t.err)
+	}
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ksqlContext is the lexical context of the template text
+type ksqlContext string
+
+const (
+	ksqlText         ksqlContext = "text"
+	ksqlString       ksqlContext = "string literal"
+	ksqlIdentifier   ksqlContext = "quoted identifier"
+	ksqlLineComment  ksqlContext = "line comment"
+	ksqlBlockComment ksqlContext = "block comment"
+)
+
+// escapers are the escape functions of the contexts
+var escapers = map[ksqlContext]string{
+	ksqlText:       "_ksqlValue",
+	ksqlString:     "_ksqlString",
+	ksqlIdentifier: "_ksqlIdentifier",
+}
+
+// escapeList adds the escapers to the actions of the list and returns the context after the list
+func escapeList(list *parse.ListNode, ctx ksqlContext) (ksqlContext, error) {
+	if list == nil {
+		return ctx, nil
+	}
+	var err error
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			ctx = scanText(string(n.Text), ctx)
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 {
+				// declarations don't write output
+				continue
+			}
+			escaper, ok := escapers[ctx]
+			if !ok {
+				return ctx, fmt.Errorf("action %v inside of %v", n, ctx)
+			}
+			cmd := &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos}
+			cmd.Args = []parse.Node{parse.NewIdentifier(escaper).SetTree(nil).SetPos(n.Pos)}
+			n.Pipe.Cmds = append(n.Pipe.Cmds, cmd)
+		case *parse.IfNode:
+			ctx, err = escapeBranch("if", &n.BranchNode, ctx)
+		case *parse.RangeNode:
+			ctx, err = escapeBranch("range", &n.BranchNode, ctx)
+		case *parse.WithNode:
+			ctx, err = escapeBranch("with", &n.BranchNode, ctx)
+		case *parse.TemplateNode:
+			err = fmt.Errorf("template %v is not supported", n.Name)
+		}
+		if err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// escapeBranch escapes the lists of if, range and with, all lists have to end in the same context
+func escapeBranch(name string, branch *parse.BranchNode, ctx ksqlContext) (ksqlContext, error) {
+	end, err := escapeList(branch.List, ctx)
+	if err != nil {
+		return ctx, err
+	}
+	elseEnd, err := escapeList(branch.ElseList, ctx)
+	if err != nil {
+		return ctx, err
+	}
+	if end != ctx || elseEnd != ctx {
+		return ctx, fmt.Errorf("branches of %v have to end in the %v context", name, ctx)
+	}
+	return ctx, nil
+}
+
+// scanText returns the context after the text
+func scanText(text string, ctx ksqlContext) ksqlContext {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch ctx {
+		case ksqlText:
+			switch {
+			case c == '\'':
+				ctx = ksqlString
+			case c == '`':
+				ctx = ksqlIdentifier
+			case strings.HasPrefix(text[i:], "--"):
+				ctx = ksqlLineComment
+				i++
+			case strings.HasPrefix(text[i:], "/*"):
+				ctx = ksqlBlockComment
+				i++
+			}
+		case ksqlString:
+			// escaped quotes leave and enter the literal
+			if c == '\'' {
+				ctx = ksqlText
+			}
+		case ksqlIdentifier:
+			if c == '`' {
+				ctx = ksqlText
+			}
+		case ksqlLineComment:
+			if c == '\n' {
+				ctx = ksqlText
+			}
+		case ksqlBlockComment:
+			if strings.HasPrefix(text[i:], "*/") {
+				ctx = ksqlText
+				i++
+			}
+		}
+	}
+	return ctx
+}
+
+// escapeValue renders identifiers as identifiers and other values as literals
+func escapeValue(value interface{}) (string, error) {
+	if id, ok := value.(Identifier); ok {
+		if id == "" {
+			return "", fmt.Errorf("empty identifier")
+		}
+		return quoteFieldName(string(id)), nil
+	}
+	return QuoteLiteral(value)
+}
+
+// escapeString renders the value inside of a string literal
+func escapeString(value interface{}) string {
+	return strings.ReplaceAll(fmt.Sprint(value), "'", "''")
+}
+
+// escapeIdentifier renders the value inside of a quoted identifier
+func escapeIdentifier(value interface{}) string {
+	return strings.ReplaceAll(fmt.Sprint(value), "`", "``")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl := ksqldb.Template("CREATE STREAM {{.Name}} (`{{.Key}}` STRING KEY) WITH (KAFKA_TOPIC='{{.Tenant}}_dogs', PARTITIONS={{.Partitions}})" +
+		" -- a comment with a quote '\n;" +
+		"INSERT INTO {{.Name}} (`{{.Key}}`) VALUES ({{.Value}});")
+
+	stmnt, err := tmpl.Render(map[string]interface{}{
+		"Name":       ksqldb.Identifier("tenant_1_dogs"),
+		"Key":        "i`d",
+		"Tenant":     "o'brien",
+		"Partitions": 3,
+		"Value":      "x'); DROP STREAM DOGS; --",
+	})
+	require.Nil(t, err)
+	require.Equal(t, "CREATE STREAM tenant_1_dogs (`i``d` STRING KEY) WITH (KAFKA_TOPIC='o''brien_dogs', PARTITIONS=3)"+
+		" -- a comment with a quote '\n;"+
+		"INSERT INTO tenant_1_dogs (`i``d`) VALUES ('x''); DROP STREAM DOGS; --');", stmnt)
+
+	stmnt, err = tmpl.Render(struct {
+		Name       ksqldb.Identifier
+		Key        string
+		Tenant     string
+		Partitions int
+		Value      bool
+	}{"my dogs", "ID", "t1", 1, true})
+	require.Nil(t, err)
+	require.Contains(t, stmnt, "CREATE STREAM `my dogs` (`ID` STRING KEY)")
+	require.Contains(t, stmnt, "VALUES (true)")
+}
+
+func TestTemplate_Branches(t *testing.T) {
+	tmpl := ksqldb.Template("SELECT * FROM {{.Name}}{{if .Id}} WHERE ID = {{.Id}}{{end}};")
+	stmnt, err := tmpl.Render(map[string]interface{}{"Name": ksqldb.Identifier("DOGS"), "Id": "1"})
+	require.Nil(t, err)
+	require.Equal(t, "SELECT * FROM DOGS WHERE ID = '1';", stmnt)
+
+	stmnt, err = tmpl.Render(map[string]interface{}{"Name": ksqldb.Identifier("DOGS")})
+	require.Nil(t, err)
+	require.Equal(t, "SELECT * FROM DOGS;", stmnt)
+}
+
+func TestTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		err  string
+	}{
+		{"SELECT {{.Name", "invalid template: template: ksql:1: unclosed action"},
+		{"SELECT * FROM DOGS /* {{.Name}} */", "invalid template: action {{.Name}} inside of block comment"},
+		{"SELECT '{{.Name}}", "invalid template: unterminated string literal"},
+		{"SELECT {{if .Name}}'{{end}}'", "invalid template: branches of if have to end in the text context"},
+		{"{{define \"x\"}}X{{end}}SELECT {{template \"x\"}}", "invalid template: template x is not supported"},
+		{"SELECT {{.Name}}", "template: ksql:1:9: executing \"ksql\" at <_ksqlValue>: error calling _ksqlValue: empty identifier"},
+	}
+	for _, tt := range tests {
+		_, err := ksqldb.Template(tt.tmpl).Render(map[string]interface{}{"Name": ksqldb.Identifier("")})
+		require.NotNil(t, err, tt.tmpl)
+		require.Equal(t, tt.err, err.Error())
+	}
+}
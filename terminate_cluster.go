@@ -70,7 +70,7 @@ func (api *KsqldbClient) TerminateCluster(topics ...string) (*KsqlResponseSlice,
 
 	body, readErr := api.readBody(res.Body)
 	if readErr != nil {
-		return nil, fmt.Errorf("could not read response body: %v", readErr)
+		return nil, fmt.Errorf("could not read response body: %w", readErr)
 	}
 
 	if res.StatusCode != http.StatusOK {
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DEFAULT_STATEMENT_TIMEOUT is the default timeout of statements
+	DEFAULT_STATEMENT_TIMEOUT = 30 * time.Second
+	// DEFAULT_PULL_TIMEOUT is the default timeout of pull queries
+	DEFAULT_PULL_TIMEOUT = 30 * time.Second
+)
+
+// Timeouts of the requests of the client, see SetTimeouts.
+// The timeouts are applied to the context of the requests, shorter deadlines
+// of the context are kept. 0 disables a timeout.
+type Timeouts struct {
+	// Statement is the timeout of statements of the /ksql endpoint
+	Statement time.Duration
+	// Pull is the timeout of pull queries
+	Pull time.Duration
+	// PushIdle is the maximum time a push query waits for the next row.
+	// Idle queries are closed and fail with ErrIdleTimeout. Push queries
+	// have no other timeout, they run until they are stopped.
+	PushIdle time.Duration
+}
+
+// SetTimeouts sets the timeouts of statements, pull and push queries.
+// Defaults are DEFAULT_STATEMENT_TIMEOUT, DEFAULT_PULL_TIMEOUT and no idle
+// timeout of push queries.
+//
+// Unlike net.Options.Timeout, which applies to the transport of all requests,
+// the timeouts don't break long running push queries.
+func (cl *KsqldbClient) SetTimeouts(timeouts Timeouts) {
+	cl.timeouts = timeouts
+}
+
+// Timeouts returns the timeouts of the client
+func (cl *KsqldbClient) Timeouts() Timeouts {
+	return cl.timeouts
+}
+
+// withTimeout returns the context with the timeout; the context itself if the timeout is 0
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// idleWatch cancels a push query which waits longer than the timeout for the next line
+type idleWatch struct {
+	timeout time.Duration
+	timer   *time.Timer
+	idled   int32
+}
+
+// newIdleWatch returns an idle watch; nil if the timeout is 0
+func newIdleWatch(timeout time.Duration, cancel context.CancelFunc) *idleWatch {
+	if timeout <= 0 {
+		return nil
+	}
+	w := &idleWatch{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&w.idled, 1)
+		cancel()
+	})
+	w.timer.Stop()
+	return w
+}
+
+// waiting starts the timeout before reading the next line
+func (w *idleWatch) waiting() {
+	if w != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// received stops the timeout after a line was read, the time
+// delivering the row to the consumer doesn't count as idle
+func (w *idleWatch) received() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// timedOut returns true if the query was cancelled by the idle timeout
+func (w *idleWatch) timedOut() bool {
+	return w != nil && atomic.LoadInt32(&w.idled) == 1
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+// blockingMock returns requests which block until their context is done
+func blockingMock() *mocknet.HTTPClient {
+	m := mocknet.HTTPClient{}
+	m.Mock.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	m.Mock.On("Do", mock.Anything).Return(nil, func(req *http.Request) error {
+		<-req.Context().Done()
+		return req.Context().Err()
+	})
+	return &m
+}
+
+func TestTimeouts(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(blockingMock())
+	require.Equal(t, ksqldb.Timeouts{Statement: ksqldb.DEFAULT_STATEMENT_TIMEOUT, Pull: ksqldb.DEFAULT_PULL_TIMEOUT}, kcl.Timeouts())
+	kcl.SetTimeouts(ksqldb.Timeouts{Statement: 10 * time.Millisecond, Pull: 20 * time.Millisecond})
+
+	_, err := kcl.Execute(ksqldb.ExecOptions{KSql: "SHOW STREAMS;"})
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	start := time.Now()
+	_, _, err = kcl.Pull(context.Background(), ksqldb.QueryOptions{Sql: "SELECT * FROM DOGS WHERE ID = '1';"})
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestTimeouts_PushIdle(t *testing.T) {
+	m := newStreamMock()
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.SetTimeouts(ksqldb.Timeouts{PushIdle: 50 * time.Millisecond})
+
+	rowChannel := make(chan ksqldb.Row)
+	done := make(chan error)
+	go func() {
+		done <- kcl.Push(context.Background(), "select * from dogs emit changes;", rowChannel, nil)
+	}()
+	m.send(streamHeader)
+	for i := 0; i < 3; i++ {
+		time.Sleep(20 * time.Millisecond)
+		m.send(`["a"]`)
+		// delivering the row to a slow consumer isn't idle
+		time.Sleep(60 * time.Millisecond)
+		require.Equal(t, ksqldb.Row{"a"}, <-rowChannel)
+	}
+	require.True(t, errors.Is(<-done, ksqldb.ErrIdleTimeout))
+}
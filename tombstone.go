@@ -0,0 +1,62 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+// IsTombstone returns true if the row of a table is a tombstone, a deleted
+// row: all columns except of the key columns are null. keys are the indexes
+// of the key columns, including WINDOWSTART and WINDOWEND of windowed tables.
+// Rows without value columns are never tombstones.
+func IsTombstone(row Row, keys ...int) bool {
+	isKey := make(map[int]bool, len(keys))
+	for _, idx := range keys {
+		isKey[idx] = true
+	}
+	values := 0
+	for idx, value := range row {
+		if isKey[idx] {
+			continue
+		}
+		if value != nil {
+			return false
+		}
+		values++
+	}
+	return values > 0
+}
+
+// IsTombstone returns true if the row of a push query on a table is a
+// tombstone, see IsTombstone. keyColumns are the names of the key columns,
+// WINDOWSTART and WINDOWEND are key columns of windowed tables.
+//
+//	for row := range handle.Rows() {
+//		if header.IsTombstone(row, "DOG_SIZE") {
+//			// the key was deleted
+//		}
+//	}
+func (h Header) IsTombstone(row Row, keyColumns ...string) bool {
+	isKey := map[string]bool{"WINDOWSTART": true, "WINDOWEND": true}
+	for _, name := range keyColumns {
+		isKey[name] = true
+	}
+	keys := []int{}
+	for idx, col := range h.columns {
+		if isKey[col.Name] {
+			keys = append(keys, idx)
+		}
+	}
+	return IsTombstone(row, keys...)
+}
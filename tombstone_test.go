@@ -0,0 +1,41 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestIsTombstone(t *testing.T) {
+	require.True(t, ksqldb.IsTombstone(ksqldb.Row{"small", nil, nil}, 0))
+	require.False(t, ksqldb.IsTombstone(ksqldb.Row{"small", nil, 1}, 0))
+	require.False(t, ksqldb.IsTombstone(ksqldb.Row{"small"}, 0))
+	require.True(t, ksqldb.IsTombstone(ksqldb.Row{nil}))
+
+	header := ksqldb.NewHeader("abc", []ksqldb.Column{
+		{Name: "WINDOWSTART", Type: "BIGINT"},
+		{Name: "WINDOWEND", Type: "BIGINT"},
+		{Name: "DOG_SIZE", Type: "STRING"},
+		{Name: "DOGS_CT", Type: "BIGINT"},
+	})
+	require.True(t, header.IsTombstone(ksqldb.Row{0, 10, "small", nil}, "DOG_SIZE"))
+	require.False(t, header.IsTombstone(ksqldb.Row{0, 10, "small", 1}, "DOG_SIZE"))
+	require.False(t, header.IsTombstone(ksqldb.Row{0, 10, "small", nil}, "DOGS_CT"))
+}
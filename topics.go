@@ -0,0 +1,65 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Topic is a Kafka topic returned by SHOW TOPICS
+type Topic struct {
+	Name string
+	// ReplicaInfo is the number of replicas of each partition
+	ReplicaInfo []int
+	// ConsumerCount and ConsumerGroupCount are only set by ListTopicsExtended
+	ConsumerCount      int
+	ConsumerGroupCount int
+}
+
+type TopicSlice []Topic
+
+// Partitions returns the number of partitions of the topic
+func (t Topic) Partitions() int {
+	return len(t.ReplicaInfo)
+}
+
+// ListTopics runs SHOW TOPICS and returns the topics
+func (api *KsqldbClient) ListTopics(ctx context.Context) ([]Topic, error) {
+	return api.listTopics(ctx, "SHOW TOPICS;")
+}
+
+// ListTopicsExtended runs SHOW TOPICS EXTENDED and returns the topics with
+// the number of their consumers and consumer groups, ex. to find out which
+// topics are actually consumed by queries.
+// This is an expensive operation on the server.
+func (api *KsqldbClient) ListTopicsExtended(ctx context.Context) ([]Topic, error) {
+	return api.listTopics(ctx, "SHOW TOPICS EXTENDED;")
+}
+
+func (api *KsqldbClient) listTopics(ctx context.Context, stmnt string) ([]Topic, error) {
+	response, err := api.execute(ctx, ExecOptions{KSql: stmnt})
+	if err != nil {
+		return nil, fmt.Errorf("can't list topics: %w", err)
+	}
+	for _, item := range *response {
+		if item.Topics != nil {
+			return *item.Topics, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no topics in the response of %v", ErrNotFound, stmnt)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestListTopicsExtended(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW TOPICS EXTENDED;", "SHOW TOPICS;", "SHOW TOPICS;"}, []string{
+		`[{"@type":"kafka_topics_extended","statementText":"SHOW TOPICS EXTENDED;","topics":[
+{"name":"dogs","replicaInfo":[1,1,1],"consumerCount":3,"consumerGroupCount":1},
+{"name":"cats","replicaInfo":[1],"consumerCount":0,"consumerGroupCount":0}],"warnings":[]}]`,
+		`[{"@type":"kafka_topics","statementText":"SHOW TOPICS;","topics":[{"name":"dogs","replicaInfo":[1,1,1]}],"warnings":[]}]`,
+		`[]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	topics, err := kcl.ListTopicsExtended(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []ksqldb.Topic{
+		{Name: "dogs", ReplicaInfo: []int{1, 1, 1}, ConsumerCount: 3, ConsumerGroupCount: 1},
+		{Name: "cats", ReplicaInfo: []int{1}},
+	}, topics)
+	require.Equal(t, 3, topics[0].Partitions())
+
+	topics, err = kcl.ListTopics(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []ksqldb.Topic{{Name: "dogs", ReplicaInfo: []int{1, 1, 1}}}, topics)
+
+	_, err = kcl.ListTopics(context.Background())
+	require.True(t, errors.Is(err, ksqldb.ErrNotFound))
+}
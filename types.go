@@ -21,6 +21,7 @@ import (
 	"context"
 
 	"github.com/thmeitz/ksqldb-go/net"
+	"github.com/thmeitz/ksqldb-go/properties"
 )
 
 type NewClientFactory interface {
@@ -103,6 +104,12 @@ type SessionVariablesMap map[string]interface{}
 
 type PropertyMap map[string]string
 
+// Validate validates the values of the known properties before they are sent
+// to the server, see the properties package
+func (p PropertyMap) Validate() error {
+	return properties.Properties(p).Validate()
+}
+
 // Row represents a row returned from a query
 type Row []interface{}
 
@@ -115,6 +122,34 @@ type Header struct {
 	columns []Column
 }
 
+// NewHeader returns a header with the given query id and columns
+func NewHeader(queryId string, columns []Column) Header {
+	return Header{queryId: queryId, columns: columns}
+}
+
+// QueryId returns the id of the query; empty for pull queries
+func (h Header) QueryId() string {
+	return h.queryId
+}
+
+// Columns returns the column definitions of the query
+func (h Header) Columns() []Column {
+	return h.columns
+}
+
+// RowToMap returns the row as record keyed by the column names.
+// The values are converted to the Go types of the column types, see NewDecodePlan.
+//
+// RowToMap parses the column types on every call, use a DecodePlan
+// to decode many rows.
+func (h Header) RowToMap(row Row) (Record, error) {
+	plan, err := NewDecodePlan(h)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Decode(row)
+}
+
 // Column represents the metadata for a column in a Row
 type Column struct {
 	Name string
@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestHeader_Accessors(t *testing.T) {
+	header := ksqldb.NewHeader("q1", []ksqldb.Column{{Name: "ID", Type: "STRING"}})
+	require.Equal(t, "q1", header.QueryId())
+	require.Equal(t, []ksqldb.Column{{Name: "ID", Type: "STRING"}}, header.Columns())
+}
+
+func TestHeader_RowToMap(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "ID", Type: "STRING"},
+		{Name: "AGE", Type: "INTEGER"},
+		{Name: "BORN", Type: "TIMESTAMP"},
+		{Name: "ADDRESS", Type: "STRUCT<`ZIP` BIGINT>"},
+		{Name: "TAGS", Type: "ARRAY<STRING>"},
+	})
+	record, err := header.RowToMap(ksqldb.Row{"1", float64(3), "2021-11-16T06:00:00.000", map[string]interface{}{"ZIP": float64(12345)}, nil})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Record{
+		"ID":      "1",
+		"AGE":     int32(3),
+		"BORN":    time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC),
+		"ADDRESS": map[string]interface{}{"ZIP": int64(12345)},
+		"TAGS":    nil,
+	}, record)
+}
+
+func TestHeader_RowToMap_Errors(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{{Name: "ID", Type: "ARRAY<"}})
+	_, err := header.RowToMap(ksqldb.Row{nil})
+	require.NotNil(t, err)
+
+	header = ksqldb.NewHeader("", []ksqldb.Column{{Name: "AGE", Type: "INTEGER"}})
+	_, err = header.RowToMap(ksqldb.Row{"old"})
+	require.NotNil(t, err)
+	require.Equal(t, "can't decode column AGE: can't convert string to INTEGER", err.Error())
+}
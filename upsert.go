@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// DEFAULT_UPSERT_VERIFY_TIMEOUT is the default time Upsert waits for the row to be materialized
+	DEFAULT_UPSERT_VERIFY_TIMEOUT = 5 * time.Second
+	// upsertVerifyInterval is the time between the pull queries of the verification
+	upsertVerifyInterval = 100 * time.Millisecond
+)
+
+// UpsertOptions of Upsert
+type UpsertOptions struct {
+	// Verify pulls the row by its key after the insert until it has the
+	// inserted values. Tables are materialized asynchronously, so without
+	// Verify a pull right after Upsert may still return the old row.
+	Verify bool
+	// VerifyTimeout is the time to wait for the row;
+	// 0 is DEFAULT_UPSERT_VERIFY_TIMEOUT
+	VerifyTimeout time.Duration
+}
+
+// Upsert inserts the struct value into the table with a primary key, which
+// replaces the row with the same key. The key columns are the fields tagged
+// with the key option, ex. `ksql:"ID,key"`; they must be populated, else
+// ErrMissingKey is returned. The columns are mapped like with Insert.
+//
+// With options.Verify the row is pulled by its key until it has the inserted
+// values; ErrNotVerified is returned if it doesn't within the timeout.
+func (api *KsqldbClient) Upsert(ctx context.Context, table string, value interface{}, options UpsertOptions) error {
+	fields, err := insertFields(value)
+	if err != nil {
+		return err
+	}
+	names, literals, err := keyValues(value, fields)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(names))
+	for idx := range names {
+		keys[idx] = quoteFieldName(names[idx]) + " = " + literals[idx]
+	}
+
+	if err := api.Insert(ctx, table, value); err != nil {
+		return err
+	}
+	if !options.Verify {
+		return nil
+	}
+
+	timeout := options.VerifyTimeout
+	if timeout <= 0 {
+		timeout = DEFAULT_UPSERT_VERIFY_TIMEOUT
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	sql := fmt.Sprintf("SELECT * FROM %v WHERE %v;", quoteFieldName(table), strings.Join(keys, " AND "))
+	for {
+		ok, err := api.verifyUpsert(ctx, sql, fields)
+		if err != nil {
+			return fmt.Errorf("can't verify upsert into %v: %w", table, err)
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrNotVerified, ctx.Err())
+		case <-time.After(upsertVerifyInterval):
+		}
+	}
+}
+
+// keyValues returns the names and the literals of the key fields of the
+// struct value, see Upsert
+func keyValues(value interface{}, fields []insertColumn) (names []string, literals []string, err error) {
+	for _, f := range fields {
+		if !f.field.hasOption("key") {
+			continue
+		}
+		if f.value.IsZero() {
+			return nil, nil, fmt.Errorf("%w: %v of %T is empty", ErrMissingKey, f.field.name, value)
+		}
+		literal, err := QuoteLiteral(f.value.Interface())
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't quote key %v: %w", f.field.name, err)
+		}
+		names = append(names, f.field.name)
+		literals = append(literals, literal)
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("%w: %T has no field tagged as key", ErrMissingKey, value)
+	}
+	return names, literals, nil
+}
+
+// verifyUpsert returns true if the pulled row has the values of the fields.
+// Fields without a column in the table are not compared.
+func (api *KsqldbClient) verifyUpsert(ctx context.Context, sql string, fields []insertColumn) (bool, error) {
+	header, payload, err := api.Pull(ctx, QueryOptions{Sql: sql, CacheTTL: -1})
+	if errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	record, err := header.RowToMap(payload[0])
+	if err != nil {
+		return false, err
+	}
+	for _, f := range fields {
+		value, ok := record[f.field.name]
+		if !ok {
+			continue
+		}
+		pulled := reflect.New(f.field.typ).Elem()
+		if err := scanValue(pulled, value); err != nil {
+			return false, err
+		}
+		want, err := QuoteLiteral(f.value.Interface())
+		if err != nil {
+			return false, err
+		}
+		got, err := QuoteLiteral(pulled.Interface())
+		if err != nil {
+			return false, err
+		}
+		if got != want {
+			return false, nil
+		}
+	}
+	return true, nil
+}
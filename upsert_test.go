@@ -0,0 +1,64 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+const upsertDogsHeader = `{"queryId":null,"columnNames":["ID","NAME","AGE"],"columnTypes":["STRING","STRING","INTEGER"]}`
+
+func TestUpsert(t *testing.T) {
+	insert := "INSERT INTO DOGS (ID, NAME, AGE, TAGS) VALUES ('1', 'Pluto', NULL, NULL);"
+	pull := "SELECT * FROM DOGS WHERE ID = '1';"
+	m := mockKsql(t,
+		[]string{insert, pull, pull, pull},
+		[]string{`[]`, `[` + upsertDogsHeader + `]`, `[` + upsertDogsHeader + `,["1","Rex",null]]`, `[` + upsertDogsHeader + `,["1","Pluto",null]]`})
+	kcl, _ := ksqldb.NewClient(m)
+
+	// the table is materialized with the third pull
+	err := kcl.Upsert(context.Background(), "DOGS", insertDog{ID: "1", Name: "Pluto"}, ksqldb.UpsertOptions{Verify: true})
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+}
+
+func TestUpsert_NotVerified(t *testing.T) {
+	insert := "INSERT INTO DOGS (ID, NAME, AGE, TAGS) VALUES ('1', 'Pluto', NULL, NULL);"
+	pull := "SELECT * FROM DOGS WHERE ID = '1';"
+	m := mockKsql(t, []string{insert}, []string{`[]`})
+	m.Mock.On("Do", matchStatement(t, pull)).Return(pullResponse(`[`+upsertDogsHeader+`,["1","Rex",null]]`), nil)
+	kcl, _ := ksqldb.NewClient(m)
+
+	err := kcl.Upsert(context.Background(), "DOGS", insertDog{ID: "1", Name: "Pluto"}, ksqldb.UpsertOptions{Verify: true, VerifyTimeout: 300 * time.Millisecond})
+	require.True(t, errors.Is(err, ksqldb.ErrNotVerified))
+}
+
+func TestUpsert_MissingKey(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockKsql(t, nil, nil))
+	err := kcl.Upsert(context.Background(), "DOGS", insertDog{Name: "Pluto"}, ksqldb.UpsertOptions{})
+	require.True(t, errors.Is(err, ksqldb.ErrMissingKey))
+	require.Equal(t, "missing key column: ID of ksqldb_test.insertDog is empty", err.Error())
+
+	err = kcl.Upsert(context.Background(), "DOGS", struct{ Name string }{"Pluto"}, ksqldb.UpsertOptions{})
+	require.True(t, errors.Is(err, ksqldb.ErrMissingKey))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/thmeitz/ksqldb-go/internal"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+// QueryValidation is the result of ValidateQuery
+type QueryValidation struct {
+	// Columns are the columns of the query result
+	Columns []SchemaColumn
+	// Sources are the streams and tables read by the query
+	Sources []string
+	// Sinks are the streams and tables written by the query, ex. of CREATE ... AS SELECT
+	Sinks []string
+	// Warnings of the server
+	Warnings []string
+	// Description is the query description returned by EXPLAIN
+	Description QueryDescription
+}
+
+// InvalidQueryError is returned by ValidateQuery for queries which are
+// rejected by the parser or the server. It matches ErrInvalidQuery.
+type InvalidQueryError struct {
+	// Query is the validated query
+	Query string
+	// Line and Column of syntax errors; 0 for errors of the server
+	Line, Column int
+	// Message describes the error, ex. DOGS does not exist.
+	Message string
+	// Err is the *parser.SqlSyntaxErrorList or the ResponseError of the server
+	Err error
+}
+
+func (e *InvalidQueryError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid query: line %v:%v: %v", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("invalid query: %v", e.Message)
+}
+
+// Unwrap returns the cause of the error
+func (e *InvalidQueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrInvalidQuery
+func (e *InvalidQueryError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// ValidateQuery validates the query with EXPLAIN without executing it and
+// returns its columns and the referenced sources, ex. to verify queries
+// of users before they are subscribed. The sql must be one query, a
+// SELECT, CREATE ... AS SELECT or INSERT INTO ... SELECT.
+//
+// Queries rejected by the parser or the server return an *InvalidQueryError,
+// other errors, ex. connection errors, are returned as they are.
+func (api *KsqldbClient) ValidateQuery(ctx context.Context, sql string) (*QueryValidation, error) {
+	query := strings.TrimSpace(internal.SanitizeQuery(sql))
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	if !strings.HasSuffix(query, ";") {
+		query += ";"
+	}
+	if errs := parser.ParseSql(query); errs != nil {
+		first := (*errs)[0]
+		return nil, &InvalidQueryError{Query: sql, Line: first.Line, Column: first.Column, Message: first.Msg, Err: errs}
+	}
+	nodes, err := parser.Parse(query)
+	if err != nil {
+		return nil, &InvalidQueryError{Query: sql, Message: err.Error(), Err: err}
+	}
+	if len(nodes) != 1 {
+		return nil, &InvalidQueryError{Query: sql, Message: fmt.Sprintf("one query expected, got %v statements", len(nodes))}
+	}
+	if nodes[0].Query == nil {
+		return nil, &InvalidQueryError{Query: sql, Message: fmt.Sprintf("%v is not a query", nodes[0].Kind)}
+	}
+
+	explained, err := api.explain(ctx, ExecOptions{}, query)
+	if err != nil {
+		var respErr ResponseError
+		if errors.As(err, &respErr) {
+			return nil, &InvalidQueryError{Query: sql, Message: respErr.Error(), Err: respErr}
+		}
+		return nil, err
+	}
+
+	desc := *explained.QueryDescription
+	validation := QueryValidation{
+		Sources:     desc.Sources,
+		Sinks:       desc.Sinks,
+		Warnings:    explained.Warnings,
+		Description: desc,
+	}
+	for _, field := range desc.Fields {
+		validation.Columns = append(validation.Columns, SchemaColumn{Name: field.Name, Key: field.Type == "KEY", Schema: field.Schema})
+	}
+	return &validation, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestValidateQuery(t *testing.T) {
+	m := mockKsql(t, []string{"EXPLAIN SELECT ID, NAME FROM DOGS WHERE DOGSIZE = 'large' EMIT CHANGES;"}, []string{
+		`[{"@type":"queryDescription","statementText":"EXPLAIN SELECT ID, NAME FROM DOGS WHERE DOGSIZE = 'large' EMIT CHANGES;",
+		"queryDescription":{"fields":[{"name":"ID","schema":{"type":"STRING"},"type":"KEY"},{"name":"NAME","schema":{"type":"STRING"}}],
+		"sources":["DOGS"],"sinks":[]},"warnings":[]}]`,
+	})
+	kcl, _ := ksqldb.NewClient(m)
+
+	validation, err := kcl.ValidateQuery(context.Background(), "SELECT ID, NAME FROM DOGS WHERE DOGSIZE = 'large' EMIT CHANGES")
+	require.Nil(t, err)
+	m.AssertExpectations(t)
+	require.Equal(t, []string{"DOGS"}, validation.Sources)
+	require.Empty(t, validation.Sinks)
+	require.Equal(t, []ksqldb.SchemaColumn{
+		{Name: "ID", Key: true, Schema: ksqldb.Schema{Type: "STRING"}},
+		{Name: "NAME", Schema: ksqldb.Schema{Type: "STRING"}},
+	}, validation.Columns)
+}
+
+func TestValidateQuery_ServerError(t *testing.T) {
+	m := mockKsql(t, []string{}, []string{})
+	m.On("Do", matchStatement(t, "EXPLAIN SELECT * FROM CATS EMIT CHANGES;")).Return(&http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"@type":"statement_error","error_code":40001,"message":"CATS does not exist."}`)),
+	}, nil)
+	kcl, _ := ksqldb.NewClient(m)
+
+	_, err := kcl.ValidateQuery(context.Background(), "SELECT * FROM CATS EMIT CHANGES;")
+	require.True(t, errors.Is(err, ksqldb.ErrInvalidQuery))
+	require.Equal(t, "invalid query: CATS does not exist.", err.Error())
+	var invalid *ksqldb.InvalidQueryError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, 0, invalid.Line)
+	var respErr ksqldb.ResponseError
+	require.True(t, errors.As(err, &respErr))
+	require.Equal(t, 40001, respErr.ErrCode)
+}
+
+func TestValidateQuery_ClientErrors(t *testing.T) {
+	kcl, _ := ksqldb.NewClient(mockKsql(t, []string{}, []string{}))
+
+	_, err := kcl.ValidateQuery(context.Background(), "SELECT * FROM;")
+	var invalid *ksqldb.InvalidQueryError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, 1, invalid.Line)
+	var syntaxErrs *parser.SqlSyntaxErrorList
+	require.True(t, errors.As(err, &syntaxErrs))
+
+	_, err = kcl.ValidateQuery(context.Background(), "DROP STREAM DOGS;")
+	require.True(t, errors.Is(err, ksqldb.ErrInvalidQuery))
+	require.Equal(t, "invalid query: DROP_STREAM is not a query", err.Error())
+
+	_, err = kcl.ValidateQuery(context.Background(), "SELECT * FROM DOGS EMIT CHANGES; SELECT * FROM CATS EMIT CHANGES;")
+	require.True(t, errors.Is(err, ksqldb.ErrInvalidQuery))
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// CLOSE_VERIFY_TIMEOUT is the time the server has to remove a closed push query from SHOW QUERIES
+	CLOSE_VERIFY_TIMEOUT = 5 * time.Second
+	// closeVerifyInterval is the polling interval of SHOW QUERIES
+	closeVerifyInterval = 250 * time.Millisecond
+)
+
+// EnableCloseVerification enables / disables the verification of closed push
+// queries. With verification the client checks with SHOW QUERIES that the server
+// removed a push query after it was closed, ex. by Stop. A query which is still
+// listed after CLOSE_VERIFY_TIMEOUT fails with a *QueryNotClosedError and its
+// close is retried in the background, see PendingCloses.
+// Disabled by default, it costs a SHOW QUERIES per closed query.
+func (cl *KsqldbClient) EnableCloseVerification(activate bool) {
+	cl.closeVerification = activate
+}
+
+// CloseVerificationEnabled returns true if closed push queries are verified; false otherwise
+func (cl *KsqldbClient) CloseVerificationEnabled() bool {
+	return cl.closeVerification
+}
+
+// VerifyClosed checks with SHOW QUERIES that the server removed the push
+// query, ex. after RawStream.Close. It polls until the query isn't listed
+// anymore; if it is still listed after CLOSE_VERIFY_TIMEOUT or when ctx is
+// done, a *QueryNotClosedError is returned.
+func (api *KsqldbClient) VerifyClosed(ctx context.Context, queryId string) error {
+	ctx, cancel := context.WithTimeout(ctx, CLOSE_VERIFY_TIMEOUT)
+	defer cancel()
+	for {
+		queries, err := api.ListQueries(ctx)
+		if err != nil {
+			return fmt.Errorf("can't verify the close of query %v: %w", queryId, err)
+		}
+		state, listed := "", false
+		for _, q := range queries {
+			if q.ID == queryId {
+				state, listed = q.State, true
+				break
+			}
+		}
+		if !listed {
+			return nil
+		}
+		if state == "" {
+			state = QUERY_STATE_RUNNING
+		}
+		select {
+		case <-ctx.Done():
+			return &QueryNotClosedError{QueryID: queryId, State: state}
+		case <-time.After(closeVerifyInterval):
+		}
+	}
+}
+
+// closeAndVerify closes the push query on the server and verifies the close if enabled
+func (api *KsqldbClient) closeAndVerify(ctx context.Context, queryId string) error {
+	if err := api.closeQuery(ctx, queryId); err != nil || !api.closeVerification {
+		return err
+	}
+	// ctx may be done, ex. the query was cancelled
+	return api.VerifyClosed(detachedContext{ctx}, queryId)
+}
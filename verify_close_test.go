@@ -0,0 +1,111 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	mocknet "github.com/thmeitz/ksqldb-go/mocks/net"
+)
+
+const showQueriesResponse = `[{"@type":"queries","statementText":"SHOW QUERIES;","queries":[
+{"queryString":"SELECT * FROM DOGS EMIT CHANGES;","sinks":[],"sinkKafkaTopics":[],"id":"abc","queryType":"PUSH","state":"RUNNING"},
+{"queryString":"CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS EMIT CHANGES;","sinks":["BIG_DOGS"],"id":"CSAS_BIG_DOGS_3","queryType":"PERSISTENT","state":"RUNNING"}],"warnings":[]}]`
+
+const showQueriesClosedResponse = `[{"@type":"queries","statementText":"SHOW QUERIES;","queries":[
+{"queryString":"CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS EMIT CHANGES;","sinks":["BIG_DOGS"],"id":"CSAS_BIG_DOGS_3","queryType":"PERSISTENT","state":"RUNNING"}],"warnings":[]}]`
+
+// listedQueryMock streams a push query which is listed by the first
+// listings responses of SHOW QUERIES
+func listedQueryMock(listings int32) (*mocknet.HTTPClient, *int32) {
+	var shows int32
+	m := mocknet.HTTPClient{}
+	m.On("GetUrl", mock.Anything).Return(func(endpoint string) string { return "http://localhost" + endpoint })
+	pr, pw := io.Pipe()
+	m.On("Do", mock.Anything).Return(func(req *http.Request) *http.Response {
+		switch req.URL.Path {
+		case ksqldb.CLOSE_QUERY_ENDPOINT:
+			pw.Close()
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(""))}
+		case ksqldb.KSQL_ENDPOINT:
+			body := showQueriesClosedResponse
+			if atomic.AddInt32(&shows, 1) <= listings {
+				body = showQueriesResponse
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}
+		}
+		go func() {
+			_, _ = pw.Write([]byte(streamHeader + "\n"))
+			<-req.Context().Done()
+			pw.CloseWithError(req.Context().Err())
+		}()
+		return &http.Response{StatusCode: http.StatusOK, Body: pr}
+	}, nil)
+	return &m, &shows
+}
+
+func TestListQueries(t *testing.T) {
+	m := mockKsql(t, []string{"SHOW QUERIES;"}, []string{showQueriesResponse})
+	kcl, _ := ksqldb.NewClient(m)
+
+	queries, err := kcl.ListQueries(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, []ksqldb.RunningQuery{
+		{ID: "abc", QueryString: "SELECT * FROM DOGS EMIT CHANGES;", Sinks: []string{}, QueryType: "PUSH", State: "RUNNING"},
+		{ID: "CSAS_BIG_DOGS_3", QueryString: "CREATE STREAM BIG_DOGS AS SELECT * FROM DOGS EMIT CHANGES;", Sinks: []string{"BIG_DOGS"}, QueryType: "PERSISTENT", State: "RUNNING"},
+	}, queries)
+}
+
+func TestStop_CloseVerification(t *testing.T) {
+	m, shows := listedQueryMock(1)
+	kcl, _ := ksqldb.NewClient(m)
+	kcl.EnableCloseVerification(true)
+	require.True(t, kcl.CloseVerificationEnabled())
+
+	q, err := kcl.Subscribe(context.TODO(), "select * from dogs emit changes;", ksqldb.SubscribeOptions{})
+	require.Nil(t, err)
+	_, err = q.Header()
+	require.Nil(t, err)
+	// the query is listed once after the close
+	require.Nil(t, q.Stop())
+	require.Equal(t, int32(2), atomic.LoadInt32(shows))
+	require.Empty(t, kcl.PendingCloses())
+}
+
+func TestVerifyClosed_NotClosed(t *testing.T) {
+	m, _ := listedQueryMock(100)
+	kcl, _ := ksqldb.NewClient(m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+	err := kcl.VerifyClosed(ctx, "abc")
+	require.ErrorIs(t, err, ksqldb.ErrQueryNotClosed)
+	require.Equal(t, &ksqldb.QueryNotClosedError{QueryID: "abc", State: "RUNNING"}, err)
+	require.Equal(t, "query abc is still RUNNING after close", err.Error())
+
+	require.Nil(t, kcl.VerifyClosed(context.Background(), "xyz"))
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// WINDOWSTART is the pseudo column with the start of the window of windowed results
+	WINDOWSTART = "WINDOWSTART"
+	// WINDOWEND is the pseudo column with the end of the window of windowed results
+	WINDOWEND = "WINDOWEND"
+)
+
+// windowBoundLayouts are the layouts of window bounds formatted with TIMESTAMPTOSTRING
+var windowBoundLayouts = []string{
+	KSQL_TIMESTAMP_FORMAT,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	time.RFC3339Nano,
+}
+
+// Window is the window of a windowed aggregation result
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the size of the window
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// isWindowColumn returns the bound name for window columns like WINDOWSTART or WINDOW_START
+func isWindowColumn(name string, bound string) bool {
+	return strings.ReplaceAll(strings.ToUpper(name), "_", "") == bound
+}
+
+// WindowBound converts a WINDOWSTART or WINDOWEND value into time.Time.
+// Numbers are epoch milliseconds, strings are parsed as timestamps.
+func WindowBound(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return epochMillis(int64(v)), nil
+	case int64:
+		return epochMillis(v), nil
+	case int32:
+		return epochMillis(int64(v)), nil
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range windowBoundLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("can't parse window bound %v", v)
+	}
+	return time.Time{}, fmt.Errorf("can't convert %T to window bound", value)
+}
+
+func epochMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+// Windowed returns true if the header has window bound columns
+func (h Header) Windowed() bool {
+	for _, col := range h.columns {
+		if isWindowColumn(col.Name, WINDOWSTART) {
+			return true
+		}
+	}
+	return false
+}
+
+// Window returns the window of the row or nil if the header has no window bound columns
+func (h Header) Window(row Row) (*Window, error) {
+	values := map[string]interface{}{}
+	for idx, col := range h.columns {
+		if idx < len(row) {
+			values[col.Name] = row[idx]
+		}
+	}
+	return windowOf(values)
+}
+
+// Window returns the window of the record or nil if the record has no window bound columns
+func (r Record) Window() (*Window, error) {
+	return windowOf(r)
+}
+
+func windowOf(values map[string]interface{}) (*Window, error) {
+	var start, end interface{}
+	found := false
+	for name, value := range values {
+		switch {
+		case isWindowColumn(name, WINDOWSTART):
+			start, found = value, true
+		case isWindowColumn(name, WINDOWEND):
+			end = value
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	w := Window{}
+	var err error
+	if start != nil {
+		if w.Start, err = WindowBound(start); err != nil {
+			return nil, err
+		}
+	}
+	if end != nil {
+		if w.End, err = WindowBound(end); err != nil {
+			return nil, err
+		}
+	}
+	return &w, nil
+}
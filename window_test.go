@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+var (
+	windowStart = time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)
+	windowEnd   = time.Date(2021, 11, 16, 6, 15, 0, 0, time.UTC)
+)
+
+func TestWindowBound(t *testing.T) {
+	for _, value := range []interface{}{
+		float64(1637042400000),
+		int64(1637042400000),
+		"2021-11-16T06:00:00.000",
+		"2021-11-16 06:00:00",
+		"2021-11-16T06:00:00Z",
+		windowStart,
+	} {
+		bound, err := ksqldb.WindowBound(value)
+		require.Nil(t, err, value)
+		require.True(t, windowStart.Equal(bound), value)
+	}
+
+	_, err := ksqldb.WindowBound("06:00")
+	require.NotNil(t, err)
+	_, err = ksqldb.WindowBound(true)
+	require.NotNil(t, err)
+}
+
+func TestHeader_Window(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "DOG_SIZE", Type: "STRING"},
+		{Name: "WINDOWSTART", Type: "BIGINT"},
+		{Name: "WINDOWEND", Type: "BIGINT"},
+	})
+	require.True(t, header.Windowed())
+
+	window, err := header.Window(ksqldb.Row{"small", float64(1637042400000), float64(1637043300000)})
+	require.Nil(t, err)
+	require.Equal(t, &ksqldb.Window{Start: windowStart, End: windowEnd}, window)
+	require.Equal(t, 15*time.Minute, window.Duration())
+
+	plain := ksqldb.NewHeader("", []ksqldb.Column{{Name: "DOG_SIZE", Type: "STRING"}})
+	require.False(t, plain.Windowed())
+	window, err = plain.Window(ksqldb.Row{"small"})
+	require.Nil(t, err)
+	require.Nil(t, window)
+}
+
+func TestRecord_Window(t *testing.T) {
+	// TIMESTAMPTOSTRING aliases
+	record := ksqldb.Record{"WINDOW_START": "2021-11-16 06:00:00", "WINDOW_END": "2021-11-16 06:15:00"}
+	window, err := record.Window()
+	require.Nil(t, err)
+	require.Equal(t, &ksqldb.Window{Start: windowStart, End: windowEnd}, window)
+
+	_, err = ksqldb.Record{"WINDOWSTART": "later"}.Window()
+	require.NotNil(t, err)
+}
+
+type windowedCount struct {
+	DogSize string    `ksql:"DOG_SIZE"`
+	Start   time.Time `ksql:"WINDOWSTART"`
+	Window  ksqldb.Window
+	Bounds  *ksqldb.Window
+}
+
+func TestRecord_Scan_Window(t *testing.T) {
+	var count windowedCount
+	err := ksqldb.Record{"DOG_SIZE": "small", "WINDOWSTART": int64(1637042400000), "WINDOWEND": int64(1637043300000)}.Scan(&count)
+	require.Nil(t, err)
+	require.Equal(t, windowedCount{
+		DogSize: "small",
+		Start:   windowStart,
+		Window:  ksqldb.Window{Start: windowStart, End: windowEnd},
+		Bounds:  &ksqldb.Window{Start: windowStart, End: windowEnd},
+	}, count)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// WindowedKey is the key of a row of a windowed table
+type WindowedKey struct {
+	// Key are the values of the key columns
+	Key Row
+	// Window of the key; End is zero for keys without window end
+	Window
+}
+
+// WindowedKey returns the windowed key of a row of a query on a windowed
+// table from the key columns and the window bound columns. No keyColumns are
+// the columns before the window bounds, ksqlDB returns the key columns first.
+func (h Header) WindowedKey(row Row, keyColumns ...string) (WindowedKey, error) {
+	if len(row) != len(h.columns) {
+		return WindowedKey{}, fmt.Errorf("got %v values for %v columns", len(row), len(h.columns))
+	}
+	window, err := h.Window(row)
+	if err != nil {
+		return WindowedKey{}, err
+	}
+	if window == nil {
+		return WindowedKey{}, fmt.Errorf("no window bound columns, the source isn't windowed")
+	}
+
+	index := map[string]int{}
+	for idx, col := range h.columns {
+		index[col.Name] = idx
+	}
+	if len(keyColumns) == 0 {
+		for _, col := range h.columns {
+			if isWindowColumn(col.Name, WINDOWSTART) || isWindowColumn(col.Name, WINDOWEND) {
+				break
+			}
+			keyColumns = append(keyColumns, col.Name)
+		}
+	}
+	key := WindowedKey{Key: make(Row, len(keyColumns)), Window: *window}
+	for idx, name := range keyColumns {
+		col, ok := index[name]
+		if !ok {
+			return WindowedKey{}, fmt.Errorf("%w: key column %v not found", ErrMissingKey, name)
+		}
+		key.Key[idx] = row[col]
+	}
+	return key, nil
+}
+
+var (
+	// legacyWindowedKey matches keys like 'small : Window{start=1637042400000 end=1637043300000}'
+	legacyWindowedKey = regexp.MustCompile(`^(.*) : Window\{start=(-?\d+) end=(-?\d+|-)\}$`)
+	// streamsWindowedKey matches keys like '[small@1637042400000/1637043300000]'
+	streamsWindowedKey = regexp.MustCompile(`^\[(.*)@(-?\d+)/(-?\d+|-)\]$`)
+)
+
+// ParseWindowedKey parses the string representation of windowed keys of older
+// ksqlDB versions and Kafka Streams, ex. 'small : Window{start=1637042400000 end=-}'
+// and '[small@1637042400000/1637043300000]'. The key is a single string.
+func ParseWindowedKey(s string) (WindowedKey, error) {
+	match := legacyWindowedKey.FindStringSubmatch(s)
+	if match == nil {
+		match = streamsWindowedKey.FindStringSubmatch(s)
+	}
+	if match == nil {
+		return WindowedKey{}, fmt.Errorf("invalid windowed key: %v", s)
+	}
+	start, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return WindowedKey{}, fmt.Errorf("invalid windowed key: %v: %w", s, err)
+	}
+	key := WindowedKey{Key: Row{match[1]}, Window: Window{Start: epochMillis(start)}}
+	if match[3] != "-" {
+		end, err := strconv.ParseInt(match[3], 10, 64)
+		if err != nil {
+			return WindowedKey{}, fmt.Errorf("invalid windowed key: %v: %w", s, err)
+		}
+		key.End = epochMillis(end)
+	}
+	return key, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+)
+
+func TestHeader_WindowedKey(t *testing.T) {
+	header := ksqldb.NewHeader("", []ksqldb.Column{
+		{Name: "DOG_SIZE", Type: "STRING"},
+		{Name: "WINDOWSTART", Type: "BIGINT"},
+		{Name: "WINDOWEND", Type: "BIGINT"},
+		{Name: "DOGS_CT", Type: "BIGINT"},
+	})
+	key, err := header.WindowedKey(ksqldb.Row{"small", float64(1637042400000), float64(1637043300000), float64(23)})
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Row{"small"}, key.Key)
+	require.Equal(t, time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC), key.Start)
+	require.Equal(t, 15*time.Minute, key.Duration())
+
+	key, err = header.WindowedKey(ksqldb.Row{"small", float64(1637042400000), nil, float64(23)}, "DOGS_CT", "DOG_SIZE")
+	require.Nil(t, err)
+	require.Equal(t, ksqldb.Row{float64(23), "small"}, key.Key)
+	require.True(t, key.End.IsZero())
+
+	_, err = header.WindowedKey(ksqldb.Row{"small", float64(1637042400000), nil, float64(23)}, "ID")
+	require.Equal(t, "missing key column: key column ID not found", err.Error())
+	_, err = header.WindowedKey(ksqldb.Row{"small"})
+	require.Equal(t, "got 1 values for 4 columns", err.Error())
+	_, err = ksqldb.NewHeader("", []ksqldb.Column{{Name: "ID", Type: "STRING"}}).WindowedKey(ksqldb.Row{"1"})
+	require.Equal(t, "no window bound columns, the source isn't windowed", err.Error())
+}
+
+func TestParseWindowedKey(t *testing.T) {
+	start := time.Date(2021, 11, 16, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		key  string
+		want ksqldb.WindowedKey
+	}{
+		{"small : Window{start=1637042400000 end=1637043300000}", ksqldb.WindowedKey{Key: ksqldb.Row{"small"}, Window: ksqldb.Window{Start: start, End: start.Add(15 * time.Minute)}}},
+		{"small : Window{start=1637042400000 end=-}", ksqldb.WindowedKey{Key: ksqldb.Row{"small"}, Window: ksqldb.Window{Start: start}}},
+		{"[a : b@1637042400000/1637043300000]", ksqldb.WindowedKey{Key: ksqldb.Row{"a : b"}, Window: ksqldb.Window{Start: start, End: start.Add(15 * time.Minute)}}},
+	}
+	for _, tt := range tests {
+		key, err := ksqldb.ParseWindowedKey(tt.key)
+		require.Nil(t, err)
+		require.Equal(t, tt.want, key)
+	}
+	_, err := ksqldb.ParseWindowedKey("small")
+	require.Equal(t, "invalid windowed key: small", err.Error())
+}
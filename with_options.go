@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SourceOptions are the WITH options of CREATE STREAM|TABLE.
+// Empty options are not rendered, so the server defaults apply.
+type SourceOptions struct {
+	// KafkaTopic is the topic of the source, it is required
+	KafkaTopic string
+	// Partitions and Replicas of the topic if it is created
+	Partitions int
+	Replicas   int
+	// KeyFormat and ValueFormat, ex. JSON, AVRO, PROTOBUF, KAFKA
+	KeyFormat   string
+	ValueFormat string
+	// KeySchemaId and ValueSchemaId are the schema registry ids of the key and value schemas
+	KeySchemaId   int
+	ValueSchemaId int
+	// TimestampColumn is the column used as ROWTIME
+	TimestampColumn string
+	// TimestampFormat is the format of a STRING TimestampColumn
+	TimestampFormat string
+	// WrapSingleValue controls wrapping of single value columns; nil is the server default
+	WrapSingleValue *bool
+	// ValueDelimiter is the delimiter of the DELIMITED value format
+	ValueDelimiter string
+	// WindowType and WindowSize of windowed keys, ex. TUMBLING and time.Hour
+	WindowType string
+	WindowSize time.Duration
+}
+
+// SinkOptions are the WITH options of CREATE STREAM|TABLE AS SELECT.
+// Empty options are not rendered, so the server defaults apply.
+type SinkOptions struct {
+	// KafkaTopic is the sink topic; the default is the name of the sink
+	KafkaTopic string
+	// Partitions and Replicas of the sink topic if it is created
+	Partitions int
+	Replicas   int
+	// KeyFormat and ValueFormat, ex. JSON, AVRO, PROTOBUF, KAFKA
+	KeyFormat   string
+	ValueFormat string
+	// TimestampColumn is the column used as ROWTIME of the sink
+	TimestampColumn string
+	// TimestampFormat is the format of a STRING TimestampColumn
+	TimestampFormat string
+	// WrapSingleValue controls wrapping of single value columns; nil is the server default
+	WrapSingleValue *bool
+	// ValueDelimiter is the delimiter of the DELIMITED value format
+	ValueDelimiter string
+}
+
+// String returns the WITH clause of the options, ex. WITH (KAFKA_TOPIC='dogs', PARTITIONS=3),
+// empty if no option is set
+func (o SourceOptions) String() string {
+	var w withClause
+	w.string("KAFKA_TOPIC", o.KafkaTopic)
+	w.int("PARTITIONS", o.Partitions)
+	w.int("REPLICAS", o.Replicas)
+	w.string("KEY_FORMAT", o.KeyFormat)
+	w.string("VALUE_FORMAT", o.ValueFormat)
+	w.int("KEY_SCHEMA_ID", o.KeySchemaId)
+	w.int("VALUE_SCHEMA_ID", o.ValueSchemaId)
+	w.string("TIMESTAMP", o.TimestampColumn)
+	w.string("TIMESTAMP_FORMAT", o.TimestampFormat)
+	w.bool("WRAP_SINGLE_VALUE", o.WrapSingleValue)
+	w.string("VALUE_DELIMITER", o.ValueDelimiter)
+	w.string("WINDOW_TYPE", o.WindowType)
+	w.duration("WINDOW_SIZE", o.WindowSize)
+	return w.String()
+}
+
+// String returns the WITH clause of the options, ex. WITH (KAFKA_TOPIC='dogs', PARTITIONS=3),
+// empty if no option is set
+func (o SinkOptions) String() string {
+	var w withClause
+	w.string("KAFKA_TOPIC", o.KafkaTopic)
+	w.int("PARTITIONS", o.Partitions)
+	w.int("REPLICAS", o.Replicas)
+	w.string("KEY_FORMAT", o.KeyFormat)
+	w.string("VALUE_FORMAT", o.ValueFormat)
+	w.string("TIMESTAMP", o.TimestampColumn)
+	w.string("TIMESTAMP_FORMAT", o.TimestampFormat)
+	w.bool("WRAP_SINGLE_VALUE", o.WrapSingleValue)
+	w.string("VALUE_DELIMITER", o.ValueDelimiter)
+	return w.String()
+}
+
+// withClause renders the set options of a WITH clause in order
+type withClause []string
+
+func (w *withClause) string(name string, value string) {
+	if value != "" {
+		*w = append(*w, name+"="+QuoteString(value))
+	}
+}
+
+func (w *withClause) int(name string, value int) {
+	if value > 0 {
+		*w = append(*w, fmt.Sprintf("%v=%v", name, value))
+	}
+}
+
+func (w *withClause) bool(name string, value *bool) {
+	if value != nil {
+		*w = append(*w, fmt.Sprintf("%v=%v", name, *value))
+	}
+}
+
+// duration renders the value as string like 1 HOUR; durations which are no
+// multiple of a millisecond are rounded
+func (w *withClause) duration(name string, value time.Duration) {
+	if value > 0 {
+		size, _ := ksqlDuration(value.Round(time.Millisecond))
+		w.string(name, size)
+	}
+}
+
+func (w withClause) String() string {
+	if len(w) == 0 {
+		return ""
+	}
+	return "WITH (" + strings.Join(w, ", ") + ")"
+}
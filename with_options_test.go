@@ -0,0 +1,44 @@
+/*
+Copyright © 2021 Thomas Meitz
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ksqldb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thmeitz/ksqldb-go"
+	"github.com/thmeitz/ksqldb-go/parser"
+)
+
+func TestSinkOptions_String(t *testing.T) {
+	require.Equal(t, "", ksqldb.SinkOptions{}.String())
+	wrap := false
+	options := ksqldb.SinkOptions{KafkaTopic: "dogs", Partitions: 3, Replicas: 2, KeyFormat: "KAFKA", ValueFormat: "AVRO",
+		TimestampColumn: "BORN", TimestampFormat: "yyyy-MM-dd", WrapSingleValue: &wrap}
+	require.Equal(t, "WITH (KAFKA_TOPIC='dogs', PARTITIONS=3, REPLICAS=2, KEY_FORMAT='KAFKA', VALUE_FORMAT='AVRO', "+
+		"TIMESTAMP='BORN', TIMESTAMP_FORMAT='yyyy-MM-dd', WRAP_SINGLE_VALUE=false)", options.String())
+}
+
+func TestSourceOptions_String(t *testing.T) {
+	require.Equal(t, "", ksqldb.SourceOptions{}.String())
+	options := ksqldb.SourceOptions{KafkaTopic: "dogs", KeyFormat: "AVRO", ValueFormat: "DELIMITED", KeySchemaId: 1,
+		ValueSchemaId: 2, ValueDelimiter: "TAB", WindowType: "TUMBLING", WindowSize: 90 * time.Minute}
+	require.Equal(t, "WITH (KAFKA_TOPIC='dogs', KEY_FORMAT='AVRO', VALUE_FORMAT='DELIMITED', KEY_SCHEMA_ID=1, VALUE_SCHEMA_ID=2, "+
+		"VALUE_DELIMITER='TAB', WINDOW_TYPE='TUMBLING', WINDOW_SIZE='90 MINUTES')", options.String())
+	require.Nil(t, parser.ParseSql("CREATE STREAM DOGS (ID STRING KEY, NAME STRING) "+options.String()+";"))
+}